@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/decisiondiff"
+)
+
+const compareDecisionsCommand = "compare-decisions"
+
+// runCompareDecisions parses the comparison flags from args, replays the
+// fixture corpus against both admission controllers and exits with a non-zero
+// code if their decisions differ, so that it can gate the rollout of a new
+// version of the admission controller.
+func runCompareDecisions(args []string) {
+	var config decisiondiff.Config
+
+	app := kingpin.New(compareDecisionsCommand, "Replay admission review fixtures against two admission controllers and report differing decisions.")
+	app.Flag("baseline", "Base URL of the admission controller currently running, e.g. https://localhost:8443").Required().StringVar(&config.Baseline)
+	app.Flag("candidate", "Base URL of the admission controller about to be rolled out").Required().StringVar(&config.Candidate)
+	app.Flag("compare-messages", "Also report differing messages of denied requests").Default("false").BoolVar(&config.CompareMessages)
+	app.Flag("corpus", "Directory of admission review fixtures, placed in subdirectories named after the webhook path like validate/awscluster").Required().StringVar(&config.Corpus)
+	app.Flag("insecure", "Skip TLS certificate verification of both admission controllers").Default("false").BoolVar(&config.Insecure)
+	app.Flag("timeout", "Timeout of a single request").Default("10s").DurationVar(&config.Timeout)
+	kingpin.MustParse(app.Parse(args))
+
+	result, err := decisiondiff.Run(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	result.Report(os.Stdout)
+	if len(result.Differences) > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	securityv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/security/v1alpha1"
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
@@ -10,6 +12,11 @@ import (
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 	restclient "k8s.io/client-go/rest"
 	apiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/clustercontext"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/ec2offering"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/servicequota"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/tracing"
 )
 
 const (
@@ -21,20 +28,92 @@ type Config struct {
 	Address                  string
 	AdminGroup               string
 	AllTargetGroup           string
+	APIWhitelistMaxEntries   int
+	AuditLogPath             string
+	AutoPatchUpgrade         bool
 	MetricsAddress           string
 	AvailabilityZones        string
+	AWSTagsMaxEntries        int
+	BypassGroups             []string
+	BypassServiceAccounts    []string
+	BypassUsers              []string
+	CertCheckInterval        time.Duration
+	CertExpiryThreshold      time.Duration
 	CertFile                 string
+	CertReloadInterval       time.Duration
+	ClusterContextResync     time.Duration
+	CRDCheckInterval         time.Duration
+	DenyOrphans              bool
 	DockerCIDR               string
+	EC2OfferingsTTL          time.Duration
 	Endpoint                 string
+	FailOpenHandlers         []string
+	GenericResources         []string
+	HandlerTimeout           time.Duration
+	HandlerTimeoutOverrides  []string
+	HandlerTimeoutPolicy     string
+	InstanceTypePolicy       string
 	IPAMNetworkCIDR          string
 	KubernetesClusterIPRange string
+	MachinePoolMaxReplicas   int
+	MachinePoolMinReplicas   int
+	MasterAZCounts           string
+	MasterInstanceFamilies   string
+	MasterInstanceType       string
 	MasterInstanceTypes      string
+	MasterMinCPU             int
+	MasterMinMemory          int
+	MaxConcurrentRequests    int
+	MirrorEndpoint           string
+	MirrorInsecure           bool
+	NetworkPoolPrefixMax     int
+	NetworkPoolPrefixMin     int
+	NodePoolDefaultMax       int
+	NodePoolDefaultMin       int
+	NodePoolHeartbeatTimeout time.Duration
+	NodePoolMaxCount         int
+	NodePoolMaxScaling       int
+	NodePoolMinCPU           int
+	NodePoolMinMemory        int
+	NodePoolMinScaling       int
+	NodePoolSpotDraining     bool
+	NodePoolSubnetSize       int
+	NodePoolVolumeSizeMax    int
+	NodePoolVolumeSizeMin    int
+	PanicPolicy              string
 	PodCIDR                  string
+	PodSecurityDefaultLevel  string
 	PodSubnet                string
+	RateLimitBurst           int
+	RateLimits               []string
+	ReadinessCacheTTL        time.Duration
 	Region                   string
+	RequiredClusterLabels    []string
+	ReservedCIDRs            string
+	ServiceQuotaPolicy       string
+	ServiceQuotaTTL          time.Duration
+	ShutdownDelay            time.Duration
+	ShutdownTimeout          time.Duration
+	StatusWriters            []string
+	TracingEndpoint          string
+	TracingInsecure          bool
+	TracingSampleRatio       float64
+	UnknownAnnotationPolicy  string
+	UpgradeCooldown          time.Duration
+	UpgradeReadinessChecks   bool
+	WebhookCAFile            string
+	WebhookConfigName        string
+	WebhookFailurePolicy     string
+	WebhookNamespaceSelector string
+	WebhookReconcileInterval time.Duration
+	WebhookServiceName       string
+	WebhookServiceNamespace  string
 	WorkerInstanceTypes      string
 	Logger                   micrologger.Logger
 	K8sClient                k8sclient.Interface
+	ClusterContext           *clustercontext.Store
+	InstanceTypeOfferings    ec2offering.Interface
+	ServiceQuotas            servicequota.Interface
 	KeyFile                  string
 }
 
@@ -59,6 +138,7 @@ func Parse() (Config, error) {
 		if err != nil {
 			return Config{}, microerror.Mask(err)
 		}
+		restConfig.WrapTransport = tracing.WrapTransport
 		c := k8sclient.ClientsConfig{
 			SchemeBuilder: k8sclient.SchemeBuilder{
 				apiv1alpha2.AddToScheme,
@@ -81,18 +161,87 @@ func Parse() (Config, error) {
 	kingpin.Flag("address", "The address to listen on").Default(defaultAddress).StringVar(&config.Address)
 	kingpin.Flag("admin-group", "Tenant Admin Target Group").Required().StringVar(&config.AdminGroup)
 	kingpin.Flag("all-target-group", "View All Target Group").Required().StringVar(&config.AllTargetGroup)
+	kingpin.Flag("auto-patch-upgrade", "Use the newest patch release of the requested minor release for all clusters").Default("false").BoolVar(&config.AutoPatchUpgrade)
+	kingpin.Flag("audit-log-path", "File the structured audit log of all admission decisions is appended to, or - for the standard output. Disabled when empty.").Default("").StringVar(&config.AuditLogPath)
 	kingpin.Flag("availability-zones", "List of AWS availability zones").Required().StringVar(&config.AvailabilityZones)
+	kingpin.Flag("deny-orphans", "Deny the creation of AWSCluster, AWSControlPlane and AWSMachineDeployment objects whose Cluster does not exist").Default("false").BoolVar(&config.DenyOrphans)
 	kingpin.Flag("docker-cidr", "Default CIDR from Docker").Required().StringVar(&config.DockerCIDR)
+	kingpin.Flag("api-whitelist-max-entries", "Maximum number of CIDRs in each API whitelist annotation of a cluster. Unlimited when 0.").Default("20").IntVar(&config.APIWhitelistMaxEntries)
+	kingpin.Flag("aws-tags-max-entries", "Maximum number of custom AWS tags of a cluster. Has to leave room for the tags set by the operators within the AWS limit of 50 tags. Unlimited when 0.").Default("30").IntVar(&config.AWSTagsMaxEntries)
+	kingpin.Flag("bypass-group", "Group whose members skip mutation and validation, e.g. a break-glass group. Can be repeated.").StringsVar(&config.BypassGroups)
+	kingpin.Flag("bypass-service-account", "Service account like giantswarm/cluster-operator which skips mutation and validation. Can be repeated.").StringsVar(&config.BypassServiceAccounts)
+	kingpin.Flag("bypass-user", "User who skips mutation and validation. Can be repeated.").StringsVar(&config.BypassUsers)
+	kingpin.Flag("cert-check-interval", "Interval in which the expiry of the serving certificate is checked").Default("1h").DurationVar(&config.CertCheckInterval)
+	kingpin.Flag("cert-expiry-threshold", "Remaining validity of the serving certificate below which it is reported as near expiry").Default("168h").DurationVar(&config.CertExpiryThreshold)
+	kingpin.Flag("cert-reload-interval", "Interval in which the serving certificate is reloaded in case file change notifications are lost. Rotated certificates are reloaded on change notifications in any case. Disabled when 0.").Default("1m").DurationVar(&config.CertReloadInterval)
+	kingpin.Flag("cluster-context-resync", "Interval in which the in-memory context of all clusters is recomputed. The context is kept up to date by watching clusters and node pools and disabled when 0.").Default("10m").DurationVar(&config.ClusterContextResync)
+	kingpin.Flag("crd-check-interval", "Interval in which installed CRD versions are detected. Handlers for missing CRD versions admit requests without processing them. Only checked on startup when 0.").Default("5m").DurationVar(&config.CRDCheckInterval)
+	kingpin.Flag("ec2-offerings-ttl", "Interval in which the EC2 instance type offerings of the region are refreshed. Instance types of control planes and node pools are checked against them, which needs the ec2:DescribeInstanceTypeOfferings permission. Disabled when 0.").Default("0s").DurationVar(&config.EC2OfferingsTTL)
 	kingpin.Flag("endpoint", "Default kubernetes endpoint").Required().StringVar(&config.Endpoint)
+	kingpin.Flag("fail-open", "Handler like validating/awsmachinedeployment which admits requests when its dependencies like the Kubernetes API fail. Denials of its checks still apply. Can be repeated.").StringsVar(&config.FailOpenHandlers)
+	kingpin.Flag("generic-resource", `Resource kind validated with generic policies, as JSON object like {"group":"infrastructure.giantswarm.io","version":"v1alpha2","kind":"AWSFoo","resource":"awsfoos","policies":["labels","release"]}. Can be repeated.`).StringsVar(&config.GenericResources)
+	kingpin.Flag("handler-timeout", "Time every handler has to decide before the request is answered according to --handler-timeout-policy. Should be below the webhook timeout of the API server. Unlimited when 0.").Default("8s").DurationVar(&config.HandlerTimeout)
+	kingpin.Flag("handler-timeout-override", "Timeout of a single handler like validating/awsmachinedeployment=5s. Can be repeated.").StringsVar(&config.HandlerTimeoutOverrides)
+	kingpin.Flag("handler-timeout-policy", "Handling of requests whose handler did not decide in time, either allow or deny").Default("allow").EnumVar(&config.HandlerTimeoutPolicy, "allow", "deny")
+	kingpin.Flag("instance-type-policy", "ConfigMap holding the instance type policy as namespace/name. Disabled when empty.").Default("").StringVar(&config.InstanceTypePolicy)
 	kingpin.Flag("ipam-network-cidr", "Default CIDR from tenant cluster").Required().StringVar(&config.IPAMNetworkCIDR)
 	kingpin.Flag("kubernetes-cluster-ip-range", "Default CIDR from Kubernetes").Required().StringVar(&config.KubernetesClusterIPRange)
+	kingpin.Flag("machine-pool-max-replicas", "Maximum number of replicas of a MachinePool").Default("100").IntVar(&config.MachinePoolMaxReplicas)
+	kingpin.Flag("machine-pool-min-replicas", "Minimum number of replicas of a MachinePool").Default("0").IntVar(&config.MachinePoolMinReplicas)
+	kingpin.Flag("master-az-counts", "List of allowed numbers of master availability zones, e.g. 1,2 in regions with only two AZs").Default("1,3").StringVar(&config.MasterAZCounts)
+	kingpin.Flag("master-instance-families", "List of EC2 instance families suitable for masters, e.g. m5,r5. Master instance types other than the default have to be of one of these families. Disabled when empty.").Default("").StringVar(&config.MasterInstanceFamilies)
+	kingpin.Flag("master-instance-type", "Default AWS master instance type of the installation. Falls back to m5.xlarge when empty.").Default("").StringVar(&config.MasterInstanceType)
 	kingpin.Flag("master-instance-types", "List of AWS master instance types").Required().StringVar(&config.MasterInstanceTypes)
+	kingpin.Flag("master-min-cpu", "Minimum number of vCPUs of master instance types. Disabled when 0.").Default("0").IntVar(&config.MasterMinCPU)
+	kingpin.Flag("master-min-memory", "Minimum memory of master instance types in GiB. Disabled when 0.").Default("0").IntVar(&config.MasterMinMemory)
+	kingpin.Flag("max-concurrent-requests", "Maximum number of admission requests processed at the same time. Further requests are answered with 429 Too Many Requests. Unlimited when 0.").Default("100").IntVar(&config.MaxConcurrentRequests)
 	kingpin.Flag("metrics-address", "The metrics address for Prometheus").Default(defaultMetricsAddress).StringVar(&config.MetricsAddress)
+	kingpin.Flag("mirror-endpoint", "Base URL of a staging admission controller which receives sanitized copies of all admission requests. Disabled when empty.").Default("").StringVar(&config.MirrorEndpoint)
+	kingpin.Flag("mirror-insecure", "Skip TLS certificate verification of the mirror endpoint").Default("false").BoolVar(&config.MirrorInsecure)
+	kingpin.Flag("network-pool-prefix-max", "Largest prefix length of NetworkPool CIDR blocks, so that pools fit at least one cluster network. Disabled when 0.").Default("0").IntVar(&config.NetworkPoolPrefixMax)
+	kingpin.Flag("network-pool-prefix-min", "Smallest prefix length of NetworkPool CIDR blocks, limiting the size of pools. Disabled when 0.").Default("0").IntVar(&config.NetworkPoolPrefixMin)
+	kingpin.Flag("node-pool-default-max-scaling", "Scaling max of node pools which are created without scaling limits. Defaulting is disabled when 0.").Default("10").IntVar(&config.NodePoolDefaultMax)
+	kingpin.Flag("node-pool-default-min-scaling", "Scaling min of node pools which are created without scaling limits").Default("3").IntVar(&config.NodePoolDefaultMin)
+	kingpin.Flag("node-pool-heartbeat-timeout", "Lifecycle hook heartbeat timeout of node pools which are created without the alpha.aws.giantswarm.io/lifecycle-hook-heartbeat-timeout annotation. Not defaulted when 0.").Default("0s").DurationVar(&config.NodePoolHeartbeatTimeout)
+	kingpin.Flag("node-pool-max-count", "Maximum number of node pools per cluster, which is limited by the partitioning of the cluster network into subnets. Disabled when 0.").Default("0").IntVar(&config.NodePoolMaxCount)
+	kingpin.Flag("node-pool-max-scaling", "Maximum number of nodes of a node pool. Disabled when 0.").Default("0").IntVar(&config.NodePoolMaxScaling)
+	kingpin.Flag("node-pool-min-cpu", "Minimum number of vCPUs of node pool instance types. Disabled when 0.").Default("0").IntVar(&config.NodePoolMinCPU)
+	kingpin.Flag("node-pool-min-memory", "Minimum memory of node pool instance types in GiB. Disabled when 0.").Default("0").IntVar(&config.NodePoolMinMemory)
+	kingpin.Flag("node-pool-min-scaling", "Minimum number of nodes a node pool has to be allowed to scale down to").Default("0").IntVar(&config.NodePoolMinScaling)
+	kingpin.Flag("node-pool-spot-draining", "Enable spot interruption draining for node pools with spot instances which are created without the alpha.aws.giantswarm.io/spot-interruption-draining annotation").Default("false").BoolVar(&config.NodePoolSpotDraining)
+	kingpin.Flag("node-pool-subnet-size", "Prefix length of node pool subnets, which are split across the availability zones of a node pool. Subnet capacity is not checked when 0.").Default("0").IntVar(&config.NodePoolSubnetSize)
+	kingpin.Flag("node-pool-volume-size-max", "Maximum size of node pool docker, kubelet and logging volumes in GB. Disabled when 0.").Default("1000").IntVar(&config.NodePoolVolumeSizeMax)
+	kingpin.Flag("node-pool-volume-size-min", "Minimum size of node pool docker, kubelet and logging volumes in GB").Default("10").IntVar(&config.NodePoolVolumeSizeMin)
+	kingpin.Flag("panic-policy", "Handling of requests whose handler panicked, either allow or deny").Default("allow").EnumVar(&config.PanicPolicy, "allow", "deny")
 	kingpin.Flag("pod-cidr", "Default pod CIDR").Required().StringVar(&config.PodCIDR)
+	kingpin.Flag("pod-security-default-level", "Pod Security level enforced by default in new workload clusters, either privileged, baseline or restricted").Default("baseline").EnumVar(&config.PodSecurityDefaultLevel, "privileged", "baseline", "restricted")
 	kingpin.Flag("pod-subnet", "Default pod subnet").Required().StringVar(&config.PodSubnet)
+	kingpin.Flag("rate-limit", "Requests per second of a handler like validating/awsmachinedeployment=10. Further requests are denied. Can be repeated.").StringsVar(&config.RateLimits)
+	kingpin.Flag("rate-limit-burst", "Number of requests a rate limited handler accepts at once above its rate").Default("20").IntVar(&config.RateLimitBurst)
+	kingpin.Flag("readiness-cache-ttl", "Time the reachability of the Kubernetes API is cached for readiness probes").Default("10s").DurationVar(&config.ReadinessCacheTTL)
 	kingpin.Flag("region", "Default cluster region").Required().StringVar(&config.Region)
+	kingpin.Flag("required-cluster-label", `Label which has to be set on new clusters, as JSON object like {"key":"cost-center","pattern":"^[0-9]+$","default":"0000"}. Can be repeated.`).StringsVar(&config.RequiredClusterLabels)
+	kingpin.Flag("reserved-cidrs", "List of additional CIDRs reserved by the installation, e.g. the management cluster VPC. NetworkPools must not overlap with them.").Default("").StringVar(&config.ReservedCIDRs)
+	kingpin.Flag("service-quota-policy", "Handling of node pools whose scaling max exceeds the remaining EC2 On-Demand instance quota of the account, either warn or deny").Default("warn").EnumVar(&config.ServiceQuotaPolicy, "warn", "deny")
+	kingpin.Flag("service-quota-ttl", "Interval in which the EC2 On-Demand instance quotas of the account and their usage are refreshed. Needs the servicequotas:GetServiceQuota and ec2:DescribeInstances permissions. Disabled when 0.").Default("0s").DurationVar(&config.ServiceQuotaTTL)
+	kingpin.Flag("shutdown-delay", "Time readiness fails after a termination signal before new connections are refused, so that the API server stops routing requests to the pod").Default("5s").DurationVar(&config.ShutdownDelay)
+	kingpin.Flag("shutdown-timeout", "Time in-flight admission requests are drained for on shutdown. Should be at least the webhook timeout.").Default("10s").DurationVar(&config.ShutdownTimeout)
+	kingpin.Flag("status-writer", "Service account like giantswarm/aws-operator which may update the status of infrastructure objects. Versioned accounts like giantswarm/aws-operator-9-3-0 are allowed as well. Can be repeated.").Default("giantswarm/aws-operator", "giantswarm/cluster-operator").StringsVar(&config.StatusWriters)
 	kingpin.Flag("tls-cert-file", "File containing the certificate for HTTPS").Required().StringVar(&config.CertFile)
 	kingpin.Flag("tls-key-file", "File containing the private key for HTTPS").Required().StringVar(&config.KeyFile)
+	kingpin.Flag("tracing-endpoint", "Host and port of the OTLP HTTP receiver admission traces are exported to. Disabled when empty.").Default("").StringVar(&config.TracingEndpoint)
+	kingpin.Flag("tracing-insecure", "Export admission traces without TLS").Default("false").BoolVar(&config.TracingInsecure)
+	kingpin.Flag("tracing-sample-ratio", "Share of admission requests which are traced when the API server did not decide about sampling, between 0 and 1").Default("0.1").Float64Var(&config.TracingSampleRatio)
+	kingpin.Flag("unknown-annotation-policy", "Handling of unknown alpha.aws.giantswarm.io and aws.giantswarm.io annotations, either warn or deny").Default("warn").EnumVar(&config.UnknownAnnotationPolicy, "warn", "deny")
+	kingpin.Flag("upgrade-cooldown", "Minimum time between two release version changes of a cluster. Disabled when 0.").Default("0s").DurationVar(&config.UpgradeCooldown)
+	kingpin.Flag("upgrade-readiness-checks", "Deny cluster upgrades when the cluster infrastructure or its node pools report not being ready.").Default("false").BoolVar(&config.UpgradeReadinessChecks)
+	kingpin.Flag("webhook-ca-file", "File containing the CA bundle injected into the self-managed webhook configurations").Default("").StringVar(&config.WebhookCAFile)
+	kingpin.Flag("webhook-config-name", "Name of the MutatingWebhookConfiguration and ValidatingWebhookConfiguration which are created and updated from the registered webhooks. Disabled when empty.").Default("").StringVar(&config.WebhookConfigName)
+	kingpin.Flag("webhook-failure-policy", "Failure policy of the self-managed webhook configurations, either Ignore or Fail").Default("Ignore").EnumVar(&config.WebhookFailurePolicy, "Ignore", "Fail")
+	kingpin.Flag("webhook-namespace-selector", "Label selector of the namespaces whose resources are sent to the self-managed webhooks. All namespaces when empty.").Default("").StringVar(&config.WebhookNamespaceSelector)
+	kingpin.Flag("webhook-reconcile-interval", "Interval in which the self-managed webhook configurations are reconciled, which reverts manual changes and injects rotated CA bundles").Default("5m").DurationVar(&config.WebhookReconcileInterval)
+	kingpin.Flag("webhook-service-name", "Name of the service the self-managed webhook configurations send requests to").Default("").StringVar(&config.WebhookServiceName)
+	kingpin.Flag("webhook-service-namespace", "Namespace of the service the self-managed webhook configurations send requests to").Default("").StringVar(&config.WebhookServiceNamespace)
 	kingpin.Flag("worker-instance-types", "List of AWS worker instance types").Required().StringVar(&config.WorkerInstanceTypes)
 
 	kingpin.Parse()
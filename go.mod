@@ -3,9 +3,10 @@ module github.com/giantswarm/aws-admission-controller/v2
 go 1.15
 
 require (
+	github.com/aws/aws-sdk-go v1.27.0
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/dylanmei/iso8601 v0.1.0
-	github.com/dyson/certman v0.2.1
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/giantswarm/apiextensions/v2 v2.6.2
 	github.com/giantswarm/apiextensions/v3 v3.27.0
 	github.com/giantswarm/backoff v0.2.0
@@ -16,7 +17,11 @@ require (
 	github.com/giantswarm/to v0.3.0
 	github.com/google/go-cmp v0.5.6
 	github.com/prometheus/client_golang v1.10.0
-	github.com/stretchr/testify v1.6.1 // indirect
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.24.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
 	k8s.io/api v0.18.19
 	k8s.io/apiextensions-apiserver v0.18.19
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"gopkg.in/alecthomas/kingpin.v2"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/loadtest"
+)
+
+const loadTestCommand = "loadtest"
+
+// runLoadTest parses the load test flags from args and runs the load test
+// against the target webhook. It is used for capacity planning and does not
+// need any of the flags of the admission controller itself.
+func runLoadTest(args []string) {
+	var config loadtest.Config
+	var objectFile string
+	var operation string
+
+	app := kingpin.New(loadTestCommand, "Send synthetic admission reviews to a webhook endpoint and report latencies and error rates.")
+	app.Flag("concurrency", "Number of requests in flight at the same time").Default("10").IntVar(&config.Concurrency)
+	app.Flag("insecure", "Skip TLS certificate verification of the target").Default("false").BoolVar(&config.Insecure)
+	app.Flag("object-file", "File containing the JSON object embedded in the admission reviews").Default("").StringVar(&objectFile)
+	app.Flag("operation", "Admission operation of the admission reviews").Default(string(admissionv1.Create)).EnumVar(&operation, string(admissionv1.Create), string(admissionv1.Update), string(admissionv1.Delete))
+	app.Flag("requests", "Total number of requests").Default("1000").IntVar(&config.Requests)
+	app.Flag("target", "URL of the webhook, e.g. https://localhost:8443/validate/awscluster").Required().StringVar(&config.Target)
+	app.Flag("timeout", "Timeout of a single request").Default("10s").DurationVar(&config.Timeout)
+	kingpin.MustParse(app.Parse(args))
+
+	config.Operation = admissionv1.Operation(strings.ToUpper(operation))
+	if objectFile != "" {
+		object, err := ioutil.ReadFile(objectFile)
+		if err != nil {
+			panic(microerror.JSON(err))
+		}
+		config.Object = object
+	}
+
+	result, err := loadtest.Run(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	result.Report(os.Stdout)
+}
@@ -3,34 +3,114 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
-	"github.com/dyson/certman"
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/microerror"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/audit"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/awscluster"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/awscontrolplane"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/awsmachinedeployment"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/cluster"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/g8scontrolplane"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/generic"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/machinedeployment"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/machinepool"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws/networkpool"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/certcheck"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/certreload"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/clustercontext"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/crd"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/ec2offering"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/events"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/handler"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mirror"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/readiness"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/servicequota"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/tracing"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/webhookconfig"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == loadTestCommand {
+		runLoadTest(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == compareDecisionsCommand {
+		runCompareDecisions(os.Args[2:])
+		return
+	}
+
 	config, err := config.Parse()
 	if err != nil {
 		panic(microerror.JSON(err))
 	}
 
+	tracer, err := tracing.New(tracing.Config{
+		Endpoint:    config.TracingEndpoint,
+		Insecure:    config.TracingInsecure,
+		Logger:      config.Logger,
+		SampleRatio: config.TracingSampleRatio,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	// The cluster context has to be set up before the handlers which read it.
+	if config.ClusterContextResync > 0 {
+		config.ClusterContext, err = clustercontext.New(clustercontext.Config{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+			Resync:    config.ClusterContextResync,
+		})
+		if err != nil {
+			panic(microerror.JSON(err))
+		}
+		go func() {
+			err := config.ClusterContext.Run(make(chan struct{}))
+			if err != nil {
+				config.Logger.Log("level", "error", "message", "unable to maintain cluster context", "stack", microerror.JSON(err))
+			}
+		}()
+	}
+
+	if config.EC2OfferingsTTL > 0 {
+		config.InstanceTypeOfferings, err = ec2offering.New(ec2offering.Config{
+			Logger: config.Logger,
+			Region: config.Region,
+			TTL:    config.EC2OfferingsTTL,
+		})
+		if err != nil {
+			panic(microerror.JSON(err))
+		}
+	}
+
+	if config.ServiceQuotaTTL > 0 {
+		config.ServiceQuotas, err = servicequota.New(servicequota.Config{
+			Logger: config.Logger,
+			Region: config.Region,
+			TTL:    config.ServiceQuotaTTL,
+		})
+		if err != nil {
+			panic(microerror.JSON(err))
+		}
+	}
+
 	// Setup handler for mutating webhook
 	awsclusterMutator, err := awscluster.NewMutator(config)
 	if err != nil {
@@ -62,6 +142,16 @@ func main() {
 		panic(microerror.JSON(err))
 	}
 
+	machinepoolMutator, err := machinepool.NewMutator(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	networkPoolMutator, err := networkpool.NewMutator(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
 	// Setup handler for validating webhook
 	awsclusterValidator, err := awscluster.NewValidator(config)
 	if err != nil {
@@ -93,34 +183,237 @@ func main() {
 		panic(microerror.JSON(err))
 	}
 
+	machinepoolValidator, err := machinepool.NewValidator(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
 	networkPoolValidator, err := networkpool.NewValidator(config)
 	if err != nil {
 		panic(microerror.JSON(err))
 	}
 
+	genericValidators, err := generic.NewValidators(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	requestMirror, err := mirror.New(mirror.Config{
+		Endpoint:           config.MirrorEndpoint,
+		InsecureSkipVerify: config.MirrorInsecure,
+		Logger:             config.Logger,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	// Side effects of admission decisions are handled asynchronously, except for
+	// metrics and the audit log, which must not miss decisions.
+	kubernetesEvents, err := events.NewKubernetesEvents(config.K8sClient, config.Logger)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	events.SubscribeSync(events.ConsumerFunc(events.RecordMetrics))
+	events.Subscribe(kubernetesEvents)
+	if config.AuditLogPath != "" {
+		auditLog, err := audit.New(audit.Config{
+			Logger: config.Logger,
+			Path:   config.AuditLogPath,
+		})
+		if err != nil {
+			panic(microerror.JSON(err))
+		}
+		events.SubscribeSync(auditLog)
+	}
+
+	crdDetector, err := crd.NewDetector(crd.Config{
+		Discovery: config.K8sClient.K8sClient().Discovery(),
+		Logger:    config.Logger,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	certChecker, err := certcheck.New(certcheck.Config{
+		CertFile:  config.CertFile,
+		KeyFile:   config.KeyFile,
+		Logger:    config.Logger,
+		Threshold: config.CertExpiryThreshold,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	awsClusters := infrastructurev1alpha2.SchemeGroupVersion.WithResource("awsclusters")
+	awsControlPlanes := infrastructurev1alpha2.SchemeGroupVersion.WithResource("awscontrolplanes")
+	awsMachineDeployments := infrastructurev1alpha2.SchemeGroupVersion.WithResource("awsmachinedeployments")
+	clusters := capiv1alpha2.GroupVersion.WithResource("clusters")
+	g8sControlPlanes := infrastructurev1alpha2.SchemeGroupVersion.WithResource("g8scontrolplanes")
+	machineDeployments := capiv1alpha2.GroupVersion.WithResource("machinedeployments")
+	machinePools := expcapiv1alpha3.GroupVersion.WithResource("machinepools")
+	networkPools := infrastructurev1alpha2.SchemeGroupVersion.WithResource("networkpools")
+
+	// MachinePools and NetworkPools are not installed everywhere, their handlers
+	// admit requests without processing them instead.
+	readinessChecker, err := readiness.New(readiness.Config{
+		CertChecker: certChecker,
+		CRDDetector: crdDetector,
+		CRDs:        []schema.GroupVersionResource{awsClusters, awsControlPlanes, awsMachineDeployments, clusters, g8sControlPlanes, machineDeployments},
+		Discovery:   config.K8sClient.K8sClient().Discovery(),
+		Logger:      config.Logger,
+		TTL:         config.ReadinessCacheTTL,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	timeouts, err := handler.NewTimeouts(handler.TimeoutsConfig{
+		Default:   config.HandlerTimeout,
+		Overrides: config.HandlerTimeoutOverrides,
+		Policy:    config.HandlerTimeoutPolicy,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	recovery, err := handler.NewRecovery(handler.RecoveryConfig{
+		Logger: config.Logger,
+		Policy: config.PanicPolicy,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	concurrencyLimiter, err := handler.NewConcurrencyLimiter(handler.ConcurrencyLimiterConfig{
+		Logger: config.Logger,
+		Max:    config.MaxConcurrentRequests,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	failOpen, err := handler.NewFailOpen(config.FailOpenHandlers)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	bypass, err := handler.NewBypass(handler.BypassConfig{
+		Groups:          config.BypassGroups,
+		ServiceAccounts: config.BypassServiceAccounts,
+		Users:           config.BypassUsers,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	rateLimits, err := handler.NewRateLimits(handler.RateLimitsConfig{
+		Burst:  config.RateLimitBurst,
+		Limits: config.RateLimits,
+	})
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	policies := handler.Policies{
+		Bypass:     bypass,
+		FailOpen:   failOpen,
+		RateLimits: rateLimits,
+		Recovery:   recovery,
+		Timeouts:   timeouts,
+	}
+
+	// All webhooks are registered here, so that the served paths and the
+	// webhook configurations are derived from the same list.
+	noneOnDryRun := admissionregistrationv1.SideEffectClassNoneOnDryRun
+	webhooks := []webhookconfig.Webhook{
+		{Type: webhookconfig.Mutating, Path: "/mutate/awscluster", Resource: awsClusters, Operations: webhookconfig.CreateUpdate, Handler: mutator.Handler(awsclusterMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/awsmachinedeployment", Resource: awsMachineDeployments, Operations: webhookconfig.CreateUpdate, Handler: mutator.Handler(awsmachinedeploymentMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/awscontrolplane", Resource: awsControlPlanes, Operations: webhookconfig.CreateUpdate, SideEffects: noneOnDryRun, Handler: mutator.Handler(awscontrolplaneMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/cluster", Resource: clusters, Operations: webhookconfig.CreateUpdate, Handler: mutator.Handler(clusterMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/g8scontrolplane", Resource: g8sControlPlanes, Operations: webhookconfig.CreateUpdate, SideEffects: noneOnDryRun, Handler: mutator.Handler(g8scontrolplaneMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/machinedeployment", Resource: machineDeployments, Operations: webhookconfig.CreateUpdate, Handler: mutator.Handler(machinedeploymentMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/machinepool", Resource: machinePools, Operations: webhookconfig.CreateUpdate, Handler: mutator.Handler(machinepoolMutator, policies)},
+		{Type: webhookconfig.Mutating, Path: "/mutate/networkpool", Resource: networkPools, Operations: webhookconfig.CreateUpdate, Handler: mutator.Handler(networkPoolMutator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/awscluster", Resource: awsClusters, Subresources: []string{"status"}, Operations: webhookconfig.CreateUpdate, Handler: validator.Handler(awsclusterValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/awscontrolplane", Resource: awsControlPlanes, Operations: webhookconfig.CreateUpdate, SideEffects: noneOnDryRun, Handler: validator.Handler(awscontrolplaneValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/awsmachinedeployment", Resource: awsMachineDeployments, Subresources: []string{"status"}, Operations: webhookconfig.CreateUpdateDelete, Handler: validator.Handler(awsmachinedeploymentValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/cluster", Resource: clusters, Operations: webhookconfig.CreateUpdate, Handler: validator.Handler(clusterValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/g8scontrolplane", Resource: g8sControlPlanes, Operations: webhookconfig.CreateUpdateDelete, SideEffects: noneOnDryRun, Handler: validator.Handler(g8scontrolplaneValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/machinedeployment", Resource: machineDeployments, Subresources: []string{"scale"}, Operations: webhookconfig.CreateUpdate, Handler: validator.Handler(machinedeploymentValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/machinepool", Resource: machinePools, Operations: webhookconfig.CreateUpdate, Handler: validator.Handler(machinepoolValidator, policies)},
+		{Type: webhookconfig.Validating, Path: "/validate/networkpool", Resource: networkPools, Operations: webhookconfig.CreateUpdate, SideEffects: noneOnDryRun, Handler: validator.Handler(networkPoolValidator, policies)},
+	}
+	for _, v := range genericValidators {
+		webhooks = append(webhooks, webhookconfig.Webhook{Type: webhookconfig.Validating, Path: v.Path(), Resource: v.GroupVersionResource(), Operations: webhookconfig.CreateUpdate, Handler: validator.Handler(v, policies)})
+	}
+
 	// Here we register our endpoints.
-	handler := http.NewServeMux()
-	handler.Handle("/mutate/awscluster", mutator.Handler(awsclusterMutator))
-	handler.Handle("/mutate/awsmachinedeployment", mutator.Handler(awsmachinedeploymentMutator))
-	handler.Handle("/mutate/awscontrolplane", mutator.Handler(awscontrolplaneMutator))
-	handler.Handle("/mutate/cluster", mutator.Handler(clusterMutator))
-	handler.Handle("/mutate/g8scontrolplane", mutator.Handler(g8scontrolplaneMutator))
-	handler.Handle("/mutate/machinedeployment", mutator.Handler(machinedeploymentMutator))
-	handler.Handle("/validate/awscluster", validator.Handler(awsclusterValidator))
-	handler.Handle("/validate/awscontrolplane", validator.Handler(awscontrolplaneValidator))
-	handler.Handle("/validate/awsmachinedeployment", validator.Handler(awsmachinedeploymentValidator))
-	handler.Handle("/validate/cluster", validator.Handler(clusterValidator))
-	handler.Handle("/validate/g8scontrolplane", validator.Handler(g8scontrolplaneValidator))
-	handler.Handle("/validate/machinedeployment", validator.Handler(machinedeploymentValidator))
-	handler.Handle("/validate/networkpool", validator.Handler(networkPoolValidator))
-
-	handler.HandleFunc("/healthz", healthCheck)
+	mux := http.NewServeMux()
+	for _, w := range webhooks {
+		mux.Handle(w.Path, concurrencyLimiter.Wrap(recovery.Wrap(requestMirror.Wrap(crdDetector.Wrap(w.Resource, w.Handler)))))
+	}
+
+	mux.HandleFunc("/healthz", healthCheck)
+	mux.Handle("/readyz", readinessChecker)
+
+	err = crdDetector.Check()
+	if err != nil {
+		config.Logger.Log("level", "warning", "message", "unable to check installed CRD versions", "stack", microerror.JSON(err))
+	}
+	if config.CRDCheckInterval > 0 {
+		go crdDetector.Run(config.CRDCheckInterval, make(chan struct{}))
+	}
+
+	if config.WebhookConfigName != "" {
+		reconciler, err := webhookconfig.New(webhookconfig.Config{
+			CAFile:            config.WebhookCAFile,
+			FailurePolicy:     admissionregistrationv1.FailurePolicyType(config.WebhookFailurePolicy),
+			K8sClient:         config.K8sClient,
+			Logger:            config.Logger,
+			Name:              config.WebhookConfigName,
+			NamespaceSelector: config.WebhookNamespaceSelector,
+			ServiceName:       config.WebhookServiceName,
+			ServiceNamespace:  config.WebhookServiceNamespace,
+			Webhooks:          webhooks,
+		})
+		if err != nil {
+			panic(microerror.JSON(err))
+		}
+		err = reconciler.Reconcile(context.Background())
+		if err != nil {
+			config.Logger.Log("level", "warning", "message", "unable to reconcile webhook configurations", "stack", microerror.JSON(err))
+		}
+		go reconciler.Run(config.WebhookReconcileInterval, make(chan struct{}))
+	}
+
+	err = certChecker.Check()
+	if err != nil {
+		config.Logger.Log("level", "warning", "message", "unable to check serving certificate", "stack", microerror.JSON(err))
+	}
+	if config.CertCheckInterval > 0 {
+		go certChecker.Run(config.CertCheckInterval, make(chan struct{}))
+	}
 
 	metrics := http.NewServeMux()
 	metrics.Handle("/metrics", promhttp.Handler())
 
-	go serveMetrics(config, metrics)
-	serveTLS(config, handler)
+	server, err := newTLSServer(config, tracing.Wrap(mux))
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+	metricsServer := &http.Server{
+		Addr:    config.MetricsAddress,
+		Handler: metrics,
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+	go serve(metricsServer.ListenAndServe)
+	go serve(func() error { return server.ListenAndServeTLS("", "") })
+
+	<-sig
+	shutdown(config, readinessChecker, server, metricsServer)
+
+	err = tracer.Shutdown(context.Background())
+	if err != nil {
+		config.Logger.Log("level", "warning", "message", "unable to export remaining traces", "stack", microerror.JSON(err))
+	}
 }
 
 func healthCheck(writer http.ResponseWriter, request *http.Request) {
@@ -131,35 +424,37 @@ func healthCheck(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
-func serveTLS(config config.Config, handler http.Handler) {
-	cm, err := certman.New(config.CertFile, config.KeyFile)
+func newTLSServer(config config.Config, handler http.Handler) (*http.Server, error) {
+	certReloader, err := certreload.New(certreload.Config{
+		CertFile: config.CertFile,
+		Interval: config.CertReloadInterval,
+		KeyFile:  config.KeyFile,
+		Logger:   config.Logger,
+	})
 	if err != nil {
-		panic(microerror.JSON(err))
-	}
-	if err := cm.Watch(); err != nil {
-		panic(microerror.JSON(err))
+		return nil, microerror.Mask(err)
 	}
+	go func() {
+		err := certReloader.Run(make(chan struct{}))
+		if err != nil {
+			config.Logger.Log("level", "error", "message", "unable to watch serving certificate for rotations", "stack", microerror.JSON(err))
+		}
+	}()
 
 	server := &http.Server{
 		Addr:    config.Address,
 		Handler: handler,
 		TLSConfig: &tls.Config{
-			GetCertificate: cm.GetCertificate,
+			GetCertificate: certReloader.GetCertificate,
 			MinVersion:     tls.VersionTLS12,
 		},
 	}
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGTERM)
-	go func() {
-		<-sig
-		err := server.Shutdown(context.Background())
-		if err != nil {
-			panic(microerror.JSON(err))
-		}
-	}()
+	return server, nil
+}
 
-	err = server.ListenAndServeTLS("", "")
+func serve(listenAndServe func() error) {
+	err := listenAndServe()
 	if err != nil {
 		if err != http.ErrServerClosed {
 			panic(microerror.JSON(err))
@@ -167,26 +462,22 @@ func serveTLS(config config.Config, handler http.Handler) {
 	}
 }
 
-func serveMetrics(config config.Config, handler http.Handler) {
-	server := &http.Server{
-		Addr:    config.MetricsAddress,
-		Handler: handler,
-	}
+// shutdown drains in-flight admission requests before the process exits.
+// Readiness fails for the shutdown delay first, so that the API server stops
+// routing new requests to the pod before its listener is closed. The metrics
+// server is shut down last to expose the drained requests.
+func shutdown(config config.Config, readinessChecker *readiness.Checker, servers ...*http.Server) {
+	readinessChecker.Shutdown()
+	config.Logger.Log("level", "info", "message", fmt.Sprintf("shutting down, draining in-flight requests after %s", config.ShutdownDelay))
+	time.Sleep(config.ShutdownDelay)
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGTERM)
-	go func() {
-		<-sig
-		err := server.Shutdown(context.Background())
-		if err != nil {
-			panic(microerror.JSON(err))
-		}
-	}()
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
 
-	err := server.ListenAndServe()
-	if err != nil {
-		if err != http.ErrServerClosed {
-			panic(microerror.JSON(err))
+	for _, server := range servers {
+		err := server.Shutdown(ctx)
+		if err != nil {
+			config.Logger.Log("level", "warning", "message", fmt.Sprintf("unable to drain requests to %s within %s", server.Addr, config.ShutdownTimeout), "stack", microerror.JSON(err))
 		}
 	}
 }
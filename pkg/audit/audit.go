@@ -0,0 +1,145 @@
+// Package audit records every admission decision as a structured JSON line,
+// separate from the debug logs of the handlers, so that decisions can be
+// shipped to a SIEM.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/events"
+)
+
+// Stdout is the path which writes the audit log to the standard output.
+const Stdout = "-"
+
+type Config struct {
+	Logger micrologger.Logger
+	// Path is the file the audit log is appended to, or Stdout.
+	Path string
+}
+
+// Log writes an audit record for every consumed decision. It has to be
+// subscribed synchronously, since asynchronous consumers miss decisions when
+// the event queue is full.
+type Log struct {
+	logger micrologger.Logger
+
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// Record is the audit log entry of a single admission decision.
+type Record struct {
+	Time      time.Time `json:"time"`
+	UID       types.UID `json:"uid"`
+	Webhook   string    `json:"webhook"`
+	Resource  string    `json:"resource"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Operation string    `json:"operation"`
+	DryRun    bool      `json:"dryRun"`
+	User      User      `json:"user"`
+	Allowed   bool      `json:"allowed"`
+	Reason    string    `json:"reason,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	// Patch is the JSON patch of admitted mutating requests.
+	Patch           json.RawMessage `json:"patch,omitempty"`
+	DurationSeconds float64         `json:"durationSeconds"`
+}
+
+// User identifies the requesting user. Extra attributes of the user info are left out since they can contain the
+// scopes of tokens.
+type User struct {
+	Username string   `json:"username"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+func New(config Config) (*Log, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Path == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Path must not be empty", config)
+	}
+
+	var writer io.Writer = os.Stdout
+	if config.Path != Stdout {
+		f, err := os.OpenFile(config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		writer = f
+	}
+
+	l := &Log{
+		logger: config.Logger,
+		writer: writer,
+	}
+
+	return l, nil
+}
+
+// Consume writes the audit record of the decision. Decisions are consumed concurrently by the handlers, so writes
+// are serialized to never interleave records.
+func (l *Log) Consume(decision events.Decision) {
+	record := NewRecord(decision, time.Now())
+	line, err := json.Marshal(record)
+	if err != nil {
+		l.logger.Log("level", "error", "message", "unable to serialize audit record", "stack", microerror.JSON(err))
+		return
+	}
+	l.mutex.Lock()
+	_, err = l.writer.Write(append(line, '\n'))
+	l.mutex.Unlock()
+	if err != nil {
+		l.logger.Log("level", "error", "message", fmt.Sprintf("unable to write audit record of request %s", record.UID), "stack", microerror.JSON(err))
+	}
+}
+
+// NewRecord returns the audit record of the decision.
+func NewRecord(decision events.Decision, now time.Time) Record {
+	record := Record{
+		Time:            now.UTC(),
+		Webhook:         decision.Webhook,
+		Resource:        decision.Resource,
+		Name:            decision.Name,
+		Allowed:         decision.Allowed,
+		Reason:          decision.Reason,
+		Message:         decision.Message,
+		DurationSeconds: decision.Duration.Seconds(),
+	}
+	if !decision.Allowed && record.Reason == "" {
+		record.Reason = events.ReasonDenied
+	}
+	if decision.Allowed && len(decision.Patch) > 0 {
+		record.Patch = json.RawMessage(decision.Patch)
+	}
+
+	request := decision.Request
+	if request == nil {
+		return record
+	}
+	record.UID = request.UID
+	record.Kind = request.Kind.Kind
+	record.Namespace = request.Namespace
+	record.Operation = string(request.Operation)
+	record.DryRun = request.DryRun != nil && *request.DryRun
+	record.User = User{
+		Username: request.UserInfo.Username,
+		UID:      request.UserInfo.UID,
+		Groups:   request.UserInfo.Groups,
+	}
+
+	return record
+}
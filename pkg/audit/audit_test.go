@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/events"
+)
+
+func TestConsume(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		decision       events.Decision
+		expectedReason string
+		expectedPatch  string
+	}{
+		{
+			// admitted mutating request with patch
+			name: "case 0",
+
+			decision: events.Decision{
+				Webhook:  events.WebhookMutating,
+				Resource: "awscluster",
+				Allowed:  true,
+				Patches:  1,
+				Patch:    []byte(`[{"op":"add","path":"/metadata/labels","value":{}}]`),
+			},
+			expectedPatch: `[{"op":"add","path":"/metadata/labels","value":{}}]`,
+		},
+		{
+			// denied validating request with reason
+			name: "case 1",
+
+			decision: events.Decision{
+				Webhook:  events.WebhookValidating,
+				Resource: "awscluster",
+				Message:  "AWSCluster is not allowed",
+				Reason:   "notAllowedError",
+			},
+			expectedReason: "notAllowedError",
+		},
+		{
+			// denied validating request without error
+			name: "case 2",
+
+			decision: events.Decision{
+				Webhook:  events.WebhookValidating,
+				Resource: "awscluster",
+			},
+			expectedReason: events.ReasonDenied,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buffer bytes.Buffer
+			l := &Log{
+				logger: microloggertest.New(),
+				writer: &buffer,
+			}
+
+			tc.decision.Name = "abc12"
+			tc.decision.Duration = 20 * time.Millisecond
+			tc.decision.Request = &admissionv1.AdmissionRequest{
+				UID:       "1234",
+				Kind:      metav1.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha2", Kind: "AWSCluster"},
+				Namespace: "org-giantswarm",
+				Operation: admissionv1.Create,
+				UserInfo: authenticationv1.UserInfo{
+					Username: "jane",
+					Groups:   []string{"system:authenticated"},
+					Extra:    map[string]authenticationv1.ExtraValue{"scopes": {"secret"}},
+				},
+			}
+			l.Consume(tc.decision)
+
+			var record Record
+			err := json.Unmarshal(buffer.Bytes(), &record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if record.UID != "1234" || record.Kind != "AWSCluster" || record.Operation != "CREATE" || record.Name != "abc12" {
+				t.Fatalf("unexpected request fields in %s", buffer.String())
+			}
+			if record.User.Username != "jane" || bytes.Contains(buffer.Bytes(), []byte("secret")) {
+				t.Fatalf("unexpected user fields in %s", buffer.String())
+			}
+			if record.Allowed != tc.decision.Allowed || record.Reason != tc.expectedReason {
+				t.Fatalf("expected allowed %t with reason %#q but got %s", tc.decision.Allowed, tc.expectedReason, buffer.String())
+			}
+			if string(record.Patch) != tc.expectedPatch {
+				t.Fatalf("expected patch %#q but got %#q", tc.expectedPatch, string(record.Patch))
+			}
+		})
+	}
+}
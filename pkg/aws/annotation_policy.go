@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/annotation"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// UnknownAnnotationPolicyDeny rejects objects with unknown AWS annotations
+	UnknownAnnotationPolicyDeny = "deny"
+	// UnknownAnnotationPolicyWarn only logs a warning for objects with unknown AWS annotations
+	UnknownAnnotationPolicyWarn = "warn"
+)
+
+// AnnotationSchema describes the valid values of a known annotation.
+type AnnotationSchema struct {
+	// Description is shown in error messages for invalid values.
+	Description string
+	Valid       func(value string) bool
+}
+
+// policyAnnotationPrefixes are the prefixes of annotations which are validated against the registry.
+var policyAnnotationPrefixes = []string{
+	"alpha.aws.giantswarm.io/",
+	"aws.giantswarm.io/",
+}
+
+// annotationRegistry holds all known AWS annotations. Annotations consumed by
+// operators have to be registered here before they can be used on clusters,
+// including those defined in the annotation package of apiextensions.
+var annotationRegistry = map[string]AnnotationSchema{
+	annotation.AWSMetadataV2:           {Description: "one of [optional required]", Valid: isOneOf("optional", "required")},
+	AnnotationAPIWhitelistPrivate:      {Description: "a comma separated list of CIDRs", Valid: isCIDRList},
+	AnnotationAPIWhitelistPublic:       {Description: "a comma separated list of CIDRs", Valid: isCIDRList},
+	AnnotationAWSSubnetSize:            {Description: fmt.Sprintf("a prefix length between %d and %d", AWSLargestSubnetPrefixLength, AWSSmallestSubnetPrefixLength), Valid: IsSubnetPrefixLength},
+	AnnotationAWSTags:                  {Description: "a JSON object of tag keys and values", Valid: isStringMap},
+	AnnotationCalicoPolicyOnly:         {Description: "a boolean", Valid: isOneOf("true", "false")},
+	AnnotationCiliumPodCIDR:            {Description: "a CIDR", Valid: isCIDR},
+	AnnotationHeartbeatTimeout:         {Description: fmt.Sprintf("a number of seconds between %d and %d", int(MinHeartbeatTimeout.Seconds()), int(MaxHeartbeatTimeout.Seconds())), Valid: IsHeartbeatTimeout},
+	AnnotationHTTPProxy:                {Description: "a http or https URL", Valid: IsProxyURL},
+	AnnotationHTTPSProxy:               {Description: "a http or https URL", Valid: IsProxyURL},
+	AnnotationIPFamily:                 {Description: fmt.Sprintf("one of %v", ValidIPFamilies()), Valid: isOneOf(ValidIPFamilies()...)},
+	AnnotationIPv6CIDRBlock:            {Description: "a CIDR", Valid: isCIDR},
+	AnnotationIRSA:                     {Description: "a boolean", Valid: isOneOf("true", "false")},
+	AnnotationIRSAOIDCBucket:           {Description: "not empty", Valid: isNotEmpty},
+	AnnotationIRSAOIDCDomain:           {Description: "not empty", Valid: isNotEmpty},
+	AnnotationLoggingVolumeSize:        {Description: "an integer greater than zero", Valid: IsIntegerGreaterThanZero},
+	AnnotationMaxPods:                  {Description: "an integer greater than zero", Valid: IsIntegerGreaterThanZero},
+	AnnotationNodeLabels:               {Description: "a comma separated list of key=value node labels", Valid: IsNodeLabelList},
+	AnnotationNodeTaints:               {Description: "a comma separated list of key=value:effect node taints", Valid: IsNodeTaintList},
+	AnnotationNoProxy:                  {Description: "a comma separated list of CIDRs, IPs and domains", Valid: IsNoProxyList},
+	AnnotationPrefixDelegation:         {Description: "a boolean", Valid: isOneOf("true", "false")},
+	AnnotationSpotInterruptionDraining: {Description: "a boolean", Valid: isOneOf("true", "false")},
+	AnnotationUpdateMaxBatchSize:       {Description: "an integer greater than zero, a ratio between 0 and 1 or a percentage up to 100 percent", Valid: MaxBatchSizeIsValid},
+	AnnotationUpdatePauseTime:          {Description: "an ISO 8601 or Go duration of at most one hour", Valid: PauseTimeIsValid},
+	AnnotationVPCMode:                  {Description: fmt.Sprintf("one of %v", ValidVPCModes()), Valid: isOneOf(ValidVPCModes()...)},
+	AnnotationWorkloadProfile:          {Description: fmt.Sprintf("one of %v", []string{WorkloadProfileStateless, WorkloadProfileStateful}), Valid: isOneOf(WorkloadProfileStateless, WorkloadProfileStateful)},
+}
+
+// ValidateAnnotationPolicy checks all AWS annotations of the given object against the registry of known
+// annotations. Unknown annotations are denied or logged depending on the unknown annotation policy.
+func ValidateAnnotationPolicy(m *Handler, meta metav1.Object, kind string, unknownPolicy string) error {
+	var keys []string
+	for key := range meta.GetAnnotations() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !isPolicyAnnotation(key) {
+			continue
+		}
+		value := meta.GetAnnotations()[key]
+		schema, ok := annotationRegistry[key]
+		if !ok {
+			if unknownPolicy == UnknownAnnotationPolicyDeny {
+				return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s annotation '%s' is unknown. Known annotations are %v.",
+					kind,
+					meta.GetName(),
+					key,
+					KnownAnnotations()),
+				)
+			}
+			m.Logger.Log("level", "warning", "message", fmt.Sprintf("%s %s annotation '%s' is unknown and probably misspelled.",
+				kind,
+				meta.GetName(),
+				key))
+			continue
+		}
+		if !schema.Valid(value) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s annotation '%s' value '%s' is not valid. The value has to be %s.",
+				kind,
+				meta.GetName(),
+				key,
+				value,
+				schema.Description),
+			)
+		}
+	}
+
+	return nil
+}
+
+// KnownAnnotations returns the sorted keys of all registered annotations.
+func KnownAnnotations() []string {
+	var keys []string
+	for key := range annotationRegistry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isPolicyAnnotation(key string) bool {
+	for _, prefix := range policyAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCIDR(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+func isCIDRList(value string) bool {
+	for _, cidr := range strings.Split(value, ",") {
+		if !isCIDR(strings.TrimSpace(cidr)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isStringMap(value string) bool {
+	var m map[string]string
+	return json.Unmarshal([]byte(value), &m) == nil
+}
+
+func isNotEmpty(value string) bool {
+	return value != ""
+}
+
+func isOneOf(values ...string) func(string) bool {
+	return func(value string) bool {
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/v3/pkg/annotation"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateAnnotationPolicy(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations   map[string]string
+		unknownPolicy string
+		valid         bool
+	}{
+		{
+			// no annotations
+			name: "case 0",
+
+			annotations:   map[string]string{},
+			unknownPolicy: UnknownAnnotationPolicyDeny,
+			valid:         true,
+		},
+		{
+			// known annotations with valid values
+			name: "case 1",
+
+			annotations: map[string]string{
+				AnnotationUpdateMaxBatchSize: "0.3",
+				AnnotationUpdatePauseTime:    "PT10M",
+				AnnotationAPIWhitelistPublic: "172.10.0.0/16, 10.0.0.1/32",
+			},
+			unknownPolicy: UnknownAnnotationPolicyDeny,
+			valid:         true,
+		},
+		{
+			// known annotation with invalid value
+			name: "case 2",
+
+			annotations: map[string]string{
+				AnnotationUpdatePauseTime: "10 minutes",
+			},
+			unknownPolicy: UnknownAnnotationPolicyWarn,
+			valid:         false,
+		},
+		{
+			// invalid enum value
+			name: "case 3",
+
+			annotations: map[string]string{
+				AnnotationWorkloadProfile: "stateles",
+			},
+			unknownPolicy: UnknownAnnotationPolicyWarn,
+			valid:         false,
+		},
+		{
+			// misspelled annotation is denied
+			name: "case 4",
+
+			annotations: map[string]string{
+				"alpha.aws.giantswarm.io/update-max-batchsize": "3",
+			},
+			unknownPolicy: UnknownAnnotationPolicyDeny,
+			valid:         false,
+		},
+		{
+			// misspelled annotation only causes a warning
+			name: "case 5",
+
+			annotations: map[string]string{
+				"aws.giantswarm.io/update-max-batchsize": "3",
+			},
+			unknownPolicy: UnknownAnnotationPolicyWarn,
+			valid:         true,
+		},
+		{
+			// annotations with other prefixes are ignored
+			name: "case 6",
+
+			annotations: map[string]string{
+				"alpha.giantswarm.io/unknown":       "true",
+				"cluster.giantswarm.io/description": "test",
+			},
+			unknownPolicy: UnknownAnnotationPolicyDeny,
+			valid:         true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+
+			err := ValidateAnnotationPolicy(&Handler{K8sClient: unittest.FakeK8sClient(), Logger: microloggertest.New()}, &awsCluster, "AWSCluster", tc.unknownPolicy)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+// TestAnnotationRegistryUpstream checks that the AWS annotations of the pinned
+// apiextensions version are known, so that they are not denied as unknown.
+func TestAnnotationRegistryUpstream(t *testing.T) {
+	upstream := []string{
+		annotation.AWSMetadataV2,
+		annotation.AWSSubnetSize,
+		annotation.AWSUpdateMaxBatchSize,
+		annotation.AWSUpdatePauseTime,
+	}
+	for _, key := range upstream {
+		if !isPolicyAnnotation(key) {
+			continue
+		}
+		if _, ok := annotationRegistry[key]; !ok {
+			t.Fatalf("expected annotation %s to be registered", key)
+		}
+	}
+}
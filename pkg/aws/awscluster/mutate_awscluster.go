@@ -36,6 +36,7 @@ type Config struct {
 
 // Mutator for AWSMachineDeployment object.
 type Mutator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
@@ -55,6 +56,7 @@ func NewMutator(config config.Config) (*Mutator, error) {
 
 	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
 	mutator := &Mutator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
@@ -68,7 +70,8 @@ func NewMutator(config config.Config) (*Mutator, error) {
 }
 
 // Mutate is the function executed for every matching webhook request.
-func (m *Mutator) Mutate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
 	var result []mutator.PatchOperation
 
 	if request.DryRun != nil && *request.DryRun {
@@ -94,6 +97,18 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSCluster: %v", err)
 	}
 
+	patch, err = m.MutateClusterLabels(awsCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateOwnerReference(*awsCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutatePodCIDR(*awsCluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -124,13 +139,19 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	result = append(result, patch...)
 
+	patch, err = m.MutateUpdateAnnotations(*awsCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*awsCluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	releaseVersion, err := aws.ReleaseVersion(awsCluster, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster: %v", err)
 	}
 	result = append(result, patch...)
 
@@ -167,7 +188,7 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	releaseVersion, err := aws.ReleaseVersion(awsCluster, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster: %v", err)
 	}
 
 	patch, err = m.MutatePodCIDR(*awsCluster)
@@ -206,6 +227,18 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	result = append(result, patch...)
 
+	patch, err = m.MutateUpdateAnnotations(*awsCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateForceCredentialSecretChange(*awsCluster, *awsClusterOld)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	if !aws.IsHAVersion(releaseVersion) {
 		patch, err = m.MutateMasterPreHA(*awsCluster)
 		if err != nil {
@@ -275,7 +308,7 @@ func (m *Mutator) MutateMasterPreHA(awsCluster infrastructurev1alpha2.AWSCluster
 		instanceType = aws.DefaultMasterInstanceType
 	}
 	if availabilityZone == "" {
-		defaultedAZs := aws.GetNavailabilityZones(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, 1, m.validAvailabilityZones)
+		defaultedAZs := aws.GetNavailabilityZones(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, 1, m.validAvailabilityZones)
 		availabilityZone = defaultedAZs[0]
 	}
 	// If the Master attributes are not set, we default them here
@@ -334,7 +367,7 @@ func (m *Mutator) fetchCredentialSecret(organization string) (corev1.Secret, err
 	// Fetch the credential secret
 	m.Log("level", "debug", "message", fmt.Sprintf("Fetching credential secret for organization %s", organization))
 	err = m.k8sClient.CtrlClient().List(
-		context.Background(),
+		m.ctx,
 		&secrets,
 		client.MatchingLabels{label.Organization: organization, label.ManagedBy: "credentiald"},
 	)
@@ -389,13 +422,13 @@ func (m *Mutator) MutateOperatorVersion(awsCluster infrastructurev1alpha2.AWSClu
 		return result, nil
 	}
 	// Retrieve the `Release` CR.
-	release, err := aws.FetchRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
+	release, err := aws.FetchRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster, *release, label.AWSOperatorVersion, "aws-operator")
+	patch, err = aws.MutateLabelFromRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster, *release, label.AWSOperatorVersion, "aws-operator")
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -404,6 +437,26 @@ func (m *Mutator) MutateOperatorVersion(awsCluster infrastructurev1alpha2.AWSClu
 	return result, nil
 }
 
+// MutateClusterLabels propagates the cluster ID and organization labels from the owning Cluster.
+// Without an owner reference the cluster ID is taken from the name of the AWSCluster.
+// The labels of the given AWSCluster are updated to reflect the patch.
+func (m *Mutator) MutateClusterLabels(awsCluster *infrastructurev1alpha2.AWSCluster) ([]mutator.PatchOperation, error) {
+	clusterID := aws.OwnerClusterID(awsCluster)
+	if clusterID == "" {
+		clusterID = awsCluster.GetName()
+	}
+	patch, err := aws.MutateClusterLabels(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsCluster, clusterID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return patch, nil
+}
+
+// MutateOwnerReference sets the owning Cluster as owner of the AWSCluster.
+func (m *Mutator) MutateOwnerReference(awsCluster infrastructurev1alpha2.AWSCluster) ([]mutator.PatchOperation, error) {
+	return aws.MutateOwnerReference(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster)
+}
+
 func (m *Mutator) MutateReleaseVersion(awsCluster infrastructurev1alpha2.AWSCluster) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -413,13 +466,13 @@ func (m *Mutator) MutateReleaseVersion(awsCluster infrastructurev1alpha2.AWSClus
 		return result, nil
 	}
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster)
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the release label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster, *cluster, label.Release)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster, *cluster, label.Release)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -443,15 +496,45 @@ func (m *Mutator) MutateRegion(awsCluster infrastructurev1alpha2.AWSCluster) ([]
 	return result, nil
 }
 
+// MutateUpdateAnnotations normalizes the formats of the update max batch size and pause time annotations.
+func (m *Mutator) MutateUpdateAnnotations(awsCluster infrastructurev1alpha2.AWSCluster) ([]mutator.PatchOperation, error) {
+	return aws.MutateUpdateAnnotations(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsCluster)
+}
+
+// MutateForceCredentialSecretChange removes the annotation forcing a credential secret change once it has been used.
+// The annotation is needed by the validator while the credential secret is changed, so it is removed with the
+// first update after the change which does not change the credential secret again.
+func (m *Mutator) MutateForceCredentialSecretChange(awsCluster infrastructurev1alpha2.AWSCluster, awsClusterOld infrastructurev1alpha2.AWSCluster) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if _, ok := awsClusterOld.GetAnnotations()[aws.AnnotationForceCredentialSecretChange]; !ok {
+		return result, nil
+	}
+	if _, ok := awsCluster.GetAnnotations()[aws.AnnotationForceCredentialSecretChange]; !ok {
+		return result, nil
+	}
+	if awsCluster.Spec.Provider.CredentialSecret != awsClusterOld.Spec.Provider.CredentialSecret {
+		return result, nil
+	}
+
+	m.Log("level", "debug", "message", fmt.Sprintf("Annotation %s of AWSCluster %s has been used and will be removed.",
+		aws.AnnotationForceCredentialSecretChange,
+		awsCluster.GetName()))
+	patch := mutator.PatchRemove(fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationForceCredentialSecretChange)))
+	result = append(result, patch)
+
+	return result, nil
+}
+
 //MutateAnnotationNodeTerminateUnhealthy migrate NodeTerminateUnhealthy annotations from alpha to stable in case it is configured.
 // TODO https://github.com/giantswarm/giantswarm/issues/17395
 // this migration code can be removed once all AWS clusters are on release 15.0.0 or newer
 func (m *Mutator) MutateAnnotationNodeTerminateUnhealthy(awsCluster infrastructurev1alpha2.AWSCluster) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 
-	release, err := semver.New(key.Release(&awsCluster))
+	release, err := aws.ReleaseVersion(&awsCluster, []mutator.PatchOperation{})
 	if err != nil {
-		return nil, microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster release has invalid value '%s'.", key.Release(&awsCluster)))
+		return nil, microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster release has invalid value '%s': %v", key.Release(&awsCluster), err))
 	}
 
 	// new annotation is available from release >= 15.x.x
@@ -480,6 +563,13 @@ func (m *Mutator) MutateAnnotationNodeTerminateUnhealthy(awsCluster infrastructu
 	return result, nil
 }
 
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Mutator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
@@ -58,7 +58,7 @@ func TestAWSClusterPodCIDR(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			patch, err = mutate.Mutate(&request)
+			patch, err = mutate.Mutate(tc.ctx, &request)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -538,3 +538,81 @@ func TestAWSClusterAnnotationNodeTerminateUnhealthy(t *testing.T) {
 		})
 	}
 }
+
+func TestMutateForceCredentialSecretChange(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		oldAnnotations map[string]string
+		annotations    map[string]string
+		oldSecret      string
+		newSecret      string
+		expectRemoval  bool
+	}{
+		{
+			// annotation is set together with the credential secret change
+			name: "case 0",
+			ctx:  context.Background(),
+
+			annotations: map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			oldSecret:   "example-credential",
+			newSecret:   "other-credential",
+		},
+		{
+			// annotation is kept while the credential secret is changed
+			name: "case 1",
+			ctx:  context.Background(),
+
+			oldAnnotations: map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			annotations:    map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			oldSecret:      "example-credential",
+			newSecret:      "other-credential",
+		},
+		{
+			// annotation is removed with the first update after the change
+			name: "case 2",
+			ctx:  context.Background(),
+
+			oldAnnotations: map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			annotations:    map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			oldSecret:      "other-credential",
+			newSecret:      "other-credential",
+			expectRemoval:  true,
+		},
+		{
+			// annotation is already removed by the user
+			name: "case 3",
+			ctx:  context.Background(),
+
+			oldAnnotations: map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			oldSecret:      "other-credential",
+			newSecret:      "other-credential",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			oldAWSCluster := unittest.DefaultAWSCluster()
+			oldAWSCluster.SetAnnotations(tc.oldAnnotations)
+			oldAWSCluster.Spec.Provider.CredentialSecret.Name = tc.oldSecret
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+			awsCluster.Spec.Provider.CredentialSecret.Name = tc.newSecret
+
+			patch, err := mutate.MutateForceCredentialSecretChange(awsCluster, oldAWSCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed := len(patch) == 1 && patch[0].Operation == "remove" && patch[0].Path == "/metadata/annotations/alpha.giantswarm.io~1force-credential-secret-change"
+			if removed != tc.expectRemoval {
+				t.Fatalf("expected removal of the annotation to be %t but got patch %v", tc.expectRemoval, patch)
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ package awscluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"regexp"
+	"strings"
 
 	"github.com/giantswarm/apiextensions/v3/pkg/annotation"
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
@@ -10,15 +14,41 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
 )
 
+var s3BucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+var awsTagRegexp = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+var iamRoleARNRegexp = regexp.MustCompile(`^arn:aws(-cn|-us-gov)?:iam::[0-9]{12}:role/[\w+=,.@/-]+$`)
+
 type Validator struct {
-	k8sClient k8sclient.Interface
-	logger    micrologger.Logger
+	apiWhitelistMaxEntries   int
+	awsTagsMaxEntries        int
+	ctx                      context.Context
+	denyOrphans              bool
+	dnsDomain                string
+	dockerCIDR               string
+	k8sClient                k8sclient.Interface
+	kubernetesClusterIPRange string
+	logger                   micrologger.Logger
+	region                   string
+	restrictedGroups         []string
+	statusWriters            []string
+	unknownAnnotationPolicy  string
+	validAvailabilityZones   []string
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -29,23 +59,60 @@ func NewValidator(config config.Config) (*Validator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	statusWriters, err := aws.ParseStatusWriters(config.StatusWriters)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StatusWriters is invalid: %v", config, err)
+	}
+
 	v := &Validator{
-		k8sClient: config.K8sClient,
-		logger:    config.Logger,
+		apiWhitelistMaxEntries:   config.APIWhitelistMaxEntries,
+		awsTagsMaxEntries:        config.AWSTagsMaxEntries,
+		ctx:                      context.Background(),
+		denyOrphans:              config.DenyOrphans,
+		dnsDomain:                strings.TrimPrefix(config.Endpoint, "k8s."),
+		dockerCIDR:               config.DockerCIDR,
+		k8sClient:                config.K8sClient,
+		kubernetesClusterIPRange: config.KubernetesClusterIPRange,
+		logger:                   config.Logger,
+		region:                   config.Region,
+		restrictedGroups: []string{
+			config.AdminGroup,
+			config.AllTargetGroup,
+		},
+		statusWriters:           statusWriters,
+		unknownAnnotationPolicy: config.UnknownAnnotationPolicy,
+		validAvailabilityZones:  strings.Split(config.AvailabilityZones, ","),
 	}
 
 	return v, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
 	var awsCluster infrastructurev1alpha2.AWSCluster
 	var err error
 
+	if request.SubResource == aws.SubResourceStatus {
+		return v.ValidateStatusUpdate(request)
+	}
+
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsCluster); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscluster: %v", err)
 	}
 
-	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), &awsCluster)
+	var oldAWSCluster infrastructurev1alpha2.AWSCluster
+	if request.Operation == admissionv1.Update {
+		if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &oldAWSCluster); err != nil {
+			return false, microerror.Maskf(parsingFailedError, "unable to parse old awscluster: %v", err)
+		}
+	}
+
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.AWSClusterAnnotationPolicyValid(awsCluster)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -73,10 +140,120 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.AWSClusterDualStackValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.AWSClusterIRSAValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.AWSClusterVPCModeValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	// The region is only validated when it is set or changed, so that existing clusters can still be updated after the installation region changed.
+	if request.Operation == admissionv1.Create || awsCluster.Spec.Provider.Region != oldAWSCluster.Spec.Provider.Region {
+		err = v.AWSClusterRegionValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+	// The master availability zone is only validated when it is set or changed, so that existing clusters can still be updated after the installation zones changed.
+	if request.Operation == admissionv1.Create || awsCluster.Spec.Provider.Master.AvailabilityZone != oldAWSCluster.Spec.Provider.Master.AvailabilityZone {
+		err = v.AWSClusterMasterAZValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+	// The API whitelists are only validated when they are set or changed, so that existing clusters exceeding the limits can still be updated.
+	if request.Operation == admissionv1.Create ||
+		awsCluster.GetAnnotations()[aws.AnnotationAPIWhitelistPublic] != oldAWSCluster.GetAnnotations()[aws.AnnotationAPIWhitelistPublic] ||
+		awsCluster.GetAnnotations()[aws.AnnotationAPIWhitelistPrivate] != oldAWSCluster.GetAnnotations()[aws.AnnotationAPIWhitelistPrivate] {
+		err = v.AWSClusterAPIWhitelistValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+	// The tags are only validated when they are set or changed, so that existing clusters exceeding the limits can still be updated.
+	if request.Operation == admissionv1.Create || awsCluster.GetAnnotations()[aws.AnnotationAWSTags] != oldAWSCluster.GetAnnotations()[aws.AnnotationAWSTags] {
+		err = v.AWSClusterTagsValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+	err = v.AWSClusterProxyValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	// The DNS domain is only validated when it is set or changed, so that existing clusters with a legacy domain can still be updated.
+	if request.Operation == admissionv1.Create || awsCluster.Spec.Cluster.DNS.Domain != oldAWSCluster.Spec.Cluster.DNS.Domain {
+		err = v.AWSClusterDNSDomainValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	if request.Operation == admissionv1.Create {
+		err = v.AWSClusterNameValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.AWSClusterOrphanValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.AWSClusterCredentialSecretValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	// The network and pod CIDRs are only validated when they are assigned or changed, so that existing clusters can still be updated.
+	if request.Operation == admissionv1.Update {
+		err = v.AWSClusterImmutableFieldsValid(oldAWSCluster, awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.AWSClusterIRSAUpdateValid(oldAWSCluster, awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.AWSClusterVPCModeUpdateValid(oldAWSCluster, awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.AWSClusterCredentialSecretUpdateValid(oldAWSCluster, awsCluster, request.UserInfo)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		if oldAWSCluster.Status.Provider.Network.CIDR == awsCluster.Status.Provider.Network.CIDR &&
+			oldAWSCluster.Spec.Provider.Nodes.NetworkPool == awsCluster.Spec.Provider.Nodes.NetworkPool &&
+			oldAWSCluster.Spec.Provider.Pods.CIDRBlock == awsCluster.Spec.Provider.Pods.CIDRBlock {
+			return true, nil
+		}
+	}
+	err = v.AWSClusterNetworkCIDRValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.AWSClusterPodCIDRValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	return true, nil
 }
 
+// ValidateStatusUpdate is the function executed for every status subresource webhook request.
+func (v *Validator) ValidateStatusUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	err := aws.ValidateStatusWriter(request.UserInfo, v.statusWriters)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	return true, nil
+}
+
 func (v *Validator) AWSClusterAnnotationCNIMinimumIPTarget(awsCluster infrastructurev1alpha2.AWSCluster) error {
 	if cniMinimumIPTarget, ok := awsCluster.GetAnnotations()[annotation.AWSCNIMinimumIPTarget]; ok {
 		if !aws.IsIntegerGreaterThanZero(cniMinimumIPTarget) {
@@ -109,14 +286,19 @@ func (v *Validator) AWSClusterAnnotationCNIWarmIPTarget(awsCluster infrastructur
 	return nil
 }
 
+// AWSClusterAnnotationPolicyValid checks the AWS annotations of the cluster against the registry of known annotations.
+func (v *Validator) AWSClusterAnnotationPolicyValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	return aws.ValidateAnnotationPolicy(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsCluster, "AWSCluster", v.unknownAnnotationPolicy)
+}
+
 func (v *Validator) AWSClusterAnnotationMaxBatchSizeIsValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
 	if maxBatchSize, ok := awsCluster.GetAnnotations()[aws.AnnotationUpdateMaxBatchSize]; ok {
 		if !aws.MaxBatchSizeIsValid(maxBatchSize) {
-			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Allowed value is either integer bigger than zero or decimal number between 0 and 1.0 defining percentage of nodes",
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Allowed value is either integer bigger than zero or decimal number between 0 and 1.0 or percentage up to 100 percent defining the share of nodes",
 				aws.AnnotationUpdateMaxBatchSize,
 				maxBatchSize),
 			)
-			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Allowed value is either integer bigger than zero or decimal number between 0 and 1.0 defining percentage of nodes",
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Allowed value is either integer bigger than zero or decimal number between 0 and 1.0 or percentage up to 100 percent defining the share of nodes",
 				aws.AnnotationUpdateMaxBatchSize,
 				maxBatchSize),
 			)
@@ -128,11 +310,11 @@ func (v *Validator) AWSClusterAnnotationMaxBatchSizeIsValid(awsCluster infrastru
 func (v *Validator) AWSClusterAnnotationPauseTimeIsValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
 	if maxBatchSize, ok := awsCluster.GetAnnotations()[aws.AnnotationUpdatePauseTime]; ok {
 		if !aws.PauseTimeIsValid(maxBatchSize) {
-			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Value must be in ISO 8601 duration format and cannot be bigger than 1 hour.",
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Value must be in ISO 8601 or Go duration format and cannot be bigger than 1 hour.",
 				aws.AnnotationUpdatePauseTime,
 				maxBatchSize),
 			)
-			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Value must be in ISO 8601 duration format and cannot be bigger than 1 hour.",
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Value must be in ISO 8601 or Go duration format and cannot be bigger than 1 hour.",
 				aws.AnnotationUpdatePauseTime,
 				maxBatchSize),
 			)
@@ -157,6 +339,754 @@ func (v *Validator) AWSClusterAnnotationNodeTerminateUnhealthy(awsCluster infras
 	return nil
 }
 
+// AWSClusterNameValid checks that the name of the AWSCluster follows the naming conventions of cluster IDs.
+func (v *Validator) AWSClusterNameValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	return aws.ValidateClusterName(&awsCluster)
+}
+
+// AWSClusterOrphanValid checks that the Cluster referenced by the AWSCluster exists when orphan objects are denied.
+func (v *Validator) AWSClusterOrphanValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	if !v.denyOrphans {
+		return nil
+	}
+	return aws.ValidateClusterExists(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsCluster)
+}
+
+// AWSClusterRegionValid checks that the cluster is created in the region of the installation,
+// since the operators only reconcile clusters in their own region.
+func (v *Validator) AWSClusterRegionValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	region := awsCluster.Spec.Provider.Region
+	if v.region == "" || region == "" || region == v.region {
+		return nil
+	}
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster region '%s' is not valid. Clusters can only be created in the installation region '%s'.",
+		region,
+		v.region),
+	)
+}
+
+// AWSClusterMasterAZValid checks that the pre-HA master availability zone exists in the installation region.
+func (v *Validator) AWSClusterMasterAZValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	availabilityZone := awsCluster.Spec.Provider.Master.AvailabilityZone
+	if availabilityZone == "" || contains(v.validAvailabilityZones, availabilityZone) {
+		return nil
+	}
+	v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster %s master availability zone %s is invalid. Valid AZs are: %v",
+		awsCluster.GetName(),
+		availabilityZone,
+		v.validAvailabilityZones),
+	)
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s master availability zone %s is invalid. Valid AZs are: %v",
+		awsCluster.GetName(),
+		availabilityZone,
+		v.validAvailabilityZones),
+	)
+}
+
+// AWSClusterAPIWhitelistValid checks that the API whitelist annotations contain a limited number of distinct, well-formed CIDRs.
+func (v *Validator) AWSClusterAPIWhitelistValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	for _, a := range []string{aws.AnnotationAPIWhitelistPublic, aws.AnnotationAPIWhitelistPrivate} {
+		value, ok := awsCluster.GetAnnotations()[a]
+		if !ok {
+			continue
+		}
+
+		var cidrs []string
+		for _, entry := range strings.Split(value, ",") {
+			cidr := strings.TrimSpace(entry)
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Entry '%s' is not a valid CIDR.",
+					a,
+					value,
+					cidr),
+				)
+			}
+			if contains(cidrs, ipNet.String()) {
+				return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. CIDR '%s' is listed more than once.",
+					a,
+					value,
+					cidr),
+				)
+			}
+			cidrs = append(cidrs, ipNet.String())
+		}
+
+		if v.apiWhitelistMaxEntries > 0 && len(cidrs) > v.apiWhitelistMaxEntries {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' contains %d CIDRs. At most %d CIDRs are allowed.",
+				a,
+				len(cidrs),
+				v.apiWhitelistMaxEntries),
+			)
+		}
+	}
+
+	return nil
+}
+
+// AWSClusterAPIWhitelistWarnings returns a warning for every CIDR in the API whitelist annotations which allows
+// access to the API from everywhere.
+func (v *Validator) AWSClusterAPIWhitelistWarnings(awsCluster infrastructurev1alpha2.AWSCluster) []string {
+	var warnings []string
+	for _, a := range []string{aws.AnnotationAPIWhitelistPublic, aws.AnnotationAPIWhitelistPrivate} {
+		value, ok := awsCluster.GetAnnotations()[a]
+		if !ok {
+			continue
+		}
+
+		for _, entry := range strings.Split(value, ",") {
+			cidr := strings.TrimSpace(entry)
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			if ones, _ := ipNet.Mask.Size(); ones == 0 {
+				warnings = append(warnings, fmt.Sprintf("AWSCluster %s annotation '%s' contains '%s' which allows access to the API from everywhere.",
+					awsCluster.GetName(),
+					a,
+					cidr),
+				)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// Warnings returns the warnings about the API whitelist of created or updated AWSClusters.
+func (v *Validator) Warnings(ctx context.Context, request *admissionv1.AdmissionRequest) []string {
+	if request.SubResource != "" {
+		return nil
+	}
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return nil
+	}
+	var awsCluster infrastructurev1alpha2.AWSCluster
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsCluster); err != nil {
+		return nil
+	}
+	return v.AWSClusterAPIWhitelistWarnings(awsCluster)
+}
+
+// AWSClusterProxyValid checks the no proxy list of a cluster with a proxy. A warning is logged for every cluster
+// network or Kubernetes service domain which would be sent through the proxy. The values of the proxy annotations
+// are checked by the annotation policy.
+func (v *Validator) AWSClusterProxyValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	var entries []string
+	if value, ok := awsCluster.GetAnnotations()[aws.AnnotationNoProxy]; ok {
+		for _, entry := range strings.Split(value, ",") {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+
+	if awsCluster.GetAnnotations()[aws.AnnotationHTTPProxy] == "" && awsCluster.GetAnnotations()[aws.AnnotationHTTPSProxy] == "" {
+		return nil
+	}
+	for _, cidr := range []string{awsCluster.Status.Provider.Network.CIDR, awsCluster.Spec.Provider.Pods.CIDRBlock, v.kubernetesClusterIPRange} {
+		if cidr != "" && !aws.NoProxyCoversCIDR(entries, cidr) {
+			v.logger.Log("level", "warning", "message", fmt.Sprintf("AWSCluster %s annotation '%s' does not contain the cluster network '%s', which will be accessed through the proxy.",
+				awsCluster.GetName(),
+				aws.AnnotationNoProxy,
+				cidr),
+			)
+		}
+	}
+	for _, domain := range aws.KubernetesServiceDomains() {
+		if !aws.NoProxyCoversDomain(entries, domain) {
+			v.logger.Log("level", "warning", "message", fmt.Sprintf("AWSCluster %s annotation '%s' does not contain the Kubernetes service domain '%s', which will be accessed through the proxy.",
+				awsCluster.GetName(),
+				aws.AnnotationNoProxy,
+				domain),
+			)
+		}
+	}
+
+	return nil
+}
+
+// AWSClusterTagsValid checks the custom tags which are propagated to all AWS resources of the cluster
+// against the AWS tag restrictions and the tags reserved by AWS, Kubernetes and the operators.
+func (v *Validator) AWSClusterTagsValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	value, ok := awsCluster.GetAnnotations()[aws.AnnotationAWSTags]
+	if !ok {
+		return nil
+	}
+
+	var tags map[string]string
+	err := json.Unmarshal([]byte(value), &tags)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. It has to be a JSON object of tag keys and values: %v",
+			aws.AnnotationAWSTags,
+			value,
+			err),
+		)
+	}
+
+	for tagKey, tagValue := range tags {
+		if len(tagKey) == 0 || len(tagKey) > aws.AWSTagKeyMaxLength || !awsTagRegexp.MatchString(tagKey) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' tag key '%s' is not valid. Tag keys have to be 1 to %d characters long and may only contain letters, numbers, spaces and the characters _.:/=+-@.",
+				aws.AnnotationAWSTags,
+				tagKey,
+				aws.AWSTagKeyMaxLength),
+			)
+		}
+		if len(tagValue) > aws.AWSTagValueMaxLength || !awsTagRegexp.MatchString(tagValue) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' of tag '%s' is not valid. Tag values have to be at most %d characters long and may only contain letters, numbers, spaces and the characters _.:/=+-@.",
+				aws.AnnotationAWSTags,
+				tagValue,
+				tagKey,
+				aws.AWSTagValueMaxLength),
+			)
+		}
+		for _, prefix := range aws.ReservedAWSTagPrefixes() {
+			if strings.HasPrefix(strings.ToLower(tagKey), prefix) {
+				return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' tag key '%s' is not allowed. Tag keys starting with %v are reserved.",
+					aws.AnnotationAWSTags,
+					tagKey,
+					aws.ReservedAWSTagPrefixes()),
+				)
+			}
+		}
+	}
+
+	if v.awsTagsMaxEntries > 0 && len(tags) > v.awsTagsMaxEntries {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' contains %d tags. At most %d custom tags are allowed because AWS resources are limited to 50 tags including the ones set by the operators.",
+			aws.AnnotationAWSTags,
+			len(tags),
+			v.awsTagsMaxEntries),
+		)
+	}
+
+	return nil
+}
+
+// AWSClusterDNSDomainValid checks that the DNS domain is the base domain of the installation. The
+// Kubernetes API of the cluster is then served at api.<clusterID>.k8s.<base domain>. The full cluster domain
+// <clusterID>.k8s.<base domain> is denied on purpose: aws-operator prepends the cluster ID itself and
+// MutateDomain defaults the field to the base domain, so existing clusters carry the base domain.
+func (v *Validator) AWSClusterDNSDomainValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	domain := awsCluster.Spec.Cluster.DNS.Domain
+	if v.dnsDomain == "" || domain == "" || domain == v.dnsDomain {
+		return nil
+	}
+	if domain == fmt.Sprintf("%s.k8s.%s", awsCluster.GetName(), v.dnsDomain) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s DNS domain '%s' is not valid. The DNS domain has to be the installation base domain '%s', the cluster ID is prepended automatically.",
+			awsCluster.GetName(),
+			domain,
+			v.dnsDomain),
+		)
+	}
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s DNS domain '%s' is not valid. The DNS domain has to be the installation base domain '%s'.",
+		awsCluster.GetName(),
+		domain,
+		v.dnsDomain),
+	)
+}
+
+func (v *Validator) AWSClusterDualStackValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	ipFamily, ipFamilySet := awsCluster.GetAnnotations()[aws.AnnotationIPFamily]
+	ipv6CIDRBlock, ipv6CIDRBlockSet := awsCluster.GetAnnotations()[aws.AnnotationIPv6CIDRBlock]
+	if !ipFamilySet && !ipv6CIDRBlockSet {
+		return nil
+	}
+	if ipFamilySet && !contains(aws.ValidIPFamilies(), ipFamily) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Allowed values are %v.",
+			aws.AnnotationIPFamily,
+			ipFamily,
+			aws.ValidIPFamilies()),
+		)
+	}
+	if ipFamily != aws.IPFamilyDualStack {
+		if ipv6CIDRBlockSet {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' can only be set when annotation '%s' is set to '%s'.",
+				aws.AnnotationIPv6CIDRBlock,
+				aws.AnnotationIPFamily,
+				aws.IPFamilyDualStack),
+			)
+		}
+		return nil
+	}
+
+	releaseVersion, err := aws.ReleaseVersion(&awsCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster: %v", err)
+	}
+	if !aws.IsDualStackVersion(releaseVersion) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s release version %s does not support dual-stack networking. The first release supporting it is %s.",
+			awsCluster.GetName(),
+			releaseVersion.String(),
+			aws.FirstDualStackRelease),
+		)
+	}
+	if awsCluster.Spec.Provider.Pods.ExternalSNAT != nil && *awsCluster.Spec.Provider.Pods.ExternalSNAT {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s can not use external SNAT for pods together with dual-stack networking.",
+			awsCluster.GetName()),
+		)
+	}
+	if ipv6CIDRBlockSet {
+		ip, ipNet, err := net.ParseCIDR(ipv6CIDRBlock)
+		if err != nil || ip.To4() != nil {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not a valid IPv6 CIDR block.",
+				aws.AnnotationIPv6CIDRBlock,
+				ipv6CIDRBlock),
+			)
+		}
+		if ones, _ := ipNet.Mask.Size(); ones != aws.IPv6VPCPrefixLength {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' must have a prefix length of /%d.",
+				aws.AnnotationIPv6CIDRBlock,
+				ipv6CIDRBlock,
+				aws.IPv6VPCPrefixLength),
+			)
+		}
+	}
+
+	return nil
+}
+
+// AWSClusterIRSAValid checks the format and combination of the IAM roles for service accounts annotations.
+func (v *Validator) AWSClusterIRSAValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	enabled, enabledSet := awsCluster.GetAnnotations()[aws.AnnotationIRSA]
+	bucket, bucketSet := awsCluster.GetAnnotations()[aws.AnnotationIRSAOIDCBucket]
+	domain, domainSet := awsCluster.GetAnnotations()[aws.AnnotationIRSAOIDCDomain]
+	if !enabledSet && !bucketSet && !domainSet {
+		return nil
+	}
+	if enabledSet && enabled != "true" && enabled != "false" {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not valid. Allowed values are [true false].",
+			aws.AnnotationIRSA,
+			enabled),
+		)
+	}
+	if enabled != "true" {
+		if bucketSet || domainSet {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotations '%s' and '%s' can only be set when annotation '%s' is set to 'true'.",
+				aws.AnnotationIRSAOIDCBucket,
+				aws.AnnotationIRSAOIDCDomain,
+				aws.AnnotationIRSA),
+			)
+		}
+		return nil
+	}
+
+	releaseVersion, err := aws.ReleaseVersion(&awsCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster: %v", err)
+	}
+	if !aws.IsIRSAVersion(releaseVersion) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s release version %s does not support IAM roles for service accounts. The first release supporting them is %s.",
+			awsCluster.GetName(),
+			releaseVersion.String(),
+			aws.FirstIRSARelease),
+		)
+	}
+	if bucketSet && (!s3BucketNameRegexp.MatchString(bucket) || net.ParseIP(bucket) != nil || strings.Contains(bucket, "..")) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not a valid S3 bucket name.",
+			aws.AnnotationIRSAOIDCBucket,
+			bucket),
+		)
+	}
+	if domainSet && len(validation.IsDNS1123Subdomain(domain)) > 0 {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster annotation '%s' value '%s' is not a valid domain.",
+			aws.AnnotationIRSAOIDCDomain,
+			domain),
+		)
+	}
+
+	return nil
+}
+
+// AWSClusterIRSAUpdateValid denies disabling IAM roles for service accounts in releases which do not support it,
+// since workloads would lose access to their AWS roles.
+func (v *Validator) AWSClusterIRSAUpdateValid(oldAWSCluster infrastructurev1alpha2.AWSCluster, newAWSCluster infrastructurev1alpha2.AWSCluster) error {
+	if !aws.IsAnnotationTrue(&oldAWSCluster, aws.AnnotationIRSA) || aws.IsAnnotationTrue(&newAWSCluster, aws.AnnotationIRSA) {
+		return nil
+	}
+
+	releaseVersion, err := aws.ReleaseVersion(&newAWSCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster: %v", err)
+	}
+	if !aws.IsIRSADisableVersion(releaseVersion) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s release version %s does not support disabling IAM roles for service accounts. Annotation '%s' can only be removed from release %s on.",
+			newAWSCluster.GetName(),
+			releaseVersion.String(),
+			aws.AnnotationIRSA,
+			aws.FirstIRSADisableRelease),
+		)
+	}
+
+	return nil
+}
+
+// AWSClusterVPCModeValid checks that the VPC mode is supported by the release of the cluster. Unknown VPC modes
+// are denied by the annotation policy.
+func (v *Validator) AWSClusterVPCModeValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	mode := aws.VPCMode(&awsCluster)
+	if mode == aws.VPCModePublic {
+		return nil
+	}
+
+	releaseVersion, err := aws.ReleaseVersion(&awsCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from AWSCluster: %v", err)
+	}
+	if !aws.IsVPCModeVersion(releaseVersion, mode) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s release version %s does not support VPC mode '%s'.",
+			awsCluster.GetName(),
+			releaseVersion.String(),
+			mode),
+		)
+	}
+
+	return nil
+}
+
+// AWSClusterVPCModeUpdateValid denies changes to the VPC mode after creation, since the load balancers, endpoints
+// and routes of the cluster VPC can not be moved to a different topology.
+func (v *Validator) AWSClusterVPCModeUpdateValid(oldAWSCluster infrastructurev1alpha2.AWSCluster, newAWSCluster infrastructurev1alpha2.AWSCluster) error {
+	oldMode := aws.VPCMode(&oldAWSCluster)
+	newMode := aws.VPCMode(&newAWSCluster)
+	if oldMode == newMode {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s VPC mode can not be changed from '%s' to '%s'. Annotation '%s' is immutable after creation.",
+		newAWSCluster.GetName(),
+		oldMode,
+		newMode,
+		aws.AnnotationVPCMode),
+	)
+}
+
+// AWSClusterNetworkCIDRValid checks that the network CIDR of the cluster and its referenced NetworkPool do not overlap
+// with the reserved ranges, other clusters or foreign NetworkPools. On creation the network CIDR is the one set in the
+// network CIDR annotation, either by the user or by the mutator.
+func (v *Validator) AWSClusterNetworkCIDRValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	var err error
+
+	// Retrieve the referenced `NetworkPool` CR and make sure it does not overlap with the reserved ranges.
+	if name := awsCluster.Spec.Provider.Nodes.NetworkPool; name != "" {
+		var networkPool infrastructurev1alpha2.NetworkPool
+		err = v.k8sClient.CtrlClient().Get(v.ctx, client.ObjectKey{Name: name, Namespace: awsCluster.GetNamespace()}, &networkPool)
+		if apierrors.IsNotFound(err) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s references NetworkPool %s which does not exist in namespace %s.",
+				awsCluster.GetName(),
+				name,
+				awsCluster.GetNamespace()),
+			)
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+		err = v.reservedRangesValid(awsCluster, fmt.Sprintf("NetworkPool %s CIDR block", name), networkPool.Spec.CIDRBlock)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	cidr := awsCluster.Status.Provider.Network.CIDR
+	if cidr == "" {
+		return nil
+	}
+	_, clusterNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s network CIDR %s is not a valid CIDR.",
+			awsCluster.GetName(),
+			cidr),
+		)
+	}
+	err = v.reservedRangesValid(awsCluster, "network CIDR", cidr)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Check the network ranges of all other clusters.
+	awsClusters, err := aws.FetchAWSClusters(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, other := range awsClusters {
+		if other.GetName() == awsCluster.GetName() && other.GetNamespace() == awsCluster.GetNamespace() {
+			continue
+		}
+		otherCIDR := other.Status.Provider.Network.CIDR
+		if otherCIDR == "" {
+			continue
+		}
+		_, otherNet, err := net.ParseCIDR(otherCIDR)
+		if err != nil {
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster %s has an invalid network CIDR %s, skipping it", other.GetName(), otherCIDR))
+			continue
+		}
+		if aws.CIDRsIntersect(clusterNet, otherNet) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s network CIDR %s overlaps with the network CIDR %s of cluster %s.",
+				awsCluster.GetName(),
+				cidr,
+				otherCIDR,
+				other.GetName()),
+			)
+		}
+	}
+
+	// Check the NetworkPools. A cluster network may be allocated from a NetworkPool, so it has to be either fully
+	// contained in a NetworkPool or not overlap with it at all.
+	networkPools, err := aws.FetchNetworkPools(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, networkPool := range networkPools {
+		_, poolNet, err := net.ParseCIDR(networkPool.Spec.CIDRBlock)
+		if err != nil {
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("NetworkPool %s has an invalid CIDR block %s, skipping it", networkPool.GetName(), networkPool.Spec.CIDRBlock))
+			continue
+		}
+		if aws.CIDRsIntersect(clusterNet, poolNet) && !aws.CIDRContains(poolNet, clusterNet) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s network CIDR %s partially overlaps with the CIDR block %s of NetworkPool %s.",
+				awsCluster.GetName(),
+				cidr,
+				networkPool.Spec.CIDRBlock,
+				networkPool.GetName()),
+			)
+		}
+	}
+
+	return nil
+}
+
+// AWSClusterCredentialSecretValid checks that the referenced credential secret exists in the namespace of
+// the default credential secret or the cluster and contains a valid IAM role ARN.
+func (v *Validator) AWSClusterCredentialSecretValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	ref := awsCluster.Spec.Provider.CredentialSecret
+	if ref.Name == "" && ref.Namespace == "" {
+		return nil
+	}
+
+	validNamespaces := []string{aws.DefaultCredentialSecret().Namespace, awsCluster.GetNamespace()}
+	if ref.Namespace != validNamespaces[0] && ref.Namespace != validNamespaces[1] {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s credential secret %s/%s is not valid. The secret has to be in one of the namespaces %v.",
+			awsCluster.GetName(),
+			ref.Namespace,
+			ref.Name,
+			validNamespaces),
+		)
+	}
+
+	var secret corev1.Secret
+	err := v.k8sClient.CtrlClient().Get(v.ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, &secret)
+	if apierrors.IsNotFound(err) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s credential secret %s/%s does not exist.",
+			awsCluster.GetName(),
+			ref.Namespace,
+			ref.Name),
+		)
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	arn := string(secret.Data[aws.CredentialSecretARNKey])
+	if !iamRoleARNRegexp.MatchString(arn) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s credential secret %s/%s key '%s' value '%s' is not a valid IAM role ARN.",
+			awsCluster.GetName(),
+			ref.Namespace,
+			ref.Name,
+			aws.CredentialSecretARNKey,
+			arn),
+		)
+	}
+
+	return nil
+}
+
+// AWSClusterImmutableFieldsValid denies changes to the region and the network ranges of clusters which have been
+// created, since the VPC can not be changed anymore.
+func (v *Validator) AWSClusterImmutableFieldsValid(oldAWSCluster infrastructurev1alpha2.AWSCluster, newAWSCluster infrastructurev1alpha2.AWSCluster) error {
+	if !isCreated(oldAWSCluster) {
+		return nil
+	}
+
+	fields := []struct {
+		path     *field.Path
+		oldValue string
+		newValue string
+	}{
+		{
+			path:     field.NewPath("spec", "provider", "region"),
+			oldValue: oldAWSCluster.Spec.Provider.Region,
+			newValue: newAWSCluster.Spec.Provider.Region,
+		},
+		{
+			path:     field.NewPath("spec", "provider", "pods", "cidrBlock"),
+			oldValue: oldAWSCluster.Spec.Provider.Pods.CIDRBlock,
+			newValue: newAWSCluster.Spec.Provider.Pods.CIDRBlock,
+		},
+		{
+			path:     field.NewPath("status", "provider", "network", "cidr"),
+			oldValue: oldAWSCluster.Status.Provider.Network.CIDR,
+			newValue: newAWSCluster.Status.Provider.Network.CIDR,
+		},
+	}
+
+	var errs field.ErrorList
+	for _, f := range fields {
+		// Fields which have not been set before the cluster was created can still be defaulted.
+		if f.oldValue == "" {
+			continue
+		}
+		errs = append(errs, apivalidation.ValidateImmutableField(f.newValue, f.oldValue, f.path)...)
+	}
+	if len(errs) > 0 {
+		return microerror.Maskf(notAllowedError, "AWSCluster %s: %s", newAWSCluster.GetName(), errs.ToAggregate().Error())
+	}
+
+	return nil
+}
+
+// AWSClusterCredentialSecretUpdateValid checks that the credential secret is not changed once it is set, because
+// switching the AWS account underneath a running cluster is not supported. Admin users and users in restricted
+// groups can override the check with the force-credential-secret-change annotation.
+func (v *Validator) AWSClusterCredentialSecretUpdateValid(oldAWSCluster infrastructurev1alpha2.AWSCluster, newAWSCluster infrastructurev1alpha2.AWSCluster, userInfo authenticationv1.UserInfo) error {
+	if aws.IsAnnotationTrue(&newAWSCluster, aws.AnnotationForceCredentialSecretChange) {
+		if v.isAdmin(userInfo) || v.isInRestrictedGroup(userInfo) {
+			v.logger.Log("level", "warning", "message", fmt.Sprintf("AWSCluster %s credential secret change is forced with annotation %s.", newAWSCluster.GetName(), aws.AnnotationForceCredentialSecretChange))
+			return nil
+		}
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster %s annotation %s is ignored because user %s is not allowed to use it.", newAWSCluster.GetName(), aws.AnnotationForceCredentialSecretChange, userInfo.Username))
+	}
+	oldSecret := oldAWSCluster.Spec.Provider.CredentialSecret
+	newSecret := newAWSCluster.Spec.Provider.CredentialSecret
+	// Clusters which have been created without a credential secret can still get one.
+	if oldSecret.Name == "" {
+		return nil
+	}
+
+	var errs field.ErrorList
+	path := field.NewPath("spec", "provider", "credentialSecret")
+	errs = append(errs, apivalidation.ValidateImmutableField(newSecret.Name, oldSecret.Name, path.Child("name"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(newSecret.Namespace, oldSecret.Namespace, path.Child("namespace"))...)
+	if len(errs) > 0 {
+		return microerror.Maskf(notAllowedError, "AWSCluster %s: %s. Changing the AWS account of a cluster is not supported.", newAWSCluster.GetName(), errs.ToAggregate().Error())
+	}
+
+	return nil
+}
+
+// AWSClusterPodCIDRValid checks that the pod CIDR does neither overlap with the network CIDR of the cluster
+// nor with the reserved ranges of the installation. All conflicts are reported as field errors.
+func (v *Validator) AWSClusterPodCIDRValid(awsCluster infrastructurev1alpha2.AWSCluster) error {
+	cidr := awsCluster.Spec.Provider.Pods.CIDRBlock
+	if cidr == "" {
+		return nil
+	}
+	path := field.NewPath("spec", "provider", "pods", "cidrBlock")
+
+	_, podNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, "%s", field.Invalid(path, cidr, "must be a valid CIDR").Error())
+	}
+
+	var errs field.ErrorList
+	ranges := []struct {
+		description string
+		cidr        string
+	}{
+		{description: "the network CIDR of the cluster", cidr: awsCluster.Status.Provider.Network.CIDR},
+		{description: "the Docker CIDR of the installation", cidr: v.dockerCIDR},
+		{description: "the Kubernetes cluster IP range of the installation", cidr: v.kubernetesClusterIPRange},
+	}
+	for _, r := range ranges {
+		if r.cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(r.cidr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if aws.CIDRsIntersect(podNet, ipNet) {
+			errs = append(errs, field.Invalid(path, cidr, fmt.Sprintf("must not overlap with %s %s", r.description, r.cidr)))
+		}
+	}
+	if len(errs) > 0 {
+		return microerror.Maskf(notAllowedError, "%s", errs.ToAggregate().Error())
+	}
+
+	return nil
+}
+
+// reservedRangesValid checks that the given CIDR does not overlap with the reserved ranges of the installation.
+func (v *Validator) reservedRangesValid(awsCluster infrastructurev1alpha2.AWSCluster, description string, cidr string) error {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s %s %s is not a valid CIDR.",
+			awsCluster.GetName(),
+			description,
+			cidr),
+		)
+	}
+	for _, reserved := range []string{v.dockerCIDR, v.kubernetesClusterIPRange} {
+		if reserved == "" {
+			continue
+		}
+		_, reservedNet, err := net.ParseCIDR(reserved)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if aws.CIDRsIntersect(ipNet, reservedNet) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster %s %s %s overlaps with the reserved range %s of the installation.",
+				awsCluster.GetName(),
+				description,
+				cidr,
+				reserved),
+			)
+		}
+	}
+	return nil
+}
+
+// isCreated returns whether the cluster has ever reached the Created condition.
+func isCreated(awsCluster infrastructurev1alpha2.AWSCluster) bool {
+	for _, c := range awsCluster.Status.Cluster.Conditions {
+		if c.Condition == infrastructurev1alpha2.ClusterStatusConditionCreated {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) isAdmin(userInfo authenticationv1.UserInfo) bool {
+	for _, u := range aws.ValidLabelAdmins() {
+		if u == userInfo.Username {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) isInRestrictedGroup(userInfo authenticationv1.UserInfo) bool {
+	for _, r := range v.restrictedGroups {
+		for _, u := range userInfo.Groups {
+			if r == u {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (v *Validator) Log(keyVals ...interface{}) {
 	v.logger.Log(keyVals...)
 }
@@ -0,0 +1,1471 @@
+package awscluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestAWSClusterDualStack(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations    map[string]string
+		releaseVersion string
+		externalSNAT   bool
+		valid          bool
+	}{
+		{
+			// no dual-stack annotations
+			name: "case 0",
+
+			annotations:    map[string]string{},
+			releaseVersion: "100.0.0",
+			valid:          true,
+		},
+		{
+			// valid dual-stack configuration
+			name: "case 1",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily:      aws.IPFamilyDualStack,
+				aws.AnnotationIPv6CIDRBlock: "2600:1f18:47b:5400::/56",
+			},
+			releaseVersion: "100.0.0",
+			valid:          true,
+		},
+		{
+			// unknown IP family
+			name: "case 2",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily: "ipv6",
+			},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// IPv6 CIDR without dual-stack
+			name: "case 3",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily:      aws.IPFamilyIPv4,
+				aws.AnnotationIPv6CIDRBlock: "2600:1f18:47b:5400::/56",
+			},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// release does not support dual-stack
+			name: "case 4",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily: aws.IPFamilyDualStack,
+			},
+			releaseVersion: "16.0.0",
+			valid:          false,
+		},
+		{
+			// IPv4 CIDR used as IPv6 CIDR
+			name: "case 5",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily:      aws.IPFamilyDualStack,
+				aws.AnnotationIPv6CIDRBlock: "10.0.0.0/16",
+			},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// IPv6 CIDR with wrong prefix length
+			name: "case 6",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily:      aws.IPFamilyDualStack,
+				aws.AnnotationIPv6CIDRBlock: "2600:1f18:47b:5400::/64",
+			},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// dual-stack with external SNAT
+			name: "case 7",
+
+			annotations: map[string]string{
+				aws.AnnotationIPFamily: aws.IPFamilyDualStack,
+			},
+			releaseVersion: "100.0.0",
+			externalSNAT:   true,
+			valid:          false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Labels[label.Release] = tc.releaseVersion
+			awsCluster.SetAnnotations(tc.annotations)
+			externalSNAT := tc.externalSNAT
+			awsCluster.Spec.Provider.Pods.ExternalSNAT = &externalSNAT
+
+			err = validate.AWSClusterDualStackValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterVPCMode(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations    map[string]string
+		releaseVersion string
+		valid          bool
+	}{
+		{
+			// no VPC mode annotation
+			name: "case 0",
+
+			annotations:    map[string]string{},
+			releaseVersion: "10.0.0",
+			valid:          true,
+		},
+		{
+			// private VPC mode in supported release
+			name: "case 1",
+
+			annotations:    map[string]string{aws.AnnotationVPCMode: aws.VPCModePrivate},
+			releaseVersion: "14.0.0",
+			valid:          true,
+		},
+		{
+			// transit gateway VPC mode in supported release
+			name: "case 2",
+
+			annotations:    map[string]string{aws.AnnotationVPCMode: aws.VPCModeTransitGateway},
+			releaseVersion: "100.0.0",
+			valid:          true,
+		},
+		{
+			// transit gateway VPC mode in unsupported release
+			name: "case 3",
+
+			annotations:    map[string]string{aws.AnnotationVPCMode: aws.VPCModeTransitGateway},
+			releaseVersion: "16.0.0",
+			valid:          false,
+		},
+		{
+			// unknown VPC mode
+			name: "case 4",
+
+			annotations:    map[string]string{aws.AnnotationVPCMode: "hybrid"},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Labels[label.Release] = tc.releaseVersion
+			awsCluster.SetAnnotations(tc.annotations)
+
+			err = validate.AWSClusterAnnotationPolicyValid(awsCluster)
+			if err == nil {
+				err = validate.AWSClusterVPCModeValid(awsCluster)
+			}
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterVPCModeUpdate(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		oldAnnotations map[string]string
+		newAnnotations map[string]string
+		valid          bool
+	}{
+		{
+			// VPC mode is not changed
+			name: "case 0",
+
+			oldAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModePrivate},
+			newAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModePrivate},
+			valid:          true,
+		},
+		{
+			// explicitly setting the default VPC mode
+			name: "case 1",
+
+			oldAnnotations: map[string]string{},
+			newAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModePublic},
+			valid:          true,
+		},
+		{
+			// public cluster is made private
+			name: "case 2",
+
+			oldAnnotations: map[string]string{},
+			newAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModePrivate},
+			valid:          false,
+		},
+		{
+			// private cluster is attached to a transit gateway
+			name: "case 3",
+
+			oldAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModePrivate},
+			newAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModeTransitGateway},
+			valid:          false,
+		},
+		{
+			// VPC mode annotation is removed
+			name: "case 4",
+
+			oldAnnotations: map[string]string{aws.AnnotationVPCMode: aws.VPCModeTransitGateway},
+			newAnnotations: map[string]string{},
+			valid:          false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			oldAWSCluster := unittest.DefaultAWSCluster()
+			oldAWSCluster.SetAnnotations(tc.oldAnnotations)
+			newAWSCluster := unittest.DefaultAWSCluster()
+			newAWSCluster.SetAnnotations(tc.newAnnotations)
+
+			err = validate.AWSClusterVPCModeUpdateValid(oldAWSCluster, newAWSCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterNetworkCIDR(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		cidr             string
+		otherClusterCIDR string
+		networkPoolCIDR  string
+		networkPoolRef   string
+		valid            bool
+	}{
+		{
+			// no network CIDR assigned yet
+			ctx:  context.Background(),
+			name: "case 0",
+
+			cidr:             "",
+			otherClusterCIDR: "10.1.0.0/24",
+			valid:            true,
+		},
+		{
+			// no overlap
+			ctx:  context.Background(),
+			name: "case 1",
+
+			cidr:             "10.1.1.0/24",
+			otherClusterCIDR: "10.1.0.0/24",
+			valid:            true,
+		},
+		{
+			// overlap with another cluster
+			ctx:  context.Background(),
+			name: "case 2",
+
+			cidr:             "10.1.0.0/16",
+			otherClusterCIDR: "10.1.0.0/24",
+			valid:            false,
+		},
+		{
+			// overlap with the docker CIDR
+			ctx:  context.Background(),
+			name: "case 3",
+
+			cidr:             "172.17.0.0/24",
+			otherClusterCIDR: "10.1.0.0/24",
+			valid:            false,
+		},
+		{
+			// allocated from a NetworkPool
+			ctx:  context.Background(),
+			name: "case 4",
+
+			cidr:             "10.10.1.0/24",
+			otherClusterCIDR: "10.1.0.0/24",
+			networkPoolCIDR:  "10.10.0.0/16",
+			valid:            true,
+		},
+		{
+			// partially overlapping a NetworkPool
+			ctx:  context.Background(),
+			name: "case 5",
+
+			cidr:             "10.10.0.0/15",
+			otherClusterCIDR: "10.1.0.0/24",
+			networkPoolCIDR:  "10.10.0.0/16",
+			valid:            false,
+		},
+		{
+			// referenced NetworkPool does not exist
+			ctx:  context.Background(),
+			name: "case 6",
+
+			otherClusterCIDR: "10.1.0.0/24",
+			networkPoolRef:   "missing",
+			valid:            false,
+		},
+		{
+			// referenced NetworkPool overlaps with the kubernetes cluster IP range
+			ctx:  context.Background(),
+			name: "case 7",
+
+			otherClusterCIDR: "10.1.0.0/24",
+			networkPoolCIDR:  "172.31.0.0/16",
+			networkPoolRef:   "example-pool",
+			valid:            false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				dockerCIDR:               "172.17.0.0/16",
+				k8sClient:                fakeK8sClient,
+				kubernetesClusterIPRange: "172.31.0.0/16",
+				logger:                   microloggertest.New(),
+			}
+
+			otherCluster := unittest.DefaultAWSCluster()
+			otherCluster.SetName("other")
+			otherCluster.Status.Provider.Network.CIDR = tc.otherClusterCIDR
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &otherCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.networkPoolCIDR != "" {
+				networkPool := unittest.DefaultNetworkPool(tc.networkPoolCIDR)
+				networkPool.SetName("example-pool")
+				networkPool.SetNamespace(otherCluster.GetNamespace())
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, networkPool)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Status.Provider.Network.CIDR = tc.cidr
+			awsCluster.Spec.Provider.Nodes.NetworkPool = tc.networkPoolRef
+
+			err = validate.AWSClusterNetworkCIDRValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterRegionValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		region string
+		valid  bool
+	}{
+		{
+			// installation region
+			ctx:  context.Background(),
+			name: "case 0",
+
+			region: unittest.DefaultClusterRegion,
+			valid:  true,
+		},
+		{
+			// region not set yet
+			ctx:  context.Background(),
+			name: "case 1",
+
+			region: "",
+			valid:  true,
+		},
+		{
+			// different region
+			ctx:  context.Background(),
+			name: "case 2",
+
+			region: "us-east-1",
+			valid:  false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+				region:    unittest.DefaultClusterRegion,
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Provider.Region = tc.region
+
+			// check if the result is as expected
+			err = handle.AWSClusterRegionValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterMasterAZ(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		availabilityZone string
+		valid            bool
+	}{
+		{
+			// valid availability zone
+			ctx:  context.Background(),
+			name: "case 0",
+
+			availabilityZone: unittest.DefaultMasterAvailabilityZone,
+			valid:            true,
+		},
+		{
+			// availability zone not set (HA masters)
+			ctx:  context.Background(),
+			name: "case 1",
+
+			availabilityZone: "",
+			valid:            true,
+		},
+		{
+			// availability zone does not exist in the region
+			ctx:  context.Background(),
+			name: "case 2",
+
+			availabilityZone: "eu-central-1d",
+			valid:            false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient:              unittest.FakeK8sClient(),
+				logger:                 microloggertest.New(),
+				validAvailabilityZones: unittest.DefaultAvailabilityZones(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Provider.Master.AvailabilityZone = tc.availabilityZone
+
+			// check if the result is as expected
+			err = handle.AWSClusterMasterAZValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterPodCIDRValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		podCIDR string
+		valid   bool
+	}{
+		{
+			// no overlap
+			ctx:  context.Background(),
+			name: "case 0",
+
+			podCIDR: "10.2.0.0/16",
+			valid:   true,
+		},
+		{
+			// pod CIDR not set yet
+			ctx:  context.Background(),
+			name: "case 1",
+
+			podCIDR: "",
+			valid:   true,
+		},
+		{
+			// invalid CIDR
+			ctx:  context.Background(),
+			name: "case 2",
+
+			podCIDR: "10.2.0.0/33",
+			valid:   false,
+		},
+		{
+			// overlap with the network CIDR of the cluster
+			ctx:  context.Background(),
+			name: "case 3",
+
+			podCIDR: "172.19.0.0/16",
+			valid:   false,
+		},
+		{
+			// overlap with the docker CIDR
+			ctx:  context.Background(),
+			name: "case 4",
+
+			podCIDR: "172.17.0.0/16",
+			valid:   false,
+		},
+		{
+			// overlap with the kubernetes cluster IP range
+			ctx:  context.Background(),
+			name: "case 5",
+
+			podCIDR: "172.31.0.0/24",
+			valid:   false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				dockerCIDR:               "172.17.0.0/16",
+				k8sClient:                unittest.FakeK8sClient(),
+				kubernetesClusterIPRange: "172.31.0.0/16",
+				logger:                   microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Provider.Pods.CIDRBlock = tc.podCIDR
+
+			// check if the result is as expected
+			err = handle.AWSClusterPodCIDRValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterAPIWhitelist(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		annotations map[string]string
+		valid       bool
+		warned      bool
+	}{
+		{
+			// no whitelist
+			ctx:  context.Background(),
+			name: "case 0",
+
+			annotations: map[string]string{},
+			valid:       true,
+		},
+		{
+			// valid whitelists
+			ctx:  context.Background(),
+			name: "case 1",
+
+			annotations: map[string]string{
+				aws.AnnotationAPIWhitelistPublic:  "185.102.95.187/32, 95.179.153.65/32",
+				aws.AnnotationAPIWhitelistPrivate: "10.0.0.0/8",
+			},
+			valid: true,
+		},
+		{
+			// open whitelist is only warned about
+			ctx:  context.Background(),
+			name: "case 2",
+
+			annotations: map[string]string{
+				aws.AnnotationAPIWhitelistPublic: "0.0.0.0/0",
+			},
+			valid:  true,
+			warned: true,
+		},
+		{
+			// invalid CIDR
+			ctx:  context.Background(),
+			name: "case 3",
+
+			annotations: map[string]string{
+				aws.AnnotationAPIWhitelistPublic: "185.102.95.187",
+			},
+			valid: false,
+		},
+		{
+			// duplicate CIDR
+			ctx:  context.Background(),
+			name: "case 4",
+
+			annotations: map[string]string{
+				aws.AnnotationAPIWhitelistPrivate: "10.0.0.0/8,10.0.0.1/8",
+			},
+			valid: false,
+		},
+		{
+			// too many entries
+			ctx:  context.Background(),
+			name: "case 5",
+
+			annotations: map[string]string{
+				aws.AnnotationAPIWhitelistPublic: "10.0.0.0/24,10.0.1.0/24,10.0.2.0/24",
+			},
+			valid: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				apiWhitelistMaxEntries: 2,
+				k8sClient:              unittest.FakeK8sClient(),
+				logger:                 microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+
+			// check if the result is as expected
+			err = handle.AWSClusterAPIWhitelistValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+			warned := len(handle.AWSClusterAPIWhitelistWarnings(awsCluster)) > 0
+			if tc.warned != warned {
+				t.Fatalf("expected warning to be %t", tc.warned)
+			}
+		})
+	}
+}
+
+func TestAWSClusterTags(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// no tags
+			ctx:  context.Background(),
+			name: "case 0",
+
+			annotations: map[string]string{},
+			valid:       true,
+		},
+		{
+			// valid tags
+			ctx:  context.Background(),
+			name: "case 1",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: `{"team":"Platform Engineering","app.example.com/owner":"ops@example.com"}`,
+			},
+			valid: true,
+		},
+		{
+			// invalid JSON
+			ctx:  context.Background(),
+			name: "case 2",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: "cost-center=1234",
+			},
+			valid: false,
+		},
+		{
+			// reserved AWS prefix
+			ctx:  context.Background(),
+			name: "case 3",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: `{"AWS:cloudformation:stack-name":"test"}`,
+			},
+			valid: false,
+		},
+		{
+			// reserved Kubernetes prefix
+			ctx:  context.Background(),
+			name: "case 4",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: `{"kubernetes.io/cluster/8y5ck":"owned"}`,
+			},
+			valid: false,
+		},
+		{
+			// key too long
+			ctx:  context.Background(),
+			name: "case 5",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: fmt.Sprintf(`{"%s":"test"}`, strings.Repeat("k", 129)),
+			},
+			valid: false,
+		},
+		{
+			// value too long
+			ctx:  context.Background(),
+			name: "case 6",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: fmt.Sprintf(`{"team":"%s"}`, strings.Repeat("v", 257)),
+			},
+			valid: false,
+		},
+		{
+			// invalid characters
+			ctx:  context.Background(),
+			name: "case 7",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: `{"team":"platform; drop"}`,
+			},
+			valid: false,
+		},
+		{
+			// too many tags
+			ctx:  context.Background(),
+			name: "case 8",
+
+			annotations: map[string]string{
+				aws.AnnotationAWSTags: `{"a":"1","b":"2","c":"3"}`,
+			},
+			valid: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				awsTagsMaxEntries: 2,
+				k8sClient:         unittest.FakeK8sClient(),
+				logger:            microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+
+			// check if the result is as expected
+			err = handle.AWSClusterTagsValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterProxy(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// no proxy configured
+			ctx:  context.Background(),
+			name: "case 0",
+
+			annotations: map[string]string{},
+			valid:       true,
+		},
+		{
+			// valid proxy configuration
+			ctx:  context.Background(),
+			name: "case 1",
+
+			annotations: map[string]string{
+				aws.AnnotationHTTPProxy:  "http://proxy.example.com:3128",
+				aws.AnnotationHTTPSProxy: "https://proxy.example.com:3129",
+				aws.AnnotationNoProxy:    "10.0.0.0/8, 172.31.0.0/16, .svc, cluster.local, 169.254.169.254, *.example.com",
+			},
+			valid: true,
+		},
+		{
+			// incomplete no proxy list is only a warning
+			ctx:  context.Background(),
+			name: "case 2",
+
+			annotations: map[string]string{
+				aws.AnnotationHTTPProxy: "http://proxy.example.com:3128",
+			},
+			valid: true,
+		},
+		{
+			// proxy without scheme
+			ctx:  context.Background(),
+			name: "case 3",
+
+			annotations: map[string]string{
+				aws.AnnotationHTTPProxy: "proxy.example.com:3128",
+			},
+			valid: false,
+		},
+		{
+			// proxy with unsupported scheme
+			ctx:  context.Background(),
+			name: "case 4",
+
+			annotations: map[string]string{
+				aws.AnnotationHTTPSProxy: "socks5://proxy.example.com:1080",
+			},
+			valid: false,
+		},
+		{
+			// invalid no proxy entry
+			ctx:  context.Background(),
+			name: "case 5",
+
+			annotations: map[string]string{
+				aws.AnnotationHTTPProxy: "http://proxy.example.com:3128",
+				aws.AnnotationNoProxy:   "10.0.0.0/8,not a domain",
+			},
+			valid: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient:                unittest.FakeK8sClient(),
+				kubernetesClusterIPRange: "172.31.0.0/16",
+				logger:                   microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+
+			// check if the result is as expected
+			err = handle.AWSClusterAnnotationPolicyValid(awsCluster)
+			if err == nil {
+				err = handle.AWSClusterProxyValid(awsCluster)
+			}
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterDNSDomain(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		domain string
+		valid  bool
+	}{
+		{
+			// installation base domain
+			ctx:  context.Background(),
+			name: "case 0",
+
+			domain: unittest.DefaultClusterDNSDomain,
+			valid:  true,
+		},
+		{
+			// domain not set yet
+			ctx:  context.Background(),
+			name: "case 1",
+
+			domain: "",
+			valid:  true,
+		},
+		{
+			// domain of another installation
+			ctx:  context.Background(),
+			name: "case 2",
+
+			domain: "ginger.eu-west-1.aws.gigantic.io",
+			valid:  false,
+		},
+		{
+			// full cluster domain instead of the base domain
+			ctx:  context.Background(),
+			name: "case 3",
+
+			domain: unittest.DefaultClusterID + ".k8s." + unittest.DefaultClusterDNSDomain,
+			valid:  false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				dnsDomain: unittest.DefaultClusterDNSDomain,
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Cluster.DNS.Domain = tc.domain
+
+			// check if the result is as expected
+			err = handle.AWSClusterDNSDomainValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterImmutableFields(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		created    bool
+		oldRegion  string
+		newRegion  string
+		oldPodCIDR string
+		newPodCIDR string
+		oldCIDR    string
+		newCIDR    string
+		valid      bool
+	}{
+		{
+			// nothing changed
+			ctx:  context.Background(),
+			name: "case 0",
+
+			created:    true,
+			oldRegion:  "eu-central-1",
+			newRegion:  "eu-central-1",
+			oldPodCIDR: "10.2.0.0/16",
+			newPodCIDR: "10.2.0.0/16",
+			oldCIDR:    "172.19.73.0/24",
+			newCIDR:    "172.19.73.0/24",
+			valid:      true,
+		},
+		{
+			// region changed before the cluster was created
+			ctx:  context.Background(),
+			name: "case 1",
+
+			created:    false,
+			oldRegion:  "eu-central-1",
+			newRegion:  "eu-west-1",
+			oldPodCIDR: "10.2.0.0/16",
+			newPodCIDR: "10.2.0.0/16",
+			valid:      true,
+		},
+		{
+			// region changed after the cluster was created
+			ctx:  context.Background(),
+			name: "case 2",
+
+			created:    true,
+			oldRegion:  "eu-central-1",
+			newRegion:  "eu-west-1",
+			oldPodCIDR: "10.2.0.0/16",
+			newPodCIDR: "10.2.0.0/16",
+			valid:      false,
+		},
+		{
+			// pod CIDR changed after the cluster was created
+			ctx:  context.Background(),
+			name: "case 3",
+
+			created:    true,
+			oldRegion:  "eu-central-1",
+			newRegion:  "eu-central-1",
+			oldPodCIDR: "10.2.0.0/16",
+			newPodCIDR: "10.3.0.0/16",
+			valid:      false,
+		},
+		{
+			// network CIDR assigned after the cluster was created
+			ctx:  context.Background(),
+			name: "case 4",
+
+			created:    true,
+			oldRegion:  "eu-central-1",
+			newRegion:  "eu-central-1",
+			oldPodCIDR: "10.2.0.0/16",
+			newPodCIDR: "10.2.0.0/16",
+			oldCIDR:    "",
+			newCIDR:    "172.19.73.0/24",
+			valid:      true,
+		},
+		{
+			// network CIDR changed after the cluster was created
+			ctx:  context.Background(),
+			name: "case 5",
+
+			created:    true,
+			oldRegion:  "eu-central-1",
+			newRegion:  "eu-central-1",
+			oldPodCIDR: "10.2.0.0/16",
+			newPodCIDR: "10.2.0.0/16",
+			oldCIDR:    "172.19.73.0/24",
+			newCIDR:    "172.19.74.0/24",
+			valid:      false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			oldAWSCluster := unittest.DefaultAWSCluster()
+			oldAWSCluster.Spec.Provider.Region = tc.oldRegion
+			oldAWSCluster.Spec.Provider.Pods.CIDRBlock = tc.oldPodCIDR
+			oldAWSCluster.Status.Provider.Network.CIDR = tc.oldCIDR
+			if !tc.created {
+				oldAWSCluster.Status.Cluster.Conditions = nil
+			}
+
+			newAWSCluster := unittest.DefaultAWSCluster()
+			newAWSCluster.Spec.Provider.Region = tc.newRegion
+			newAWSCluster.Spec.Provider.Pods.CIDRBlock = tc.newPodCIDR
+			newAWSCluster.Status.Provider.Network.CIDR = tc.newCIDR
+
+			// check if the result is as expected
+			err = handle.AWSClusterImmutableFieldsValid(oldAWSCluster, newAWSCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterCredentialSecretUpdate(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		oldSecret   string
+		newSecret   string
+		annotations map[string]string
+		groups      []string
+		valid       bool
+	}{
+		{
+			// credential secret unchanged
+			ctx:  context.Background(),
+			name: "case 0",
+
+			oldSecret: "example-credential",
+			newSecret: "example-credential",
+			valid:     true,
+		},
+		{
+			// credential secret changed
+			ctx:  context.Background(),
+			name: "case 1",
+
+			oldSecret: "example-credential",
+			newSecret: "other-credential",
+			valid:     false,
+		},
+		{
+			// credential secret changed with override annotation by a user in a restricted group
+			ctx:  context.Background(),
+			name: "case 2",
+
+			oldSecret:   "example-credential",
+			newSecret:   "other-credential",
+			annotations: map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			groups:      []string{"giantswarm-admins"},
+			valid:       true,
+		},
+		{
+			// credential secret set for the first time
+			ctx:  context.Background(),
+			name: "case 3",
+
+			oldSecret: "",
+			newSecret: "example-credential",
+			valid:     true,
+		},
+		{
+			// credential secret changed with override annotation by a user outside the restricted groups
+			ctx:  context.Background(),
+			name: "case 4",
+
+			oldSecret:   "example-credential",
+			newSecret:   "other-credential",
+			annotations: map[string]string{aws.AnnotationForceCredentialSecretChange: "true"},
+			groups:      []string{"customer-admins"},
+			valid:       false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient:        unittest.FakeK8sClient(),
+				logger:           microloggertest.New(),
+				restrictedGroups: []string{"giantswarm-admins"},
+			}
+
+			oldAWSCluster := unittest.DefaultAWSCluster()
+			oldAWSCluster.Spec.Provider.CredentialSecret.Name = tc.oldSecret
+
+			newAWSCluster := unittest.DefaultAWSCluster()
+			newAWSCluster.Spec.Provider.CredentialSecret.Name = tc.newSecret
+			newAWSCluster.SetAnnotations(tc.annotations)
+
+			// check if the result is as expected
+			err = handle.AWSClusterCredentialSecretUpdateValid(oldAWSCluster, newAWSCluster, authenticationv1.UserInfo{Username: "jane", Groups: tc.groups})
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterCredentialSecret(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		secretNamespace string
+		secretARN       string
+		refName         string
+		refNamespace    string
+		valid           bool
+	}{
+		{
+			// valid secret in the default credential namespace
+			ctx:  context.Background(),
+			name: "case 0",
+
+			secretNamespace: "giantswarm",
+			secretARN:       "arn:aws:iam::123456789012:role/GiantSwarmAWSOperator",
+			refName:         "example-credential",
+			refNamespace:    "giantswarm",
+			valid:           true,
+		},
+		{
+			// valid secret in the cluster namespace
+			ctx:  context.Background(),
+			name: "case 1",
+
+			secretNamespace: "default",
+			secretARN:       "arn:aws-cn:iam::123456789012:role/path/GiantSwarmAWSOperator",
+			refName:         "example-credential",
+			refNamespace:    "default",
+			valid:           true,
+		},
+		{
+			// no credential secret referenced
+			ctx:  context.Background(),
+			name: "case 2",
+
+			secretNamespace: "giantswarm",
+			valid:           true,
+		},
+		{
+			// secret does not exist
+			ctx:  context.Background(),
+			name: "case 3",
+
+			secretNamespace: "giantswarm",
+			secretARN:       "arn:aws:iam::123456789012:role/GiantSwarmAWSOperator",
+			refName:         "missing",
+			refNamespace:    "giantswarm",
+			valid:           false,
+		},
+		{
+			// secret in an unexpected namespace
+			ctx:  context.Background(),
+			name: "case 4",
+
+			secretNamespace: "example-namespace",
+			secretARN:       "arn:aws:iam::123456789012:role/GiantSwarmAWSOperator",
+			refName:         "example-credential",
+			refNamespace:    "example-namespace",
+			valid:           false,
+		},
+		{
+			// ARN of a user instead of a role
+			ctx:  context.Background(),
+			name: "case 5",
+
+			secretNamespace: "giantswarm",
+			secretARN:       "arn:aws:iam::123456789012:user/admin",
+			refName:         "example-credential",
+			refNamespace:    "giantswarm",
+			valid:           false,
+		},
+		{
+			// secret without ARN
+			ctx:  context.Background(),
+			name: "case 6",
+
+			secretNamespace: "giantswarm",
+			refName:         "example-credential",
+			refNamespace:    "giantswarm",
+			valid:           false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			secret := unittest.DefaultClusterCredentialSecret()
+			secret.SetNamespace(tc.secretNamespace)
+			if tc.secretARN != "" {
+				secret.Data = map[string][]byte{
+					aws.CredentialSecretARNKey: []byte(tc.secretARN),
+				}
+			}
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Provider.CredentialSecret.Name = tc.refName
+			awsCluster.Spec.Provider.CredentialSecret.Namespace = tc.refNamespace
+
+			err = validate.AWSClusterCredentialSecretValid(awsCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAWSClusterIRSA(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		oldAnnotations map[string]string
+		annotations    map[string]string
+		releaseVersion string
+		valid          bool
+	}{
+		{
+			// no IRSA annotations
+			name: "case 0",
+
+			oldAnnotations: map[string]string{},
+			annotations:    map[string]string{},
+			releaseVersion: "100.0.0",
+			valid:          true,
+		},
+		{
+			// IRSA enabled with OIDC settings
+			name: "case 1",
+
+			oldAnnotations: map[string]string{},
+			annotations: map[string]string{
+				aws.AnnotationIRSA:           "true",
+				aws.AnnotationIRSAOIDCBucket: "8y5ck-oidc-pod-identity",
+				aws.AnnotationIRSAOIDCDomain: "irsa.gauss.eu-west-1.aws.gigantic.io",
+			},
+			releaseVersion: "100.0.0",
+			valid:          true,
+		},
+		{
+			// invalid enable value
+			name: "case 2",
+
+			oldAnnotations: map[string]string{},
+			annotations:    map[string]string{aws.AnnotationIRSA: "yes"},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// OIDC settings without IRSA
+			name: "case 3",
+
+			oldAnnotations: map[string]string{},
+			annotations:    map[string]string{aws.AnnotationIRSAOIDCBucket: "8y5ck-oidc-pod-identity"},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// invalid bucket name
+			name: "case 4",
+
+			oldAnnotations: map[string]string{},
+			annotations: map[string]string{
+				aws.AnnotationIRSA:           "true",
+				aws.AnnotationIRSAOIDCBucket: "Invalid_Bucket",
+			},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// invalid domain
+			name: "case 5",
+
+			oldAnnotations: map[string]string{},
+			annotations: map[string]string{
+				aws.AnnotationIRSA:           "true",
+				aws.AnnotationIRSAOIDCDomain: "https://irsa.example.com",
+			},
+			releaseVersion: "100.0.0",
+			valid:          false,
+		},
+		{
+			// release does not support IRSA
+			name: "case 6",
+
+			oldAnnotations: map[string]string{},
+			annotations:    map[string]string{aws.AnnotationIRSA: "true"},
+			releaseVersion: "15.1.0",
+			valid:          false,
+		},
+		{
+			// IRSA removed in a release which does not support disabling it
+			name: "case 7",
+
+			oldAnnotations: map[string]string{aws.AnnotationIRSA: "true"},
+			annotations:    map[string]string{},
+			releaseVersion: "18.0.0",
+			valid:          false,
+		},
+		{
+			// IRSA disabled in a release which supports disabling it
+			name: "case 8",
+
+			oldAnnotations: map[string]string{aws.AnnotationIRSA: "true"},
+			annotations:    map[string]string{aws.AnnotationIRSA: "false"},
+			releaseVersion: "19.0.0",
+			valid:          true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			oldAWSCluster := unittest.DefaultAWSCluster()
+			oldAWSCluster.SetAnnotations(tc.oldAnnotations)
+			oldAWSCluster.Labels[label.Release] = tc.releaseVersion
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+			awsCluster.Labels[label.Release] = tc.releaseVersion
+
+			err = validate.AWSClusterIRSAValid(awsCluster)
+			if err == nil {
+				err = validate.AWSClusterIRSAUpdateValid(oldAWSCluster, awsCluster)
+			}
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package awscontrolplane
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -18,10 +19,13 @@ import (
 )
 
 type Mutator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
+	defaultInstanceType    string
 	validAvailabilityZones []string
+	validMasterAZCounts    []int
 }
 
 func NewMutator(config config.Config) (*Mutator, error) {
@@ -33,17 +37,25 @@ func NewMutator(config config.Config) (*Mutator, error) {
 	}
 
 	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
+	masterAZCounts, err := aws.ParseMasterAZCounts(config.MasterAZCounts)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MasterAZCounts are invalid: %v", config, err)
+	}
 	mutator := &Mutator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
+		defaultInstanceType:    config.MasterInstanceType,
 		validAvailabilityZones: availabilityZones,
+		validMasterAZCounts:    masterAZCounts,
 	}
 
 	return mutator, nil
 }
 
-func (m *Mutator) Mutate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
 	var result []mutator.PatchOperation
 
 	if request.DryRun != nil && *request.DryRun {
@@ -69,13 +81,25 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
 	}
 
+	patch, err = m.MutateClusterLabels(awsControlPlaneCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateOwnerReference(*awsControlPlaneCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*awsControlPlaneCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	releaseVersion, err := aws.ReleaseVersion(awsControlPlaneCR, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSControlPlane")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSControlPlane: %v", err)
 	}
 	result = append(result, patch...)
 
@@ -93,7 +117,7 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 
 	// We try to fetch the G8sControlPlane belonging to the AWSControlPlane here.
 	replicas := 0
-	g8sControlPlane, err := aws.FetchG8sControlPlane(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlaneCR)
+	g8sControlPlane, err := aws.FetchG8sControlPlane(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlaneCR)
 	if aws.IsNotFound(err) {
 		// Note that while we do log the error, we don't fail if the G8sControlPlane doesn't exist yet. That is okay because the order of CR creation can vary.
 		m.Log("level", "debug", "message", fmt.Sprintf("No G8sControlPlane %s could be found: %v", awsControlPlaneCR.GetName(), err))
@@ -139,12 +163,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	releaseVersion, err := aws.ReleaseVersion(awsControlPlaneCR, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSControlPlane")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from AWSControlPlane: %v", err)
 	}
 
 	// We try to fetch the G8sControlPlane belonging to the AWSControlPlane here.
 	replicas := 0
-	g8sControlPlane, err := aws.FetchG8sControlPlane(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlaneCR)
+	g8sControlPlane, err := aws.FetchG8sControlPlane(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlaneCR)
 	if aws.IsNotFound(err) {
 		// Note that while we do log the error, we don't fail if the G8sControlPlane doesn't exist yet. That is okay because the order of CR creation can vary.
 		m.Log("level", "debug", "message", fmt.Sprintf("No G8sControlPlane %s could be found: %v", awsControlPlaneCR.GetName(), err))
@@ -185,7 +209,7 @@ func (m *Mutator) MutatePreHA(awsControlPlane infrastructurev1alpha2.AWSControlP
 	var patch []mutator.PatchOperation
 	var err error
 
-	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
 	if aws.IsNotFound(err) {
 		// Note that while we do log the error, we don't fail if the AWSCluster doesn't exist yet. That is okay because the order of CR creation can vary.
 		// In this case we simply default as usual with one AZ.
@@ -219,24 +243,31 @@ func (m *Mutator) MutatePreHA(awsControlPlane infrastructurev1alpha2.AWSControlP
 	return result, nil
 }
 
+// MutateAvailabilityZones defaults the master AZs when they are not set or fewer than the replicas of the
+// G8sControlPlane. The added AZs are the ones least used by the control planes of the installation.
 func (m *Mutator) MutateAvailabilityZones(replicas int, awsControlPlaneCR infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
-	// We only need to manipulate if AZs are not set
-	if awsControlPlaneCR.Spec.AvailabilityZones != nil {
+	// We only need to manipulate if AZs are not set or missing
+	if awsControlPlaneCR.Spec.AvailabilityZones != nil && len(awsControlPlaneCR.Spec.AvailabilityZones) >= replicas {
 		return result, nil
 	}
 	var numberOfAZs int
 	{
-		numberOfAZs = aws.DefaultMasterReplicas
+		numberOfAZs = aws.DefaultMasterAZCount(m.validMasterAZCounts)
 		// If there is a G8sControlPlane, the default AZs match the replicas
 		if replicas != 0 {
 			numberOfAZs = replicas
 		}
 	}
 	// Trigger defaulting of the master availability zones
-	m.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane %s AvailabilityZones is nil and will be defaulted", awsControlPlaneCR.ObjectMeta.Name))
-	// We default the AZs
-	defaultedAZs := aws.GetNavailabilityZones(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, numberOfAZs, m.validAvailabilityZones)
+	m.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane %s AvailabilityZones %v will be defaulted to %v AZs", awsControlPlaneCR.ObjectMeta.Name, awsControlPlaneCR.Spec.AvailabilityZones, numberOfAZs))
+	// We balance the AZs across the control planes of the installation
+	awsControlPlanes, err := aws.FetchAWSControlPlanes(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	usage := aws.GetAvailabilityZoneUsage(awsControlPlanes)
+	defaultedAZs := aws.GetLeastUsedAvailabilityZones(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, numberOfAZs, m.validAvailabilityZones, usage, awsControlPlaneCR.Spec.AvailabilityZones)
 	patch := mutator.PatchAdd("/spec/availabilityZones", defaultedAZs)
 	result = append(result, patch)
 	return result, nil
@@ -262,13 +293,21 @@ func (m *Mutator) MutateInstanceType(awsControlPlaneCR infrastructurev1alpha2.AW
 	}
 	// Trigger defaulting of the master instance type
 	m.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane %s InstanceType is nil and will be defaulted", awsControlPlaneCR.ObjectMeta.Name))
-	patch := mutator.PatchAdd("/spec/instanceType", aws.DefaultMasterInstanceType)
+	patch := mutator.PatchAdd("/spec/instanceType", m.instanceType())
 	result = append(result, patch)
 	return result, nil
 }
 
+// instanceType returns the default master instance type of the installation.
+func (m *Mutator) instanceType() string {
+	if m.defaultInstanceType == "" {
+		return aws.DefaultMasterInstanceType
+	}
+	return m.defaultInstanceType
+}
+
 func (m *Mutator) MutateControlPlaneLabel(awsControlPlane infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
-	return aws.MutateLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane, label.ControlPlane, awsControlPlane.Name)
+	return aws.MutateLabel(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane, label.ControlPlane, awsControlPlane.Name)
 }
 
 func (m *Mutator) MutateInstanceTypePreHA(instanceType string, awsControlPlaneCR infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
@@ -293,13 +332,13 @@ func (m *Mutator) MutateOperatorVersion(awsControlPlane infrastructurev1alpha2.A
 		return result, nil
 	}
 	// Retrieve the `AWSCluster` CR related to this object.
-	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane, *awsCluster, label.AWSOperatorVersion)
+	patch, err = aws.MutateLabelFromAWSCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane, *awsCluster, label.AWSOperatorVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -308,6 +347,21 @@ func (m *Mutator) MutateOperatorVersion(awsControlPlane infrastructurev1alpha2.A
 	return result, nil
 }
 
+// MutateClusterLabels propagates the cluster ID and organization labels from the owning Cluster.
+// The labels of the given AWSControlPlane are updated to reflect the patch.
+func (m *Mutator) MutateClusterLabels(awsControlPlane *infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
+	patch, err := aws.MutateClusterLabels(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlane, aws.OwnerClusterID(awsControlPlane))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return patch, nil
+}
+
+// MutateOwnerReference sets the owning Cluster as owner of the AWSControlPlane.
+func (m *Mutator) MutateOwnerReference(awsControlPlane infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
+	return aws.MutateOwnerReference(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
+}
+
 func (m *Mutator) MutateReleaseVersion(awsControlPlane infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -317,13 +371,13 @@ func (m *Mutator) MutateReleaseVersion(awsControlPlane infrastructurev1alpha2.AW
 		return result, nil
 	}
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the release label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane, *cluster, label.Release)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsControlPlane, *cluster, label.Release)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -332,6 +386,13 @@ func (m *Mutator) MutateReleaseVersion(awsControlPlane infrastructurev1alpha2.AW
 	return result, nil
 }
 
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Mutator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
@@ -3,12 +3,15 @@ package awscontrolplane
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/microloggertest"
 	"github.com/giantswarm/ruleengine"
 	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
@@ -135,7 +138,7 @@ func TestAZAWSControlPlaneAdmit(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			patch, err = mutate.Mutate(request)
+			patch, err = mutate.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -255,3 +258,87 @@ func updatedAZinExpectedAZs(az string, expectedAZs []string) bool {
 	}
 	return false
 }
+
+func TestAZSpreading(t *testing.T) {
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		// if the Replicas are nil, it means no g8sControlPlane exists
+		g8sControlplaneReplicas *int
+		currentAvailabilityZone []string
+		// availability zones of the existing control planes in the installation
+		existingAvailabilityZones [][]string
+		expectAvailabilityZones   []string
+	}{
+		{
+			// The least used AZ is picked for a single master
+			name: "case 0",
+			ctx:  context.Background(),
+
+			g8sControlplaneReplicas:   ruleengine.ToIntPtr(1),
+			existingAvailabilityZones: [][]string{{"eu-central-1a"}, {"eu-central-1c"}, {"eu-central-1a", "eu-central-1b", "eu-central-1c"}},
+			expectAvailabilityZones:   []string{"eu-central-1b"},
+		},
+		{
+			// Missing AZs are added when there are fewer AZs than replicas
+			name: "case 1",
+			ctx:  context.Background(),
+
+			g8sControlplaneReplicas:   ruleengine.ToIntPtr(3),
+			currentAvailabilityZone:   []string{"eu-central-1c"},
+			existingAvailabilityZones: [][]string{{"eu-central-1a"}},
+			expectAvailabilityZones:   []string{"eu-central-1c", "eu-central-1a", "eu-central-1b"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			var updatedAZs []string
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Mutator{
+				validAvailabilityZones: unittest.DefaultAvailabilityZones(),
+				k8sClient:              fakeK8sClient,
+				logger:                 microloggertest.New(),
+			}
+
+			// create G8sControlPlane if needed
+			if tc.g8sControlplaneReplicas != nil {
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, g8sControlPlane(*tc.g8sControlplaneReplicas))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			// create the control planes of other clusters
+			for j, azs := range tc.existingAvailabilityZones {
+				awsControlPlane := unittest.DefaultAWSControlPlane()
+				awsControlPlane.SetName(fmt.Sprintf("other%d", j))
+				awsControlPlane.Spec.AvailabilityZones = azs
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsControlPlane)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			// run admission request to default AWSControlPlane AZ's
+			request, err := awsControlPlaneAdmissionRequest(tc.currentAvailabilityZone, "m4.xlarge", HAReleaseVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patch, err := mutate.Mutate(tc.ctx, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// parse patches
+			for _, p := range patch {
+				if p.Path == "/spec/availabilityZones" {
+					updatedAZs = p.Value.([]string)
+				}
+			}
+
+			if !reflect.DeepEqual(updatedAZs, tc.expectAvailabilityZones) {
+				t.Fatalf("expected %v to be equal to %v", tc.expectAvailabilityZones, updatedAZs)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ func TestInstanceTypeAWSControlPlaneAdmit(t *testing.T) {
 	testCases := []struct {
 		name                 string
 		ctx                  context.Context
+		defaultInstanceType  string
 		currentInstanceType  string
 		expectedInstanceType string
 	}{
@@ -36,6 +37,15 @@ func TestInstanceTypeAWSControlPlaneAdmit(t *testing.T) {
 			currentInstanceType:  "",
 			expectedInstanceType: aws.DefaultMasterInstanceType,
 		},
+		{
+			// Default the InstanceType to the installation default
+			name: "case 2",
+			ctx:  context.Background(),
+
+			defaultInstanceType:  "r5.xlarge",
+			currentInstanceType:  "",
+			expectedInstanceType: "r5.xlarge",
+		},
 	}
 
 	for i, tc := range testCases {
@@ -53,6 +63,7 @@ func TestInstanceTypeAWSControlPlaneAdmit(t *testing.T) {
 			}
 			fakeK8sClient := unittest.FakeK8sClient()
 			mutate := &Mutator{
+				defaultInstanceType:    tc.defaultInstanceType,
 				validAvailabilityZones: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
 				k8sClient:              fakeK8sClient,
 				logger:                 newLogger,
@@ -64,7 +75,7 @@ func TestInstanceTypeAWSControlPlaneAdmit(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			patch, err = mutate.Mutate(request)
+			patch, err = mutate.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
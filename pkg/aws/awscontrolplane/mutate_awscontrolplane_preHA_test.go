@@ -96,7 +96,7 @@ func TestPreHAAWSControlPlaneAdmit(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			patch, err = mutate.Mutate(request)
+			patch, err = mutate.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -3,6 +3,7 @@ package awscontrolplane
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
@@ -10,20 +11,33 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/ec2offering"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
 )
 
 type Validator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
-	validAvailabilityZones []string
-	validInstanceTypes     []string
+	defaultInstanceType     string
+	denyOrphans             bool
+	instanceTypeOfferings   ec2offering.Interface
+	instanceTypePolicy      types.NamespacedName
+	minCPU                  int
+	minMemory               int
+	unknownAnnotationPolicy string
+	validAvailabilityZones  []string
+	validInstanceFamilies   []string
+	validInstanceTypes      []string
+	validMasterAZCounts     []int
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -37,19 +51,40 @@ func NewValidator(config config.Config) (*Validator, error) {
 	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
 	var instanceTypes []string = strings.Split(config.MasterInstanceTypes, ",")
 
+	instanceTypePolicy, err := aws.ParseInstanceTypePolicyRef(config.InstanceTypePolicy)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstanceTypePolicy is invalid: %v", config, err)
+	}
+	masterAZCounts, err := aws.ParseMasterAZCounts(config.MasterAZCounts)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MasterAZCounts are invalid: %v", config, err)
+	}
+
 	validator := &Validator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
-		validAvailabilityZones: availabilityZones,
-		validInstanceTypes:     instanceTypes,
+		defaultInstanceType:     config.MasterInstanceType,
+		denyOrphans:             config.DenyOrphans,
+		instanceTypeOfferings:   config.InstanceTypeOfferings,
+		instanceTypePolicy:      instanceTypePolicy,
+		minCPU:                  config.MasterMinCPU,
+		minMemory:               config.MasterMinMemory,
+		unknownAnnotationPolicy: config.UnknownAnnotationPolicy,
+		validAvailabilityZones:  availabilityZones,
+		validInstanceFamilies:   aws.ParseInstanceFamilies(config.MasterInstanceFamilies),
+		validInstanceTypes:      instanceTypes,
+		validMasterAZCounts:     masterAZCounts,
 	}
 
 	return validator, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
 	var awsControlPlane infrastructurev1alpha2.AWSControlPlane
+	var awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane
 	var g8sControlPlane *infrastructurev1alpha2.G8sControlPlane
 	var err error
 
@@ -68,14 +103,17 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
-	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), &awsControlPlane)
+	err = v.AnnotationPolicyValid(awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), &awsControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
 	// The order can only change on update
 	if request.Operation == admissionv1.Update {
-		var awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane
 		if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsControlPlaneOld); err != nil {
 			return false, microerror.Maskf(parsingFailedError, "unable to parse old awscontrol plane: %v", err)
 		}
@@ -83,6 +121,72 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 		if err != nil {
 			return false, microerror.Mask(err)
 		}
+		err = v.AZAdditive(awsControlPlane, awsControlPlaneOld)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.HAMigrationValid(awsControlPlaneOld, awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		if awsControlPlane.Spec.InstanceType != awsControlPlaneOld.Spec.InstanceType {
+			err = v.InstanceTypePolicyValid(awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+			err = v.InstanceFamilyValid(awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+			err = v.InstanceSizeValid(awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+			err = v.InstanceTypeUpdateValid(awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+			err = v.ArchitectureValid(awsControlPlaneOld, awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+		}
+		if awsControlPlane.Spec.InstanceType != awsControlPlaneOld.Spec.InstanceType ||
+			!reflect.DeepEqual(awsControlPlane.Spec.AvailabilityZones, awsControlPlaneOld.Spec.AvailabilityZones) {
+			err = v.InstanceTypeOfferedValid(awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+			err = v.UpgradeConflictValid(awsControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+		}
+	} else {
+		err = v.OrphanValid(awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.SingleControlPlaneValid(awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.InstanceTypePolicyValid(awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.InstanceFamilyValid(awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.InstanceSizeValid(awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.InstanceTypeOfferedValid(awsControlPlane)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
 	}
 	err = v.AZUnique(awsControlPlane)
 	if err != nil {
@@ -93,7 +197,7 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 		return false, microerror.Mask(err)
 	}
 	// We try to fetch the G8sControlPlane belonging to the AWSControlPlane here.
-	g8sControlPlane, err = aws.FetchG8sControlPlane(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane)
+	g8sControlPlane, err = aws.FetchG8sControlPlane(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane)
 	if aws.IsNotFound(err) {
 		// Note that while we do log the error, we don't fail if the G8sControlPlane doesn't exist yet. That is okay because the order of CR creation can vary.
 		v.Log("level", "debug", "message", fmt.Sprintf("No G8sControlPlane %s could be found: %v", awsControlPlane.GetName(), err))
@@ -105,12 +209,16 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 		if err != nil {
 			return false, microerror.Mask(err)
 		}
-		// when updating from single to HA validation of AZ replicas has to be ignored
 		if request.Operation == admissionv1.Create {
 			err = v.AZReplicaMatch(awsControlPlane, *g8sControlPlane)
 			if err != nil {
 				return false, microerror.Mask(err)
 			}
+		} else {
+			err = v.AZReplicaUpdateMatch(awsControlPlaneOld, awsControlPlane, *g8sControlPlane)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
 		}
 	}
 	return true, nil
@@ -136,17 +244,43 @@ func (v *Validator) AZReplicaMatch(awsControlPlane infrastructurev1alpha2.AWSCon
 
 	return nil
 }
+
+// AZReplicaUpdateMatch checks that a changed number of master availability zones matches the replicas of the
+// G8sControlPlane. Otherwise aws-operator would reconcile both objects towards conflicting desired states. The
+// update from single to HA masters is ignored since the AZs are updated before the replicas in that case.
+func (v *Validator) AZReplicaUpdateMatch(awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane, awsControlPlane infrastructurev1alpha2.AWSControlPlane, g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
+	if len(awsControlPlane.Spec.AvailabilityZones) == len(awsControlPlaneOld.Spec.AvailabilityZones) {
+		return nil
+	}
+	if isUpdateFromSingleToHA(awsControlPlaneOld, awsControlPlane, g8sControlPlane) {
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane %s is updated from single to HA masters", key.ControlPlane(&awsControlPlane)))
+		return nil
+	}
+	return v.AZReplicaMatch(awsControlPlane, g8sControlPlane)
+}
+
+// HAMigrationValid checks that the migration from a single master AZ to HA master AZs is only done while the
+// cluster is in a stable state. This also covers the AZs updated by the G8sControlPlane mutator.
+func (v *Validator) HAMigrationValid(awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane, awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	if len(awsControlPlaneOld.Spec.AvailabilityZones) != 1 || len(awsControlPlane.Spec.AvailabilityZones) <= 1 {
+		return nil
+	}
+	return aws.ValidateClusterTransitioned(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane, "AWSControlPlane", "be migrated to HA masters")
+}
+
+// AZCount checks that the number of master availability zones is one of the counts allowed in the installation.
 func (v *Validator) AZCount(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
-	if !aws.IsValidMasterReplicas(len(awsControlPlane.Spec.AvailabilityZones)) {
+	validCounts := v.masterAZCounts()
+	if !aws.IsValidMasterAZCount(len(awsControlPlane.Spec.AvailabilityZones), validCounts) {
 		v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane %s has an invalid count of %v availability zones. Valid AZ counts are: %v",
 			key.ControlPlane(&awsControlPlane),
 			len(awsControlPlane.Spec.AvailabilityZones),
-			aws.ValidMasterReplicas()),
+			validCounts),
 		)
 		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSControlPlane %s has an invalid count of %v availability zones. Valid AZ counts are: %v",
 			key.ControlPlane(&awsControlPlane),
 			len(awsControlPlane.Spec.AvailabilityZones),
-			aws.ValidMasterReplicas()),
+			validCounts),
 		)
 	}
 
@@ -167,6 +301,26 @@ func (v *Validator) AZOrder(awsControlPlane infrastructurev1alpha2.AWSControlPla
 	}
 	return nil
 }
+
+// AZAdditive checks that master availability zones are only added to an existing AWSControlPlane, because removing
+// or replacing an AZ destroys the etcd member running in it. The check can be skipped with the
+// AnnotationForceAvailabilityZoneChange annotation.
+func (v *Validator) AZAdditive(awsControlPlane infrastructurev1alpha2.AWSControlPlane, awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane) error {
+	if isPrefix(awsControlPlaneOld.Spec.AvailabilityZones, awsControlPlane.Spec.AvailabilityZones) {
+		return nil
+	}
+	if aws.IsAnnotationTrue(&awsControlPlane, aws.AnnotationForceAvailabilityZoneChange) {
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane %s AZ change check is skipped due to annotation %s", key.ControlPlane(&awsControlPlane), aws.AnnotationForceAvailabilityZoneChange))
+		return nil
+	}
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSControlPlane %s AZs can not be changed from %v to %v. AZs can only be added because removing an AZ destroys its etcd member. Set annotation %s to \"true\" to change them anyway.",
+		key.ControlPlane(&awsControlPlane),
+		awsControlPlaneOld.Spec.AvailabilityZones,
+		awsControlPlane.Spec.AvailabilityZones,
+		aws.AnnotationForceAvailabilityZoneChange),
+	)
+}
+
 func (v *Validator) AZUnique(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
 	// We always want to select as many distinct AZs as possible
 	distinctAZs := countUniqueValues(awsControlPlane.Spec.AvailabilityZones)
@@ -234,10 +388,123 @@ func (v *Validator) InstanceTypeValid(awsControlPlane infrastructurev1alpha2.AWS
 	return nil
 }
 
+// InstanceFamilyValid checks that a master instance type other than the installation default is of a family
+// suitable for masters.
+func (v *Validator) InstanceFamilyValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	if len(v.validInstanceFamilies) == 0 || awsControlPlane.Spec.InstanceType == "" {
+		return nil
+	}
+	if awsControlPlane.Spec.InstanceType == v.instanceType() {
+		return nil
+	}
+	if !contains(v.validInstanceFamilies, aws.InstanceTypeFamily(awsControlPlane.Spec.InstanceType)) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSControlPlane %s master instance type %v is not of an instance family suitable for masters. Valid instance families are: %v",
+			key.ControlPlane(&awsControlPlane),
+			awsControlPlane.Spec.InstanceType,
+			v.validInstanceFamilies),
+		)
+	}
+
+	return nil
+}
+
+// InstanceSizeValid checks that the master instance type has the minimum number of vCPUs and memory of the
+// installation, since undersized masters lead to an unstable etcd.
+func (v *Validator) InstanceSizeValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	return aws.ValidateInstanceTypeMinimum(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane, "AWSControlPlane", awsControlPlane.Spec.InstanceType, v.minCPU, v.minMemory)
+}
+
+// InstanceTypeUpdateValid checks that the master instance type is only changed while the cluster is in a stable
+// state, because all masters are rolled to apply it.
+func (v *Validator) InstanceTypeUpdateValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	return aws.ValidateClusterTransitioned(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane, "AWSControlPlane", "change its master instance type")
+}
+
+// UpgradeConflictValid checks that the master instance type and availability zones are not changed while a release
+// upgrade of the cluster is pending or ongoing, because overlapping rolling operations are not supported.
+func (v *Validator) UpgradeConflictValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	return aws.ValidateNoPendingUpgrade(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane, "AWSControlPlane", "change its masters")
+}
+
+// ArchitectureValid checks that the master instance type is not changed to an instance type of another CPU
+// architecture, since the masters are rolled with the machine image of the existing architecture.
+func (v *Validator) ArchitectureValid(awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane, awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	oldArchitecture := aws.InstanceTypeArchitecture(awsControlPlaneOld.Spec.InstanceType)
+	newArchitecture := aws.InstanceTypeArchitecture(awsControlPlane.Spec.InstanceType)
+	if awsControlPlaneOld.Spec.InstanceType == "" || oldArchitecture == newArchitecture {
+		return nil
+	}
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSControlPlane %s master instance type can not be changed from %s with architecture %s to %s with architecture %s.",
+		key.ControlPlane(&awsControlPlane),
+		awsControlPlaneOld.Spec.InstanceType,
+		oldArchitecture,
+		awsControlPlane.Spec.InstanceType,
+		newArchitecture),
+	)
+}
+
+// Warnings returns a warning when the master instance type of an existing AWSControlPlane changes, because all
+// masters are replaced one by one.
+func (v *Validator) Warnings(ctx context.Context, request *admissionv1.AdmissionRequest) []string {
+	v = v.withContext(ctx)
+	if request.Operation != admissionv1.Update {
+		return nil
+	}
+	var awsControlPlane infrastructurev1alpha2.AWSControlPlane
+	var awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsControlPlane); err != nil {
+		return nil
+	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsControlPlaneOld); err != nil {
+		return nil
+	}
+	if awsControlPlaneOld.Spec.InstanceType == "" || awsControlPlane.Spec.InstanceType == awsControlPlaneOld.Spec.InstanceType {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("Changing the master instance type of AWSControlPlane %s from %s to %s rolls all %d master nodes one by one. The Kubernetes API may be briefly unavailable while single masters are replaced.",
+			key.ControlPlane(&awsControlPlane),
+			awsControlPlaneOld.Spec.InstanceType,
+			awsControlPlane.Spec.InstanceType,
+			len(awsControlPlane.Spec.AvailabilityZones)),
+	}
+}
+
+// AnnotationPolicyValid checks the AWS annotations of the control plane against the registry of known annotations.
+func (v *Validator) AnnotationPolicyValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	return aws.ValidateAnnotationPolicy(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane, "AWSControlPlane", v.unknownAnnotationPolicy)
+}
+
+// InstanceTypePolicyValid checks the master instance type against the instance type policy of the organization.
+func (v *Validator) InstanceTypePolicyValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	return aws.ValidateInstanceTypePolicy(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, v.instanceTypePolicy, &awsControlPlane, "AWSControlPlane", awsControlPlane.Spec.InstanceType)
+}
+
+// InstanceTypeOfferedValid checks that the master instance type exists and is offered in all master availability zones.
+func (v *Validator) InstanceTypeOfferedValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	return aws.ValidateInstanceTypeOffered(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, v.instanceTypeOfferings, &awsControlPlane, "AWSControlPlane", awsControlPlane.Spec.InstanceType, awsControlPlane.Spec.AvailabilityZones)
+}
+
 func (v *Validator) ControlPlaneLabelSet(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
 	return aws.ValidateLabelSet(&awsControlPlane, label.ControlPlane)
 }
 
+// masterAZCounts returns the allowed numbers of master availability zones of the installation.
+func (v *Validator) masterAZCounts() []int {
+	if len(v.validMasterAZCounts) == 0 {
+		return aws.ValidMasterReplicas()
+	}
+	return v.validMasterAZCounts
+}
+
+// instanceType returns the default master instance type of the installation.
+func (v *Validator) instanceType() string {
+	if v.defaultInstanceType == "" {
+		return aws.DefaultMasterInstanceType
+	}
+	return v.defaultInstanceType
+}
+
 func (v *Validator) isValidMasterAvailabilityZones(availabilityZones []string) bool {
 	for _, az := range availabilityZones {
 		if !contains(v.validAvailabilityZones, az) {
@@ -261,6 +528,20 @@ func countUniqueValues(s []string) int {
 	}
 	return len(counter)
 }
+
+// isPrefix returns whether the old values are the first values of the new ones.
+func isPrefix(old []string, new []string) bool {
+	if len(old) > len(new) {
+		return false
+	}
+	for i, o := range old {
+		if new[i] != o {
+			return false
+		}
+	}
+	return true
+}
+
 func orderChanged(old []string, new []string) bool {
 	if len(old) <= len(new) {
 		for i, o := range old {
@@ -282,6 +563,34 @@ func orderChanged(old []string, new []string) bool {
 	return false
 }
 
+// SingleControlPlaneValid checks that no other AWSControlPlane exists for the cluster.
+func (v *Validator) SingleControlPlaneValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	awsControlPlanes, err := aws.FetchAWSControlPlanes(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	var controlPlanes []metav1.Object
+	for i := range awsControlPlanes {
+		controlPlanes = append(controlPlanes, &awsControlPlanes[i])
+	}
+	return aws.ValidateSingleControlPlane(&awsControlPlane, "AWSControlPlane", controlPlanes)
+}
+
+// OrphanValid checks that the Cluster referenced by the AWSControlPlane exists when orphan objects are denied.
+func (v *Validator) OrphanValid(awsControlPlane infrastructurev1alpha2.AWSControlPlane) error {
+	if !v.denyOrphans {
+		return nil
+	}
+	return aws.ValidateClusterExists(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane)
+}
+
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (v *Validator) Log(keyVals ...interface{}) {
 	v.logger.Log(keyVals...)
 }
@@ -289,3 +598,7 @@ func (v *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "awscontrolplane"
 }
+
+func isUpdateFromSingleToHA(awsControlPlaneOld infrastructurev1alpha2.AWSControlPlane, awsControlPlane infrastructurev1alpha2.AWSControlPlane, g8sControlPlane infrastructurev1alpha2.G8sControlPlane) bool {
+	return len(awsControlPlaneOld.Spec.AvailabilityZones) == 1 && len(awsControlPlane.Spec.AvailabilityZones) == 3 && g8sControlPlane.Spec.Replicas == 1
+}
@@ -2,11 +2,17 @@ package awscontrolplane
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"testing"
 
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
 
@@ -64,7 +70,7 @@ func TestAZReplicaMatch(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(&admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, &admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
@@ -77,8 +83,9 @@ func TestAZCount(t *testing.T) {
 		ctx  context.Context
 		name string
 
-		allowed bool
-		azs     []string
+		allowed     bool
+		azs         []string
+		validCounts []int
 	}{
 		{
 			ctx:  context.Background(),
@@ -101,6 +108,32 @@ func TestAZCount(t *testing.T) {
 			allowed: false,
 			azs:     []string{"eu-central-1a", "eu-central-1a", "eu-central-1a", "eu-central-1c"},
 		},
+		{
+			// two AZs are not allowed by default
+			ctx:  context.Background(),
+			name: "case 4",
+
+			allowed: false,
+			azs:     []string{"eu-central-1a", "eu-central-1b"},
+		},
+		{
+			// two AZs are allowed in regions configured for them
+			ctx:  context.Background(),
+			name: "case 5",
+
+			allowed:     true,
+			azs:         []string{"eu-central-1a", "eu-central-1b"},
+			validCounts: []int{1, 2},
+		},
+		{
+			// three AZs are not allowed in regions configured for two
+			ctx:  context.Background(),
+			name: "case 6",
+
+			allowed:     false,
+			azs:         []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			validCounts: []int{1, 2},
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -110,6 +143,7 @@ func TestAZCount(t *testing.T) {
 			validate := &Validator{
 				validAvailabilityZones: unittest.DefaultAvailabilityZones(),
 				validInstanceTypes:     unittest.DefaultInstanceTypes(),
+				validMasterAZCounts:    tc.validCounts,
 				k8sClient:              fakeK8sClient,
 				logger:                 microloggertest.New(),
 			}
@@ -125,7 +159,7 @@ func TestAZCount(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
@@ -142,6 +176,7 @@ func TestAZOrder(t *testing.T) {
 		newAZs  []string
 	}{
 		{
+			// AZs do not change
 			ctx:  context.Background(),
 			name: "case 0",
 
@@ -150,14 +185,16 @@ func TestAZOrder(t *testing.T) {
 			newAZs:  []string{"eu-central-1a"},
 		},
 		{
+			// AZ is replaced, which removes the old one
 			ctx:  context.Background(),
 			name: "case 1",
 
-			allowed: true,
+			allowed: false,
 			oldAZs:  []string{"eu-central-1a"},
 			newAZs:  []string{"eu-central-1b"},
 		},
 		{
+			// AZs are appended
 			ctx:  context.Background(),
 			name: "case 2",
 
@@ -166,6 +203,7 @@ func TestAZOrder(t *testing.T) {
 			newAZs:  []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
 		},
 		{
+			// AZs are added in front of the existing one
 			ctx:  context.Background(),
 			name: "case 3",
 
@@ -174,6 +212,7 @@ func TestAZOrder(t *testing.T) {
 			newAZs:  []string{"eu-central-1b", "eu-central-1a", "eu-central-1c"},
 		},
 		{
+			// AZs are reordered
 			ctx:  context.Background(),
 			name: "case 4",
 
@@ -205,7 +244,7 @@ func TestAZOrder(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(&admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, &admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
@@ -285,7 +324,7 @@ func TestAZUnique(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(&admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, &admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
@@ -339,7 +378,7 @@ func TestAZValid(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(&admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, &admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
@@ -392,10 +431,338 @@ func TestInstanceTypeValid(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(&admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, &admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
 		})
 	}
 }
+
+func TestInstanceFamilyValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		validInstanceFamilies []string
+		defaultInstanceType   string
+		instanceType          string
+		valid                 bool
+	}{
+		{
+			// no instance families configured
+			name: "case 0",
+
+			instanceType: "t3.xlarge",
+			valid:        true,
+		},
+		{
+			// instance type of an allowed family
+			name: "case 1",
+
+			validInstanceFamilies: []string{"m5", "r5"},
+			instanceType:          "r5.2xlarge",
+			valid:                 true,
+		},
+		{
+			// instance type of a family unsuitable for masters
+			name: "case 2",
+
+			validInstanceFamilies: []string{"m5", "r5"},
+			instanceType:          "t3.xlarge",
+			valid:                 false,
+		},
+		{
+			// installation default is always allowed
+			name: "case 3",
+
+			validInstanceFamilies: []string{"r5"},
+			instanceType:          "m5.xlarge",
+			valid:                 true,
+		},
+		{
+			// configured installation default is always allowed
+			name: "case 4",
+
+			validInstanceFamilies: []string{"r5"},
+			defaultInstanceType:   "c5.2xlarge",
+			instanceType:          "c5.2xlarge",
+			valid:                 true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				defaultInstanceType:   tc.defaultInstanceType,
+				validInstanceFamilies: tc.validInstanceFamilies,
+				k8sClient:             unittest.FakeK8sClient(),
+				logger:                microloggertest.New(),
+			}
+
+			awsControlPlane := unittest.DefaultAWSControlPlane()
+			awsControlPlane.Spec.InstanceType = tc.instanceType
+
+			err := validate.InstanceFamilyValid(awsControlPlane)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestAZReplicaUpdateMatch(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		oldAvailabilityZones []string
+		availabilityZones    []string
+		replicas             int
+		valid                bool
+	}{
+		{
+			// AZ count does not change
+			name: "case 0",
+
+			oldAvailabilityZones: []string{"eu-central-1a"},
+			availabilityZones:    []string{"eu-central-1b"},
+			replicas:             1,
+			valid:                true,
+		},
+		{
+			// AZ count changes to match the replicas
+			name: "case 1",
+
+			oldAvailabilityZones: []string{"eu-central-1a"},
+			availabilityZones:    unittest.DefaultAvailabilityZones(),
+			replicas:             3,
+			valid:                true,
+		},
+		{
+			// update from single to HA masters
+			name: "case 2",
+
+			oldAvailabilityZones: []string{"eu-central-1a"},
+			availabilityZones:    unittest.DefaultAvailabilityZones(),
+			replicas:             1,
+			valid:                true,
+		},
+		{
+			// AZ count is reduced while the replicas stay
+			name: "case 3",
+
+			oldAvailabilityZones: unittest.DefaultAvailabilityZones(),
+			availabilityZones:    []string{"eu-central-1a"},
+			replicas:             3,
+			valid:                false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			awsControlPlaneOld := unittest.DefaultAWSControlPlane()
+			awsControlPlaneOld.Spec.AvailabilityZones = tc.oldAvailabilityZones
+			awsControlPlane := unittest.DefaultAWSControlPlane()
+			awsControlPlane.Spec.AvailabilityZones = tc.availabilityZones
+			g8sControlPlane := unittest.DefaultG8sControlPlane()
+			g8sControlPlane.Spec.Replicas = tc.replicas
+
+			err := validate.AZReplicaUpdateMatch(awsControlPlaneOld, awsControlPlane, g8sControlPlane)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceTypeUpdate(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		condition       string
+		oldInstanceType string
+		instanceType    string
+		valid           bool
+		warning         bool
+	}{
+		{
+			// instance type does not change
+			name: "case 0",
+			ctx:  context.Background(),
+
+			condition:       infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			oldInstanceType: "m5.xlarge",
+			instanceType:    "m5.xlarge",
+			valid:           true,
+			warning:         false,
+		},
+		{
+			// instance type changes while the cluster is created
+			name: "case 1",
+			ctx:  context.Background(),
+
+			condition:       infrastructurev1alpha2.ClusterStatusConditionCreated,
+			oldInstanceType: "m5.xlarge",
+			instanceType:    "m5.2xlarge",
+			valid:           true,
+			warning:         true,
+		},
+		{
+			// instance type changes while the cluster is updating
+			name: "case 2",
+			ctx:  context.Background(),
+
+			condition:       infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			oldInstanceType: "m5.xlarge",
+			instanceType:    "m5.2xlarge",
+			valid:           false,
+			warning:         true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Status.Cluster.Conditions = []infrastructurev1alpha2.CommonClusterStatusCondition{
+				{
+					LastTransitionTime: metav1.Now(),
+					Condition:          tc.condition,
+				},
+			}
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			typeMeta := metav1.TypeMeta{
+				Kind:       "AWSControlPlane",
+				APIVersion: "infrastructure.giantswarm.io/v1alpha2",
+			}
+			awsControlPlaneOld := unittest.DefaultAWSControlPlane()
+			awsControlPlaneOld.TypeMeta = typeMeta
+			awsControlPlaneOld.Spec.InstanceType = tc.oldInstanceType
+			awsControlPlane := unittest.DefaultAWSControlPlane()
+			awsControlPlane.TypeMeta = typeMeta
+			awsControlPlane.Spec.InstanceType = tc.instanceType
+
+			if tc.oldInstanceType != tc.instanceType {
+				err = validate.InstanceTypeUpdateValid(awsControlPlane)
+				// check if the result is as expected
+				if tc.valid && err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if !tc.valid && err == nil {
+					t.Fatalf("expected error but returned %v", err)
+				}
+			}
+
+			oldRaw, err := json.Marshal(awsControlPlaneOld)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := json.Marshal(awsControlPlane)
+			if err != nil {
+				t.Fatal(err)
+			}
+			warnings := validate.Warnings(tc.ctx, &admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				Object:    runtime.RawExtension{Raw: raw},
+				OldObject: runtime.RawExtension{Raw: oldRaw},
+			})
+			if tc.warning != (len(warnings) > 0) {
+				t.Fatalf("expected warning %v but got %v", tc.warning, warnings)
+			}
+		})
+	}
+}
+
+func TestAZAdditive(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		oldAZs      []string
+		newAZs      []string
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// AZs do not change
+			name: "case 0",
+
+			oldAZs: []string{"eu-central-1a"},
+			newAZs: []string{"eu-central-1a"},
+			valid:  true,
+		},
+		{
+			// AZs are added
+			name: "case 1",
+
+			oldAZs: []string{"eu-central-1a"},
+			newAZs: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			valid:  true,
+		},
+		{
+			// AZs are removed
+			name: "case 2",
+
+			oldAZs: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			newAZs: []string{"eu-central-1a"},
+			valid:  false,
+		},
+		{
+			// AZ is swapped
+			name: "case 3",
+
+			oldAZs: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			newAZs: []string{"eu-central-1a", "eu-central-1b", "eu-central-1a"},
+			valid:  false,
+		},
+		{
+			// AZs are removed with the force annotation
+			name: "case 4",
+
+			oldAZs:      []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			newAZs:      []string{"eu-central-1a"},
+			annotations: map[string]string{aws.AnnotationForceAvailabilityZoneChange: "true"},
+			valid:       true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			awsControlPlaneOld := unittest.DefaultAWSControlPlane()
+			awsControlPlaneOld.Spec.AvailabilityZones = tc.oldAZs
+			awsControlPlane := unittest.DefaultAWSControlPlane()
+			awsControlPlane.Spec.AvailabilityZones = tc.newAZs
+			awsControlPlane.SetAnnotations(tc.annotations)
+
+			err := validate.AZAdditive(awsControlPlane, awsControlPlaneOld)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -2,13 +2,17 @@
 package awsmachinedeployment
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
@@ -29,8 +33,15 @@ type Config struct {
 
 // Mutator for AWSMachineDeployment object.
 type Mutator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	defaultMaxScaling int
+	defaultMinScaling int
+	heartbeatTimeout  time.Duration
+	spotDraining      bool
+	subnetSize        int
 }
 
 func NewMutator(config config.Config) (*Mutator, error) {
@@ -41,16 +52,34 @@ func NewMutator(config config.Config) (*Mutator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	if config.NodePoolDefaultMin < 0 || config.NodePoolDefaultMax < config.NodePoolDefaultMin {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NodePoolDefaultMax must not be smaller than %T.NodePoolDefaultMin", config, config)
+	}
+	if config.NodePoolSubnetSize != 0 && !aws.IsSubnetPrefixLength(strconv.Itoa(config.NodePoolSubnetSize)) {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NodePoolSubnetSize must be a prefix length between %d and %d", config, aws.AWSLargestSubnetPrefixLength, aws.AWSSmallestSubnetPrefixLength)
+	}
+	if config.NodePoolHeartbeatTimeout != 0 && (config.NodePoolHeartbeatTimeout < aws.MinHeartbeatTimeout || config.NodePoolHeartbeatTimeout > aws.MaxHeartbeatTimeout) {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NodePoolHeartbeatTimeout must be between %s and %s", config, aws.MinHeartbeatTimeout, aws.MaxHeartbeatTimeout)
+	}
+
 	mutator := &Mutator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		defaultMaxScaling: config.NodePoolDefaultMax,
+		defaultMinScaling: config.NodePoolDefaultMin,
+		heartbeatTimeout:  config.NodePoolHeartbeatTimeout,
+		spotDraining:      config.NodePoolSpotDraining,
+		subnetSize:        config.NodePoolSubnetSize,
 	}
 
 	return mutator, nil
 }
 
 // Mutate is the function executed for every matching webhook request.
-func (m *Mutator) Mutate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
 	var result []mutator.PatchOperation
 
 	if request.DryRun != nil && *request.DryRun {
@@ -76,6 +105,30 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	if _, _, err := mutator.Deserializer.Decode(request.Object.Raw, nil, awsMachineDeploymentNewCR); err != nil {
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSMachineDeployment: %v", err)
 	}
+	patch, err = m.MutateClusterLabels(awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateName(awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateMachineDeploymentLabel(awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateOwnerReference(*awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateAvailabilityZones(*awsMachineDeploymentNewCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -88,6 +141,36 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	result = append(result, patch...)
 
+	patch, err = m.MutateScaling(*awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	// The annotations map has to exist before the annotations below can be defaulted one by one
+	if awsMachineDeploymentNewCR.GetAnnotations() == nil {
+		result = append(result, mutator.PatchAdd("/metadata/annotations", map[string]string{}))
+		awsMachineDeploymentNewCR.SetAnnotations(map[string]string{})
+	}
+
+	patch, err = m.MutateSubnetSize(*awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateTerminationHandling(*awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateUpdateAnnotations(*awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*awsMachineDeploymentNewCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -124,6 +207,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	result = append(result, patch...)
 
+	patch, err = m.MutateUpdateAnnotations(*awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -135,7 +224,7 @@ func (m *Mutator) MutateAvailabilityZones(awsMachineDeployment infrastructurev1a
 	}
 
 	// Retrieve the `AWSControlPlane` CR related to this object.
-	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
+	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -146,7 +235,7 @@ func (m *Mutator) MutateAvailabilityZones(awsMachineDeployment infrastructurev1a
 	// Trigger defaulting of the worker availability zones
 	m.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment %s AvailabilityZones are not set and will be defaulted", awsMachineDeployment.ObjectMeta.Name))
 	// We default the AZs
-	defaultedAZs := aws.GetNavailabilityZones(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, aws.DefaultNodePoolAZs, awsControlPlane.Spec.AvailabilityZones)
+	defaultedAZs := aws.GetNavailabilityZones(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, aws.DefaultNodePoolAZs, awsControlPlane.Spec.AvailabilityZones)
 	patch := mutator.PatchAdd("/spec/provider/availabilityZones", defaultedAZs)
 	result = append(result, patch)
 	return result, nil
@@ -166,6 +255,69 @@ func (m *Mutator) MutateOnDemandPercentage(awsMachineDeployment infrastructurev1
 	return result, nil
 }
 
+// MutateScaling defaults the scaling limits of a node pool which is created without them. A missing max is
+// defaulted as well, since a node pool can not be scaled to a maximum of zero nodes.
+func (m *Mutator) MutateScaling(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	scaling := awsMachineDeployment.Spec.NodePool.Scaling
+	if m.defaultMaxScaling == 0 || scaling.Max != 0 {
+		return result, nil
+	}
+	if scaling.Min == 0 {
+		scaling.Min = m.defaultMinScaling
+	}
+	scaling.Max = m.defaultMaxScaling
+	if scaling.Max < scaling.Min {
+		scaling.Max = scaling.Min
+	}
+	m.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment %s scaling is not set and will be defaulted to min %d and max %d", awsMachineDeployment.GetName(), scaling.Min, scaling.Max))
+	result = append(result, mutator.PatchAdd("/spec/nodePool/scaling", scaling))
+
+	return result, nil
+}
+
+// MutateSubnetSize defaults the subnet size annotation to the subnet size of the installation if it is not set.
+func (m *Mutator) MutateSubnetSize(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if m.subnetSize <= 0 {
+		return result, nil
+	}
+	if _, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationAWSSubnetSize]; ok {
+		return result, nil
+	}
+	return aws.MutateAnnotation(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, aws.AnnotationAWSSubnetSize, strconv.Itoa(m.subnetSize))
+}
+
+// MutateTerminationHandling defaults the node termination handling annotations from the installation policy if they
+// are not set. Spot interruption draining is only defaulted for node pools with spot instances.
+func (m *Mutator) MutateTerminationHandling(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if _, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationHeartbeatTimeout]; !ok && m.heartbeatTimeout > 0 {
+		patch, err := aws.MutateAnnotation(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, aws.AnnotationHeartbeatTimeout, strconv.Itoa(int(m.heartbeatTimeout.Seconds())))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
+	}
+	if _, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationSpotInterruptionDraining]; !ok && m.spotDraining && aws.UsesSpotInstances(awsMachineDeployment) {
+		patch, err := aws.MutateAnnotation(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, aws.AnnotationSpotInterruptionDraining, "true")
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
+	}
+
+	return result, nil
+}
+
+// MutateUpdateAnnotations normalizes the formats of the update max batch size and pause time annotations.
+func (m *Mutator) MutateUpdateAnnotations(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	return aws.MutateUpdateAnnotations(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
+}
+
 func (m *Mutator) MutateOperatorVersion(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -175,13 +327,13 @@ func (m *Mutator) MutateOperatorVersion(awsMachineDeployment infrastructurev1alp
 		return result, nil
 	}
 	// Retrieve the `AWSCluster` CR related to this object.
-	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, *awsCluster, label.AWSOperatorVersion)
+	patch, err = aws.MutateLabelFromAWSCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, *awsCluster, label.AWSOperatorVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -190,6 +342,51 @@ func (m *Mutator) MutateOperatorVersion(awsMachineDeployment infrastructurev1alp
 	return result, nil
 }
 
+// MutateClusterLabels propagates the cluster ID and organization labels from the owning Cluster.
+// The labels of the given AWSMachineDeployment are updated to reflect the patch.
+func (m *Mutator) MutateClusterLabels(awsMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	patch, err := aws.MutateClusterLabels(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsMachineDeployment, aws.OwnerClusterID(awsMachineDeployment))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return patch, nil
+}
+
+// MutateName generates a node pool ID which is not used in the cluster yet when the AWSMachineDeployment is
+// created with a generate name. The name of the given AWSMachineDeployment is updated to reflect the patch.
+func (m *Mutator) MutateName(awsMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if awsMachineDeployment.GetName() != "" || awsMachineDeployment.GetGenerateName() == "" || key.Cluster(awsMachineDeployment) == "" {
+		return result, nil
+	}
+	awsMachineDeployments, err := aws.FetchAWSMachineDeployments(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsMachineDeployment)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	var nodePools []metav1.Object
+	for i := range awsMachineDeployments {
+		nodePools = append(nodePools, &awsMachineDeployments[i])
+	}
+	name := aws.NodePoolName(awsMachineDeployment, aws.GenerateNodePoolID(aws.UsedNodePoolIDs(nodePools)))
+	m.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment with generate name %s will be named %s", awsMachineDeployment.GetGenerateName(), name))
+	result = append(result, mutator.PatchAdd("/metadata/name", name))
+	awsMachineDeployment.SetName(name)
+
+	return result, nil
+}
+
+// MutateMachineDeploymentLabel defaults the node pool ID label from the name of the AWSMachineDeployment.
+// The labels of the given AWSMachineDeployment are updated to reflect the patch.
+func (m *Mutator) MutateMachineDeploymentLabel(awsMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	return aws.MutateDerivedLabel(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, awsMachineDeployment, label.MachineDeployment, aws.NodePoolIDFromName(awsMachineDeployment))
+}
+
+// MutateOwnerReference sets the owning Cluster as owner of the AWSMachineDeployment.
+func (m *Mutator) MutateOwnerReference(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
+	return aws.MutateOwnerReference(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
+}
+
 func (m *Mutator) MutateReleaseVersion(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -199,13 +396,13 @@ func (m *Mutator) MutateReleaseVersion(awsMachineDeployment infrastructurev1alph
 		return result, nil
 	}
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the release label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, *cluster, label.Release)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &awsMachineDeployment, *cluster, label.Release)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -214,6 +411,13 @@ func (m *Mutator) MutateReleaseVersion(awsMachineDeployment infrastructurev1alph
 	return result, nil
 }
 
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Mutator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
@@ -3,8 +3,11 @@ package awsmachinedeployment
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/microerror"
@@ -13,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
@@ -58,7 +62,7 @@ func TestAWSMachineDeploymentAdmit(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			_, err = mutator.Mutate(request)
+			_, err = mutator.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -135,6 +139,170 @@ func TestAWSMachineDeploymentAvailabilityZones(t *testing.T) {
 	}
 }
 
+func TestAWSMachineDeploymentScaling(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		scaling         infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling
+		defaultMax      int
+		defaultMin      int
+		expectedScaling *infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling
+	}{
+		{
+			// scaling is set
+			name: "case 0",
+
+			scaling:         infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{Min: 1, Max: 5},
+			defaultMax:      10,
+			defaultMin:      3,
+			expectedScaling: nil,
+		},
+		{
+			// scaling is not set
+			name: "case 1",
+
+			scaling:         infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{},
+			defaultMax:      10,
+			defaultMin:      3,
+			expectedScaling: &infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{Min: 3, Max: 10},
+		},
+		{
+			// only min is set and larger than the default max
+			name: "case 2",
+
+			scaling:         infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{Min: 12},
+			defaultMax:      10,
+			defaultMin:      3,
+			expectedScaling: &infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{Min: 12, Max: 12},
+		},
+		{
+			// defaulting is disabled
+			name: "case 3",
+
+			scaling:         infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{},
+			expectedScaling: nil,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+
+				defaultMaxScaling: tc.defaultMax,
+				defaultMinScaling: tc.defaultMin,
+			}
+
+			awsmachinedeployment := unittest.DefaultAWSMachineDeployment()
+			awsmachinedeployment.Spec.NodePool.Scaling = tc.scaling
+			patch, err := mutate.MutateScaling(awsmachinedeployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var scaling *infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling
+			for _, p := range patch {
+				if p.Path == "/spec/nodePool/scaling" {
+					s := p.Value.(infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling)
+					scaling = &s
+				}
+			}
+			if tc.expectedScaling == nil && scaling != nil {
+				t.Fatalf("expected no scaling patch but got %v", *scaling)
+			}
+			if tc.expectedScaling != nil && (scaling == nil || *scaling != *tc.expectedScaling) {
+				t.Fatalf("expected scaling %v but got %v", *tc.expectedScaling, scaling)
+			}
+		})
+	}
+}
+
+func TestAWSMachineDeploymentName(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		objectName    string
+		generateName  string
+		patched       bool
+		clusterPrefix bool
+	}{
+		{
+			// name is set
+			name: "case 0",
+			ctx:  context.Background(),
+
+			objectName: unittest.DefaultMachineDeploymentID,
+			patched:    false,
+		},
+		{
+			// generate name without prefix
+			name: "case 1",
+			ctx:  context.Background(),
+
+			generateName: "np-",
+			patched:      true,
+		},
+		{
+			// generate name with cluster ID prefix
+			name: "case 2",
+			ctx:  context.Background(),
+
+			generateName:  unittest.DefaultClusterID + "-",
+			patched:       true,
+			clusterPrefix: true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Mutator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+			existing := unittest.DefaultAWSMachineDeployment()
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, &existing)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			awsmachinedeployment := unittest.DefaultAWSMachineDeployment()
+			awsmachinedeployment.SetName(tc.objectName)
+			awsmachinedeployment.SetGenerateName(tc.generateName)
+			patch, err := mutate.MutateName(&awsmachinedeployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var name string
+			for _, p := range patch {
+				if p.Path == "/metadata/name" {
+					name = p.Value.(string)
+				}
+			}
+			if !tc.patched {
+				if name != "" {
+					t.Fatalf("expected no name patch but got %#q", name)
+				}
+				return
+			}
+			nodePoolID := name
+			if tc.clusterPrefix {
+				nodePoolID = strings.TrimPrefix(name, unittest.DefaultClusterID+"-")
+				if nodePoolID == name {
+					t.Fatalf("expected %#q to be prefixed with the cluster ID", name)
+				}
+			}
+			if len(nodePoolID) != aws.NodePoolIDLength || nodePoolID == unittest.DefaultMachineDeploymentID {
+				t.Fatalf("expected a free node pool ID but got %#q", nodePoolID)
+			}
+			if awsmachinedeployment.GetName() != name {
+				t.Fatalf("expected %#q to be equal to %#q", awsmachinedeployment.GetName(), name)
+			}
+		})
+	}
+}
+
 func awsMachineDeploymentAdmissionRequest() (*admissionv1.AdmissionRequest, error) {
 	awsmachinedeployment, err := awsMachineDeploymentRawByte()
 	if err != nil {
@@ -236,3 +404,139 @@ func awsMachineDeployment() *infrastructurev1alpha2.AWSMachineDeployment {
 		},
 	}
 }
+
+func TestAWSMachineDeploymentSubnetSize(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations   map[string]string
+		subnetSize    int
+		expectedPatch bool
+	}{
+		{
+			// subnet size is defaulted
+			name: "case 0",
+
+			annotations:   nil,
+			subnetSize:    25,
+			expectedPatch: true,
+		},
+		{
+			// subnet size is set
+			name: "case 1",
+
+			annotations:   map[string]string{aws.AnnotationAWSSubnetSize: "26"},
+			subnetSize:    25,
+			expectedPatch: false,
+		},
+		{
+			// defaulting is disabled
+			name: "case 2",
+
+			annotations:   nil,
+			subnetSize:    0,
+			expectedPatch: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+
+				subnetSize: tc.subnetSize,
+			}
+
+			awsmachinedeployment := unittest.DefaultAWSMachineDeployment()
+			awsmachinedeployment.SetAnnotations(tc.annotations)
+			patch, err := mutate.MutateSubnetSize(awsmachinedeployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedPatch && len(patch) == 0 {
+				t.Fatalf("expected subnet size patch but got none")
+			}
+			if !tc.expectedPatch && len(patch) != 0 {
+				t.Fatalf("expected no patch but got %v", patch)
+			}
+		})
+	}
+}
+
+func TestAWSMachineDeploymentTerminationHandling(t *testing.T) {
+	fifty := 50
+
+	testCases := []struct {
+		name string
+
+		annotations        map[string]string
+		onDemandPercentage *int
+		heartbeatTimeout   time.Duration
+		spotDraining       bool
+		expectedPatches    []string
+	}{
+		{
+			// heartbeat timeout and spot interruption draining are defaulted
+			name: "case 0",
+
+			annotations:        map[string]string{},
+			onDemandPercentage: &fifty,
+			heartbeatTimeout:   5 * time.Minute,
+			spotDraining:       true,
+			expectedPatches:    []string{"300", "true"},
+		},
+		{
+			// spot interruption draining is not defaulted without spot instances
+			name: "case 1",
+
+			annotations:      map[string]string{},
+			heartbeatTimeout: 5 * time.Minute,
+			spotDraining:     true,
+			expectedPatches:  []string{"300"},
+		},
+		{
+			// annotations are set
+			name: "case 2",
+
+			annotations:        map[string]string{aws.AnnotationHeartbeatTimeout: "600", aws.AnnotationSpotInterruptionDraining: "false"},
+			onDemandPercentage: &fifty,
+			heartbeatTimeout:   5 * time.Minute,
+			spotDraining:       true,
+			expectedPatches:    nil,
+		},
+		{
+			// defaulting is disabled
+			name: "case 3",
+
+			annotations:        map[string]string{},
+			onDemandPercentage: &fifty,
+			expectedPatches:    nil,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+
+				heartbeatTimeout: tc.heartbeatTimeout,
+				spotDraining:     tc.spotDraining,
+			}
+
+			awsmachinedeployment := unittest.DefaultAWSMachineDeployment()
+			awsmachinedeployment.SetAnnotations(tc.annotations)
+			awsmachinedeployment.Spec.Provider.InstanceDistribution.OnDemandPercentageAboveBaseCapacity = tc.onDemandPercentage
+			patch, err := mutate.MutateTerminationHandling(awsmachinedeployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var values []string
+			for _, p := range patch {
+				values = append(values, p.Value.(string))
+			}
+			if !reflect.DeepEqual(values, tc.expectedPatches) {
+				t.Fatalf("expected patches %v but got %v", tc.expectedPatches, values)
+			}
+		})
+	}
+}
@@ -3,30 +3,57 @@ package awsmachinedeployment
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/blang/semver"
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api/api/v1alpha2"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/clustercontext"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/ec2offering"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/servicequota"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
 )
 
 type Validator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
-	validInstanceTypes []string
+	clusterContext          *clustercontext.Store
+	denyOrphans             bool
+	instanceTypeOfferings   ec2offering.Interface
+	instanceTypePolicy      types.NamespacedName
+	maxNodePools            int
+	maxScaling              int
+	maxVolumeSize           int
+	minCPU                  int
+	minMemory               int
+	minScaling              int
+	minVolumeSize           int
+	serviceQuotaPolicy      string
+	serviceQuotas           servicequota.Interface
+	statusWriters           []string
+	subnetSize              int
+	unknownAnnotationPolicy string
+	validAvailabilityZones  []string
+	validInstanceTypes      []string
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -37,46 +64,196 @@ func NewValidator(config config.Config) (*Validator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	if config.NodePoolMinScaling < 0 || (config.NodePoolMaxScaling > 0 && config.NodePoolMaxScaling < config.NodePoolMinScaling) {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NodePoolMaxScaling must not be smaller than %T.NodePoolMinScaling", config, config)
+	}
+
+	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
 	var instanceTypes []string = strings.Split(config.WorkerInstanceTypes, ",")
 
+	instanceTypePolicy, err := aws.ParseInstanceTypePolicyRef(config.InstanceTypePolicy)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstanceTypePolicy is invalid: %v", config, err)
+	}
+	statusWriters, err := aws.ParseStatusWriters(config.StatusWriters)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StatusWriters is invalid: %v", config, err)
+	}
+
 	validator := &Validator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
-		validInstanceTypes: instanceTypes,
+		clusterContext:          config.ClusterContext,
+		denyOrphans:             config.DenyOrphans,
+		instanceTypeOfferings:   config.InstanceTypeOfferings,
+		instanceTypePolicy:      instanceTypePolicy,
+		maxNodePools:            config.NodePoolMaxCount,
+		maxScaling:              config.NodePoolMaxScaling,
+		maxVolumeSize:           config.NodePoolVolumeSizeMax,
+		minCPU:                  config.NodePoolMinCPU,
+		minMemory:               config.NodePoolMinMemory,
+		minScaling:              config.NodePoolMinScaling,
+		minVolumeSize:           config.NodePoolVolumeSizeMin,
+		serviceQuotaPolicy:      config.ServiceQuotaPolicy,
+		serviceQuotas:           config.ServiceQuotas,
+		statusWriters:           statusWriters,
+		subnetSize:              config.NodePoolSubnetSize,
+		unknownAnnotationPolicy: config.UnknownAnnotationPolicy,
+		validAvailabilityZones:  availabilityZones,
+		validInstanceTypes:      instanceTypes,
 	}
 
 	return validator, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
+	if request.SubResource == aws.SubResourceStatus {
+		return v.ValidateStatusUpdate(request)
+	}
 	if request.Operation == admissionv1.Update {
 		return v.ValidateUpdate(request)
 	}
 	if request.Operation == admissionv1.Create {
 		return v.ValidateCreate(request)
 	}
+	if request.Operation == admissionv1.Delete {
+		return v.ValidateDelete(request)
+	}
+	return true, nil
+}
+
+// ValidateStatusUpdate is the function executed for every status subresource webhook request.
+func (v *Validator) ValidateStatusUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	err := aws.ValidateStatusWriter(request.UserInfo, v.statusWriters)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	return true, nil
 }
 
 func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment
+	var oldAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsMachineDeployment); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awsmachinedeployment: %v", err)
 	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &oldAWSMachineDeployment); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old awsmachinedeployment: %v", err)
+	}
+
+	// Node pools which are being deleted only receive metadata updates like finalizer removals,
+	// which must not be blocked by the remaining checks.
+	if awsMachineDeployment.GetDeletionTimestamp() != nil {
+		err = v.DeletionUpdateValid(oldAWSMachineDeployment, awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		return true, nil
+	}
 
 	err = v.InstanceTypeValid(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
+	if awsMachineDeployment.Spec.Provider.Worker.InstanceType != oldAWSMachineDeployment.Spec.Provider.Worker.InstanceType {
+		err = v.InstanceTypePolicyValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.InstanceSizeValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.InstanceTypeReleaseValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	if awsMachineDeployment.Spec.Provider.Worker != oldAWSMachineDeployment.Spec.Provider.Worker {
+		err = v.AlikeInstanceTypesValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	err = v.VolumesUpdateValid(oldAWSMachineDeployment, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.ClusterStatusValid(oldAWSMachineDeployment, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	if awsMachineDeployment.Spec.NodePool.Scaling.Max != oldAWSMachineDeployment.Spec.NodePool.Scaling.Max ||
+		!reflect.DeepEqual(awsMachineDeployment.Spec.Provider.AvailabilityZones, oldAWSMachineDeployment.Spec.Provider.AvailabilityZones) ||
+		awsMachineDeployment.GetAnnotations()[aws.AnnotationAWSSubnetSize] != oldAWSMachineDeployment.GetAnnotations()[aws.AnnotationAWSSubnetSize] {
+		err = v.SubnetCapacityValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	if !reflect.DeepEqual(awsMachineDeployment.Spec.Provider.AvailabilityZones, oldAWSMachineDeployment.Spec.Provider.AvailabilityZones) {
+		err = v.AZValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.AZUnique(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	if awsMachineDeployment.Spec.Provider.Worker.InstanceType != oldAWSMachineDeployment.Spec.Provider.Worker.InstanceType ||
+		!reflect.DeepEqual(awsMachineDeployment.Spec.Provider.AvailabilityZones, oldAWSMachineDeployment.Spec.Provider.AvailabilityZones) {
+		err = v.InstanceTypeOfferedValid(awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	if awsMachineDeployment.Spec.Provider.Worker.InstanceType != oldAWSMachineDeployment.Spec.Provider.Worker.InstanceType ||
+		awsMachineDeployment.Spec.NodePool.Scaling.Max != oldAWSMachineDeployment.Spec.NodePool.Scaling.Max {
+		err = v.ServiceQuotaValid(&oldAWSMachineDeployment, awsMachineDeployment)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
 	err = v.MachineDeploymentLabelMatch(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
+	err = v.NodeLabelsValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.TerminationHandlingValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.MaxPodsValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.AnnotationPolicyValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.MachineDeploymentAnnotationMaxBatchSizeIsValid(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -92,6 +269,11 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.WorkloadProfileValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	return true, nil
 }
 
@@ -103,7 +285,7 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awsmachinedeployment: %v", err)
 	}
 
-	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), &awsMachineDeployment)
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), &awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -113,16 +295,116 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.InstanceTypePolicyValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.InstanceSizeValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.InstanceTypeReleaseValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.AZValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.AZUnique(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.InstanceTypeOfferedValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.ServiceQuotaValid(nil, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.AlikeInstanceTypesValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.VolumesValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.SubnetSizeValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.SubnetCapacityValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.OrphanValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.ValidateCluster(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
+	err = v.ClusterLabelsMatch(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.NodePoolNameValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.NodePoolIDValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.NodePoolCountValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.MachineDeploymentLabelMatch(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
+	err = v.NodeLabelsValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.TerminationHandlingValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.MaxPodsValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.AnnotationPolicyValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.MachineDeploymentAnnotationMaxBatchSizeIsValid(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -138,9 +420,301 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.WorkloadProfileValid(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (v *Validator) ValidateDelete(request *admissionv1.AdmissionRequest) (bool, error) {
+	var awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment
+	var err error
+
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsMachineDeployment); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse awsmachinedeployment: %v", err)
+	}
+
+	err = v.LastNodePoolDeletionAllowed(awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	return true, nil
 }
 
+// LastNodePoolDeletionAllowed denies the deletion of the last node pool of a cluster while the Cluster still exists,
+// because a cluster without node pools loses all workloads including its CNI pods.
+func (v *Validator) LastNodePoolDeletionAllowed(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if aws.IsAnnotationTrue(&awsMachineDeployment, aws.AnnotationForceDelete) {
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("Deletion protection of AWSMachineDeployment %s is skipped due to annotation %s", awsMachineDeployment.GetName(), aws.AnnotationForceDelete))
+		return nil
+	}
+	if key.Cluster(&awsMachineDeployment) == "" {
+		return nil
+	}
+
+	// Retrieve the `Cluster` CR related to this object.
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	// If the Cluster is already gone there is nothing left to protect.
+	if aws.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	if cluster.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	awsMachineDeployments, err := aws.FetchAWSMachineDeployments(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, md := range awsMachineDeployments {
+		if md.GetName() == awsMachineDeployment.GetName() && md.GetNamespace() == awsMachineDeployment.GetNamespace() {
+			continue
+		}
+		if md.GetDeletionTimestamp() == nil {
+			return nil
+		}
+	}
+
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s can not be deleted because it is the last node pool of Cluster %s, which would lose all workloads. Create another node pool first, delete the Cluster instead or set annotation %s to \"true\".",
+		awsMachineDeployment.GetName(),
+		key.Cluster(cluster),
+		aws.AnnotationForceDelete),
+	)
+}
+
+// DeletionUpdateValid denies spec changes to node pools which are being deleted,
+// since they race the teardown of the node pool infrastructure.
+func (v *Validator) DeletionUpdateValid(oldAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment, newAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if newAWSMachineDeployment.GetDeletionTimestamp() == nil {
+		return nil
+	}
+	if !reflect.DeepEqual(oldAWSMachineDeployment.Spec, newAWSMachineDeployment.Spec) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s is being deleted. Its spec can not be changed anymore.",
+			newAWSMachineDeployment.GetName()),
+		)
+	}
+
+	return nil
+}
+
+// WorkloadProfileValid checks the workload profile annotation. Stateful node pools need to be pinned to
+// availability zones, since EBS volumes can not follow pods into other availability zones.
+func (v *Validator) WorkloadProfileValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	profile, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationWorkloadProfile]
+	if !ok {
+		return nil
+	}
+	validProfiles := []string{aws.WorkloadProfileStateless, aws.WorkloadProfileStateful}
+	if !contains(validProfiles, profile) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Allowed values are %v.",
+			aws.AnnotationWorkloadProfile,
+			profile,
+			validProfiles),
+		)
+	}
+	if profile != aws.WorkloadProfileStateful {
+		return nil
+	}
+
+	availabilityZones := awsMachineDeployment.Spec.Provider.AvailabilityZones
+	if len(availabilityZones) == 0 {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s has workload profile '%s' but no availability zones. Stateful node pools need to be pinned to at least one availability zone.",
+			awsMachineDeployment.GetName(),
+			profile),
+		)
+	}
+
+	return nil
+}
+
+// WorkloadProfileWarning returns a warning when a stateful node pool spans multiple availability zones, since pods
+// with EBS backed volumes can only be rescheduled to nodes in the availability zone of their volumes.
+func (v *Validator) WorkloadProfileWarning(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) string {
+	if awsMachineDeployment.GetAnnotations()[aws.AnnotationWorkloadProfile] != aws.WorkloadProfileStateful {
+		return ""
+	}
+	availabilityZones := awsMachineDeployment.Spec.Provider.AvailabilityZones
+	if len(availabilityZones) <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("AWSMachineDeployment %s has workload profile '%s' and spans availability zones %v. Pods with EBS backed volumes can only be rescheduled to nodes in the availability zone of their volumes.",
+		awsMachineDeployment.GetName(),
+		aws.WorkloadProfileStateful,
+		availabilityZones,
+	)
+}
+
+// ClusterStatusValid checks that the worker instance type, availability zones and scaling limits of the node pool
+// are only changed while the cluster is in a stable state, because aws-operator can not reconcile node pool
+// changes while the cluster is being created or upgraded.
+func (v *Validator) ClusterStatusValid(oldAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment, newAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if oldAWSMachineDeployment.Spec.Provider.Worker.InstanceType == newAWSMachineDeployment.Spec.Provider.Worker.InstanceType &&
+		reflect.DeepEqual(oldAWSMachineDeployment.Spec.Provider.AvailabilityZones, newAWSMachineDeployment.Spec.Provider.AvailabilityZones) &&
+		oldAWSMachineDeployment.Spec.NodePool.Scaling == newAWSMachineDeployment.Spec.NodePool.Scaling {
+		return nil
+	}
+	return aws.ValidateClusterTransitioned(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &newAWSMachineDeployment, "AWSMachineDeployment", "change its worker instance type, availability zones or scaling")
+}
+
+// SubnetSizeValid checks that the subnet size annotation of the node pool is a valid prefix length which fits into
+// the cluster network and can be split across the availability zones of the node pool.
+func (v *Validator) SubnetSizeValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if _, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationAWSSubnetSize]; !ok {
+		return nil
+	}
+	subnetSize, err := aws.NodePoolSubnetSize(&awsMachineDeployment, v.subnetSize)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	var clusterCIDR string
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if err == nil {
+		clusterCIDR = awsCluster.Status.Provider.Network.CIDR
+	} else if !aws.IsNotFound(err) {
+		return microerror.Mask(err)
+	}
+	return aws.ValidateNodePoolSubnetSize(&awsMachineDeployment, "AWSMachineDeployment", clusterCIDR, subnetSize, len(awsMachineDeployment.Spec.Provider.AvailabilityZones))
+}
+
+// SubnetCapacityValid checks that the subnet of the node pool has enough IP addresses for the maximum number of nodes
+// in each of its availability zones, so that the node pool does not exhaust its IP space only after scaling up.
+func (v *Validator) SubnetCapacityValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	subnetSize, err := aws.NodePoolSubnetSize(&awsMachineDeployment, v.subnetSize)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if subnetSize <= 0 {
+		return nil
+	}
+	var clusterCIDR string
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if err == nil {
+		clusterCIDR = awsCluster.Status.Provider.Network.CIDR
+	} else if !aws.IsNotFound(err) {
+		return microerror.Mask(err)
+	}
+	return aws.ValidateNodePoolSubnetCapacity(&awsMachineDeployment, "AWSMachineDeployment", clusterCIDR, subnetSize, len(awsMachineDeployment.Spec.Provider.AvailabilityZones), awsMachineDeployment.Spec.NodePool.Scaling.Max)
+}
+
+// VolumesValid checks that the sizes of the docker, kubelet and logging volumes of the node pool workers are within
+// the bounds of the installation. Containerd keeps its data on the docker volume. Volumes without a size get the
+// default size of aws-operator.
+func (v *Validator) VolumesValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	volumes, err := nodePoolVolumes(awsMachineDeployment)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, volume := range volumes {
+		err = v.volumeSizeValid(awsMachineDeployment, volume)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	return nil
+}
+
+// VolumesUpdateValid checks the bounds of changed volume sizes and denies shrinking volumes, since EBS volumes
+// can not be shrunk. Volumes without a size are compared with the default size of aws-operator.
+func (v *Validator) VolumesUpdateValid(oldAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment, newAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	oldVolumes, err := nodePoolVolumes(oldAWSMachineDeployment)
+	if err != nil {
+		// Invalid sizes were never applied, so they can be changed freely.
+		oldVolumes = nil
+	}
+	newVolumes, err := nodePoolVolumes(newAWSMachineDeployment)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, newVolume := range newVolumes {
+		var oldSize int
+		for _, oldVolume := range oldVolumes {
+			if oldVolume.field == newVolume.field {
+				oldSize = oldVolume.effectiveSize()
+			}
+		}
+		if newVolume.effectiveSize() == oldSize {
+			continue
+		}
+		if newVolume.effectiveSize() < oldSize {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s %s can not be shrunk from %d GB to %d GB. EBS volumes can only grow.",
+				newAWSMachineDeployment.GetName(),
+				newVolume.name,
+				oldSize,
+				newVolume.effectiveSize()),
+			)
+		}
+		err = v.volumeSizeValid(newAWSMachineDeployment, newVolume)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) volumeSizeValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment, volume nodePoolVolume) error {
+	if volume.size == 0 {
+		return nil
+	}
+	if volume.size < v.minVolumeSize {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s %s size %d GB is not valid. It must be at least %d GB.",
+			awsMachineDeployment.GetName(),
+			volume.name,
+			volume.size,
+			v.minVolumeSize),
+		)
+	}
+	if v.maxVolumeSize > 0 && volume.size > v.maxVolumeSize {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s %s size %d GB is not valid. It must be at most %d GB.",
+			awsMachineDeployment.GetName(),
+			volume.name,
+			volume.size,
+			v.maxVolumeSize),
+		)
+	}
+	return nil
+}
+
+// AZValid checks that all availability zones of the node pool are availability zones of the installation region.
+// Node pools without availability zones are defaulted by the mutator.
+func (v *Validator) AZValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if len(v.validAvailabilityZones) == 0 {
+		return nil
+	}
+	for _, az := range awsMachineDeployment.Spec.Provider.AvailabilityZones {
+		if !contains(v.validAvailabilityZones, az) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s availability zone %s is invalid. Valid AZs are: %v",
+				awsMachineDeployment.GetName(),
+				az,
+				v.validAvailabilityZones),
+			)
+		}
+	}
+
+	return nil
+}
+
+// AZUnique checks that no availability zone is listed more than once for the node pool.
+func (v *Validator) AZUnique(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	seen := map[string]bool{}
+	for _, az := range awsMachineDeployment.Spec.Provider.AvailabilityZones {
+		if seen[az] {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s availability zones %v contain %s more than once.",
+				awsMachineDeployment.GetName(),
+				awsMachineDeployment.Spec.Provider.AvailabilityZones,
+				az),
+			)
+		}
+		seen[az] = true
+	}
+
+	return nil
+}
+
 func (v *Validator) InstanceTypeValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
 	if !contains(v.validInstanceTypes, awsMachineDeployment.Spec.Provider.Worker.InstanceType) {
 		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s worker instance type %v is invalid. Valid instance types are: %v",
@@ -153,6 +727,176 @@ func (v *Validator) InstanceTypeValid(awsMachineDeployment infrastructurev1alpha
 	return nil
 }
 
+// InstanceSizeValid checks that the worker instance type has the minimum number of vCPUs and memory of the
+// installation, since smaller nodes can not even run the default apps.
+func (v *Validator) InstanceSizeValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateInstanceTypeMinimum(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType, v.minCPU, v.minMemory)
+}
+
+// InstanceTypeReleaseValid checks that the release version of the node pool supports GPU and Graviton worker instance
+// types.
+func (v *Validator) InstanceTypeReleaseValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if key.Release(&awsMachineDeployment) == "" {
+		return nil
+	}
+	releaseVersion, err := aws.ReleaseVersion(&awsMachineDeployment, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from AWSMachineDeployment: %v", err)
+	}
+	return aws.ValidateInstanceTypeRelease(&awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType, releaseVersion)
+}
+
+// AlikeInstanceTypesValid checks that the instance types of the installation which are used along with the worker
+// instance type, when alike instance types are enabled, have the same architecture and a similar size.
+func (v *Validator) AlikeInstanceTypesValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if !awsMachineDeployment.Spec.Provider.Worker.UseAlikeInstanceTypes {
+		return nil
+	}
+	return aws.ValidateAlikeInstanceTypes(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType, v.validInstanceTypes)
+}
+
+// NodeLabelsValid checks the syntax of the node labels and taints annotations of the node pool.
+func (v *Validator) NodeLabelsValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateNodeLabels(&awsMachineDeployment)
+}
+
+// TerminationHandlingValid checks the values and combinations of the node termination handling annotations.
+func (v *Validator) TerminationHandlingValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateTerminationHandling(awsMachineDeployment)
+}
+
+// MaxPodsValid checks that the max pods annotation of the node pool does not exceed the pods the network interfaces
+// of the worker instance type can provide IP addresses for.
+func (v *Validator) MaxPodsValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	var releaseVersion *semver.Version
+	if key.Release(&awsMachineDeployment) != "" {
+		var err error
+		releaseVersion, err = aws.ReleaseVersion(&awsMachineDeployment, []mutator.PatchOperation{})
+		if err != nil {
+			return microerror.Maskf(parsingFailedError, "unable to parse release version from AWSMachineDeployment: %v", err)
+		}
+	}
+	return aws.ValidateMaxPods(&awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType, releaseVersion)
+}
+
+// AnnotationPolicyValid checks the AWS annotations of the node pool against the registry of known annotations.
+func (v *Validator) AnnotationPolicyValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateAnnotationPolicy(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment, "AWSMachineDeployment", v.unknownAnnotationPolicy)
+}
+
+// InstanceTypePolicyValid checks the worker instance type against the instance type policy of the organization.
+func (v *Validator) InstanceTypePolicyValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateInstanceTypePolicy(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, v.instanceTypePolicy, &awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType)
+}
+
+// InstanceTypeOfferedValid checks that the worker instance type exists and is offered in all node pool availability zones.
+func (v *Validator) InstanceTypeOfferedValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateInstanceTypeOffered(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, v.instanceTypeOfferings, &awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType, awsMachineDeployment.Spec.Provider.AvailabilityZones)
+}
+
+// ServiceQuotaValid checks, when the service quota policy is deny, that the node pool can scale up to its max within
+// the remaining EC2 On-Demand instance quota of the account.
+func (v *Validator) ServiceQuotaValid(oldAWSMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment, newAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateServiceQuota(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, v.serviceQuotas, &newAWSMachineDeployment, "AWSMachineDeployment", newAWSMachineDeployment.Spec.Provider.Worker.InstanceType, additionalNodes(oldAWSMachineDeployment, newAWSMachineDeployment), v.serviceQuotaPolicy)
+}
+
+// Warnings returns a warning when a stateful node pool spans multiple availability zones, and when the node pool
+// can not scale up to its max within the remaining EC2 On-Demand instance quota of the account and the service
+// quota policy is warn.
+func (v *Validator) Warnings(ctx context.Context, request *admissionv1.AdmissionRequest) []string {
+	v = v.withContext(ctx)
+	if request.SubResource != "" {
+		return nil
+	}
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return nil
+	}
+	var awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsMachineDeployment); err != nil {
+		return nil
+	}
+
+	var warnings []string
+	if message := v.WorkloadProfileWarning(awsMachineDeployment); message != "" {
+		warnings = append(warnings, message)
+	}
+
+	if v.serviceQuotaPolicy != aws.ServiceQuotaPolicyWarn {
+		return warnings
+	}
+	var oldAWSMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment
+	if request.Operation == admissionv1.Update {
+		oldAWSMachineDeployment = &infrastructurev1alpha2.AWSMachineDeployment{}
+		if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, oldAWSMachineDeployment); err != nil {
+			return warnings
+		}
+	}
+
+	message := aws.ServiceQuotaExceeded(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, v.serviceQuotas, &awsMachineDeployment, "AWSMachineDeployment", awsMachineDeployment.Spec.Provider.Worker.InstanceType, additionalNodes(oldAWSMachineDeployment, awsMachineDeployment))
+	if message != "" {
+		warnings = append(warnings, message)
+	}
+	return warnings
+}
+
+// NodePoolIDValid checks the format of the node pool ID and that it is unique within the cluster.
+func (v *Validator) NodePoolIDValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	err := aws.ValidateNodePoolID(&awsMachineDeployment)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if key.Cluster(&awsMachineDeployment) == "" {
+		return nil
+	}
+	awsMachineDeployments, err := aws.FetchAWSMachineDeployments(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	var nodePools []metav1.Object
+	for i := range awsMachineDeployments {
+		nodePools = append(nodePools, &awsMachineDeployments[i])
+	}
+	return aws.ValidateNodePoolIDUnique(&awsMachineDeployment, nodePools)
+}
+
+// NodePoolCountValid checks that the cluster does not exceed the maximum number of node pools, since the cluster
+// network can only be partitioned into a limited number of node pool subnets.
+func (v *Validator) NodePoolCountValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if v.maxNodePools <= 0 {
+		return nil
+	}
+	// The precomputed cluster context is used when available to avoid API calls.
+	var count int
+	if snapshot, cached := v.clusterSnapshot(&awsMachineDeployment); cached {
+		count = snapshot.NodePoolCount()
+	} else {
+		awsMachineDeployments, err := aws.FetchAWSMachineDeployments(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for _, md := range awsMachineDeployments {
+			if md.GetName() == awsMachineDeployment.GetName() && md.GetNamespace() == awsMachineDeployment.GetNamespace() {
+				continue
+			}
+			count++
+		}
+	}
+	if count >= v.maxNodePools {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment %s can not be created because cluster %s already has %d node pools. At most %d node pools are allowed per cluster.",
+			awsMachineDeployment.GetName(),
+			key.Cluster(&awsMachineDeployment),
+			count,
+			v.maxNodePools),
+		)
+	}
+
+	return nil
+}
+
+func (v *Validator) NodePoolNameValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	return aws.ValidateNodePoolName(&awsMachineDeployment)
+}
+
 func (v *Validator) MachineDeploymentLabelMatch(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
 	var machineDeployment v1alpha2.MachineDeployment
 	var err error
@@ -162,15 +906,17 @@ func (v *Validator) MachineDeploymentLabelMatch(awsMachineDeployment infrastruct
 	{
 		v.Log("level", "debug", "message", fmt.Sprintf("Fetching MachineDeployment %s", awsMachineDeployment.Name))
 		fetch = func() error {
-			ctx := context.Background()
+			ctx := v.ctx
 
 			err = v.k8sClient.CtrlClient().Get(
 				ctx,
 				types.NamespacedName{Name: awsMachineDeployment.GetName(), Namespace: awsMachineDeployment.GetNamespace()},
 				&machineDeployment,
 			)
-			if err != nil {
+			if apierrors.IsNotFound(err) {
 				return microerror.Maskf(notFoundError, "failed to fetch MachineDeployment: %v", err)
+			} else if err != nil {
+				return microerror.Mask(err)
 			}
 			return nil
 		}
@@ -211,7 +957,7 @@ func (v *Validator) MachineDeploymentLabelMatch(awsMachineDeployment infrastruct
 func (v *Validator) MachineDeploymentAnnotationMaxBatchSizeIsValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
 	if maxBatchSize, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationUpdateMaxBatchSize]; ok {
 		if !aws.MaxBatchSizeIsValid(maxBatchSize) {
-			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Allowed value is either integer bigger than zero or decimal number between 0 and 1.0 defining percentage of nodes",
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Allowed value is either integer bigger than zero or decimal number between 0 and 1.0 or percentage up to 100 percent defining the share of nodes",
 				aws.AnnotationUpdateMaxBatchSize,
 				maxBatchSize),
 			)
@@ -223,11 +969,11 @@ func (v *Validator) MachineDeploymentAnnotationMaxBatchSizeIsValid(awsMachineDep
 func (v *Validator) MachineDeploymentAnnotationPauseTimeIsValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
 	if maxBatchSize, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationUpdatePauseTime]; ok {
 		if !aws.PauseTimeIsValid(maxBatchSize) {
-			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Value must be in ISO 8601 duration format and cannot be bigger than 1 hour.",
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Value must be in ISO 8601 or Go duration format and cannot be bigger than 1 hour.",
 				aws.AnnotationUpdatePauseTime,
 				maxBatchSize),
 			)
-			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Value must be in ISO 8601 duration format and cannot be bigger than 1 hour.",
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. Value must be in ISO 8601 or Go duration format and cannot be bigger than 1 hour.",
 				aws.AnnotationUpdatePauseTime,
 				maxBatchSize),
 			)
@@ -240,8 +986,10 @@ func (v *Validator) ValidateCluster(awsMachineDeployment infrastructurev1alpha2.
 	var err error
 
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
-	if err != nil {
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if aws.IsNotFound(err) && awsMachineDeployment.GetAnnotations()[aws.AnnotationAllowMissingCluster] == "true" {
+		return nil
+	} else if err != nil {
 		return microerror.Mask(err)
 	}
 	// make sure the cluster is not deleted
@@ -256,16 +1004,102 @@ func (v *Validator) ValidateCluster(awsMachineDeployment infrastructurev1alpha2.
 	return nil
 }
 
+// ClusterLabelsMatch checks that the release version label matches the one of the Cluster and the aws-operator
+// version label matches the one of the AWSCluster.
+func (v *Validator) ClusterLabelsMatch(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if aws.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	err = aws.ValidateLabelsMatch(&awsMachineDeployment, "AWSMachineDeployment", cluster, "Cluster", label.Release)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+	if aws.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	return aws.ValidateLabelsMatch(&awsMachineDeployment, "AWSMachineDeployment", awsCluster, "AWSCluster", label.AWSOperatorVersion)
+}
+
+// OrphanValid checks that the Cluster referenced by the AWSMachineDeployment exists when orphan objects are denied.
+func (v *Validator) OrphanValid(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if !v.denyOrphans {
+		return nil
+	}
+	return aws.ValidateClusterExists(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &awsMachineDeployment)
+}
+
+// MachineDeploymentScaling checks that the scaling limits of the node pool are consistent and within the
+// bounds of the installation.
 func (v *Validator) MachineDeploymentScaling(md infrastructurev1alpha2.AWSMachineDeployment) error {
 	min := md.Spec.NodePool.Scaling.Min
 	max := md.Spec.NodePool.Scaling.Max
 
+	if max == 0 {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment %s scaling max must be greater than 0. To scale the node pool down to zero nodes, set scaling min to 0 and let the cluster autoscaler remove the idle nodes, or delete the node pool.",
+			md.GetName())
+	}
 	if min > max {
 		return microerror.Maskf(notAllowedError, "AWSMachineDeployment.Spec.Scaling.Min must not be greater that AWSMachineDeployment.Spec.Scaling.Max.")
 	}
+	if min < v.minScaling {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment %s scaling min %d is too small. The node pool has to keep at least %d nodes in this installation.",
+			md.GetName(),
+			min,
+			v.minScaling)
+	}
+	if v.maxScaling > 0 && max > v.maxScaling {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment %s scaling max %d is too large. Node pools can have at most %d nodes in this installation.",
+			md.GetName(),
+			max,
+			v.maxScaling)
+	}
 
 	return nil
 }
+
+// nodePoolVolume is a volume of the node pool workers with its size in GB as set in the AWSMachineDeployment.
+type nodePoolVolume struct {
+	field string
+	name  string
+	size  int
+}
+
+// effectiveSize returns the size aws-operator gives the volume, which is the default size when it has no size.
+func (v nodePoolVolume) effectiveSize() int {
+	if v.size == 0 {
+		return aws.DefaultNodePoolVolumeSize
+	}
+	return v.size
+}
+
+func nodePoolVolumes(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) ([]nodePoolVolume, error) {
+	volumes := []nodePoolVolume{
+		{field: "dockerVolumeSizeGB", name: "docker volume", size: awsMachineDeployment.Spec.NodePool.Machine.DockerVolumeSizeGB},
+		{field: "kubeletVolumeSizeGB", name: "kubelet volume", size: awsMachineDeployment.Spec.NodePool.Machine.KubeletVolumeSizeGB},
+	}
+	// The logging volume is always listed, so that removing its annotation is compared with the default size.
+	loggingVolume := nodePoolVolume{field: aws.AnnotationLoggingVolumeSize, name: "logging volume"}
+	if value, ok := awsMachineDeployment.GetAnnotations()[aws.AnnotationLoggingVolumeSize]; ok {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, microerror.Maskf(notAllowedError, fmt.Sprintf("AWSMachineDeployment annotation '%s' value '%s' is not valid. The size of the logging volume must be an integer number of GB.",
+				aws.AnnotationLoggingVolumeSize,
+				value),
+			)
+		}
+		loggingVolume.size = size
+	}
+	volumes = append(volumes, loggingVolume)
+	return volumes, nil
+}
+
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
@@ -275,6 +1109,30 @@ func contains(s []string, e string) bool {
 	return false
 }
 
+// additionalNodes returns the number of nodes the node pool can scale up by compared to the old node pool. All nodes
+// up to the max are additional for new node pools and when the instance type changes, since the nodes are replaced.
+func additionalNodes(oldAWSMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment, newAWSMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) int {
+	if oldAWSMachineDeployment == nil || oldAWSMachineDeployment.Spec.Provider.Worker.InstanceType != newAWSMachineDeployment.Spec.Provider.Worker.InstanceType {
+		return newAWSMachineDeployment.Spec.NodePool.Scaling.Max
+	}
+	return newAWSMachineDeployment.Spec.NodePool.Scaling.Max - oldAWSMachineDeployment.Spec.NodePool.Scaling.Max
+}
+
+// clusterSnapshot returns the precomputed context of the cluster of the given object and whether it exists.
+func (v *Validator) clusterSnapshot(meta metav1.Object) (clustercontext.Snapshot, bool) {
+	if v.clusterContext == nil {
+		return clustercontext.Snapshot{}, false
+	}
+	return v.clusterContext.Get(key.Cluster(meta))
+}
+
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (v *Validator) Log(keyVals ...interface{}) {
 	v.logger.Log(keyVals...)
 }
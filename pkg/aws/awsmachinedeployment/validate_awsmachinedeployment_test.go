@@ -11,7 +11,9 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/giantswarm/micrologger/microloggertest"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
@@ -62,6 +64,65 @@ func TestInstanceTypeValid(t *testing.T) {
 	}
 }
 
+func TestAZValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		availabilityZones []string
+		allowed           bool
+	}{
+		{
+			// valid AZs
+			name: "case 0",
+
+			availabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+			allowed:           true,
+		},
+		{
+			// AZ of another region
+			name: "case 1",
+
+			availabilityZones: []string{"eu-central-1a", "eu-west-1b"},
+			allowed:           false,
+		},
+		{
+			// duplicate AZs
+			name: "case 2",
+
+			availabilityZones: []string{"eu-central-1a", "eu-central-1a"},
+			allowed:           false,
+		},
+		{
+			// AZs are defaulted
+			name: "case 3",
+
+			availabilityZones: nil,
+			allowed:           true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Spec.Provider.AvailabilityZones = tc.availabilityZones
+
+			validate := &Validator{
+				validAvailabilityZones: unittest.DefaultAvailabilityZones(),
+				logger:                 microloggertest.New(),
+			}
+			err := validate.AZValid(md)
+			if err == nil {
+				err = validate.AZUnique(md)
+			}
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
 func TestMachineDeploymentLabelMatch(t *testing.T) {
 	testCases := []struct {
 		ctx  context.Context
@@ -192,8 +253,10 @@ func TestValidateCluster(t *testing.T) {
 
 func TestValidateMachineDeploymentScaling(t *testing.T) {
 	testCases := []struct {
-		scaling infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling
-		matcher func(error) bool
+		scaling    infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling
+		minScaling int
+		maxScaling int
+		matcher    func(error) bool
 	}{
 		{
 			// case 0
@@ -217,7 +280,7 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 				Min: 0,
 				Max: 0,
 			},
-			matcher: nil,
+			matcher: IsNotAllowed,
 		},
 		{
 			// case 3
@@ -259,6 +322,34 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 			},
 			matcher: nil,
 		},
+		{
+			// case 8
+			scaling: infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 0,
+				Max: 10,
+			},
+			minScaling: 1,
+			matcher:    IsNotAllowed,
+		},
+		{
+			// case 9
+			scaling: infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 1,
+				Max: 60,
+			},
+			maxScaling: 50,
+			matcher:    IsNotAllowed,
+		},
+		{
+			// case 10
+			scaling: infrastructurev1alpha2.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 1,
+				Max: 50,
+			},
+			minScaling: 1,
+			maxScaling: 50,
+			matcher:    nil,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -266,6 +357,9 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 			v := &Validator{
 				k8sClient: unittest.FakeK8sClient(),
 				logger:    microloggertest.New(),
+
+				maxScaling: tc.maxScaling,
+				minScaling: tc.minScaling,
 			}
 
 			md := unittest.DefaultAWSMachineDeployment()
@@ -286,3 +380,614 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 		})
 	}
 }
+
+func TestDeletionUpdateValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		deleting        bool
+		oldInstanceType string
+		newInstanceType string
+		newFinalizers   []string
+		allowed         bool
+	}{
+		{
+			// spec change of a node pool which is not being deleted
+			name: "case 0",
+
+			deleting:        false,
+			oldInstanceType: "m5.xlarge",
+			newInstanceType: "c5.xlarge",
+			allowed:         true,
+		},
+		{
+			// finalizer removal of a node pool which is being deleted
+			name: "case 1",
+
+			deleting:        true,
+			oldInstanceType: "m5.xlarge",
+			newInstanceType: "m5.xlarge",
+			newFinalizers:   []string{},
+			allowed:         true,
+		},
+		{
+			// spec change of a node pool which is being deleted
+			name: "case 2",
+
+			deleting:        true,
+			oldInstanceType: "m5.xlarge",
+			newInstanceType: "c5.xlarge",
+			allowed:         false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			oldMD := unittest.DefaultAWSMachineDeployment()
+			oldMD.Spec.Provider.Worker.InstanceType = tc.oldInstanceType
+			oldMD.SetFinalizers([]string{"operatorkit.giantswarm.io/aws-operator"})
+
+			newMD := unittest.DefaultAWSMachineDeployment()
+			newMD.Spec.Provider.Worker.InstanceType = tc.newInstanceType
+			newMD.SetFinalizers(tc.newFinalizers)
+			if tc.deleting {
+				now := v1.Now()
+				newMD.SetDeletionTimestamp(&now)
+			}
+
+			validate := &Validator{
+				logger: microloggertest.New(),
+			}
+			err := validate.DeletionUpdateValid(oldMD, newMD)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestLastNodePoolDeletionAllowed(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterDeleting bool
+		otherNodePool   bool
+		otherDeleting   bool
+		forceDelete     bool
+		allowed         bool
+	}{
+		{
+			// another node pool exists
+			ctx:  context.Background(),
+			name: "case 0",
+
+			otherNodePool: true,
+			allowed:       true,
+		},
+		{
+			// last node pool of the cluster
+			ctx:  context.Background(),
+			name: "case 1",
+
+			allowed: false,
+		},
+		{
+			// the other node pool is being deleted as well
+			ctx:  context.Background(),
+			name: "case 2",
+
+			otherNodePool: true,
+			otherDeleting: true,
+			allowed:       false,
+		},
+		{
+			// last node pool of a cluster which is being deleted
+			ctx:  context.Background(),
+			name: "case 3",
+
+			clusterDeleting: true,
+			allowed:         true,
+		},
+		{
+			// last node pool but deletion is forced
+			ctx:  context.Background(),
+			name: "case 4",
+
+			forceDelete: true,
+			allowed:     true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			nodePools := 1
+			if tc.otherNodePool {
+				nodePools = 2
+			}
+			scenario, err := unittest.NewScenarioBuilder(fakeK8sClient).
+				WithNodePools(nodePools).
+				WithCluster(func(cluster *capiv1alpha2.Cluster) {
+					if tc.clusterDeleting {
+						now := v1.Now()
+						cluster.SetDeletionTimestamp(&now)
+					}
+				}).
+				WithAWSMachineDeployment(func(i int, md *infrastructurev1alpha2.AWSMachineDeployment) {
+					if i > 0 && tc.otherDeleting {
+						now := v1.Now()
+						md.SetDeletionTimestamp(&now)
+					}
+				}).
+				Build(tc.ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			awsMachineDeployment := scenario.AWSMachineDeployments[0]
+			if tc.forceDelete {
+				awsMachineDeployment.SetAnnotations(map[string]string{aws.AnnotationForceDelete: "true"})
+			}
+
+			err = validate.LastNodePoolDeletionAllowed(awsMachineDeployment)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+func TestWorkloadProfile(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		profile string
+		AZs     []string
+		allowed bool
+		warned  bool
+	}{
+		{
+			// no workload profile
+			name: "case 0",
+
+			profile: "",
+			AZs:     []string{"eu-central-1a", "eu-central-1b"},
+			allowed: true,
+			warned:  false,
+		},
+		{
+			// stateless node pool in multiple AZs
+			name: "case 1",
+
+			profile: aws.WorkloadProfileStateless,
+			AZs:     []string{"eu-central-1a", "eu-central-1b"},
+			allowed: true,
+			warned:  false,
+		},
+		{
+			// stateful node pool in multiple AZs
+			name: "case 2",
+
+			profile: aws.WorkloadProfileStateful,
+			AZs:     []string{"eu-central-1a", "eu-central-1b"},
+			allowed: true,
+			warned:  true,
+		},
+		{
+			// stateful node pool without AZs
+			name: "case 3",
+
+			profile: aws.WorkloadProfileStateful,
+			AZs:     nil,
+			allowed: false,
+			warned:  false,
+		},
+		{
+			// stateful node pool in a single AZ
+			name: "case 4",
+
+			profile: aws.WorkloadProfileStateful,
+			AZs:     []string{"eu-central-1b"},
+			allowed: true,
+			warned:  false,
+		},
+		{
+			// invalid workload profile
+			name: "case 5",
+
+			profile: "batch",
+			AZs:     []string{"eu-central-1a"},
+			allowed: false,
+			warned:  false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			awsMachineDeployment := unittest.DefaultAWSMachineDeployment()
+			awsMachineDeployment.Spec.Provider.AvailabilityZones = tc.AZs
+			if tc.profile != "" {
+				awsMachineDeployment.SetAnnotations(map[string]string{aws.AnnotationWorkloadProfile: tc.profile})
+			}
+
+			validate := &Validator{
+				logger: microloggertest.New(),
+			}
+			err := validate.WorkloadProfileValid(awsMachineDeployment)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+			warned := validate.WorkloadProfileWarning(awsMachineDeployment) != ""
+			if tc.warned != warned {
+				t.Fatalf("expected warning to be %t", tc.warned)
+			}
+		})
+	}
+}
+
+func TestNodePoolCountValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		existingNodePools int
+		maxNodePools      int
+		allowed           bool
+	}{
+		{
+			// below the limit
+			name: "case 0",
+			ctx:  context.Background(),
+
+			existingNodePools: 2,
+			maxNodePools:      3,
+			allowed:           true,
+		},
+		{
+			// limit reached
+			name: "case 1",
+			ctx:  context.Background(),
+
+			existingNodePools: 3,
+			maxNodePools:      3,
+			allowed:           false,
+		},
+		{
+			// limit disabled
+			name: "case 2",
+			ctx:  context.Background(),
+
+			existingNodePools: 3,
+			maxNodePools:      0,
+			allowed:           true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+
+				maxNodePools: tc.maxNodePools,
+			}
+
+			_, err := unittest.NewScenarioBuilder(fakeK8sClient).
+				WithNodePools(tc.existingNodePools).
+				Build(tc.ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// node pools of other clusters are not counted
+			other := unittest.DefaultAWSMachineDeployment()
+			other.SetName("other")
+			other.Labels[label.Cluster] = "other"
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &other)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			newNodePool := unittest.DefaultAWSMachineDeployment()
+			newNodePool.SetName("x9z8y")
+			err = validate.NodePoolCountValid(newNodePool)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestVolumesValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		oldDockerVolumeSize  int
+		dockerVolumeSize     int
+		oldLoggingVolumeSize string
+		loggingVolumeSize    string
+		update               bool
+		allowed              bool
+	}{
+		{
+			// volume sizes within bounds
+			name: "case 0",
+
+			dockerVolumeSize:  100,
+			loggingVolumeSize: "15",
+			allowed:           true,
+		},
+		{
+			// docker volume too small
+			name: "case 1",
+
+			dockerVolumeSize: 5,
+			allowed:          false,
+		},
+		{
+			// logging volume too large
+			name: "case 2",
+
+			dockerVolumeSize:  100,
+			loggingVolumeSize: "2000",
+			allowed:           false,
+		},
+		{
+			// logging volume size is not a number
+			name: "case 3",
+
+			dockerVolumeSize:  100,
+			loggingVolumeSize: "15GB",
+			allowed:           false,
+		},
+		{
+			// docker volume grows
+			name: "case 4",
+
+			oldDockerVolumeSize: 100,
+			dockerVolumeSize:    200,
+			update:              true,
+			allowed:             true,
+		},
+		{
+			// docker volume shrinks
+			name: "case 5",
+
+			oldDockerVolumeSize: 200,
+			dockerVolumeSize:    100,
+			update:              true,
+			allowed:             false,
+		},
+		{
+			// docker volume grows beyond bounds
+			name: "case 6",
+
+			oldDockerVolumeSize: 100,
+			dockerVolumeSize:    2000,
+			update:              true,
+			allowed:             false,
+		},
+		{
+			// docker volume without size is set below the default size
+			name: "case 7",
+
+			dockerVolumeSize: 50,
+			update:           true,
+			allowed:          false,
+		},
+		{
+			// docker volume without size is set above the default size
+			name: "case 8",
+
+			dockerVolumeSize: 200,
+			update:           true,
+			allowed:          true,
+		},
+		{
+			// docker volume size is removed, which shrinks it to the default size
+			name: "case 9",
+
+			oldDockerVolumeSize: 200,
+			update:              true,
+			allowed:             false,
+		},
+		{
+			// logging volume size annotation is removed, which shrinks it to the default size
+			name: "case 10",
+
+			dockerVolumeSize:     100,
+			oldDockerVolumeSize:  100,
+			oldLoggingVolumeSize: "200",
+			update:               true,
+			allowed:              false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			validate := &Validator{
+				logger:        microloggertest.New(),
+				maxVolumeSize: 1000,
+				minVolumeSize: 10,
+			}
+
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Spec.NodePool.Machine.DockerVolumeSizeGB = tc.dockerVolumeSize
+			if tc.loggingVolumeSize != "" {
+				md.SetAnnotations(map[string]string{aws.AnnotationLoggingVolumeSize: tc.loggingVolumeSize})
+			}
+
+			if tc.update {
+				oldMD := unittest.DefaultAWSMachineDeployment()
+				oldMD.Spec.NodePool.Machine.DockerVolumeSizeGB = tc.oldDockerVolumeSize
+				if tc.oldLoggingVolumeSize != "" {
+					oldMD.SetAnnotations(map[string]string{aws.AnnotationLoggingVolumeSize: tc.oldLoggingVolumeSize})
+				}
+				err = validate.VolumesUpdateValid(oldMD, md)
+			} else {
+				err = validate.VolumesValid(md)
+			}
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestClusterStatusValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		condition    string
+		instanceType string
+		scalingMax   int
+		allowed      bool
+	}{
+		{
+			// instance type is changed while the cluster is updated
+			ctx:  context.Background(),
+			name: "case 0",
+
+			condition:    infrastructurev1alpha2.ClusterStatusConditionUpdated,
+			instanceType: "m5.xlarge",
+			scalingMax:   5,
+			allowed:      true,
+		},
+		{
+			// instance type is changed while the cluster is updating
+			ctx:  context.Background(),
+			name: "case 1",
+
+			condition:    infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			instanceType: "m5.xlarge",
+			scalingMax:   5,
+			allowed:      false,
+		},
+		{
+			// scaling is changed while the cluster is updating
+			ctx:  context.Background(),
+			name: "case 2",
+
+			condition:    infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			instanceType: "m5.2xlarge",
+			scalingMax:   10,
+			allowed:      false,
+		},
+		{
+			// nothing relevant is changed while the cluster is updating
+			ctx:  context.Background(),
+			name: "case 3",
+
+			condition:    infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			instanceType: "m5.2xlarge",
+			scalingMax:   5,
+			allowed:      true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			// create the AWSCluster
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Status.Cluster.Conditions = []infrastructurev1alpha2.CommonClusterStatusCondition{
+				{
+					LastTransitionTime: v1.Now(),
+					Condition:          tc.condition,
+				},
+			}
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldMD := unittest.DefaultAWSMachineDeployment()
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Spec.Provider.Worker.InstanceType = tc.instanceType
+			md.Spec.NodePool.Scaling.Max = tc.scalingMax
+
+			err = validate.ClusterStatusValid(oldMD, md)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceSizeValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType string
+		allowed      bool
+	}{
+		{
+			// instance type is large enough
+			name: "case 0",
+
+			instanceType: "t3.medium",
+			allowed:      true,
+		},
+		{
+			// instance type has too little memory
+			name: "case 1",
+
+			instanceType: "t3.small",
+			allowed:      false,
+		},
+		{
+			// instance type is not in the catalog
+			name: "case 2",
+
+			instanceType: "x1e.xlarge",
+			allowed:      true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+				minCPU:    2,
+				minMemory: 4,
+			}
+
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Spec.Provider.Worker.InstanceType = tc.instanceType
+			err := validate.InstanceSizeValid(md)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/blang/semver"
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -25,8 +28,13 @@ type Config struct {
 
 // Mutator for Cluster object.
 type Mutator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	autoPatchUpgrade        bool
+	podSecurityDefaultLevel string
+	requiredLabels          []aws.RequiredLabel
 }
 
 func NewMutator(config config.Config) (*Mutator, error) {
@@ -37,16 +45,27 @@ func NewMutator(config config.Config) (*Mutator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	requiredLabels, err := aws.ParseRequiredLabels(config.RequiredClusterLabels)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RequiredClusterLabels are invalid: %v", config, err)
+	}
+
 	mutator := &Mutator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		autoPatchUpgrade:        config.AutoPatchUpgrade,
+		podSecurityDefaultLevel: config.PodSecurityDefaultLevel,
+		requiredLabels:          requiredLabels,
 	}
 
 	return mutator, nil
 }
 
 // Mutate is the function executed for every matching webhook request.
-func (m *Mutator) Mutate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
 	var result []mutator.PatchOperation
 
 	if request.DryRun != nil && *request.DryRun {
@@ -73,6 +92,24 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse Cluster: %v", err)
 	}
 
+	patch, err = m.MutateClusterLabel(cluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = aws.MutateRequiredLabels(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, cluster.GetObjectMeta(), m.requiredLabels)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutatePodSecurityDefault(*cluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	capi, err := aws.IsCAPIRelease(cluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -85,9 +122,15 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
+	if len(patch) == 0 {
+		patch, err = m.MutateReleasePatchVersion(*cluster)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
 	releaseVersion, err := aws.ReleaseVersion(cluster, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
 	}
 	result = append(result, patch...)
 
@@ -124,15 +167,103 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 		return result, nil
 	}
 
+	if key.Release(cluster) != key.Release(oldCluster) {
+		patch, err = m.MutateReleasePatchVersion(*cluster)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
+		// the operator version has to be taken from the bumped release
+		releaseVersion, err := aws.ReleaseVersion(cluster, patch)
+		if err != nil {
+			return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
+		}
+		cluster.Labels[label.Release] = releaseVersion.String()
+	}
+
 	patch, err = m.MutateReleaseUpdate(*cluster, *oldCluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	result = append(result, patch...)
 
+	patch, err = m.MutateBreakingChangesAcknowledgement(*cluster, *oldCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateForceUpgrade(*cluster, *oldCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
+// MutateBreakingChangesAcknowledgement removes the acknowledgement of breaking changes once it has been used.
+// The acknowledgement is needed by the validator during the upgrade, so it is removed with the first update
+// after the upgrade to the acknowledged release which does not change the release version. Acknowledgements
+// of upcoming upgrades are kept.
+func (m *Mutator) MutateBreakingChangesAcknowledgement(cluster capiv1alpha2.Cluster, oldCluster capiv1alpha2.Cluster) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if key.Release(&cluster) != key.Release(&oldCluster) {
+		return result, nil
+	}
+	if _, ok := oldCluster.GetAnnotations()[aws.AnnotationAcknowledgeBreakingChanges]; !ok {
+		return result, nil
+	}
+	if cluster.GetAnnotations()[aws.AnnotationAcknowledgeBreakingChanges] != key.Release(&cluster) {
+		return result, nil
+	}
+
+	m.Log("level", "debug", "message", fmt.Sprintf("Annotation %s of Cluster %s has been used and will be removed.",
+		aws.AnnotationAcknowledgeBreakingChanges,
+		cluster.GetName()))
+	patch := mutator.PatchRemove(fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationAcknowledgeBreakingChanges)))
+	result = append(result, patch)
+
+	return result, nil
+}
+
+// MutateForceUpgrade removes the force upgrade annotation once it has been honoured. The annotation is needed by
+// the validator during the upgrade, so it is removed with the first update after it has been set which does not
+// change the release version. It has to be set together with or right before the release version change.
+func (m *Mutator) MutateForceUpgrade(cluster capiv1alpha2.Cluster, oldCluster capiv1alpha2.Cluster) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if key.Release(&cluster) != key.Release(&oldCluster) {
+		return result, nil
+	}
+	if _, ok := oldCluster.GetAnnotations()[aws.AnnotationForceUpgrade]; !ok {
+		return result, nil
+	}
+	if _, ok := cluster.GetAnnotations()[aws.AnnotationForceUpgrade]; !ok {
+		return result, nil
+	}
+
+	m.Log("level", "debug", "message", fmt.Sprintf("Annotation %s of Cluster %s has been used and will be removed.",
+		aws.AnnotationForceUpgrade,
+		cluster.GetName()))
+	patch := mutator.PatchRemove(fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationForceUpgrade)))
+	result = append(result, patch)
+
+	return result, nil
+}
+
+// MutateClusterLabel defaults the cluster label from the name of the Cluster, which is its cluster ID.
+// The labels of the given Cluster are updated to reflect the patch.
+func (m *Mutator) MutateClusterLabel(cluster *capiv1alpha2.Cluster) ([]mutator.PatchOperation, error) {
+	return aws.MutateDerivedLabel(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, cluster, label.Cluster, cluster.GetName())
+}
+
+// MutatePodSecurityDefault defaults the enforced Pod Security level of the workload cluster to the installation standard.
+func (m *Mutator) MutatePodSecurityDefault(cluster capiv1alpha2.Cluster) ([]mutator.PatchOperation, error) {
+	return aws.MutatePodSecurityDefault(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &cluster, m.podSecurityDefaultLevel)
+}
+
 func (m *Mutator) MutateOperatorVersion(cluster capiv1alpha2.Cluster, releaseVersion *semver.Version) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -142,13 +273,13 @@ func (m *Mutator) MutateOperatorVersion(cluster capiv1alpha2.Cluster, releaseVer
 		return result, nil
 	}
 	// Retrieve the `Release` CR.
-	release, err := aws.FetchRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
+	release, err := aws.FetchRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &cluster, *release, label.ClusterOperatorVersion, "cluster-operator")
+	patch, err = aws.MutateLabelFromRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &cluster, *release, label.ClusterOperatorVersion, "cluster-operator")
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -165,7 +296,7 @@ func (m *Mutator) MutateReleaseVersion(cluster capiv1alpha2.Cluster) ([]mutator.
 		return result, nil
 	}
 	// Find the newest active release.
-	newestRelease, err := aws.FetchNewestReleaseVersion(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger})
+	newestRelease, err := aws.FetchNewestReleaseVersion(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger})
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -180,6 +311,45 @@ func (m *Mutator) MutateReleaseVersion(cluster capiv1alpha2.Cluster) ([]mutator.
 	return result, nil
 }
 
+// MutateReleasePatchVersion replaces the requested release version with the newest active patch release
+// of the same minor release if the cluster or the installation opted into it.
+func (m *Mutator) MutateReleasePatchVersion(cluster capiv1alpha2.Cluster) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+	var err error
+
+	if !m.autoPatchUpgrade && !aws.IsAnnotationTrue(&cluster, aws.AnnotationAutoPatchUpgrade) {
+		return result, nil
+	}
+	if key.Release(&cluster) == "" {
+		return result, nil
+	}
+	releaseVersion, err := aws.ReleaseVersion(&cluster, []mutator.PatchOperation{})
+	if err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
+	}
+	// Pre-releases are requested explicitly and are never bumped.
+	if !aws.IsVersionProductionReady(releaseVersion) {
+		return result, nil
+	}
+	newestPatch, err := aws.FetchNewestPatchReleaseVersion(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if newestPatch.EQ(*releaseVersion) {
+		return result, nil
+	}
+
+	// mutate the release label
+	m.Log("level", "debug", "message", fmt.Sprintf("Label %s will be changed from %s to newest patch version %s.",
+		label.Release,
+		releaseVersion.String(),
+		newestPatch.String()))
+	patch := mutator.PatchAdd(fmt.Sprintf("/metadata/labels/%s", aws.EscapeJSONPatchString(label.Release)), newestPatch.String())
+	result = append(result, patch)
+
+	return result, nil
+}
+
 func (m *Mutator) MutateReleaseUpdate(cluster capiv1alpha2.Cluster, oldCluster capiv1alpha2.Cluster) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -191,15 +361,29 @@ func (m *Mutator) MutateReleaseUpdate(cluster capiv1alpha2.Cluster, oldCluster c
 	// Retrieve the `Release` CR.
 	releaseVersion, err := aws.ReleaseVersion(&cluster, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
 	}
-	release, err := aws.FetchRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
+	release, err := aws.FetchRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, releaseVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &cluster, *release, label.ClusterOperatorVersion, "cluster-operator")
+	patch, err = aws.MutateLabelFromRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &cluster, *release, label.ClusterOperatorVersion, "cluster-operator")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	// record the time of the release change so consecutive upgrades can be rate limited
+	patch, err = aws.MutateAnnotation(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &cluster, aws.AnnotationLastUpgradeTime, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	// summarize the component changes for UIs until the operators clear the annotation after the rollout
+	patch, err = m.MutateReleaseUpgradeChanges(cluster, oldCluster, *release)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -208,6 +392,40 @@ func (m *Mutator) MutateReleaseUpdate(cluster capiv1alpha2.Cluster, oldCluster c
 	return result, nil
 }
 
+// MutateReleaseUpgradeChanges sets an annotation summarizing the component version changes between the
+// old and the new release of an upgrade.
+func (m *Mutator) MutateReleaseUpgradeChanges(cluster capiv1alpha2.Cluster, oldCluster capiv1alpha2.Cluster, release releasev1alpha1.Release) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	oldReleaseVersion, err := aws.ReleaseVersion(&oldCluster, []mutator.PatchOperation{})
+	if err != nil {
+		m.Log("level", "debug", "message", fmt.Sprintf("Release changes of Cluster %s can not be computed: %v", cluster.GetName(), err))
+		return result, nil
+	}
+	oldRelease, err := aws.FetchRelease(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, oldReleaseVersion)
+	if aws.IsNotFound(err) {
+		// Old releases may have been deleted already.
+		m.Log("level", "debug", "message", fmt.Sprintf("Release changes of Cluster %s can not be computed: %v", cluster.GetName(), err))
+		return result, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	changes := aws.GetReleaseComponentChanges(*oldRelease, release)
+	if changes == "" {
+		return result, nil
+	}
+
+	return aws.MutateAnnotation(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &cluster, aws.AnnotationReleaseUpgradeChanges, changes)
+}
+
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Mutator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
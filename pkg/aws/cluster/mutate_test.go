@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"testing"
 
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
 
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
@@ -152,3 +153,405 @@ func TestMutateReleaseUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestMutateReleasePatchVersion(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		autoPatchUpgrade bool
+		annotation       string
+		releaseVersion   string
+		expectedPatch    string
+	}{
+		{
+			// Don't bump the release if not opted in
+			name: "case 0",
+			ctx:  context.Background(),
+
+			releaseVersion: "3.1.0",
+			expectedPatch:  "",
+		},
+		{
+			// Bump the release if opted in via annotation
+			name: "case 1",
+			ctx:  context.Background(),
+
+			annotation:     "true",
+			releaseVersion: "3.1.0",
+			expectedPatch:  "3.1.2",
+		},
+		{
+			// Bump the release if opted in via installation config
+			name: "case 2",
+			ctx:  context.Background(),
+
+			autoPatchUpgrade: true,
+			releaseVersion:   "3.1.1",
+			expectedPatch:    "3.1.2",
+		},
+		{
+			// Don't bump the release if it is the newest patch already
+			name: "case 3",
+			ctx:  context.Background(),
+
+			autoPatchUpgrade: true,
+			releaseVersion:   "3.1.2",
+			expectedPatch:    "",
+		},
+		{
+			// Don't bump the release if there is no newer patch of the same minor
+			name: "case 4",
+			ctx:  context.Background(),
+
+			autoPatchUpgrade: true,
+			releaseVersion:   "3.0.0",
+			expectedPatch:    "",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			var updatedRelease string
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Mutator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+
+				autoPatchUpgrade: tc.autoPatchUpgrade,
+			}
+			// create releases
+			releases := []unittest.ReleaseData{
+				{
+					Name:  "v3.0.0",
+					State: releasev1alpha1.StateActive,
+				},
+				{
+					Name:  "v3.1.0",
+					State: releasev1alpha1.StateActive,
+				},
+				{
+					Name:  "v3.1.1",
+					State: releasev1alpha1.StateActive,
+				},
+				{
+					Name:  "v3.1.2",
+					State: releasev1alpha1.StateActive,
+				},
+				{
+					Name:  "v3.1.3",
+					State: releasev1alpha1.StateDeprecated,
+				},
+				{
+					Name:  "v3.2.0",
+					State: releasev1alpha1.StateActive,
+				},
+			}
+			for _, r := range releases {
+				release := unittest.DefaultRelease()
+				release.SetName(r.Name)
+				release.Spec.State = r.State
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &release)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.SetLabels(map[string]string{label.Release: tc.releaseVersion})
+			if tc.annotation != "" {
+				cluster.SetAnnotations(map[string]string{aws.AnnotationAutoPatchUpgrade: tc.annotation})
+			}
+
+			// run mutate function to bump the release label
+			var patch []mutator.PatchOperation
+			patch, err = mutate.MutateReleasePatchVersion(*cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// parse patches
+			for _, p := range patch {
+				if p.Path == fmt.Sprintf("/metadata/labels/%s", aws.EscapeJSONPatchString(label.Release)) {
+					updatedRelease = p.Value.(string)
+				}
+			}
+			// check if the release label is as expected
+			if tc.expectedPatch != updatedRelease {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedPatch, updatedRelease)
+			}
+		})
+	}
+}
+
+func TestMutateBreakingChangesAcknowledgement(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		newVersion      string
+		oldVersion      string
+		oldAcknowledged bool
+		newAcknowledged bool
+		expectRemoval   bool
+	}{
+		{
+			// keep the acknowledgement during the upgrade
+			name: "case 0",
+			ctx:  context.Background(),
+
+			newVersion:      "4.0.0",
+			oldVersion:      "3.0.0",
+			oldAcknowledged: true,
+			newAcknowledged: true,
+			expectRemoval:   false,
+		},
+		{
+			// keep a newly set acknowledgement
+			name: "case 1",
+			ctx:  context.Background(),
+
+			newVersion:      "3.0.0",
+			oldVersion:      "3.0.0",
+			oldAcknowledged: false,
+			newAcknowledged: true,
+			expectRemoval:   false,
+		},
+		{
+			// remove the acknowledgement after the upgrade
+			name: "case 2",
+			ctx:  context.Background(),
+
+			newVersion:      "4.0.0",
+			oldVersion:      "4.0.0",
+			oldAcknowledged: true,
+			newAcknowledged: true,
+			expectRemoval:   true,
+		},
+		{
+			// acknowledgement already removed
+			name: "case 3",
+			ctx:  context.Background(),
+
+			newVersion:      "4.0.0",
+			oldVersion:      "4.0.0",
+			oldAcknowledged: true,
+			newAcknowledged: false,
+			expectRemoval:   false,
+		},
+		{
+			// keep the acknowledgement of an upcoming upgrade during other updates
+			name: "case 4",
+			ctx:  context.Background(),
+
+			newVersion:      "3.0.0",
+			oldVersion:      "3.0.0",
+			oldAcknowledged: true,
+			newAcknowledged: true,
+			expectRemoval:   false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			// create old and new objects
+			cluster := unittest.DefaultCluster()
+			oldCluster := unittest.DefaultCluster()
+			cluster.SetLabels(map[string]string{label.Release: tc.newVersion})
+			oldCluster.SetLabels(map[string]string{label.Release: tc.oldVersion})
+			if tc.newAcknowledged {
+				cluster.SetAnnotations(map[string]string{aws.AnnotationAcknowledgeBreakingChanges: "4.0.0"})
+			}
+			if tc.oldAcknowledged {
+				oldCluster.SetAnnotations(map[string]string{aws.AnnotationAcknowledgeBreakingChanges: "4.0.0"})
+			}
+
+			var patch []mutator.PatchOperation
+			patch, err = mutate.MutateBreakingChangesAcknowledgement(*cluster, *oldCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed := false
+			for _, p := range patch {
+				if p.Operation == "remove" && p.Path == fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationAcknowledgeBreakingChanges)) {
+					removed = true
+				}
+			}
+			if tc.expectRemoval != removed {
+				t.Fatalf("expected removal to be %t", tc.expectRemoval)
+			}
+		})
+	}
+}
+
+func TestMutateForceUpgrade(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		newVersion    string
+		oldVersion    string
+		oldForced     bool
+		newForced     bool
+		expectRemoval bool
+	}{
+		{
+			// keep the annotation during the upgrade
+			name: "case 0",
+			ctx:  context.Background(),
+
+			newVersion:    "4.0.0",
+			oldVersion:    "3.0.0",
+			oldForced:     true,
+			newForced:     true,
+			expectRemoval: false,
+		},
+		{
+			// keep a newly set annotation
+			name: "case 1",
+			ctx:  context.Background(),
+
+			newVersion:    "3.0.0",
+			oldVersion:    "3.0.0",
+			oldForced:     false,
+			newForced:     true,
+			expectRemoval: false,
+		},
+		{
+			// remove the annotation after the upgrade
+			name: "case 2",
+			ctx:  context.Background(),
+
+			newVersion:    "4.0.0",
+			oldVersion:    "4.0.0",
+			oldForced:     true,
+			newForced:     true,
+			expectRemoval: true,
+		},
+		{
+			// annotation already removed
+			name: "case 3",
+			ctx:  context.Background(),
+
+			newVersion:    "4.0.0",
+			oldVersion:    "4.0.0",
+			oldForced:     true,
+			newForced:     false,
+			expectRemoval: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			// create old and new objects
+			cluster := unittest.DefaultCluster()
+			oldCluster := unittest.DefaultCluster()
+			cluster.SetLabels(map[string]string{label.Release: tc.newVersion})
+			oldCluster.SetLabels(map[string]string{label.Release: tc.oldVersion})
+			if tc.newForced {
+				cluster.SetAnnotations(map[string]string{aws.AnnotationForceUpgrade: "true"})
+			}
+			if tc.oldForced {
+				oldCluster.SetAnnotations(map[string]string{aws.AnnotationForceUpgrade: "true"})
+			}
+
+			var patch []mutator.PatchOperation
+			patch, err = mutate.MutateForceUpgrade(*cluster, *oldCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed := false
+			for _, p := range patch {
+				if p.Operation == "remove" && p.Path == fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationForceUpgrade)) {
+					removed = true
+				}
+			}
+			if tc.expectRemoval != removed {
+				t.Fatalf("expected removal to be %t", tc.expectRemoval)
+			}
+		})
+	}
+}
+
+func TestMutatePodSecurityDefault(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		defaultLevel  string
+		annotations   map[string]string
+		expectedLevel string
+	}{
+		{
+			// default the enforced level
+			name: "case 0",
+			ctx:  context.Background(),
+
+			defaultLevel:  "baseline",
+			annotations:   nil,
+			expectedLevel: "baseline",
+		},
+		{
+			// keep the enforced level
+			name: "case 1",
+			ctx:  context.Background(),
+
+			defaultLevel:  "baseline",
+			annotations:   map[string]string{aws.AnnotationPodSecurityEnforce: "privileged"},
+			expectedLevel: "",
+		},
+		{
+			// default the enforced level when only warnings are configured
+			name: "case 2",
+			ctx:  context.Background(),
+
+			defaultLevel:  "restricted",
+			annotations:   map[string]string{aws.AnnotationPodSecurityWarn: "restricted"},
+			expectedLevel: "restricted",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			mutate := &Mutator{
+				k8sClient:               unittest.FakeK8sClient(),
+				logger:                  microloggertest.New(),
+				podSecurityDefaultLevel: tc.defaultLevel,
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(tc.annotations)
+
+			var patch []mutator.PatchOperation
+			patch, err = mutate.MutatePodSecurityDefault(*cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			level := ""
+			for _, p := range patch {
+				if p.Path == fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationPodSecurityEnforce)) {
+					level = p.Value.(string)
+				}
+				if p.Path == "/metadata/annotations" {
+					level = p.Value.(map[string]string)[aws.AnnotationPodSecurityEnforce]
+				}
+			}
+			if tc.expectedLevel != level {
+				t.Fatalf("expected level %#q to be defaulted but got %#q", tc.expectedLevel, level)
+			}
+		})
+	}
+}
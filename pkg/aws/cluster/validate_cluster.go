@@ -2,8 +2,12 @@ package cluster
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"time"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -13,16 +17,22 @@ import (
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/clustercontext"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
 )
 
 type Validator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
+	clusterContext   *clustercontext.Store
+	readinessChecks  bool
+	requiredLabels   []aws.RequiredLabel
 	restrictedGroups []string
+	upgradeCooldown  time.Duration
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -33,20 +43,31 @@ func NewValidator(config config.Config) (*Validator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
 
+	requiredLabels, err := aws.ParseRequiredLabels(config.RequiredClusterLabels)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RequiredClusterLabels are invalid: %v", config, err)
+	}
+
 	v := &Validator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
+		clusterContext:  config.ClusterContext,
+		readinessChecks: config.UpgradeReadinessChecks,
+		requiredLabels:  requiredLabels,
 		restrictedGroups: []string{
 			config.AdminGroup,
 			config.AllTargetGroup,
 		},
+		upgradeCooldown: config.UpgradeCooldown,
 	}
 
 	return v, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
 	if request.Operation == admissionv1.Create {
 		return v.ValidateCreate(request)
 	}
@@ -64,7 +85,32 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, cluster); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscluster: %v", err)
 	}
-	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), cluster)
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.RequiredLabelsValid(cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.ClusterNameValid(cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.PodSecurityValid(cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	capi, err := aws.IsCAPIRelease(cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	if capi {
+		return true, nil
+	}
+
+	err = v.ReleaseVersionCreateValid(cluster)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -84,6 +130,10 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 	if _, _, err := mutator.Deserializer.Decode(request.OldObject.Raw, nil, oldCluster); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse old Cluster: %v", err)
 	}
+	err = v.PodSecurityValid(cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	capi, err := aws.IsCAPIRelease(cluster)
 	if err != nil {
@@ -98,6 +148,10 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		if err != nil {
 			return false, microerror.Mask(err)
 		}
+		err = v.ControlPlaneChangeValid(oldCluster, cluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
 		err = v.ClusterLabelKeysValid(oldCluster, cluster)
 		if err != nil {
 			return false, microerror.Mask(err)
@@ -110,35 +164,253 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		if err != nil {
 			return false, microerror.Mask(err)
 		}
+		err = v.UpgradeCooldownValid(oldCluster, cluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.LastUpgradeTimeValid(oldCluster, cluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.BreakingChangesAcknowledgedValid(oldCluster, cluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+		err = v.CiliumMigrationValid(oldCluster, cluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
 	}
 
 	return true, nil
 }
 
-func (v *Validator) ClusterLabelKeysValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
-	return aws.ValidateLabelKeys(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, oldCluster, newCluster)
-}
+// BreakingChangesAcknowledgedValid checks that upgrades to a release containing breaking changes
+// are acknowledged by annotating the Cluster with the target release version.
+func (v *Validator) BreakingChangesAcknowledgedValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	var err error
 
-func (v *Validator) ClusterLabelValuesValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
-	return aws.ValidateLabelValues(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, oldCluster, newCluster)
+	if key.Release(newCluster) == key.Release(oldCluster) {
+		return nil
+	}
+	releaseVersion, err := aws.ReleaseVersion(newCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
+	}
+	// Retrieve the `Release` CR.
+	release, err := aws.FetchRelease(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, releaseVersion)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if !aws.IsAnnotationTrue(release, aws.AnnotationReleaseBreakingChanges) {
+		return nil
+	}
+	if newCluster.GetAnnotations()[aws.AnnotationAcknowledgeBreakingChanges] != releaseVersion.String() {
+		return microerror.Maskf(notAllowedError, "Release %v contains breaking changes. Set annotation %s to \"%s\" to acknowledge them and upgrade Cluster %v.",
+			release.GetName(),
+			aws.AnnotationAcknowledgeBreakingChanges,
+			releaseVersion.String(),
+			newCluster.GetName())
+	}
+
+	return nil
 }
 
-func (v *Validator) ClusterStatusValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+// CiliumMigrationValid checks the prerequisites of upgrades from a release using aws-cni to a release using Cilium.
+// The AWSCluster needs an overlay pod CIDR for Cilium which is large enough and does not overlap the networks which
+// are in use by the cluster, and the Calico network policies of aws-cni must be disabled.
+func (v *Validator) CiliumMigrationValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
 	var err error
 
 	if key.Release(newCluster) == key.Release(oldCluster) {
 		return nil
 	}
+	releaseVersion, err := aws.ReleaseVersion(newCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
+	}
+	oldReleaseVersion, err := aws.ReleaseVersion(oldCluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
+	}
+	if aws.IsCiliumVersion(oldReleaseVersion) || !aws.IsCiliumVersion(releaseVersion) {
+		return nil
+	}
+
 	// Retrieve the `AWSCluster` CR.
-	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, newCluster)
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, newCluster)
 	if err != nil {
 		return microerror.Mask(err)
 	}
-	if !v.isTransitioned(awsCluster.GetCommonClusterStatus()) {
+	if aws.IsAnnotationTrue(awsCluster, aws.AnnotationCalicoPolicyOnly) {
+		return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded to release %v because it switches the CNI to Cilium, which enforces network policies itself. Remove annotation %s from AWSCluster %v before the upgrade.",
+			newCluster.GetName(),
+			releaseVersion.String(),
+			aws.AnnotationCalicoPolicyOnly,
+			awsCluster.GetName())
+	}
+	podCIDR, ok := awsCluster.GetAnnotations()[aws.AnnotationCiliumPodCIDR]
+	if !ok {
+		return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded to release %v because it switches the CNI to Cilium, which needs an overlay pod CIDR. Set annotation %s on AWSCluster %v to a free CIDR of size /%d or larger before the upgrade.",
+			newCluster.GetName(),
+			releaseVersion.String(),
+			aws.AnnotationCiliumPodCIDR,
+			awsCluster.GetName(),
+			aws.CiliumPodCIDRMaxPrefixLength)
+	}
+	_, podNet, err := net.ParseCIDR(podCIDR)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, "AWSCluster %v annotation %s value %#q is not a valid CIDR.",
+			awsCluster.GetName(),
+			aws.AnnotationCiliumPodCIDR,
+			podCIDR)
+	}
+	if ones, _ := podNet.Mask.Size(); ones > aws.CiliumPodCIDRMaxPrefixLength {
+		return microerror.Maskf(notAllowedError, "AWSCluster %v annotation %s value %#q is too small for the pods of the cluster. Use a CIDR of size /%d or larger.",
+			awsCluster.GetName(),
+			aws.AnnotationCiliumPodCIDR,
+			podCIDR,
+			aws.CiliumPodCIDRMaxPrefixLength)
+	}
+	// During the migration aws-cni and Cilium run side by side, so the overlay must not overlap any network in use.
+	for _, cidr := range []string{awsCluster.Status.Provider.Network.CIDR, awsCluster.Spec.Provider.Pods.CIDRBlock} {
+		_, usedNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if aws.CIDRsIntersect(podNet, usedNet) {
+			return microerror.Maskf(notAllowedError, "AWSCluster %v annotation %s value %#q overlaps with CIDR %v which is in use by the cluster. Choose a free CIDR for the Cilium pods.",
+				awsCluster.GetName(),
+				aws.AnnotationCiliumPodCIDR,
+				podCIDR,
+				cidr)
+		}
+	}
+
+	return nil
+}
+
+// PodSecurityValid checks the Pod Security annotations configuring the defaults of the workload cluster.
+func (v *Validator) PodSecurityValid(cluster *capiv1alpha2.Cluster) error {
+	return aws.ValidatePodSecurity(cluster)
+}
+
+func (v *Validator) ClusterLabelKeysValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	return aws.ValidateLabelKeys(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, oldCluster, newCluster)
+}
+
+func (v *Validator) ClusterLabelValuesValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	return aws.ValidateLabelValues(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, oldCluster, newCluster)
+}
+
+func (v *Validator) ClusterNameValid(cluster *capiv1alpha2.Cluster) error {
+	return aws.ValidateClusterName(cluster)
+}
+
+func (v *Validator) ClusterStatusValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	if key.Release(newCluster) == key.Release(oldCluster) {
+		return nil
+	}
+	// The precomputed cluster context is used when available to avoid API calls.
+	snapshot, cached := v.clusterSnapshot(newCluster)
+	transitioned := snapshot.Transitioned
+	if !cached {
+		// Retrieve the `AWSCluster` CR.
+		awsCluster, err := aws.FetchAWSCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, newCluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		transitioned = v.isTransitioned(awsCluster.GetCommonClusterStatus())
+	}
+	if !transitioned {
 		return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded at the present moment because it has not transitioned yet.",
 			newCluster.GetName(),
 		)
 	}
+	if !v.readinessChecks {
+		return nil
+	}
+	// The annotation only overrides the readiness checks. Clusters which have
+	// not transitioned yet can not be upgraded in any case.
+	if aws.IsAnnotationTrue(newCluster, aws.AnnotationForceUpgrade) {
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("Readiness checks for Cluster %s are skipped due to annotation %s", newCluster.GetName(), aws.AnnotationForceUpgrade))
+		return nil
+	}
+	// The status of the old object is used because status changes are not part of a regular update.
+	if !oldCluster.Status.InfrastructureReady {
+		return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded at the present moment because its infrastructure is not ready. Set annotation %s to \"true\" to upgrade anyway.",
+			newCluster.GetName(),
+			aws.AnnotationForceUpgrade,
+		)
+	}
+	if oldCluster.Status.ErrorMessage != nil {
+		return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded at the present moment because it reports an error: %s. Set annotation %s to \"true\" to upgrade anyway.",
+			newCluster.GetName(),
+			*oldCluster.Status.ErrorMessage,
+			aws.AnnotationForceUpgrade,
+		)
+	}
+	nodePools := snapshot.NodePools
+	if !cached {
+		// Retrieve the `MachineDeployment` CRs.
+		machineDeployments, err := aws.FetchMachineDeployments(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, newCluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for _, md := range machineDeployments {
+			nodePools = append(nodePools, clustercontext.NodePool{
+				ID:                  key.MachineDeployment(&md),
+				Replicas:            md.Status.Replicas,
+				ReadyReplicas:       md.Status.ReadyReplicas,
+				UnavailableReplicas: md.Status.UnavailableReplicas,
+			})
+		}
+	}
+	for _, np := range nodePools {
+		if np.UnavailableReplicas > 0 || np.ReadyReplicas < np.Replicas {
+			return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded at the present moment because node pool %s has %v of %v nodes ready. Set annotation %s to \"true\" to upgrade anyway.",
+				newCluster.GetName(),
+				np.ID,
+				np.ReadyReplicas,
+				np.Replicas,
+				aws.AnnotationForceUpgrade,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ControlPlaneChangeValid checks that the release version is not changed while the number of masters of the cluster
+// is still being changed, because overlapping rolling operations are not supported.
+func (v *Validator) ControlPlaneChangeValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	if key.Release(newCluster) == key.Release(oldCluster) {
+		return nil
+	}
+	if aws.IsAnnotationTrue(newCluster, aws.AnnotationForceUpgrade) {
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("Control plane check for Cluster %s is skipped due to annotation %s", newCluster.GetName(), aws.AnnotationForceUpgrade))
+		return nil
+	}
+	// Retrieve the `G8sControlPlane` CR.
+	g8sControlPlane, err := aws.FetchG8sControlPlane(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, newCluster)
+	if aws.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	// A status without replicas has not been reported by the operator yet.
+	status := g8sControlPlane.Status
+	if status.Replicas == 0 {
+		return nil
+	}
+	if status.Replicas != int32(g8sControlPlane.Spec.Replicas) || status.ReadyReplicas < int32(g8sControlPlane.Spec.Replicas) {
+		return microerror.Maskf(notAllowedError, "Cluster %v can not be upgraded at the present moment because its control plane is being changed to %v masters and has %v of them ready. Set annotation %s to \"true\" to upgrade anyway.",
+			newCluster.GetName(),
+			g8sControlPlane.Spec.Replicas,
+			status.ReadyReplicas,
+			aws.AnnotationForceUpgrade,
+		)
+	}
 
 	return nil
 }
@@ -151,11 +423,11 @@ func (v *Validator) ReleaseVersionValid(oldCluster *capiv1alpha2.Cluster, newClu
 	}
 	releaseVersion, err := aws.ReleaseVersion(newCluster, []mutator.PatchOperation{})
 	if err != nil {
-		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster")
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
 	}
 	oldReleaseVersion, err := aws.ReleaseVersion(oldCluster, []mutator.PatchOperation{})
 	if err != nil {
-		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster")
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
 	}
 	if releaseVersion.Major < oldReleaseVersion.Major {
 		return microerror.Maskf(notAllowedError, "Upgrade from %v to %v is a major downgrade and is not supported.",
@@ -168,7 +440,7 @@ func (v *Validator) ReleaseVersionValid(oldCluster *capiv1alpha2.Cluster, newClu
 			releaseVersion.String())
 	}
 	// Retrieve the `Release` CR.
-	release, err := aws.FetchRelease(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, releaseVersion)
+	release, err := aws.FetchRelease(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, releaseVersion)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -179,6 +451,98 @@ func (v *Validator) ReleaseVersionValid(oldCluster *capiv1alpha2.Cluster, newClu
 	return nil
 }
 
+func (v *Validator) ReleaseVersionCreateValid(cluster *capiv1alpha2.Cluster) error {
+	var err error
+
+	// The release version is defaulted by the mutator, so there is nothing to validate yet.
+	if key.Release(cluster) == "" {
+		return nil
+	}
+	releaseVersion, err := aws.ReleaseVersion(cluster, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster: %v", err)
+	}
+	// Retrieve the `Release` CR.
+	var reason string
+	release, err := aws.FetchRelease(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, releaseVersion)
+	if aws.IsNotFound(err) {
+		reason = "does not exist"
+	} else if err != nil {
+		return microerror.Mask(err)
+	} else if release.Spec.State == releasev1alpha1.StateDeprecated {
+		reason = "is deprecated and can not be used for new clusters"
+	} else {
+		return nil
+	}
+
+	// The active releases are only listed for the error message.
+	activeReleases, err := aws.FetchActiveReleaseVersions(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return microerror.Maskf(notAllowedError, "Release %v %s. Valid release versions are: %v",
+		releaseVersion.String(),
+		reason,
+		activeReleases)
+}
+
+func (v *Validator) RequiredLabelsValid(cluster *capiv1alpha2.Cluster) error {
+	return aws.ValidateRequiredLabels(cluster.GetObjectMeta(), v.requiredLabels)
+}
+
+func (v *Validator) UpgradeCooldownValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	if key.Release(newCluster) == key.Release(oldCluster) {
+		return nil
+	}
+	if v.upgradeCooldown == 0 || aws.IsAnnotationTrue(newCluster, aws.AnnotationForceUpgrade) {
+		return nil
+	}
+	lastUpgrade, ok := oldCluster.GetAnnotations()[aws.AnnotationLastUpgradeTime]
+	if !ok {
+		return nil
+	}
+	lastUpgradeTime, err := time.Parse(time.RFC3339, lastUpgrade)
+	if err != nil {
+		// An unparsable timestamp is treated as an upgrade right now, so that it can not be used to bypass the cooldown.
+		v.Log("level", "debug", "message", fmt.Sprintf("Cluster %s annotation %s has invalid value %#q: %v", newCluster.GetName(), aws.AnnotationLastUpgradeTime, lastUpgrade, err))
+		lastUpgradeTime = time.Now()
+	}
+	if time.Since(lastUpgradeTime) < v.upgradeCooldown {
+		return microerror.Maskf(notAllowedError, "Cluster %v was last upgraded at %v. The next upgrade is allowed after %v. Set annotation %s to \"true\" to force the upgrade.",
+			newCluster.GetName(),
+			lastUpgradeTime.Format(time.RFC3339),
+			lastUpgradeTime.Add(v.upgradeCooldown).Format(time.RFC3339),
+			aws.AnnotationForceUpgrade)
+	}
+
+	return nil
+}
+
+// LastUpgradeTimeValid denies adding, removing or changing the last upgrade time annotation outside of release
+// version changes. The annotation is maintained by the mutator and changing it would bypass the upgrade cooldown.
+func (v *Validator) LastUpgradeTimeValid(oldCluster *capiv1alpha2.Cluster, newCluster *capiv1alpha2.Cluster) error {
+	if key.Release(newCluster) != key.Release(oldCluster) {
+		return nil
+	}
+	oldValue, oldOK := oldCluster.GetAnnotations()[aws.AnnotationLastUpgradeTime]
+	newValue, newOK := newCluster.GetAnnotations()[aws.AnnotationLastUpgradeTime]
+	if oldOK == newOK && oldValue == newValue {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, "Cluster %v annotation %s can not be changed. It is maintained by the admission controller when the release version changes.",
+		newCluster.GetName(),
+		aws.AnnotationLastUpgradeTime)
+}
+
+// clusterSnapshot returns the precomputed context of the given cluster and whether it exists.
+func (v *Validator) clusterSnapshot(cluster *capiv1alpha2.Cluster) (clustercontext.Snapshot, bool) {
+	if v.clusterContext == nil {
+		return clustercontext.Snapshot{}, false
+	}
+	return v.clusterContext.Get(key.Cluster(cluster))
+}
+
 func (v *Validator) isAdmin(userInfo authenticationv1.UserInfo) bool {
 	for _, u := range aws.ValidLabelAdmins() {
 		if u == userInfo.Username {
@@ -204,6 +568,13 @@ func (v *Validator) isTransitioned(status infrastructurev1alpha2.CommonClusterSt
 	return condition == infrastructurev1alpha2.ClusterStatusConditionCreated || condition == infrastructurev1alpha2.ClusterStatusConditionUpdated
 }
 
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (v *Validator) Log(keyVals ...interface{}) {
 	v.logger.Log(keyVals...)
 }
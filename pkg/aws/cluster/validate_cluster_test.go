@@ -12,6 +12,7 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
 
@@ -210,6 +211,7 @@ func TestValidClusterStatus(t *testing.T) {
 		oldReleaseVersion string
 		newReleaseVersion string
 		conditions        []infrastructurev1alpha2.CommonClusterStatusCondition
+		force             bool
 
 		valid bool
 	}{
@@ -290,6 +292,22 @@ func TestValidClusterStatus(t *testing.T) {
 			newReleaseVersion: "4.0.0",
 			valid:             true,
 		},
+		{
+			// Cluster is updating and the upgrade is forced
+			name: "case 5",
+			ctx:  context.Background(),
+
+			conditions: []infrastructurev1alpha2.CommonClusterStatusCondition{
+				{LastTransitionTime: metav1.NewTime(time.Now()),
+					Condition: infrastructurev1alpha2.ClusterStatusConditionUpdating},
+				{LastTransitionTime: metav1.NewTime(time.Now().Add(-15 * time.Minute)),
+					Condition: infrastructurev1alpha2.ClusterStatusConditionCreated},
+			},
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "4.0.0",
+			force:             true,
+			valid:             false,
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -318,6 +336,121 @@ func TestValidClusterStatus(t *testing.T) {
 			newLabels := unittest.DefaultLabels()
 			newLabels[label.ReleaseVersion] = tc.newReleaseVersion
 			newObject.SetLabels(newLabels)
+			if tc.force {
+				newObject.SetAnnotations(map[string]string{aws.AnnotationForceUpgrade: "true"})
+			}
+
+			// check if the result is as expected
+			err = handle.ClusterStatusValid(oldObject, newObject)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateUpgradeReadiness(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		infrastructureReady bool
+		unavailableNodes    int32
+		force               bool
+		readinessChecks     bool
+
+		valid bool
+	}{
+		{
+			// healthy cluster
+			name: "case 0",
+			ctx:  context.Background(),
+
+			infrastructureReady: true,
+			readinessChecks:     true,
+			valid:               true,
+		},
+		{
+			// infrastructure not ready
+			name: "case 1",
+			ctx:  context.Background(),
+
+			infrastructureReady: false,
+			readinessChecks:     true,
+			valid:               false,
+		},
+		{
+			// node pool with unavailable nodes
+			name: "case 2",
+			ctx:  context.Background(),
+
+			infrastructureReady: true,
+			unavailableNodes:    1,
+			readinessChecks:     true,
+			valid:               false,
+		},
+		{
+			// unhealthy cluster with forced upgrade
+			name: "case 3",
+			ctx:  context.Background(),
+
+			infrastructureReady: false,
+			unavailableNodes:    1,
+			force:               true,
+			readinessChecks:     true,
+			valid:               true,
+		},
+		{
+			// unhealthy cluster with readiness checks disabled
+			name: "case 4",
+			ctx:  context.Background(),
+
+			infrastructureReady: false,
+			unavailableNodes:    1,
+			readinessChecks:     false,
+			valid:               true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			handle := &Validator{
+				k8sClient:       fakeK8sClient,
+				logger:          microloggertest.New(),
+				readinessChecks: tc.readinessChecks,
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			machineDeployment := unittest.DefaultMachineDeployment()
+			machineDeployment.Status.UnavailableReplicas = tc.unavailableNodes
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &machineDeployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// create old and new object with release version labels
+			oldObject := unittest.DefaultCluster()
+			oldLabels := unittest.DefaultLabels()
+			oldLabels[label.ReleaseVersion] = "3.0.0"
+			oldObject.SetLabels(oldLabels)
+			oldObject.Status.InfrastructureReady = tc.infrastructureReady
+
+			newObject := unittest.DefaultCluster()
+			newLabels := unittest.DefaultLabels()
+			newLabels[label.ReleaseVersion] = "3.1.0"
+			newObject.SetLabels(newLabels)
+			if tc.force {
+				newObject.SetAnnotations(map[string]string{aws.AnnotationForceUpgrade: "true"})
+			}
 
 			// check if the result is as expected
 			err = handle.ClusterStatusValid(oldObject, newObject)
@@ -330,3 +463,855 @@ func TestValidClusterStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUpgradeCooldown(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		oldReleaseVersion string
+		newReleaseVersion string
+		lastUpgrade       string
+		force             bool
+		cooldown          time.Duration
+
+		valid bool
+	}{
+		{
+			// no upgrade
+			name: "case 0",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.0.0",
+			lastUpgrade:       time.Now().Add(-1 * time.Minute).UTC().Format(time.RFC3339),
+			cooldown:          time.Hour,
+			valid:             true,
+		},
+		{
+			// upgrade within the cooldown period
+			name: "case 1",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			lastUpgrade:       time.Now().Add(-1 * time.Minute).UTC().Format(time.RFC3339),
+			cooldown:          time.Hour,
+			valid:             false,
+		},
+		{
+			// upgrade after the cooldown period
+			name: "case 2",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			lastUpgrade:       time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+			cooldown:          time.Hour,
+			valid:             true,
+		},
+		{
+			// forced upgrade within the cooldown period
+			name: "case 3",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			lastUpgrade:       time.Now().Add(-1 * time.Minute).UTC().Format(time.RFC3339),
+			force:             true,
+			cooldown:          time.Hour,
+			valid:             true,
+		},
+		{
+			// cooldown disabled
+			name: "case 4",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			lastUpgrade:       time.Now().Add(-1 * time.Minute).UTC().Format(time.RFC3339),
+			cooldown:          0,
+			valid:             true,
+		},
+		{
+			// first upgrade of the cluster
+			name: "case 5",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			lastUpgrade:       "",
+			cooldown:          time.Hour,
+			valid:             true,
+		},
+		{
+			// unparsable last upgrade time is treated as an upgrade right now
+			name: "case 6",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			lastUpgrade:       "yesterday",
+			cooldown:          time.Hour,
+			valid:             false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient:       unittest.FakeK8sClient(),
+				logger:          microloggertest.New(),
+				upgradeCooldown: tc.cooldown,
+			}
+
+			// create old and new object with release version labels
+			oldObject := unittest.DefaultCluster()
+			oldLabels := unittest.DefaultLabels()
+			oldLabels[label.ReleaseVersion] = tc.oldReleaseVersion
+			oldObject.SetLabels(oldLabels)
+			if tc.lastUpgrade != "" {
+				oldObject.SetAnnotations(map[string]string{aws.AnnotationLastUpgradeTime: tc.lastUpgrade})
+			}
+
+			newObject := unittest.DefaultCluster()
+			newLabels := unittest.DefaultLabels()
+			newLabels[label.ReleaseVersion] = tc.newReleaseVersion
+			newObject.SetLabels(newLabels)
+			if tc.force {
+				newObject.SetAnnotations(map[string]string{aws.AnnotationForceUpgrade: "true"})
+			}
+
+			// check if the result is as expected
+			err = handle.UpgradeCooldownValid(oldObject, newObject)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateLastUpgradeTime(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		oldReleaseVersion string
+		newReleaseVersion string
+		oldLastUpgrade    string
+		newLastUpgrade    string
+
+		valid bool
+	}{
+		{
+			// annotation unchanged
+			name: "case 0",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.0.0",
+			oldLastUpgrade:    "2021-01-01T00:00:00Z",
+			newLastUpgrade:    "2021-01-01T00:00:00Z",
+			valid:             true,
+		},
+		{
+			// annotation changed
+			name: "case 1",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.0.0",
+			oldLastUpgrade:    "2021-01-01T00:00:00Z",
+			newLastUpgrade:    "2020-01-01T00:00:00Z",
+			valid:             false,
+		},
+		{
+			// annotation removed
+			name: "case 2",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.0.0",
+			oldLastUpgrade:    "2021-01-01T00:00:00Z",
+			newLastUpgrade:    "",
+			valid:             false,
+		},
+		{
+			// annotation added
+			name: "case 3",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.0.0",
+			oldLastUpgrade:    "",
+			newLastUpgrade:    "2020-01-01T00:00:00Z",
+			valid:             false,
+		},
+		{
+			// annotation changed by the mutator with the release version
+			name: "case 4",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			oldLastUpgrade:    "2021-01-01T00:00:00Z",
+			newLastUpgrade:    "2021-02-01T00:00:00Z",
+			valid:             true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			handle := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			// create old and new object with release version labels and last upgrade times
+			oldObject := unittest.DefaultCluster()
+			oldLabels := unittest.DefaultLabels()
+			oldLabels[label.ReleaseVersion] = tc.oldReleaseVersion
+			oldObject.SetLabels(oldLabels)
+			if tc.oldLastUpgrade != "" {
+				oldObject.SetAnnotations(map[string]string{aws.AnnotationLastUpgradeTime: tc.oldLastUpgrade})
+			}
+
+			newObject := unittest.DefaultCluster()
+			newLabels := unittest.DefaultLabels()
+			newLabels[label.ReleaseVersion] = tc.newReleaseVersion
+			newObject.SetLabels(newLabels)
+			if tc.newLastUpgrade != "" {
+				newObject.SetAnnotations(map[string]string{aws.AnnotationLastUpgradeTime: tc.newLastUpgrade})
+			}
+
+			// check if the result is as expected
+			err = handle.LastUpgradeTimeValid(oldObject, newObject)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateReleaseVersionCreate(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		releaseVersion string
+		valid          bool
+	}{
+		{
+			// active release
+			name: "case 0",
+			ctx:  context.Background(),
+
+			releaseVersion: "3.1.0",
+			valid:          true,
+		},
+		{
+			// deprecated release
+			name: "case 1",
+			ctx:  context.Background(),
+
+			releaseVersion: "3.0.0",
+			valid:          false,
+		},
+		{
+			// release does not exist
+			name: "case 2",
+			ctx:  context.Background(),
+
+			releaseVersion: "3.2.0",
+			valid:          false,
+		},
+		{
+			// release is not set yet
+			name: "case 3",
+			ctx:  context.Background(),
+
+			releaseVersion: "",
+			valid:          true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			handle := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			// create releases for testing
+			releases := []unittest.ReleaseData{
+				{
+					Name:  "v3.1.0",
+					State: releasev1alpha1.StateActive,
+				},
+				{
+					Name:  "v3.0.0",
+					State: releasev1alpha1.StateDeprecated,
+				},
+			}
+			for _, r := range releases {
+				release := unittest.DefaultRelease()
+				release.SetName(r.Name)
+				release.Spec.State = r.State
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &release)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			object := unittest.DefaultCluster()
+			labels := unittest.DefaultLabels()
+			labels[label.ReleaseVersion] = tc.releaseVersion
+			object.SetLabels(labels)
+
+			// check if the result is as expected
+			err = handle.ReleaseVersionCreateValid(object)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRequiredLabels(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		requiredLabels []string
+		labels         map[string]string
+		valid          bool
+	}{
+		{
+			// No labels required
+			name: "case 0",
+			ctx:  context.Background(),
+
+			requiredLabels: nil,
+			labels:         map[string]string{},
+			valid:          true,
+		},
+		{
+			// Required label is set and matches
+			name: "case 1",
+			ctx:  context.Background(),
+
+			requiredLabels: []string{`{"key":"cost-center","pattern":"^[0-9]{4}$"}`},
+			labels:         map[string]string{"cost-center": "1234"},
+			valid:          true,
+		},
+		{
+			// Required label is missing
+			name: "case 2",
+			ctx:  context.Background(),
+
+			requiredLabels: []string{`{"key":"cost-center","pattern":"^[0-9]{4}$"}`},
+			labels:         map[string]string{},
+			valid:          false,
+		},
+		{
+			// Required label does not match pattern
+			name: "case 3",
+			ctx:  context.Background(),
+
+			requiredLabels: []string{`{"key":"cost-center","pattern":"^[0-9]{4}$"}`},
+			labels:         map[string]string{"cost-center": "marketing"},
+			valid:          false,
+		},
+		{
+			// Required label without pattern is set
+			name: "case 4",
+			ctx:  context.Background(),
+
+			requiredLabels: []string{`{"key":"environment"}`},
+			labels:         map[string]string{"environment": "staging"},
+			valid:          true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			requiredLabels, err := aws.ParseRequiredLabels(tc.requiredLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			handle := &Validator{
+				k8sClient:      unittest.FakeK8sClient(),
+				logger:         microloggertest.New(),
+				requiredLabels: requiredLabels,
+			}
+
+			object := unittest.DefaultCluster()
+			labels := unittest.DefaultLabels()
+			for k, v := range tc.labels {
+				labels[k] = v
+			}
+			object.SetLabels(labels)
+
+			// check if the result is as expected
+			err = handle.RequiredLabelsValid(object)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateBreakingChangesAcknowledged(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		oldReleaseVersion string
+		newReleaseVersion string
+		acknowledged      string
+
+		valid bool
+	}{
+		{
+			// no upgrade
+			name: "case 0",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "4.0.0",
+			newReleaseVersion: "4.0.0",
+			valid:             true,
+		},
+		{
+			// upgrade to a release without breaking changes
+			name: "case 1",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.0.0",
+			newReleaseVersion: "3.1.0",
+			valid:             true,
+		},
+		{
+			// upgrade to a release with breaking changes without acknowledgement
+			name: "case 2",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.1.0",
+			newReleaseVersion: "4.0.0",
+			valid:             false,
+		},
+		{
+			// upgrade to a release with breaking changes with acknowledgement
+			name: "case 3",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.1.0",
+			newReleaseVersion: "4.0.0",
+			acknowledged:      "4.0.0",
+			valid:             true,
+		},
+		{
+			// acknowledgement for another release
+			name: "case 4",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "3.1.0",
+			newReleaseVersion: "4.0.0",
+			acknowledged:      "3.1.0",
+			valid:             false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			handle := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			// create releases for testing
+			for _, name := range []string{"v3.0.0", "v3.1.0", "v4.0.0"} {
+				release := unittest.DefaultRelease()
+				release.SetName(name)
+				release.Spec.State = releasev1alpha1.StateActive
+				if name == "v4.0.0" {
+					release.SetAnnotations(map[string]string{aws.AnnotationReleaseBreakingChanges: "true"})
+				}
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &release)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// create old and new object with release version labels
+			oldObject := unittest.DefaultCluster()
+			oldLabels := unittest.DefaultLabels()
+			oldLabels[label.ReleaseVersion] = tc.oldReleaseVersion
+			oldObject.SetLabels(oldLabels)
+
+			newObject := unittest.DefaultCluster()
+			newLabels := unittest.DefaultLabels()
+			newLabels[label.ReleaseVersion] = tc.newReleaseVersion
+			newObject.SetLabels(newLabels)
+			if tc.acknowledged != "" {
+				newObject.SetAnnotations(map[string]string{aws.AnnotationAcknowledgeBreakingChanges: tc.acknowledged})
+			}
+
+			// check if the result is as expected
+			err = handle.BreakingChangesAcknowledgedValid(oldObject, newObject)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePodSecurity(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// No Pod Security annotations
+			name: "case 0",
+			ctx:  context.Background(),
+
+			annotations: map[string]string{},
+			valid:       true,
+		},
+		{
+			// Valid levels for all modes
+			name: "case 1",
+			ctx:  context.Background(),
+
+			annotations: map[string]string{
+				aws.AnnotationPodSecurityEnforce: "baseline",
+				aws.AnnotationPodSecurityAudit:   "restricted",
+				aws.AnnotationPodSecurityWarn:    "restricted",
+			},
+			valid: true,
+		},
+		{
+			// Unknown level
+			name: "case 2",
+			ctx:  context.Background(),
+
+			annotations: map[string]string{
+				aws.AnnotationPodSecurityEnforce: "strict",
+			},
+			valid: false,
+		},
+		{
+			// Unknown mode
+			name: "case 3",
+			ctx:  context.Background(),
+
+			annotations: map[string]string{
+				aws.AnnotationPodSecurityPrefix + "warning": "restricted",
+			},
+			valid: false,
+		},
+		{
+			// Levels are case sensitive
+			name: "case 4",
+			ctx:  context.Background(),
+
+			annotations: map[string]string{
+				aws.AnnotationPodSecurityWarn: "Restricted",
+			},
+			valid: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handle := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			object := unittest.DefaultCluster()
+			object.SetAnnotations(tc.annotations)
+
+			// check if the result is as expected
+			err := handle.PodSecurityValid(object)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCiliumMigration(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		oldReleaseVersion string
+		newReleaseVersion string
+		annotations       map[string]string
+
+		valid bool
+	}{
+		{
+			// upgrade does not cross the Cilium release
+			name: "case 0",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "17.0.0",
+			newReleaseVersion: "18.0.0",
+			annotations:       map[string]string{},
+			valid:             true,
+		},
+		{
+			// upgrade between Cilium releases
+			name: "case 1",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "19.0.0",
+			newReleaseVersion: "19.1.0",
+			annotations:       map[string]string{},
+			valid:             true,
+		},
+		{
+			// Cilium migration with all prerequisites
+			name: "case 2",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "18.0.0",
+			newReleaseVersion: "19.0.0",
+			annotations:       map[string]string{aws.AnnotationCiliumPodCIDR: "192.168.0.0/16"},
+			valid:             true,
+		},
+		{
+			// Cilium migration without pod CIDR
+			name: "case 3",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "18.0.0",
+			newReleaseVersion: "19.0.0",
+			annotations:       map[string]string{},
+			valid:             false,
+		},
+		{
+			// Cilium migration with Calico network policies
+			name: "case 4",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "18.0.0",
+			newReleaseVersion: "19.0.0",
+			annotations:       map[string]string{aws.AnnotationCiliumPodCIDR: "192.168.0.0/16", aws.AnnotationCalicoPolicyOnly: "true"},
+			valid:             false,
+		},
+		{
+			// Cilium migration with too small pod CIDR
+			name: "case 5",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "18.0.0",
+			newReleaseVersion: "19.0.0",
+			annotations:       map[string]string{aws.AnnotationCiliumPodCIDR: "192.168.0.0/20"},
+			valid:             false,
+		},
+		{
+			// Cilium migration with pod CIDR overlapping the aws-cni pod CIDR
+			name: "case 6",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "18.0.0",
+			newReleaseVersion: "19.0.0",
+			annotations:       map[string]string{aws.AnnotationCiliumPodCIDR: "10.0.0.0/8"},
+			valid:             false,
+		},
+		{
+			// Cilium migration with pod CIDR overlapping the network CIDR
+			name: "case 7",
+			ctx:  context.Background(),
+
+			oldReleaseVersion: "18.0.0",
+			newReleaseVersion: "19.0.0",
+			annotations:       map[string]string{aws.AnnotationCiliumPodCIDR: "172.16.0.0/12"},
+			valid:             false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			handle := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetAnnotations(tc.annotations)
+			awsCluster.Spec.Provider.Pods.CIDRBlock = unittest.DefaultPodCIDR
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// create old and new object with release version labels
+			oldObject := unittest.DefaultCluster()
+			oldLabels := unittest.DefaultLabels()
+			oldLabels[label.ReleaseVersion] = tc.oldReleaseVersion
+			oldObject.SetLabels(oldLabels)
+
+			newObject := unittest.DefaultCluster()
+			newLabels := unittest.DefaultLabels()
+			newLabels[label.ReleaseVersion] = tc.newReleaseVersion
+			newObject.SetLabels(newLabels)
+
+			// check if the result is as expected
+			err = handle.CiliumMigrationValid(oldObject, newObject)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateControlPlaneChange(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		newReleaseVersion string
+		replicas          int
+		statusReplicas    int32
+		readyReplicas     int32
+		force             bool
+
+		valid bool
+	}{
+		{
+			// upgrade with a stable control plane
+			name: "case 0",
+			ctx:  context.Background(),
+
+			newReleaseVersion: "100.1.0",
+			replicas:          3,
+			statusReplicas:    3,
+			readyReplicas:     3,
+			valid:             true,
+		},
+		{
+			// upgrade while masters are being added
+			name: "case 1",
+			ctx:  context.Background(),
+
+			newReleaseVersion: "100.1.0",
+			replicas:          3,
+			statusReplicas:    1,
+			readyReplicas:     1,
+			valid:             false,
+		},
+		{
+			// upgrade while masters are not ready
+			name: "case 2",
+			ctx:  context.Background(),
+
+			newReleaseVersion: "100.1.0",
+			replicas:          3,
+			statusReplicas:    3,
+			readyReplicas:     2,
+			valid:             false,
+		},
+		{
+			// forced upgrade while masters are being added
+			name: "case 3",
+			ctx:  context.Background(),
+
+			newReleaseVersion: "100.1.0",
+			replicas:          3,
+			statusReplicas:    1,
+			readyReplicas:     1,
+			force:             true,
+			valid:             true,
+		},
+		{
+			// no upgrade while masters are being added
+			name: "case 4",
+			ctx:  context.Background(),
+
+			newReleaseVersion: "100.0.0",
+			replicas:          3,
+			statusReplicas:    1,
+			readyReplicas:     1,
+			valid:             true,
+		},
+		{
+			// upgrade without a reported control plane status
+			name: "case 5",
+			ctx:  context.Background(),
+
+			newReleaseVersion: "100.1.0",
+			replicas:          3,
+			valid:             true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			handle := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			g8sControlPlane := unittest.DefaultG8sControlPlane()
+			g8sControlPlane.Spec.Replicas = tc.replicas
+			g8sControlPlane.Status.Replicas = tc.statusReplicas
+			g8sControlPlane.Status.ReadyReplicas = tc.readyReplicas
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &g8sControlPlane)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldObject := unittest.DefaultCluster()
+			newObject := unittest.DefaultCluster()
+			newObject.Labels[label.ReleaseVersion] = tc.newReleaseVersion
+			if tc.force {
+				newObject.SetAnnotations(map[string]string{aws.AnnotationForceUpgrade: "true"})
+			}
+
+			// check if the result is as expected
+			err = handle.ControlPlaneChangeValid(oldObject, newObject)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package aws
 
 import (
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/blang/semver"
 	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
@@ -23,12 +26,94 @@ const (
 	// DefaultMasterInstanceType is the default master instance type
 	DefaultMasterInstanceType = "m5.xlarge"
 
+	// DefaultNodePoolVolumeSize is the size in GB aws-operator gives the docker, kubelet and logging volumes
+	// of node pool workers without a configured size
+	DefaultNodePoolVolumeSize = 100
+
 	// FirstCAPIRelease is the first GS release that runs on CAPI controllers
 	FirstCAPIRelease = "20.0.0-v1alpha3"
 
+	// FirstCiliumRelease is the first GS release for AWS that uses Cilium instead of aws-cni as CNI
+	FirstCiliumRelease = "19.0.0"
+
+	// CiliumPodCIDRMaxPrefixLength is the longest prefix of the Cilium pod CIDR which still leaves enough
+	// space for the pods of all nodes
+	CiliumPodCIDRMaxPrefixLength = 18
+
+	// FirstARM64Release is the first GS release for AWS that ships arm64 node images for Graviton instance types
+	FirstARM64Release = "18.0.0"
+
+	// FirstDualStackRelease is the first GS release for AWS that supports dual-stack networking
+	FirstDualStackRelease = "17.0.0"
+
+	// FirstGPURelease is the first GS release for AWS that ships the NVIDIA device plugin for GPU instance types
+	FirstGPURelease = "12.0.0"
+
 	// FirstHARelease is the first GS release for AWS that supports HA Masters
 	FirstHARelease = "11.4.0"
 
+	// FirstIRSARelease is the first GS release for AWS that supports IAM roles for service accounts
+	FirstIRSARelease = "16.0.0"
+
+	// FirstIRSADisableRelease is the first GS release for AWS that supports disabling IAM roles for service accounts again
+	FirstIRSADisableRelease = "19.0.0"
+
+	// FirstPrivateVPCRelease is the first GS release for AWS that supports private clusters without public endpoints
+	FirstPrivateVPCRelease = "14.0.0"
+
+	// FirstTransitGatewayRelease is the first GS release for AWS that supports attaching clusters to a transit gateway
+	FirstTransitGatewayRelease = "16.1.0"
+
+	// IPFamilyDualStack is the IP family of clusters using both IPv4 and IPv6
+	IPFamilyDualStack = "dualstack"
+
+	// IPFamilyIPv4 is the IP family of clusters using only IPv4
+	IPFamilyIPv4 = "ipv4"
+
+	// VPCModePublic is the VPC mode of clusters with public load balancers and API endpoints
+	VPCModePublic = "public"
+
+	// VPCModePrivate is the VPC mode of clusters which are only reachable through their private endpoints
+	VPCModePrivate = "private"
+
+	// VPCModeTransitGateway is the VPC mode of private clusters which are routed through a transit gateway
+	VPCModeTransitGateway = "transit-gateway"
+
+	// IPv6VPCPrefixLength is the prefix length of IPv6 CIDR blocks assigned to AWS VPCs
+	IPv6VPCPrefixLength = 56
+
+	// WorkloadProfileStateless is the workload profile of node pools without persistent volumes
+	WorkloadProfileStateless = "stateless"
+	// WorkloadProfileStateful is the workload profile of node pools running workloads with EBS backed persistent volumes
+	WorkloadProfileStateful = "stateful"
+
+	// PodSecurityLevelPrivileged is the unrestricted Pod Security level
+	PodSecurityLevelPrivileged = "privileged"
+	// PodSecurityLevelBaseline is the Pod Security level preventing known privilege escalations
+	PodSecurityLevelBaseline = "baseline"
+	// PodSecurityLevelRestricted is the Pod Security level following current pod hardening best practices
+	PodSecurityLevelRestricted = "restricted"
+
+	// PodSecurityModeEnforce rejects pods violating the Pod Security level
+	PodSecurityModeEnforce = "enforce"
+	// PodSecurityModeAudit records violations of the Pod Security level in the audit log
+	PodSecurityModeAudit = "audit"
+	// PodSecurityModeWarn returns warnings to users creating pods which violate the Pod Security level
+	PodSecurityModeWarn = "warn"
+
+	// AWSTagKeyMaxLength is the maximum length of AWS tag keys
+	AWSTagKeyMaxLength = 128
+	// AWSTagValueMaxLength is the maximum length of AWS tag values
+	AWSTagValueMaxLength = 256
+
+	// CredentialSecretARNKey is the key of the IAM role ARN used by aws-operator in credential secrets
+	CredentialSecretARNKey = "aws.awsoperator.arn"
+
+	// SubResourceStatus is the name of the status subresource in admission requests
+	SubResourceStatus = "status"
+	// SubResourceScale is the name of the scale subresource in admission requests
+	SubResourceScale = "scale"
+
 	// GiantSwarmLabelPart is the part of label keys that shows that they are protected giantswarm labels
 	GiantSwarmLabelPart = "giantswarm.io"
 )
@@ -40,6 +125,85 @@ const (
 	AnnotationUpdatePauseTime    = "alpha.aws.giantswarm.io/update-pause-time"
 
 	AnnotationAlphaNodeTerminateUnhealthy = "alpha.node.giantswarm.io/terminate-unhealthy"
+
+	// AnnotationAutoPatchUpgrade opts a Cluster into using the newest patch release of the requested minor release when set to "true"
+	AnnotationAutoPatchUpgrade = "alpha.giantswarm.io/auto-patch-upgrade"
+	// AnnotationIPFamily defines the IP family of the cluster network, either "ipv4" or "dualstack"
+	AnnotationIPFamily = "alpha.aws.giantswarm.io/ip-family"
+	// AnnotationIPv6CIDRBlock defines the IPv6 CIDR block of the cluster VPC in dual-stack mode
+	AnnotationIPv6CIDRBlock = "alpha.aws.giantswarm.io/ipv6-cidr-block"
+
+	// AnnotationForceUpgrade allows to skip the upgrade safety checks of a Cluster when set to "true"
+	AnnotationForceUpgrade = "alpha.giantswarm.io/force-upgrade"
+	// AnnotationAcknowledgeBreakingChanges holds the release version whose breaking changes have been acknowledged for a Cluster upgrade
+	AnnotationAcknowledgeBreakingChanges = "alpha.giantswarm.io/acknowledge-breaking-changes"
+	// AnnotationReleaseBreakingChanges marks a Release which contains breaking changes when set to "true"
+	AnnotationReleaseBreakingChanges = "release.giantswarm.io/breaking-changes"
+	// AnnotationLastUpgradeTime is maintained by the mutator and holds the time of the last release version change
+	AnnotationLastUpgradeTime = "alpha.giantswarm.io/last-upgrade-time"
+	// AnnotationReleaseUpgradeChanges is maintained by the mutator and summarizes the component version changes
+	// of the last release version change until the operators clear it after the rollout
+	AnnotationReleaseUpgradeChanges = "alpha.giantswarm.io/release-upgrade-changes"
+	// AnnotationForceDelete allows to skip the deletion safety checks of an object when set to "true"
+	AnnotationForceDelete = "alpha.giantswarm.io/force-delete"
+	// AnnotationForceAvailabilityZoneChange allows to remove or replace master availability zones of an existing AWSControlPlane when set to "true"
+	AnnotationForceAvailabilityZoneChange = "alpha.giantswarm.io/force-availability-zone-change"
+	// AnnotationForceCredentialSecretChange allows admins to change the credential secret of an existing AWSCluster when set to "true"
+	AnnotationForceCredentialSecretChange = "alpha.giantswarm.io/force-credential-secret-change"
+	// AnnotationAllowPublicCIDR allows to use a NetworkPool CIDR block outside the RFC 1918 private address ranges when set to "true"
+	AnnotationAllowPublicCIDR = "alpha.giantswarm.io/allow-public-cidr"
+	// AnnotationAllowMissingCluster allows to create infrastructure objects before their Cluster when set to "true"
+	AnnotationAllowMissingCluster = "alpha.giantswarm.io/allow-missing-cluster"
+	// AnnotationAPIWhitelistPublic holds a comma separated list of CIDRs which are allowed to access the public Kubernetes API endpoint
+	AnnotationAPIWhitelistPublic = "alpha.aws.giantswarm.io/api-whitelist-public"
+	// AnnotationAPIWhitelistPrivate holds a comma separated list of CIDRs which are allowed to access the private Kubernetes API endpoint
+	AnnotationAPIWhitelistPrivate = "alpha.aws.giantswarm.io/api-whitelist-private"
+	// AnnotationVPCMode defines the network topology of the cluster VPC, one of "public", "private" or "transit-gateway"
+	AnnotationVPCMode = "alpha.aws.giantswarm.io/vpc-mode"
+	// AnnotationWorkloadProfile describes the workloads running on a node pool, either "stateless" or "stateful"
+	AnnotationWorkloadProfile = "alpha.aws.giantswarm.io/workload-profile"
+	// AnnotationIRSA enables IAM roles for service accounts of a cluster when set to "true"
+	AnnotationIRSA = "alpha.aws.giantswarm.io/iam-roles-for-service-accounts"
+	// AnnotationIRSAOIDCBucket defines the S3 bucket holding the OIDC discovery documents for IAM roles for service accounts
+	AnnotationIRSAOIDCBucket = "alpha.aws.giantswarm.io/irsa-oidc-bucket"
+	// AnnotationIRSAOIDCDomain defines the domain serving the OIDC discovery documents for IAM roles for service accounts
+	AnnotationIRSAOIDCDomain = "alpha.aws.giantswarm.io/irsa-oidc-domain"
+	// AnnotationLoggingVolumeSize defines the size of the logging volume of node pool workers in GB
+	AnnotationLoggingVolumeSize = "alpha.aws.giantswarm.io/logging-volume-size"
+	// AnnotationAWSSubnetSize defines the prefix length of the subnet of a node pool, which is split across its availability zones
+	AnnotationAWSSubnetSize = "alpha.aws.giantswarm.io/aws-subnet-size"
+	// AnnotationNodeLabels holds a comma separated list of key=value labels which are added to all nodes of a node pool
+	AnnotationNodeLabels = "alpha.aws.giantswarm.io/node-labels"
+	// AnnotationNodeTaints holds a comma separated list of key=value:effect taints which are added to all nodes of a node pool
+	AnnotationNodeTaints = "alpha.aws.giantswarm.io/node-taints"
+	// AnnotationSpotInterruptionDraining enables draining of node pool workers when AWS announces the interruption of their spot instance
+	AnnotationSpotInterruptionDraining = "alpha.aws.giantswarm.io/spot-interruption-draining"
+	// AnnotationHeartbeatTimeout defines the seconds the termination lifecycle hook of node pool workers waits for draining to complete
+	AnnotationHeartbeatTimeout = "alpha.aws.giantswarm.io/lifecycle-hook-heartbeat-timeout"
+	// AnnotationMaxPods defines the maximum number of pods the kubelet of node pool workers runs
+	AnnotationMaxPods = "alpha.aws.giantswarm.io/max-pods"
+	// AnnotationPrefixDelegation enables AWS CNI prefix delegation for node pool workers, which assigns /28 prefixes instead of single IPs to network interfaces
+	AnnotationPrefixDelegation = "alpha.aws.giantswarm.io/aws-cni-prefix-delegation"
+	// AnnotationAWSTags holds a JSON object of custom tags which are added to all AWS resources of a cluster
+	AnnotationAWSTags = "alpha.aws.giantswarm.io/tags"
+	// AnnotationHTTPProxy defines the URL of the proxy used for HTTP requests of the cluster nodes
+	AnnotationHTTPProxy = "alpha.aws.giantswarm.io/http-proxy"
+	// AnnotationHTTPSProxy defines the URL of the proxy used for HTTPS requests of the cluster nodes
+	AnnotationHTTPSProxy = "alpha.aws.giantswarm.io/https-proxy"
+	// AnnotationNoProxy holds a comma separated list of CIDRs, IPs and domains which are accessed without the proxy
+	AnnotationNoProxy = "alpha.aws.giantswarm.io/no-proxy"
+	// AnnotationCiliumPodCIDR defines the overlay pod CIDR used by Cilium after the migration from aws-cni
+	AnnotationCiliumPodCIDR = "alpha.aws.giantswarm.io/cilium-pod-cidr"
+	// AnnotationCalicoPolicyOnly enables Calico network policy enforcement on top of aws-cni when set to "true"
+	AnnotationCalicoPolicyOnly = "alpha.aws.giantswarm.io/calico-policy-only"
+	// AnnotationPodSecurityPrefix is the prefix of the annotations defining the default Pod Security admission levels of the workload cluster
+	AnnotationPodSecurityPrefix = "alpha.giantswarm.io/pod-security-"
+	// AnnotationPodSecurityEnforce defines the Pod Security level which is enforced by default in the workload cluster
+	AnnotationPodSecurityEnforce = AnnotationPodSecurityPrefix + PodSecurityModeEnforce
+	// AnnotationPodSecurityAudit defines the Pod Security level which is audited by default in the workload cluster
+	AnnotationPodSecurityAudit = AnnotationPodSecurityPrefix + PodSecurityModeAudit
+	// AnnotationPodSecurityWarn defines the Pod Security level which causes warnings by default in the workload cluster
+	AnnotationPodSecurityWarn = AnnotationPodSecurityPrefix + PodSecurityModeWarn
 )
 
 // DefaultCredentialSecret returns the default credentials for clusters
@@ -50,11 +214,61 @@ func DefaultCredentialSecret() types.NamespacedName {
 	}
 }
 
+// ValidIPFamilies are the allowed values of the IP family annotation
+func ValidIPFamilies() []string {
+	return []string{IPFamilyIPv4, IPFamilyDualStack}
+}
+
+// ValidVPCModes are the allowed values of the VPC mode annotation
+func ValidVPCModes() []string {
+	return []string{VPCModePublic, VPCModePrivate, VPCModeTransitGateway}
+}
+
+// VPCMode returns the VPC mode of an AWSCluster, clusters without the annotation are public
+func VPCMode(meta metav1.Object) string {
+	if mode := meta.GetAnnotations()[AnnotationVPCMode]; mode != "" {
+		return mode
+	}
+	return VPCModePublic
+}
+
+// ReservedAWSTagPrefixes are the prefixes of AWS tag keys which are used by AWS, Kubernetes and the operators
+func ReservedAWSTagPrefixes() []string {
+	return []string{"aws:", "kubernetes.io/cluster", "giantswarm.io/"}
+}
+
+// ValidPodSecurityLevels are the allowed values of the Pod Security annotations
+func ValidPodSecurityLevels() []string {
+	return []string{PodSecurityLevelPrivileged, PodSecurityLevelBaseline, PodSecurityLevelRestricted}
+}
+
+// ValidPodSecurityModes are the Pod Security admission modes which can be configured with annotations
+func ValidPodSecurityModes() []string {
+	return []string{PodSecurityModeEnforce, PodSecurityModeAudit, PodSecurityModeWarn}
+}
+
 // ValidMasterReplicas are the allowed number of master node replicas
 func ValidMasterReplicas() []int {
 	return []int{1, 3}
 }
 
+// ParseMasterAZCounts parses a comma separated list of allowed numbers of master availability zones. The
+// default counts are used when the list is empty.
+func ParseMasterAZCounts(value string) ([]int, error) {
+	if strings.TrimSpace(value) == "" {
+		return ValidMasterReplicas(), nil
+	}
+	var counts []int
+	for _, c := range strings.Split(value, ",") {
+		count, err := strconv.Atoi(strings.TrimSpace(c))
+		if err != nil || count < 1 {
+			return nil, microerror.Maskf(invalidConfigError, "master AZ count %#q is not a positive integer", c)
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
 // ValidLabelAdmins returns the list of accounts used to manipulate labels
 func ValidLabelAdmins() []string {
 	return []string{
@@ -67,17 +281,96 @@ func VersionLabels() []string {
 	return []string{label.Release, label.ClusterOperatorVersion}
 }
 
+// IsAnnotationTrue returns whether the given annotation is set to "true" on an object
+func IsAnnotationTrue(meta metav1.Object, annotation string) bool {
+	return meta.GetAnnotations()[annotation] == "true"
+}
+
+// CIDRContains returns whether the network range inner is fully contained in the network range outer
+func CIDRContains(outer, inner *net.IPNet) bool {
+	outerOnes, _ := outer.Mask.Size()
+	innerOnes, _ := inner.Mask.Size()
+	return outer.Contains(inner.IP) && outerOnes <= innerOnes
+}
+
+// IsPrivateCIDR returns whether the network range is fully contained in one of the RFC 1918 private address ranges
+func IsPrivateCIDR(n *net.IPNet) bool {
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"} {
+		_, private, _ := net.ParseCIDR(cidr)
+		if CIDRContains(private, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// CIDRsIntersect returns whether two network ranges overlap
+func CIDRsIntersect(n1, n2 *net.IPNet) bool {
+	return n2.Contains(n1.IP) || n1.Contains(n2.IP)
+}
+
 // IsGiantSwarmLabel returns whether a label is considered a giantswarm label
 func IsGiantSwarmLabel(label string) bool {
 	return strings.Contains(label, GiantSwarmLabelPart)
 }
 
+// IsCiliumVersion returns whether a given releaseVersion uses Cilium as CNI
+func IsCiliumVersion(releaseVersion *semver.Version) bool {
+	ciliumVersion, _ := semver.New(FirstCiliumRelease)
+	return releaseVersion.GE(*ciliumVersion)
+}
+
+// IsDualStackVersion returns whether a given releaseVersion supports dual-stack networking
+func IsDualStackVersion(releaseVersion *semver.Version) bool {
+	dualStackVersion, _ := semver.New(FirstDualStackRelease)
+	return releaseVersion.GE(*dualStackVersion)
+}
+
+// IsVPCModeVersion returns whether a given releaseVersion supports the given VPC mode
+func IsVPCModeVersion(releaseVersion *semver.Version, mode string) bool {
+	var firstRelease string
+	switch mode {
+	case VPCModePrivate:
+		firstRelease = FirstPrivateVPCRelease
+	case VPCModeTransitGateway:
+		firstRelease = FirstTransitGatewayRelease
+	default:
+		return true
+	}
+	vpcModeVersion, _ := semver.New(firstRelease)
+	return releaseVersion.GE(*vpcModeVersion)
+}
+
+// IsARM64Version returns whether a given releaseVersion supports arm64 nodes
+func IsARM64Version(releaseVersion *semver.Version) bool {
+	arm64Version, _ := semver.New(FirstARM64Release)
+	return releaseVersion.GE(*arm64Version)
+}
+
+// IsGPUVersion returns whether a given releaseVersion supports GPU nodes
+func IsGPUVersion(releaseVersion *semver.Version) bool {
+	gpuVersion, _ := semver.New(FirstGPURelease)
+	return releaseVersion.GE(*gpuVersion)
+}
+
 // IsHAVersion returns whether a given releaseVersion supports HA Masters
 func IsHAVersion(releaseVersion *semver.Version) bool {
 	HAVersion, _ := semver.New(FirstHARelease)
 	return releaseVersion.GE(*HAVersion)
 }
 
+// IsIRSAVersion returns whether a given releaseVersion supports IAM roles for service accounts
+func IsIRSAVersion(releaseVersion *semver.Version) bool {
+	irsaVersion, _ := semver.New(FirstIRSARelease)
+	return releaseVersion.GE(*irsaVersion)
+}
+
+// IsIRSADisableVersion returns whether a given releaseVersion supports disabling IAM roles for service accounts
+func IsIRSADisableVersion(releaseVersion *semver.Version) bool {
+	irsaDisableVersion, _ := semver.New(FirstIRSADisableRelease)
+	return releaseVersion.GE(*irsaDisableVersion)
+}
+
 // IsCAPIVersion returns whether a given releaseVersion is using CAPI controllers
 func IsCAPIVersion(releaseVersion *semver.Version) (bool, error) {
 	CAPIVersion, err := semver.New(FirstCAPIRelease)
@@ -104,8 +397,28 @@ func IsVersionProductionReady(version *semver.Version) bool {
 
 // IsValidMasterReplicas returns whether a given number is a valid number of Master node replicas
 func IsValidMasterReplicas(replicas int) bool {
-	for _, r := range ValidMasterReplicas() {
-		if r == replicas {
+	return IsValidMasterAZCount(replicas, ValidMasterReplicas())
+}
+
+// DefaultMasterAZCount returns the default number of master replicas and availability zones. It is the
+// default master replicas when allowed by the valid counts and the highest valid count otherwise.
+func DefaultMasterAZCount(validCounts []int) int {
+	if len(validCounts) == 0 || IsValidMasterAZCount(DefaultMasterReplicas, validCounts) {
+		return DefaultMasterReplicas
+	}
+	count := validCounts[0]
+	for _, c := range validCounts {
+		if c > count {
+			count = c
+		}
+	}
+	return count
+}
+
+// IsValidMasterAZCount returns whether a given number is one of the valid numbers of master availability zones
+func IsValidMasterAZCount(count int, validCounts []int) bool {
+	for _, c := range validCounts {
+		if c == count {
 			return true
 		}
 	}
@@ -1,7 +1,6 @@
 package aws
 
 import (
-	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -12,10 +11,12 @@ import (
 	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
 	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/internal/releaseversion"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 )
@@ -40,8 +41,8 @@ func FetchAWSCluster(m *Handler, meta metav1.Object) (*infrastructurev1alpha2.AW
 	{
 		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching AWSCluster %s", clusterID))
 		fetch = func() error {
-			err := m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: clusterID, Namespace: namespace}, &awsCluster)
-			if IsNotFound(err) {
+			err := m.K8sClient.CtrlClient().Get(m.Context(), client.ObjectKey{Name: clusterID, Namespace: namespace}, &awsCluster)
+			if IsNotFound(err) || apierrors.IsNotFound(err) {
 				return microerror.Maskf(notFoundError, "Looking for AWSCluster named %s but it was not found.", clusterID)
 			} else if err != nil {
 				return microerror.Mask(err)
@@ -60,6 +61,93 @@ func FetchAWSCluster(m *Handler, meta metav1.Object) (*infrastructurev1alpha2.AW
 	return &awsCluster, nil
 }
 
+func FetchAWSClusters(m *Handler) ([]infrastructurev1alpha2.AWSCluster, error) {
+	var awsClusters infrastructurev1alpha2.AWSClusterList
+	var err error
+	var fetch func() error
+
+	// Fetch all AWSCluster CRs.
+	{
+		m.Logger.Log("level", "debug", "message", "Fetching all AWSClusters")
+		fetch = func() error {
+			err = m.K8sClient.CtrlClient().List(m.Context(), &awsClusters)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "failed to fetch AWSClusters: %v", err)
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return awsClusters.Items, nil
+}
+
+func FetchAWSControlPlanes(m *Handler) ([]infrastructurev1alpha2.AWSControlPlane, error) {
+	var awsControlPlanes infrastructurev1alpha2.AWSControlPlaneList
+	var err error
+	var fetch func() error
+
+	// Fetch all AWSControlPlane CRs.
+	{
+		m.Logger.Log("level", "debug", "message", "Fetching all AWSControlPlanes")
+		fetch = func() error {
+			err = m.K8sClient.CtrlClient().List(m.Context(), &awsControlPlanes)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "failed to fetch AWSControlPlanes: %v", err)
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return awsControlPlanes.Items, nil
+}
+
+func FetchG8sControlPlanes(m *Handler) ([]infrastructurev1alpha2.G8sControlPlane, error) {
+	var g8sControlPlanes infrastructurev1alpha2.G8sControlPlaneList
+	var err error
+	var fetch func() error
+
+	// Fetch all G8sControlPlane CRs.
+	{
+		m.Logger.Log("level", "debug", "message", "Fetching all G8sControlPlanes")
+		fetch = func() error {
+			err = m.K8sClient.CtrlClient().List(m.Context(), &g8sControlPlanes)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "failed to fetch G8sControlPlanes: %v", err)
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return g8sControlPlanes.Items, nil
+}
+
 func FetchAWSControlPlane(m *Handler, meta metav1.Object) (*infrastructurev1alpha2.AWSControlPlane, error) {
 	var awsControlPlane infrastructurev1alpha2.AWSControlPlane
 	var err error
@@ -77,12 +165,14 @@ func FetchAWSControlPlane(m *Handler, meta metav1.Object) (*infrastructurev1alph
 		fetch = func() error {
 			awsControlPlanes := infrastructurev1alpha2.AWSControlPlaneList{}
 			err = m.K8sClient.CtrlClient().List(
-				context.Background(),
+				m.Context(),
 				&awsControlPlanes,
 				client.MatchingLabels{label.Cluster: clusterID},
 			)
-			if err != nil {
+			if apierrors.IsNotFound(err) {
 				return microerror.Maskf(notFoundError, "failed to fetch AWSControlplane for Cluster %s: %v", clusterID, err)
+			} else if err != nil {
+				return microerror.Mask(err)
 			}
 			if len(awsControlPlanes.Items) == 0 {
 				return microerror.Maskf(notFoundError, "Could not find AWSControlplane for Cluster %s", clusterID)
@@ -124,8 +214,8 @@ func FetchCluster(m *Handler, meta metav1.Object) (*capiv1alpha2.Cluster, error)
 	{
 		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching Cluster %s", clusterID))
 		fetch = func() error {
-			err := m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: clusterID, Namespace: namespace}, &cluster)
-			if IsNotFound(err) {
+			err := m.K8sClient.CtrlClient().Get(m.Context(), client.ObjectKey{Name: clusterID, Namespace: namespace}, &cluster)
+			if IsNotFound(err) || apierrors.IsNotFound(err) {
 				return microerror.Maskf(notFoundError, "Looking for Cluster named %s but it was not found.", clusterID)
 			} else if err != nil {
 				return microerror.Mask(err)
@@ -161,12 +251,14 @@ func FetchG8sControlPlane(m *Handler, meta metav1.Object) (*infrastructurev1alph
 		fetch = func() error {
 			awsControlPlanes := infrastructurev1alpha2.G8sControlPlaneList{}
 			err = m.K8sClient.CtrlClient().List(
-				context.Background(),
+				m.Context(),
 				&awsControlPlanes,
 				client.MatchingLabels{label.Cluster: clusterID},
 			)
-			if err != nil {
+			if apierrors.IsNotFound(err) {
 				return microerror.Maskf(notFoundError, "failed to fetch G8sControlplane for Cluster %s: %v", clusterID, err)
+			} else if err != nil {
+				return microerror.Mask(err)
 			}
 			if len(awsControlPlanes.Items) == 0 {
 				return microerror.Maskf(notFoundError, "Could not find G8sControlplane for Cluster %s", clusterID)
@@ -190,6 +282,150 @@ func FetchG8sControlPlane(m *Handler, meta metav1.Object) (*infrastructurev1alph
 	return &g8sControlPlane, nil
 }
 
+// FetchAWSMachineDeployments returns all AWSMachineDeployments of the cluster referenced by the given object.
+func FetchAWSMachineDeployments(m *Handler, meta metav1.Object) ([]infrastructurev1alpha2.AWSMachineDeployment, error) {
+	var awsMachineDeployments infrastructurev1alpha2.AWSMachineDeploymentList
+	var err error
+	var fetch func() error
+
+	// Retrieve the Cluster ID.
+	clusterID := key.Cluster(meta)
+	if clusterID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "Object has no %s label, can't fetch AWSMachineDeployments.", label.Cluster)
+	}
+
+	// Fetch the AWSMachineDeployments.
+	{
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching AWSMachineDeployments for Cluster %s", clusterID))
+		fetch = func() error {
+			err = m.K8sClient.CtrlClient().List(
+				m.Context(),
+				&awsMachineDeployments,
+				client.MatchingLabels{label.Cluster: clusterID},
+			)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "failed to fetch AWSMachineDeployments for Cluster %s: %v", clusterID, err)
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return awsMachineDeployments.Items, nil
+}
+
+// FetchAWSMachineDeployment fetches the AWSMachineDeployment of the node pool of the given object, which has the same
+// name and namespace as the MachineDeployment of the node pool.
+func FetchAWSMachineDeployment(m *Handler, meta metav1.Object) (*infrastructurev1alpha2.AWSMachineDeployment, error) {
+	var awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment
+	var err error
+	var fetch func() error
+
+	namespace := meta.GetNamespace()
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	// Fetch the AWSMachineDeployment.
+	{
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching AWSMachineDeployment %s", meta.GetName()))
+		fetch = func() error {
+			err := m.K8sClient.CtrlClient().Get(m.Context(), client.ObjectKey{Name: meta.GetName(), Namespace: namespace}, &awsMachineDeployment)
+			if IsNotFound(err) || apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "Looking for AWSMachineDeployment named %s but it was not found.", meta.GetName())
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return &awsMachineDeployment, nil
+}
+
+func FetchMachineDeployments(m *Handler, meta metav1.Object) ([]capiv1alpha2.MachineDeployment, error) {
+	var machineDeployments capiv1alpha2.MachineDeploymentList
+	var err error
+	var fetch func() error
+
+	// Retrieve the Cluster ID.
+	clusterID := key.Cluster(meta)
+	if clusterID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "Object has no %s label, can't fetch MachineDeployments.", label.Cluster)
+	}
+
+	// Fetch the MachineDeployments.
+	{
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching MachineDeployments for Cluster %s", clusterID))
+		fetch = func() error {
+			err = m.K8sClient.CtrlClient().List(
+				m.Context(),
+				&machineDeployments,
+				client.MatchingLabels{label.Cluster: clusterID},
+			)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "failed to fetch MachineDeployments for Cluster %s: %v", clusterID, err)
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return machineDeployments.Items, nil
+}
+
+func FetchNetworkPools(m *Handler) ([]infrastructurev1alpha2.NetworkPool, error) {
+	var networkPools infrastructurev1alpha2.NetworkPoolList
+	var err error
+	var fetch func() error
+
+	// Fetch all NetworkPool CRs.
+	{
+		m.Logger.Log("level", "debug", "message", "Fetching all NetworkPools")
+		fetch = func() error {
+			err = m.K8sClient.CtrlClient().List(m.Context(), &networkPools)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "failed to fetch NetworkPools: %v", err)
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+	}
+
+	{
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	return networkPools.Items, nil
+}
+
 func FetchNewestReleaseVersion(m *Handler) (*semver.Version, error) {
 	var activeReleases []semver.Version
 	var err error
@@ -199,11 +435,13 @@ func FetchNewestReleaseVersion(m *Handler) (*semver.Version, error) {
 	{
 
 		err = m.K8sClient.CtrlClient().List(
-			context.Background(),
+			m.Context(),
 			&releases,
 		)
-		if err != nil {
+		if apierrors.IsNotFound(err) {
 			return nil, microerror.Maskf(notFoundError, "failed to fetch releases: %v", err)
+		} else if err != nil {
+			return nil, microerror.Mask(err)
 		}
 		if len(releases.Items) == 0 {
 			return nil, microerror.Maskf(notFoundError, "Could not find any releases.")
@@ -217,7 +455,7 @@ func FetchNewestReleaseVersion(m *Handler) (*semver.Version, error) {
 			}
 
 			var version *semver.Version
-			version, err = semver.New(strings.TrimPrefix(r.GetName(), "v"))
+			version, err = releaseversion.Parse(r.GetName())
 			if err != nil {
 				continue
 			}
@@ -246,6 +484,91 @@ func FetchNewestReleaseVersion(m *Handler) (*semver.Version, error) {
 	return &activeReleases[0], nil
 }
 
+func FetchActiveReleaseVersions(m *Handler) ([]string, error) {
+	var activeReleases []semver.Version
+	var result []string
+	var err error
+
+	// Fetch the Release CRs
+	releases := releasev1alpha1.ReleaseList{}
+	{
+		err = m.K8sClient.CtrlClient().List(
+			m.Context(),
+			&releases,
+		)
+		if apierrors.IsNotFound(err) {
+			return nil, microerror.Maskf(notFoundError, "failed to fetch releases: %v", err)
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	// Find the active releases
+	{
+		for _, r := range releases.Items {
+			if r.Spec.State != releasev1alpha1.StateActive {
+				continue
+			}
+			version, err := releaseversion.Parse(r.GetName())
+			if err != nil {
+				continue
+			}
+			activeReleases = append(activeReleases, *version)
+		}
+	}
+	// Sort releases by version (descending).
+	sort.Slice(activeReleases, func(i, j int) bool {
+		return activeReleases[i].GT(activeReleases[j])
+	})
+	for _, r := range activeReleases {
+		result = append(result, r.String())
+	}
+
+	return result, nil
+}
+
+// FetchNewestPatchReleaseVersion returns the newest active release version with the same major and minor version as the given one.
+func FetchNewestPatchReleaseVersion(m *Handler, version *semver.Version) (*semver.Version, error) {
+	var err error
+	newest := *version
+
+	// Fetch the Release CRs
+	releases := releasev1alpha1.ReleaseList{}
+	{
+		err = m.K8sClient.CtrlClient().List(
+			m.Context(),
+			&releases,
+		)
+		if apierrors.IsNotFound(err) {
+			return nil, microerror.Maskf(notFoundError, "failed to fetch releases: %v", err)
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+	// Find the newest active, production-ready patch release
+	{
+		for _, r := range releases.Items {
+			if r.Spec.State != releasev1alpha1.StateActive {
+				continue
+			}
+			candidate, err := releaseversion.Parse(r.GetName())
+			if err != nil {
+				continue
+			}
+			if !IsVersionProductionReady(candidate) {
+				continue
+			}
+			if candidate.Major != version.Major || candidate.Minor != version.Minor {
+				continue
+			}
+			if candidate.GT(newest) {
+				newest = *candidate
+			}
+		}
+	}
+
+	return &newest, nil
+}
+
 func FetchRelease(m *Handler, version *semver.Version) (*releasev1alpha1.Release, error) {
 	var releaseName string
 	var release releasev1alpha1.Release
@@ -261,8 +584,8 @@ func FetchRelease(m *Handler, version *semver.Version) (*releasev1alpha1.Release
 	// Fetch the Release CR
 	{
 		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching Release %s", releaseName))
-		err = m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: releaseName, Namespace: metav1.NamespaceDefault}, &release)
-		if IsNotFound(err) {
+		err = m.K8sClient.CtrlClient().Get(m.Context(), client.ObjectKey{Name: releaseName, Namespace: metav1.NamespaceDefault}, &release)
+		if IsNotFound(err) || apierrors.IsNotFound(err) {
 			return nil, microerror.Maskf(notFoundError, "Looking for Release %s but it was not found.", releaseName)
 		} else if err != nil {
 			return nil, microerror.Mask(err)
@@ -6,11 +6,40 @@ import (
 	"testing"
 
 	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/micrologger/microloggertest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/handler"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
 
+// failingK8sClient returns err for all requests of its controller-runtime client.
+type failingK8sClient struct {
+	k8sclient.Interface
+	err error
+}
+
+func (f *failingK8sClient) CtrlClient() client.Client {
+	return &failingCtrlClient{Client: f.Interface.CtrlClient(), err: f.err}
+}
+
+type failingCtrlClient struct {
+	client.Client
+	err error
+}
+
+func (f *failingCtrlClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return f.err
+}
+
+func (f *failingCtrlClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return f.err
+}
+
 func TestNewestReleaseVersion(t *testing.T) {
 	testCases := []struct {
 		ctx  context.Context
@@ -127,3 +156,67 @@ func TestNewestReleaseVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchFailures(t *testing.T) {
+	clusters := schema.GroupResource{Group: "cluster.x-k8s.io", Resource: "clusters"}
+
+	testCases := []struct {
+		name string
+
+		err               error
+		notFound          bool
+		dependencyFailure bool
+	}{
+		{
+			// object does not exist
+			name: "case 0",
+
+			err:               apierrors.NewNotFound(clusters, unittest.DefaultClusterID),
+			notFound:          true,
+			dependencyFailure: false,
+		},
+		{
+			// API server unavailable
+			name: "case 1",
+
+			err:               apierrors.NewServiceUnavailable("etcd leader changed"),
+			notFound:          false,
+			dependencyFailure: true,
+		},
+		{
+			// API request timed out
+			name: "case 2",
+
+			err:               apierrors.NewTimeoutError("request timed out", 1),
+			notFound:          false,
+			dependencyFailure: true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			h := &Handler{
+				K8sClient: &failingK8sClient{Interface: unittest.FakeK8sClient(), err: tc.err},
+				Logger:    microloggertest.New(),
+			}
+			cluster := unittest.DefaultCluster()
+
+			// Get requests
+			_, err := FetchCluster(h, cluster)
+			if IsNotFound(err) != tc.notFound {
+				t.Fatalf("expected not found to be %t for %v", tc.notFound, err)
+			}
+			if handler.IsDependencyFailure(err) != tc.dependencyFailure {
+				t.Fatalf("expected dependency failure to be %t for %v", tc.dependencyFailure, err)
+			}
+
+			// List requests
+			_, err = FetchMachineDeployments(h, cluster)
+			if IsNotFound(err) != tc.notFound {
+				t.Fatalf("expected not found to be %t for %v", tc.notFound, err)
+			}
+			if handler.IsDependencyFailure(err) != tc.dependencyFailure {
+				t.Fatalf("expected dependency failure to be %t for %v", tc.dependencyFailure, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"sort"
@@ -8,21 +9,34 @@ import (
 	"time"
 
 	"github.com/blang/semver"
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/internal/releaseversion"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
 )
 
 type Handler struct {
+	// Ctx is the context of the admission request the handler works for. API calls are part of its trace and are
+	// cancelled with it.
+	Ctx       context.Context
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
 }
 
+// Context returns the context of the admission request, or the background context outside of admission requests.
+func (m *Handler) Context() context.Context {
+	if m.Ctx == nil {
+		return context.Background()
+	}
+	return m.Ctx
+}
+
 func GetReleaseComponentLabels(release releasev1alpha1.Release) map[string]string {
 	components := map[string]string{}
 	for _, component := range release.Spec.Components {
@@ -31,6 +45,41 @@ func GetReleaseComponentLabels(release releasev1alpha1.Release) map[string]strin
 	return components
 }
 
+// GetReleaseComponentChanges summarizes the component version changes between two releases
+// in a form like "aws-operator 9.0.0 -> 9.1.0, kubernetes 1.18.0 -> 1.19.0". Components which
+// only exist in one of the releases are shown with "none" as version.
+func GetReleaseComponentChanges(oldRelease releasev1alpha1.Release, release releasev1alpha1.Release) string {
+	oldComponents := GetReleaseComponentLabels(oldRelease)
+	components := GetReleaseComponentLabels(release)
+
+	var names []string
+	for name := range oldComponents {
+		names = append(names, name)
+	}
+	for name := range components {
+		if _, ok := oldComponents[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		oldVersion, newVersion := oldComponents[name], components[name]
+		if oldVersion == newVersion {
+			continue
+		}
+		if oldVersion == "" {
+			oldVersion = "none"
+		}
+		if newVersion == "" {
+			newVersion = "none"
+		}
+		changes = append(changes, fmt.Sprintf("%s %s -> %s", name, oldVersion, newVersion))
+	}
+	return strings.Join(changes, ", ")
+}
+
 func GetNavailabilityZones(m *Handler, n int, azs []string) []string {
 	randomAZs := azs
 	// In case there are not enough distinct AZs, we repeat them
@@ -47,13 +96,74 @@ func GetNavailabilityZones(m *Handler, n int, azs []string) []string {
 	return randomAZs
 }
 
+// GetLeastUsedAvailabilityZones extends the selected AZs to n AZs. Every added AZ is the one used least by the
+// selected AZs and, among those, by the given usage of the installation, so that masters are spread evenly
+// across all AZs. Ties are broken randomly and the added AZs are sorted alphabetically.
+func GetLeastUsedAvailabilityZones(m *Handler, n int, azs []string, usage map[string]int, selected []string) []string {
+	result := append([]string{}, selected...)
+	if len(azs) == 0 {
+		return result
+	}
+
+	candidates := append([]string{}, azs...)
+	rand.Seed(time.Now().UnixNano())
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+	own := map[string]int{}
+	for _, az := range selected {
+		own[az]++
+	}
+	for len(result) < n {
+		best := candidates[0]
+		for _, az := range candidates[1:] {
+			if own[az] < own[best] || (own[az] == own[best] && usage[az] < usage[best]) {
+				best = az
+			}
+		}
+		result = append(result, best)
+		own[best]++
+	}
+	sort.Strings(result[len(selected):])
+	m.Logger.Log("level", "debug", "message", fmt.Sprintf("available AZ's: %v, AZ usage: %v, selected AZ's: %v", azs, usage, result))
+
+	return result
+}
+
+// GetAvailabilityZoneUsage counts the masters of the given control planes per AZ.
+func GetAvailabilityZoneUsage(awsControlPlanes []infrastructurev1alpha2.AWSControlPlane) map[string]int {
+	usage := map[string]int{}
+	for _, awsControlPlane := range awsControlPlanes {
+		for _, az := range awsControlPlane.Spec.AvailabilityZones {
+			usage[az]++
+		}
+	}
+	return usage
+}
+
+// InstanceTypeFamily returns the family of an EC2 instance type, e.g. m5 for m5.xlarge.
+func InstanceTypeFamily(instanceType string) string {
+	return strings.SplitN(instanceType, ".", 2)[0]
+}
+
+// ParseInstanceFamilies parses a comma separated list of EC2 instance families.
+func ParseInstanceFamilies(value string) []string {
+	var families []string
+	for _, f := range strings.Split(value, ",") {
+		f = strings.TrimSpace(f)
+		if f != "" {
+			families = append(families, f)
+		}
+	}
+	return families
+}
+
 func IsCAPIRelease(meta metav1.Object) (bool, error) {
 	if meta.GetLabels()[label.Release] == "" {
 		return false, nil
 	}
 	releaseVersion, err := ReleaseVersion(meta, []mutator.PatchOperation{})
 	if err != nil {
-		return false, microerror.Maskf(parsingFailedError, "unable to parse release version from object")
+		return false, microerror.Maskf(parsingFailedError, "unable to parse release version from object: %v", err)
 	}
 	return IsCAPIVersion(releaseVersion)
 }
@@ -65,7 +175,7 @@ func ReleaseVersion(meta metav1.Object, patch []mutator.PatchOperation) (*semver
 	for _, p := range patch {
 		if p.Path == fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Release)) {
 			version = p.Value.(string)
-			return semver.New(version)
+			return releaseversion.ParseLabel(label.Release, version)
 		}
 	}
 	// otherwise check the labels
@@ -73,7 +183,7 @@ func ReleaseVersion(meta metav1.Object, patch []mutator.PatchOperation) (*semver
 	if !ok {
 		return nil, microerror.Maskf(parsingFailedError, "unable to get release version from Object %s", meta.GetName())
 	}
-	return semver.New(version)
+	return releaseversion.ParseLabel(label.Release, version)
 }
 
 // Ensure the needed escapes are in place. See https://tools.ietf.org/html/rfc6901#section-3 .
@@ -2,9 +2,13 @@ package aws
 
 import (
 	"context"
+	"reflect"
 	"strconv"
 	"testing"
 
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
@@ -65,3 +69,111 @@ func TestCAPIReleaseLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestReleaseComponentChanges(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		oldComponents   []releasev1alpha1.ReleaseSpecComponent
+		components      []releasev1alpha1.ReleaseSpecComponent
+		expectedChanges string
+	}{
+		{
+			// No component changes
+			name: "case 0",
+
+			oldComponents:   []releasev1alpha1.ReleaseSpecComponent{{Name: "aws-operator", Version: "9.0.0"}},
+			components:      []releasev1alpha1.ReleaseSpecComponent{{Name: "aws-operator", Version: "9.0.0"}},
+			expectedChanges: "",
+		},
+		{
+			// Changed components are sorted by name
+			name: "case 1",
+
+			oldComponents:   []releasev1alpha1.ReleaseSpecComponent{{Name: "kubernetes", Version: "1.18.0"}, {Name: "aws-operator", Version: "9.0.0"}, {Name: "etcd", Version: "3.4.9"}},
+			components:      []releasev1alpha1.ReleaseSpecComponent{{Name: "kubernetes", Version: "1.19.0"}, {Name: "aws-operator", Version: "9.1.0"}, {Name: "etcd", Version: "3.4.9"}},
+			expectedChanges: "aws-operator 9.0.0 -> 9.1.0, kubernetes 1.18.0 -> 1.19.0",
+		},
+		{
+			// Added and removed components
+			name: "case 2",
+
+			oldComponents:   []releasev1alpha1.ReleaseSpecComponent{{Name: "calico", Version: "3.15.0"}},
+			components:      []releasev1alpha1.ReleaseSpecComponent{{Name: "cilium", Version: "1.9.0"}},
+			expectedChanges: "calico 3.15.0 -> none, cilium none -> 1.9.0",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			oldRelease := unittest.DefaultRelease()
+			oldRelease.Spec.Components = tc.oldComponents
+			release := unittest.DefaultRelease()
+			release.Spec.Components = tc.components
+
+			changes := GetReleaseComponentChanges(oldRelease, release)
+			if changes != tc.expectedChanges {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedChanges, changes)
+			}
+		})
+	}
+}
+
+func TestLeastUsedAvailabilityZones(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		n        int
+		azs      []string
+		usage    map[string]int
+		selected []string
+		// expectedAZs is nil when the result is random
+		expectedAZs []string
+	}{
+		{
+			// Least used AZ is picked for a single master
+			name: "case 0",
+
+			n:           1,
+			azs:         []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			usage:       map[string]int{"eu-central-1a": 3, "eu-central-1b": 1, "eu-central-1c": 2},
+			expectedAZs: []string{"eu-central-1b"},
+		},
+		{
+			// Distinct AZs are preferred over unused AZs
+			name: "case 1",
+
+			n:           3,
+			azs:         []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			usage:       map[string]int{"eu-central-1a": 5},
+			expectedAZs: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+		},
+		{
+			// Selected AZs are kept and extended with the least used AZs
+			name: "case 2",
+
+			n:           3,
+			azs:         []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			usage:       map[string]int{"eu-central-1a": 1, "eu-central-1b": 1},
+			selected:    []string{"eu-central-1b"},
+			expectedAZs: []string{"eu-central-1b", "eu-central-1a", "eu-central-1c"},
+		},
+		{
+			// AZs are repeated when there are fewer AZs than masters
+			name: "case 3",
+
+			n:           3,
+			azs:         []string{"cn-north-1a", "cn-north-1b"},
+			usage:       map[string]int{"cn-north-1a": 2},
+			expectedAZs: []string{"cn-north-1a", "cn-north-1b", "cn-north-1b"},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handler := &Handler{K8sClient: unittest.FakeK8sClient(), Logger: microloggertest.New()}
+			azs := GetLeastUsedAvailabilityZones(handler, tc.n, tc.azs, tc.usage, tc.selected)
+			if !reflect.DeepEqual(azs, tc.expectedAZs) {
+				t.Fatalf("expected %v to be equal to %v", tc.expectedAZs, azs)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package aws
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
@@ -9,9 +12,33 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
 )
 
+func MutateAnnotation(m *Handler, meta metav1.Object, annotation string, value string) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if meta.GetAnnotations()[annotation] == value {
+		return result, nil
+	}
+
+	m.Logger.Log("level", "debug", "message", fmt.Sprintf("Annotation %s will be set to %s.",
+		annotation,
+		value))
+	// The annotations map has to exist before a single annotation can be added
+	if meta.GetAnnotations() == nil {
+		patch := mutator.PatchAdd("/metadata/annotations", map[string]string{annotation: value})
+		result = append(result, patch)
+		return result, nil
+	}
+	patch := mutator.PatchAdd(fmt.Sprintf("/metadata/annotations/%s", EscapeJSONPatchString(annotation)), value)
+	result = append(result, patch)
+
+	return result, nil
+}
+
 func MutateLabel(m *Handler, meta metav1.Object, label string, defaultValue string) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 
@@ -92,3 +119,193 @@ func MutateLabelFromRelease(m *Handler, meta metav1.Object, release releasev1alp
 
 	return result, nil
 }
+
+// MutateClusterLabels defaults the cluster ID and organization labels of an infrastructure object
+// from its owning Cluster, so that manifests don't have to repeat them. The labels of the given
+// object are updated to reflect the patch, so that subsequent mutations can fetch related objects.
+func MutateClusterLabels(m *Handler, meta metav1.Object, clusterID string) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if key.Cluster(meta) == "" && clusterID == "" {
+		return result, nil
+	}
+	patch, err := MutateDerivedLabel(m, meta, label.Cluster, clusterID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+	if key.Organization(meta) != "" {
+		return result, nil
+	}
+
+	cluster, err := FetchCluster(m, meta)
+	if IsNotFound(err) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Cluster %s was not found, labels can not be propagated.", key.Cluster(meta)))
+		return result, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if key.Organization(cluster) == "" {
+		return result, nil
+	}
+	patch, err = MutateLabelFromCluster(m, meta, *cluster, label.Organization)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+	labels := meta.GetLabels()
+	labels[label.Organization] = key.Organization(cluster)
+	meta.SetLabels(labels)
+
+	return result, nil
+}
+
+// MutateDerivedLabel defaults a label which can be derived from other parts of the object, like its name,
+// if it is not set. Unlike MutateLabel it creates the labels map if needed, and the labels of the given
+// object are updated to reflect the patch, so that subsequent mutations can rely on the label.
+func MutateDerivedLabel(m *Handler, meta metav1.Object, label string, value string) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if meta.GetLabels()[label] != "" || value == "" {
+		return result, nil
+	}
+	// The labels map has to exist before a single label can be added
+	if meta.GetLabels() == nil {
+		result = append(result, mutator.PatchAdd("/metadata/labels", map[string]string{}))
+		meta.SetLabels(map[string]string{})
+	}
+	patch, err := MutateLabel(m, meta, label, value)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+	labels := meta.GetLabels()
+	labels[label] = value
+	meta.SetLabels(labels)
+
+	return result, nil
+}
+
+// NodePoolIDFromName returns the node pool ID encoded in the name of a node pool object. Names are
+// either the node pool ID itself or the node pool ID prefixed with the cluster ID.
+func NodePoolIDFromName(meta metav1.Object) string {
+	if key.Cluster(meta) == "" {
+		return meta.GetName()
+	}
+	return strings.TrimPrefix(meta.GetName(), key.Cluster(meta)+"-")
+}
+
+// OwnerClusterID returns the name of the Cluster referenced in the owner references of the given
+// object or an empty string if there is none.
+func OwnerClusterID(meta metav1.Object) string {
+	for _, ref := range meta.GetOwnerReferences() {
+		if ref.Kind == "Cluster" && strings.HasPrefix(ref.APIVersion, capiv1alpha2.GroupVersion.Group+"/") {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// MutateOwnerReference sets the owning Cluster as owner of the given object if it does not have one yet,
+// so that the object is garbage collected together with its Cluster. The owner reference is skipped while
+// the Cluster does not exist, as the order of CR creation can vary.
+func MutateOwnerReference(m *Handler, meta metav1.Object) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if OwnerClusterID(meta) != "" || key.Cluster(meta) == "" {
+		return result, nil
+	}
+	cluster, err := FetchCluster(m, meta)
+	if IsNotFound(err) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Cluster %s was not found, owner reference can not be set.", key.Cluster(meta)))
+		return result, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if cluster.GetUID() == "" {
+		return result, nil
+	}
+
+	ownerReference := metav1.OwnerReference{
+		APIVersion: capiv1alpha2.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.GetName(),
+		UID:        cluster.GetUID(),
+	}
+	m.Logger.Log("level", "debug", "message", fmt.Sprintf("Owner reference will be set to Cluster %s.", cluster.GetName()))
+	// The owner references list has to exist before a single reference can be appended
+	if meta.GetOwnerReferences() == nil {
+		result = append(result, mutator.PatchAdd("/metadata/ownerReferences", []metav1.OwnerReference{ownerReference}))
+	} else {
+		result = append(result, mutator.PatchAdd("/metadata/ownerReferences/-", ownerReference))
+	}
+
+	return result, nil
+}
+
+// MutateUpdateAnnotations normalizes the update max batch size and pause time annotations to the formats aws-operator
+// understands, e.g. 50% to 0.5 and 10m to PT10M. Values which can not be normalized are left to the validators.
+func MutateUpdateAnnotations(m *Handler, meta metav1.Object) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	normalizers := []struct {
+		annotation string
+		normalize  func(string) string
+	}{
+		{annotation: AnnotationUpdateMaxBatchSize, normalize: NormalizeMaxBatchSize},
+		{annotation: AnnotationUpdatePauseTime, normalize: NormalizePauseTime},
+	}
+	for _, n := range normalizers {
+		value, ok := meta.GetAnnotations()[n.annotation]
+		if !ok {
+			continue
+		}
+		patch, err := MutateAnnotation(m, meta, n.annotation, n.normalize(value))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
+	}
+
+	return result, nil
+}
+
+// NormalizeMaxBatchSize converts a percentage like 50% into the ratio 0.5. Ratios always have a decimal point,
+// so that 100% is not mistaken for a batch size of one node.
+func NormalizeMaxBatchSize(value string) string {
+	if !strings.HasSuffix(value, "%") {
+		return value
+	}
+	percentage, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+	if err != nil {
+		return value
+	}
+	ratio := strconv.FormatFloat(percentage/100, 'f', -1, 64)
+	if !strings.Contains(ratio, ".") {
+		ratio += ".0"
+	}
+	return ratio
+}
+
+// NormalizePauseTime converts a Go duration like 10m or 1m30s into an ISO 8601 duration like PT10M or PT1M30S.
+// Durations with fractions of seconds are left as they are.
+func NormalizePauseTime(value string) string {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 || d%time.Second != 0 {
+		return value
+	}
+	if d == 0 {
+		return "PT0S"
+	}
+	normalized := "PT"
+	if h := d / time.Hour; h > 0 {
+		normalized += fmt.Sprintf("%dH", h)
+	}
+	if min := (d % time.Hour) / time.Minute; min > 0 {
+		normalized += fmt.Sprintf("%dM", min)
+	}
+	if s := (d % time.Minute) / time.Second; s > 0 {
+		normalized += fmt.Sprintf("%dS", s)
+	}
+	return normalized
+}
@@ -3,10 +3,12 @@ package aws
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
 	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
@@ -247,3 +249,375 @@ func TestLabelFromRelease(t *testing.T) {
 		})
 	}
 }
+
+func TestAnnotation(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations   map[string]string
+		value         string
+		expectedPath  string
+		expectedPatch interface{}
+	}{
+		{
+			// Don't patch the annotation if it is already set to the value
+			name: "case 0",
+
+			annotations:   map[string]string{AnnotationLastUpgradeTime: "abcd"},
+			value:         "abcd",
+			expectedPath:  "",
+			expectedPatch: nil,
+		},
+		{
+			// Patch the annotation if it has a different value
+			name: "case 1",
+
+			annotations:   map[string]string{AnnotationLastUpgradeTime: "abcd"},
+			value:         "efgh",
+			expectedPath:  fmt.Sprintf("/metadata/annotations/%s", EscapeJSONPatchString(AnnotationLastUpgradeTime)),
+			expectedPatch: "efgh",
+		},
+		{
+			// Patch the whole annotation map if there are no annotations
+			name: "case 2",
+
+			annotations:   nil,
+			value:         "efgh",
+			expectedPath:  "/metadata/annotations",
+			expectedPatch: map[string]string{AnnotationLastUpgradeTime: "efgh"},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Handler{
+				K8sClient: unittest.FakeK8sClient(),
+				Logger:    microloggertest.New(),
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(tc.annotations)
+			patch, err := MutateAnnotation(mutate, cluster, AnnotationLastUpgradeTime, tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedPath == "" {
+				if len(patch) != 0 {
+					t.Fatalf("expected no patch but got %v", patch)
+				}
+				return
+			}
+			if len(patch) != 1 {
+				t.Fatalf("expected one patch but got %v", patch)
+			}
+			if patch[0].Path != tc.expectedPath {
+				t.Fatalf("expected path %#q to be equal to %#q", patch[0].Path, tc.expectedPath)
+			}
+			if !reflect.DeepEqual(patch[0].Value, tc.expectedPatch) {
+				t.Fatalf("expected %v to be equal to %v", patch[0].Value, tc.expectedPatch)
+			}
+		})
+	}
+}
+
+func TestClusterLabels(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterID            string
+		currentLabels        map[string]string
+		clusterExists        bool
+		expectedCluster      string
+		expectedOrganization string
+	}{
+		{
+			// Don't default the labels if they are set
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterID:            unittest.DefaultClusterID,
+			currentLabels:        map[string]string{label.Cluster: unittest.DefaultClusterID, label.Organization: "other-organization"},
+			clusterExists:        true,
+			expectedCluster:      "",
+			expectedOrganization: "",
+		},
+		{
+			// Default the cluster and organization labels if the labels map is missing
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterID:            unittest.DefaultClusterID,
+			currentLabels:        nil,
+			clusterExists:        true,
+			expectedCluster:      unittest.DefaultClusterID,
+			expectedOrganization: "example-organization",
+		},
+		{
+			// Default only the organization label if the cluster label is set
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterID:            "",
+			currentLabels:        map[string]string{label.Cluster: unittest.DefaultClusterID},
+			clusterExists:        true,
+			expectedCluster:      "",
+			expectedOrganization: "example-organization",
+		},
+		{
+			// Default only the cluster label if the Cluster does not exist
+			name: "case 3",
+			ctx:  context.Background(),
+
+			clusterID:            unittest.DefaultClusterID,
+			currentLabels:        map[string]string{},
+			clusterExists:        false,
+			expectedCluster:      unittest.DefaultClusterID,
+			expectedOrganization: "",
+		},
+		{
+			// Don't default anything if the cluster ID is unknown
+			name: "case 4",
+			ctx:  context.Background(),
+
+			clusterID:            "",
+			currentLabels:        map[string]string{},
+			clusterExists:        true,
+			expectedCluster:      "",
+			expectedOrganization: "",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var updatedCluster string
+			var updatedOrganization string
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			if tc.clusterExists {
+				err := fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultCluster())
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			// run mutate function to default the AWSControlPlane labels
+			awscontrolplane := unittest.DefaultAWSControlPlane()
+			awscontrolplane.SetLabels(tc.currentLabels)
+			patch, err := MutateClusterLabels(mutate, &awscontrolplane, tc.clusterID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// parse patches
+			for _, p := range patch {
+				switch p.Path {
+				case fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Cluster)):
+					updatedCluster = p.Value.(string)
+				case fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Organization)):
+					updatedOrganization = p.Value.(string)
+				}
+			}
+			// check if the labels are as expected
+			if tc.expectedCluster != updatedCluster {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedCluster, updatedCluster)
+			}
+			if tc.expectedOrganization != updatedOrganization {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedOrganization, updatedOrganization)
+			}
+		})
+	}
+}
+
+func TestOwnerClusterID(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		ownerReferences []metav1.OwnerReference
+		expectedID      string
+	}{
+		{
+			// Return the name of the owning Cluster
+			name: "case 0",
+
+			ownerReferences: []metav1.OwnerReference{
+				{APIVersion: "cluster.x-k8s.io/v1alpha2", Kind: "Cluster", Name: unittest.DefaultClusterID},
+			},
+			expectedID: unittest.DefaultClusterID,
+		},
+		{
+			// Ignore owners of other groups
+			name: "case 1",
+
+			ownerReferences: []metav1.OwnerReference{
+				{APIVersion: "infrastructure.giantswarm.io/v1alpha2", Kind: "Cluster", Name: "abcd"},
+			},
+			expectedID: "",
+		},
+		{
+			// Return an empty ID without owner references
+			name: "case 2",
+
+			ownerReferences: nil,
+			expectedID:      "",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			awscontrolplane := unittest.DefaultAWSControlPlane()
+			awscontrolplane.SetOwnerReferences(tc.ownerReferences)
+			id := OwnerClusterID(&awscontrolplane)
+			if tc.expectedID != id {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedID, id)
+			}
+		})
+	}
+}
+
+func TestOwnerReference(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		ownerReferences []metav1.OwnerReference
+		clusterExists   bool
+		expectedPath    string
+	}{
+		{
+			// Set the owner reference if there is none
+			name: "case 0",
+			ctx:  context.Background(),
+
+			ownerReferences: nil,
+			clusterExists:   true,
+			expectedPath:    "/metadata/ownerReferences",
+		},
+		{
+			// Append the owner reference to other owners
+			name: "case 1",
+			ctx:  context.Background(),
+
+			ownerReferences: []metav1.OwnerReference{
+				{APIVersion: "infrastructure.giantswarm.io/v1alpha2", Kind: "G8sControlPlane", Name: "abcd", UID: "1234"},
+			},
+			clusterExists: true,
+			expectedPath:  "/metadata/ownerReferences/-",
+		},
+		{
+			// Don't set the owner reference if the Cluster already owns the object
+			name: "case 2",
+			ctx:  context.Background(),
+
+			ownerReferences: []metav1.OwnerReference{
+				{APIVersion: "cluster.x-k8s.io/v1alpha2", Kind: "Cluster", Name: unittest.DefaultClusterID, UID: "1234"},
+			},
+			clusterExists: true,
+			expectedPath:  "",
+		},
+		{
+			// Don't set the owner reference if the Cluster does not exist
+			name: "case 3",
+			ctx:  context.Background(),
+
+			ownerReferences: nil,
+			clusterExists:   false,
+			expectedPath:    "",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			if tc.clusterExists {
+				cluster := unittest.DefaultCluster()
+				cluster.SetUID("5678")
+				err := fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			// run mutate function to default the AWSControlPlane owner reference
+			awscontrolplane := unittest.DefaultAWSControlPlane()
+			awscontrolplane.SetOwnerReferences(tc.ownerReferences)
+			patch, err := MutateOwnerReference(mutate, &awscontrolplane)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedPath == "" {
+				if len(patch) != 0 {
+					t.Fatalf("expected no patch but got %v", patch)
+				}
+				return
+			}
+			if len(patch) != 1 {
+				t.Fatalf("expected one patch but got %v", patch)
+			}
+			if patch[0].Path != tc.expectedPath {
+				t.Fatalf("expected path %#q to be equal to %#q", patch[0].Path, tc.expectedPath)
+			}
+		})
+	}
+}
+
+func TestNormalizeUpdateAnnotations(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		maxBatchSize         string
+		pauseTime            string
+		expectedMaxBatchSize string
+		expectedPauseTime    string
+	}{
+		{
+			// canonical values are kept
+			name: "case 0",
+
+			maxBatchSize:         "0.5",
+			pauseTime:            "PT10M",
+			expectedMaxBatchSize: "0.5",
+			expectedPauseTime:    "PT10M",
+		},
+		{
+			// percentage and go duration are converted
+			name: "case 1",
+
+			maxBatchSize:         "30%",
+			pauseTime:            "1m30s",
+			expectedMaxBatchSize: "0.3",
+			expectedPauseTime:    "PT1M30S",
+		},
+		{
+			// all nodes are not mistaken for one node
+			name: "case 2",
+
+			maxBatchSize:         "100%",
+			pauseTime:            "1h",
+			expectedMaxBatchSize: "1.0",
+			expectedPauseTime:    "PT1H",
+		},
+		{
+			// invalid values are left to the validators
+			name: "case 3",
+
+			maxBatchSize:         "many%",
+			pauseTime:            "10 minutes",
+			expectedMaxBatchSize: "many%",
+			expectedPauseTime:    "10 minutes",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			maxBatchSize := NormalizeMaxBatchSize(tc.maxBatchSize)
+			if maxBatchSize != tc.expectedMaxBatchSize {
+				t.Fatalf("expected max batch size %#q to be equal to %#q", maxBatchSize, tc.expectedMaxBatchSize)
+			}
+			pauseTime := NormalizePauseTime(tc.pauseTime)
+			if pauseTime != tc.expectedPauseTime {
+				t.Fatalf("expected pause time %#q to be equal to %#q", pauseTime, tc.expectedPauseTime)
+			}
+		})
+	}
+}
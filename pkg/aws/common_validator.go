@@ -3,19 +3,28 @@ package aws
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/dylanmei/iso8601"
 	securityv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/security/v1alpha1"
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
 	"github.com/giantswarm/microerror"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/internal/normalize"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 )
 
+// statusWriterVersionPattern matches the version suffix of versioned operator service accounts like
+// aws-operator-9-3-0.
+var statusWriterVersionPattern = regexp.MustCompile("^-[0-9]+(-[0-9]+)*$")
+
 func ValidateLabelKeys(m *Handler, old metav1.Object, new metav1.Object) error {
 	// validate for each giantswarm.io label that its value has not been modified
 	oldLabels := old.GetLabels()
@@ -61,12 +70,203 @@ func ValidateLabelValues(m *Handler, old metav1.Object, new metav1.Object) error
 	return nil
 }
 
+// ParseStatusWriters returns the usernames of the given service accounts like giantswarm/aws-operator, which
+// reconcile the status of infrastructure objects.
+func ParseStatusWriters(serviceAccounts []string) ([]string, error) {
+	var writers []string
+	for _, s := range serviceAccounts {
+		parts := strings.Split(s, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "status writer %#q has to be a service account like giantswarm/aws-operator", s)
+		}
+		writers = append(writers, fmt.Sprintf("system:serviceaccount:%s:%s", parts[0], parts[1]))
+	}
+	return writers, nil
+}
+
+// ValidateStatusWriter validates that a write to the status subresource comes from one of the operators,
+// so that users can not fake conditions which other checks, like upgrade checks, rely on. The operator
+// service accounts match with or without a version suffix.
+func ValidateStatusWriter(userInfo authenticationv1.UserInfo, writers []string) error {
+	for _, writer := range writers {
+		if userInfo.Username == writer {
+			return nil
+		}
+		if strings.HasPrefix(userInfo.Username, writer) && statusWriterVersionPattern.MatchString(strings.TrimPrefix(userInfo.Username, writer)) {
+			return nil
+		}
+	}
+	return microerror.Maskf(notAllowedError, "User %#q is not allowed to update the status, which is reserved to the operators.", userInfo.Username)
+}
+
+// ValidateClusterExists validates that the Cluster referenced by the cluster label of an infrastructure object
+// exists, so that no orphan objects are created which the operators reconcile into nothing. Bootstrap flows
+// which create the Cluster last can skip the check with the AnnotationAllowMissingCluster annotation.
+func ValidateClusterExists(m *Handler, meta metav1.Object) error {
+	if meta.GetAnnotations()[AnnotationAllowMissingCluster] == "true" {
+		return nil
+	}
+	if meta.GetLabels()[label.Cluster] == "" {
+		return microerror.Maskf(notAllowedError, "Label %#q must reference the Cluster of %s.", label.Cluster, meta.GetName())
+	}
+	_, err := FetchCluster(m, meta)
+	if IsNotFound(err) {
+		return microerror.Maskf(notAllowedError, "Cluster %s referenced by label %#q of %s does not exist. Set annotation %#q to %#q to create the object before its Cluster.",
+			meta.GetLabels()[label.Cluster],
+			label.Cluster,
+			meta.GetName(),
+			AnnotationAllowMissingCluster,
+			"true")
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+// ValidateClusterTransitioned validates that the AWSCluster of the given object is in a stable state, i.e. its
+// latest condition is Created or Updated, before a change which rolls the masters. The change is described like
+// "be migrated to HA masters". The check can be skipped with the AnnotationForceUpgrade annotation on the object.
+func ValidateClusterTransitioned(m *Handler, meta metav1.Object, kind string, change string) error {
+	if IsAnnotationTrue(meta, AnnotationForceUpgrade) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Status check of the Cluster of %s %s is skipped due to annotation %s", kind, meta.GetName(), AnnotationForceUpgrade))
+		return nil
+	}
+	awsCluster, err := FetchAWSCluster(m, meta)
+	if IsNotFound(err) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster of %s %s could not be found: %v", kind, meta.GetName(), err))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	condition := awsCluster.GetCommonClusterStatus().LatestCondition()
+	if condition != infrastructurev1alpha2.ClusterStatusConditionCreated && condition != infrastructurev1alpha2.ClusterStatusConditionUpdated {
+		return microerror.Maskf(notAllowedError, "%s %s can not %s at the present moment because Cluster %s is in state %#q. Wait until it is %#q or %#q, or set annotation %s to \"true\" to proceed anyway.",
+			kind,
+			meta.GetName(),
+			change,
+			awsCluster.GetName(),
+			condition,
+			infrastructurev1alpha2.ClusterStatusConditionCreated,
+			infrastructurev1alpha2.ClusterStatusConditionUpdated,
+			AnnotationForceUpgrade,
+		)
+	}
+	return nil
+}
+
+// ValidateLabelsMatch validates that the given labels of an object have the values of the object it belongs to, so
+// that e.g. node pools are not created with another release or operator version than their cluster. Labels which
+// are not set are skipped, since they are defaulted by the mutators.
+func ValidateLabelsMatch(meta metav1.Object, kind string, owner metav1.Object, ownerKind string, labels ...string) error {
+	for _, l := range labels {
+		value := meta.GetLabels()[l]
+		if value == "" {
+			continue
+		}
+		if value != owner.GetLabels()[l] {
+			return microerror.Maskf(notAllowedError, "%s %s label %s value %#q does not match value %#q of %s %s.",
+				kind,
+				meta.GetName(),
+				l,
+				value,
+				owner.GetLabels()[l],
+				ownerKind,
+				owner.GetName(),
+			)
+		}
+	}
+	return nil
+}
+
+// ValidateNoPendingUpgrade validates that the release version of the given object matches the one of its Cluster.
+// A mismatch means that a release upgrade of the cluster is pending or ongoing, and aws-operator does not support
+// another rolling change of the same nodes at the same time.
+func ValidateNoPendingUpgrade(m *Handler, meta metav1.Object, kind string, change string) error {
+	if IsAnnotationTrue(meta, AnnotationForceUpgrade) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Release upgrade check of the Cluster of %s %s is skipped due to annotation %s", kind, meta.GetName(), AnnotationForceUpgrade))
+		return nil
+	}
+	if key.Release(meta) == "" {
+		return nil
+	}
+	cluster, err := FetchCluster(m, meta)
+	if IsNotFound(err) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Cluster of %s %s could not be found: %v", kind, meta.GetName(), err))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	if key.Release(cluster) != key.Release(meta) {
+		return microerror.Maskf(notAllowedError, "%s %s can not %s at the present moment because Cluster %s is being upgraded from release %s to %s. Wait until the upgrade is finished, or set annotation %s to \"true\" to proceed anyway.",
+			kind,
+			meta.GetName(),
+			change,
+			cluster.GetName(),
+			key.Release(meta),
+			key.Release(cluster),
+			AnnotationForceUpgrade,
+		)
+	}
+	return nil
+}
+
+// ValidateSingleControlPlane validates that none of the given control planes belongs to the cluster of the new
+// control plane, so that aws-operator does not reconcile two conflicting desired states of the same masters.
+func ValidateSingleControlPlane(meta metav1.Object, kind string, controlPlanes []metav1.Object) error {
+	clusterID := key.Cluster(meta)
+	if clusterID == "" {
+		return nil
+	}
+	for _, c := range controlPlanes {
+		if key.Cluster(c) != clusterID {
+			continue
+		}
+		if c.GetName() == meta.GetName() && c.GetNamespace() == meta.GetNamespace() {
+			continue
+		}
+		return microerror.Maskf(notAllowedError, "%s %s can not be created because %s %s already exists for cluster %s.",
+			kind,
+			meta.GetName(),
+			kind,
+			c.GetName(),
+			clusterID,
+		)
+	}
+	return nil
+}
+
+// ValidateClusterName validates that the name of a cluster object is its cluster ID, because tooling
+// relies on being able to look up cluster objects by the cluster ID.
+func ValidateClusterName(obj metav1.Object) error {
+	clusterID := obj.GetLabels()[label.Cluster]
+	if clusterID != "" && obj.GetName() != clusterID {
+		return microerror.Maskf(notAllowedError, "Name %#q must be equal to the cluster ID %#q of label %#q.", obj.GetName(), clusterID, label.Cluster)
+	}
+	return nil
+}
+
+// ValidateNodePoolName validates that the name of a node pool object is its node pool ID, optionally
+// prefixed with its cluster ID like <cluster ID>-<node pool ID>.
+func ValidateNodePoolName(obj metav1.Object) error {
+	nodePoolID := obj.GetLabels()[label.MachineDeployment]
+	if nodePoolID == "" {
+		return nil
+	}
+	clusterID := obj.GetLabels()[label.Cluster]
+	if obj.GetName() == nodePoolID || (clusterID != "" && obj.GetName() == clusterID+"-"+nodePoolID) {
+		return nil
+	}
+	return microerror.Maskf(notAllowedError, "Name %#q must be equal to the node pool ID %#q of label %#q, optionally prefixed with the cluster ID like %#q.", obj.GetName(), nodePoolID, label.MachineDeployment, clusterID+"-"+nodePoolID)
+}
+
 // MaxBatchSizeIsValid will validate the value into valid maxBatchSize
 // valid values can be either:
 // an integer bigger than 0
 // a float between 0 < x <= 1
 // float value is used as ratio of a total worker count
 func MaxBatchSizeIsValid(value string) bool {
+	// percentages are converted to ratios by the mutators
+	value = NormalizeMaxBatchSize(value)
 	// try parse an integer
 	integer, err := strconv.Atoi(value)
 	if err == nil {
@@ -94,10 +294,10 @@ func MaxBatchSizeIsValid(value string) bool {
 	return false
 }
 
-// PauseTimeIsValid checks if the value is in proper ISO 8601 duration format
-// and ensure the duration is not bigger than 1 Hour (AWS limitation)
+// PauseTimeIsValid checks if the value is in proper ISO 8601 duration format, or a Go duration which the mutators
+// convert to it, and ensure the duration is not bigger than 1 Hour (AWS limitation)
 func PauseTimeIsValid(value string) bool {
-	d, err := iso8601.ParseDuration(value)
+	d, err := iso8601.ParseDuration(NormalizePauseTime(value))
 	if err != nil {
 		return false
 	}
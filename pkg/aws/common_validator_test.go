@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 
 	securityv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/security/v1alpha1"
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -66,9 +69,9 @@ func Test_MaxBatchSizeIsValid(t *testing.T) {
 			valid: false,
 		},
 		{
-			name:  "case 9: invalid value - '50%'",
+			name:  "case 9: percentage - '50%'",
 			input: "50%",
-			valid: false,
+			valid: true,
 		},
 		{
 			name:  "case 10: invalid value - string",
@@ -85,6 +88,16 @@ func Test_MaxBatchSizeIsValid(t *testing.T) {
 			input: "0.5erft",
 			valid: false,
 		},
+		{
+			name:  "case 13: percentage - invalid value - too big",
+			input: "150%",
+			valid: false,
+		},
+		{
+			name:  "case 14: percentage - all nodes",
+			input: "100%",
+			valid: true,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -125,14 +138,14 @@ func Test_PauseTimeIsValid(t *testing.T) {
 			valid: true,
 		},
 		{
-			name:  "case 5: invalid value value",
+			name:  "case 5: go duration",
 			value: "10m",
-			valid: false,
+			valid: true,
 		},
 		{
-			name:  "case 6: invalid value value",
+			name:  "case 6: go duration",
 			value: "10s",
-			valid: false,
+			valid: true,
 		},
 		{
 			name:  "case 7: invalid value value",
@@ -159,6 +172,11 @@ func Test_PauseTimeIsValid(t *testing.T) {
 			value: "PT1H2M",
 			valid: false,
 		},
+		{
+			name:  "case 12: go duration too big",
+			value: "2h",
+			valid: false,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -432,3 +450,496 @@ func Test_Organization(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateClusterName(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		objectName string
+		labels     map[string]string
+		valid      bool
+	}{
+		{
+			// name is the cluster ID
+			name: "case 0",
+
+			objectName: unittest.DefaultClusterID,
+			labels:     map[string]string{label.Cluster: unittest.DefaultClusterID},
+			valid:      true,
+		},
+		{
+			// name differs from the cluster ID
+			name: "case 1",
+
+			objectName: "my-cluster",
+			labels:     map[string]string{label.Cluster: unittest.DefaultClusterID},
+			valid:      false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			object := unittest.DefaultCluster()
+			object.SetName(tc.objectName)
+			object.SetLabels(tc.labels)
+			err = ValidateClusterName(object)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNodePoolName(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		objectName string
+		valid      bool
+	}{
+		{
+			// name is the node pool ID
+			name: "case 0",
+
+			objectName: unittest.DefaultMachineDeploymentID,
+			valid:      true,
+		},
+		{
+			// name is the node pool ID prefixed with the cluster ID
+			name: "case 1",
+
+			objectName: unittest.DefaultClusterID + "-" + unittest.DefaultMachineDeploymentID,
+			valid:      true,
+		},
+		{
+			// name is prefixed with another cluster ID
+			name: "case 2",
+
+			objectName: "abcde-" + unittest.DefaultMachineDeploymentID,
+			valid:      false,
+		},
+		{
+			// name differs from the node pool ID
+			name: "case 3",
+
+			objectName: "workers",
+			valid:      false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			object := unittest.DefaultAWSMachineDeployment()
+			object.SetName(tc.objectName)
+			err = ValidateNodePoolName(&object)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateStatusWriter(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		username string
+		valid    bool
+	}{
+		{
+			// aws-operator updates the status
+			name: "case 0",
+
+			username: "system:serviceaccount:giantswarm:aws-operator-9-3-0",
+			valid:    true,
+		},
+		{
+			// cluster-operator updates the status
+			name: "case 1",
+
+			username: "system:serviceaccount:giantswarm:cluster-operator-3-7-0",
+			valid:    true,
+		},
+		{
+			// user updates the status
+			name: "case 2",
+
+			username: "jane@example.com",
+			valid:    false,
+		},
+		{
+			// service account of another namespace updates the status
+			name: "case 3",
+
+			username: "system:serviceaccount:default:aws-operator",
+			valid:    false,
+		},
+		{
+			// unversioned aws-operator updates the status
+			name: "case 4",
+
+			username: "system:serviceaccount:giantswarm:aws-operator",
+			valid:    true,
+		},
+		{
+			// service account sharing the name prefix of aws-operator updates the status
+			name: "case 5",
+
+			username: "system:serviceaccount:giantswarm:aws-operator-impostor",
+			valid:    false,
+		},
+		{
+			// service account sharing the name prefix of cluster-operator updates the status
+			name: "case 6",
+
+			username: "system:serviceaccount:giantswarm:cluster-operatorx",
+			valid:    false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			writers, err := ParseStatusWriters([]string{"giantswarm/aws-operator", "giantswarm/cluster-operator"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = ValidateStatusWriter(authenticationv1.UserInfo{Username: tc.username}, writers)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestParseStatusWriters(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		serviceAccounts []string
+		expected        []string
+		valid           bool
+	}{
+		{
+			// service accounts are converted to usernames
+			name: "case 0",
+
+			serviceAccounts: []string{"giantswarm/aws-operator", "kube-system/status-writer"},
+			expected:        []string{"system:serviceaccount:giantswarm:aws-operator", "system:serviceaccount:kube-system:status-writer"},
+			valid:           true,
+		},
+		{
+			// service account without namespace
+			name: "case 1",
+
+			serviceAccounts: []string{"aws-operator"},
+			valid:           false,
+		},
+		{
+			// service account with empty name
+			name: "case 2",
+
+			serviceAccounts: []string{"giantswarm/"},
+			valid:           false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			writers, err := ParseStatusWriters(tc.serviceAccounts)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but returned %v", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(writers, tc.expected) {
+				t.Fatalf("expected %v to be equal to %v", writers, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateClusterExists(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterID   string
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// Cluster exists
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterID: unittest.DefaultClusterID,
+			valid:     true,
+		},
+		{
+			// Cluster does not exist
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterID: "abcde",
+			valid:     false,
+		},
+		{
+			// Cluster label is missing
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterID: "",
+			valid:     false,
+		},
+		{
+			// Cluster does not exist but the object opts out
+			name: "case 3",
+			ctx:  context.Background(),
+
+			clusterID:   "abcde",
+			annotations: map[string]string{AnnotationAllowMissingCluster: "true"},
+			valid:       true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultCluster())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object := unittest.DefaultAWSControlPlane()
+			object.SetLabels(map[string]string{label.Cluster: tc.clusterID})
+			object.SetAnnotations(tc.annotations)
+			err = ValidateClusterExists(handler, &object)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateClusterTransitioned(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		condition   string
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// cluster is created
+			name: "case 0",
+			ctx:  context.Background(),
+
+			condition: infrastructurev1alpha2.ClusterStatusConditionCreated,
+			valid:     true,
+		},
+		{
+			// cluster is updated
+			name: "case 1",
+			ctx:  context.Background(),
+
+			condition: infrastructurev1alpha2.ClusterStatusConditionUpdated,
+			valid:     true,
+		},
+		{
+			// cluster is updating
+			name: "case 2",
+			ctx:  context.Background(),
+
+			condition: infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			valid:     false,
+		},
+		{
+			// cluster is updating but the check is skipped
+			name: "case 3",
+			ctx:  context.Background(),
+
+			condition:   infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			annotations: map[string]string{AnnotationForceUpgrade: "true"},
+			valid:       true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Status.Cluster.Conditions = []infrastructurev1alpha2.CommonClusterStatusCondition{
+				{
+					LastTransitionTime: metav1.Now(),
+					Condition:          tc.condition,
+				},
+			}
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object := unittest.DefaultG8sControlPlane()
+			object.SetAnnotations(tc.annotations)
+			err = ValidateClusterTransitioned(handler, &object, "G8sControlPlane", "be migrated to HA masters")
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNoPendingUpgrade(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterRelease string
+		annotations    map[string]string
+		valid          bool
+	}{
+		{
+			// release versions match
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterRelease: "100.0.0",
+			valid:          true,
+		},
+		{
+			// cluster is being upgraded
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterRelease: "101.0.0",
+			valid:          false,
+		},
+		{
+			// cluster is being upgraded but the check is skipped
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterRelease: "101.0.0",
+			annotations:    map[string]string{AnnotationForceUpgrade: "true"},
+			valid:          true,
+		},
+		{
+			// cluster does not exist
+			name: "case 3",
+			ctx:  context.Background(),
+
+			valid: true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			if tc.clusterRelease != "" {
+				cluster := unittest.DefaultCluster()
+				cluster.Labels[label.Release] = tc.clusterRelease
+				err := fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			object := unittest.DefaultG8sControlPlane()
+			object.SetAnnotations(tc.annotations)
+			err := ValidateNoPendingUpgrade(handler, &object, "G8sControlPlane", "change its number of masters")
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSingleControlPlane(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		existingName      string
+		existingClusterID string
+		valid             bool
+	}{
+		{
+			// no control plane exists for the cluster
+			name: "case 0",
+
+			existingName:      "b3xyz",
+			existingClusterID: "abcde",
+			valid:             true,
+		},
+		{
+			// another control plane exists for the cluster
+			name: "case 1",
+
+			existingName:      "b3xyz",
+			existingClusterID: unittest.DefaultClusterID,
+			valid:             false,
+		},
+		{
+			// the control plane itself exists
+			name: "case 2",
+
+			existingName:      "a2wax",
+			existingClusterID: unittest.DefaultClusterID,
+			valid:             true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			existing := unittest.DefaultAWSControlPlane()
+			existing.SetName(tc.existingName)
+			existing.SetLabels(map[string]string{label.Cluster: tc.existingClusterID})
+
+			object := unittest.DefaultAWSControlPlane()
+			err := ValidateSingleControlPlane(&object, "AWSControlPlane", []metav1.Object{&existing})
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -24,11 +24,20 @@ import (
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
 )
 
+const (
+	// infrastructureRefAPIVersion is the API version of the AWSControlPlane referenced by a G8sControlPlane
+	infrastructureRefAPIVersion = "infrastructure.giantswarm.io/v1alpha2"
+	// infrastructureRefKind is the kind of the object referenced by a G8sControlPlane
+	infrastructureRefKind = "AWSControlPlane"
+)
+
 type Mutator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
 	validAvailabilityZones []string
+	validMasterAZCounts    []int
 }
 
 func NewMutator(config config.Config) (*Mutator, error) {
@@ -40,17 +49,24 @@ func NewMutator(config config.Config) (*Mutator, error) {
 	}
 
 	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
+	masterAZCounts, err := aws.ParseMasterAZCounts(config.MasterAZCounts)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MasterAZCounts are invalid: %v", config, err)
+	}
 	mutator := &Mutator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
 		validAvailabilityZones: availabilityZones,
+		validMasterAZCounts:    masterAZCounts,
 	}
 
 	return mutator, nil
 }
 
-func (m *Mutator) Mutate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
 	var result []mutator.PatchOperation
 
 	if request.DryRun != nil && *request.DryRun {
@@ -82,12 +98,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 
 	releaseVersion, err := aws.ReleaseVersion(g8sControlPlaneNewCR, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from G8sControlPlane")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from G8sControlPlane: %v", err)
 	}
 
 	// We try to fetch the AWSControlPlane belonging to the G8sControlPlane here.
 	availabilityZones := 0
-	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneNewCR)
+	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneNewCR)
 	if aws.IsNotFound(err) {
 		// Note that while we do log the error, we don't fail if the AWSControlPlane doesn't exist yet. That is okay because the order of CR creation can vary.
 		m.Log("level", "debug", "message", fmt.Sprintf("No AWSControlPlane %s could be found: %v", g8sControlPlaneNewCR.GetName(), err))
@@ -123,13 +139,19 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse g8scontrol plane: %v", err)
 	}
 
+	patch, err = m.MutateOwnerReference(*g8sControlPlaneCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*g8sControlPlaneCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	releaseVersion, err := aws.ReleaseVersion(g8sControlPlaneCR, patch)
 	if err != nil {
-		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from G8sControlPlane")
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from G8sControlPlane: %v", err)
 	}
 	result = append(result, patch...)
 
@@ -147,7 +169,7 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 
 	// We try to fetch the AWSControlPlane belonging to the G8sControlPlane here.
 	availabilityZones := 0
-	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneCR)
+	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneCR)
 	if aws.IsNotFound(err) {
 		// Note that while we do log the error, we don't fail if the AWSControlPlane doesn't exist yet. That is okay because the order of CR creation can vary.
 		m.Log("level", "debug", "message", fmt.Sprintf("No AWSControlPlane %s could be found: %v", g8sControlPlaneCR.GetName(), err))
@@ -168,7 +190,7 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 }
 
 func (m *Mutator) MutateControlPlaneLabel(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) ([]mutator.PatchOperation, error) {
-	return aws.MutateLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane, label.ControlPlane, g8sControlPlane.Name)
+	return aws.MutateLabel(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane, label.ControlPlane, g8sControlPlane.Name)
 }
 
 func (m *Mutator) MutateReplicaUpdate(g8sControlPlaneNewCR infrastructurev1alpha2.G8sControlPlane, g8sControlPlaneOldCR infrastructurev1alpha2.G8sControlPlane, awsControlPlane infrastructurev1alpha2.AWSControlPlane) ([]mutator.PatchOperation, error) {
@@ -179,7 +201,7 @@ func (m *Mutator) MutateReplicaUpdate(g8sControlPlaneNewCR infrastructurev1alpha
 	}
 	// If the availability zones need to be updated from 1 to 3, we do it here
 	update := func() error {
-		ctx := context.Background()
+		ctx := m.ctx
 		m.Log("level", "debug", "message", fmt.Sprintf("Updating AWSControlPlane AZs for HA %s", awsControlPlane.Name))
 		awsControlPlane.Spec.AvailabilityZones = m.getHAavailabilityZones(awsControlPlane.Spec.AvailabilityZones[0], m.validAvailabilityZones)
 		err := m.k8sClient.CtrlClient().Update(ctx, &awsControlPlane)
@@ -196,21 +218,39 @@ func (m *Mutator) MutateReplicaUpdate(g8sControlPlaneNewCR infrastructurev1alpha
 	return result, nil
 }
 
+// MutateInfraRef completes the reference to the AWSControlPlane of the cluster. The AWSControlPlane is looked up by
+// the cluster ID and assumed to have the name of the G8sControlPlane when it does not exist yet.
 func (m *Mutator) MutateInfraRef(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
-	if g8sControlPlane.Spec.InfrastructureRef.Name != "" && g8sControlPlane.Spec.InfrastructureRef.Namespace != "" {
+	ref := g8sControlPlane.Spec.InfrastructureRef
+	if ref.Name != "" && ref.Namespace != "" && ref.Kind != "" && ref.APIVersion != "" {
 		return result, nil
 	}
 	namespace := g8sControlPlane.GetNamespace()
 	if namespace == "" {
 		namespace = metav1.NamespaceDefault
 	}
-	// Since the AWSControlplane object likely doesn't exist yet, we are not fetching it here.
-	// Instead we make the assumption that it will be created correctly and thus has the same name as the G8sControlplane object.
+	name := ref.Name
+	if name == "" {
+		name = g8sControlPlane.GetName()
+	}
+	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane)
+	if aws.IsNotFound(err) || aws.IsInvalidConfig(err) {
+		// The AWSControlPlane likely doesn't exist yet. We make the assumption that it will be created correctly
+		// and thus has the same name as the G8sControlPlane object.
+		m.Log("level", "debug", "message", fmt.Sprintf("AWSControlPlane of G8sControlPlane %s could not be fetched: %v", g8sControlPlane.GetName(), err))
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	} else {
+		name = awsControlPlane.GetName()
+		if awsControlPlane.GetNamespace() != "" {
+			namespace = awsControlPlane.GetNamespace()
+		}
+	}
 	infrastructureCRRef := v1.ObjectReference{
-		APIVersion: "infrastructure.giantswarm.io/v1alpha2",
-		Kind:       "AWSControlPlane",
-		Name:       g8sControlPlane.GetName(),
+		APIVersion: infrastructureRefAPIVersion,
+		Kind:       infrastructureRefKind,
+		Name:       name,
 		Namespace:  namespace,
 	}
 	m.Log("level", "debug", "message", fmt.Sprintf("Updating infrastructure reference to  %s", g8sControlPlane.Name))
@@ -218,6 +258,12 @@ func (m *Mutator) MutateInfraRef(g8sControlPlane infrastructurev1alpha2.G8sContr
 	result = append(result, patch)
 	return result, nil
 }
+
+// MutateOwnerReference sets the owning Cluster as owner of the G8sControlPlane.
+func (m *Mutator) MutateOwnerReference(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) ([]mutator.PatchOperation, error) {
+	return aws.MutateOwnerReference(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane)
+}
+
 func (m *Mutator) MutateReleaseVersion(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -227,20 +273,20 @@ func (m *Mutator) MutateReleaseVersion(g8sControlPlane infrastructurev1alpha2.G8
 		return result, nil
 	}
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane)
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the release label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane, *cluster, label.Release)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane, *cluster, label.Release)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	result = append(result, patch...)
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane, *cluster, label.ClusterOperatorVersion)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &g8sControlPlane, *cluster, label.ClusterOperatorVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -257,7 +303,7 @@ func (m *Mutator) MutateReplicas(availabilityZones int, g8sControlPlane infrastr
 	}
 	var replicas int
 	{
-		replicas = aws.DefaultMasterReplicas
+		replicas = aws.DefaultMasterAZCount(m.validMasterAZCounts)
 		// If there is an AWSControlPlane, the default replicas match the number of AZs
 		if availabilityZones != 0 {
 			replicas = availabilityZones
@@ -315,6 +361,13 @@ func (m *Mutator) getHAavailabilityZones(firstAZ string, azs []string) []string
 	}
 }
 
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Mutator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
@@ -100,7 +100,7 @@ func TestAZG8sControlPlaneAdmit(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			_, err = mutate.Mutate(request)
+			_, err = mutate.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
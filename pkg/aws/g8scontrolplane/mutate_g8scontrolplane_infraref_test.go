@@ -23,6 +23,7 @@ func TestInfraRefG8sControlPlaneAdmit(t *testing.T) {
 		name string
 		ctx  context.Context
 
+		awsControlPlaneName   string
 		expectedReferenceName string
 		reference             bool
 	}{
@@ -42,6 +43,15 @@ func TestInfraRefG8sControlPlaneAdmit(t *testing.T) {
 			reference:             true,
 			expectedReferenceName: "",
 		},
+		{
+			// Reference not set, AWSControlPlane with a different name exists
+			name: "case 2",
+			ctx:  context.Background(),
+
+			awsControlPlaneName:   "abc12",
+			reference:             false,
+			expectedReferenceName: "abc12",
+		},
 	}
 
 	for i, tc := range testCases {
@@ -66,6 +76,15 @@ func TestInfraRefG8sControlPlaneAdmit(t *testing.T) {
 				logger:                 newLogger,
 			}
 
+			if tc.awsControlPlaneName != "" {
+				awsControlPlane := awsControlPlane([]string{"eu-central-1a"})
+				awsControlPlane.Name = tc.awsControlPlaneName
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, awsControlPlane)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
 			if !tc.reference {
 				// run admission request for g8sControlPlane without reference
 				request, err = g8sControlPlaneNoReferenceAdmissionRequest()
@@ -79,7 +98,7 @@ func TestInfraRefG8sControlPlaneAdmit(t *testing.T) {
 					t.Fatal(err)
 				}
 			}
-			patch, err = mutate.Mutate(request)
+			patch, err = mutate.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
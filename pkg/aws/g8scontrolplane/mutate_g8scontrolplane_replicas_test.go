@@ -23,6 +23,7 @@ func TestReplicasG8sControlPlaneAdmit(t *testing.T) {
 		currentReplicas         int
 		expectReplicas          int
 		preHArelease            bool
+		validMasterAZCounts     []int
 	}{
 		{
 			// Default replicas for 1 awscontrolplane AZ
@@ -79,6 +80,26 @@ func TestReplicasG8sControlPlaneAdmit(t *testing.T) {
 			currentReplicas:         0,
 			expectReplicas:          1,
 		},
+		{
+			// Default replicas without awscontrolplane to the highest count allowed in the installation
+			name: "case 6",
+			ctx:  context.Background(),
+
+			currentAvailabilityZone: nil,
+			currentReplicas:         0,
+			expectReplicas:          2,
+			validMasterAZCounts:     []int{1, 2},
+		},
+		{
+			// Default replicas for 2 awscontrolplane AZs
+			name: "case 7",
+			ctx:  context.Background(),
+
+			currentAvailabilityZone: []string{"eu-central-1a", "eu-central-1b"},
+			currentReplicas:         0,
+			expectReplicas:          2,
+			validMasterAZCounts:     []int{1, 2},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -98,6 +119,7 @@ func TestReplicasG8sControlPlaneAdmit(t *testing.T) {
 			fakeK8sClient := unittest.FakeK8sClient()
 			mutate := &Mutator{
 				validAvailabilityZones: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+				validMasterAZCounts:    tc.validMasterAZCounts,
 				k8sClient:              fakeK8sClient,
 				logger:                 newLogger,
 			}
@@ -120,7 +142,7 @@ func TestReplicasG8sControlPlaneAdmit(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			patch, err = mutate.Mutate(request)
+			patch, err = mutate.Mutate(tc.ctx, request)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -9,6 +9,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
@@ -18,8 +19,11 @@ import (
 )
 
 type Validator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	validMasterAZCounts []int
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -29,21 +33,33 @@ func NewValidator(config config.Config) (*Validator, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	masterAZCounts, err := aws.ParseMasterAZCounts(config.MasterAZCounts)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MasterAZCounts are invalid: %v", config, err)
+	}
+
 	validator := &Validator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		validMasterAZCounts: masterAZCounts,
 	}
 
 	return validator, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
 	if request.Operation == admissionv1.Update {
 		return v.ValidateUpdate(request)
 	}
 	if request.Operation == admissionv1.Create {
 		return v.ValidateCreate(request)
 	}
+	if request.Operation == admissionv1.Delete {
+		return v.ValidateDelete(request)
+	}
 	return true, nil
 }
 
@@ -59,11 +75,15 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
-	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), &g8sControlPlane)
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), &g8sControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
+	err = v.SingleControlPlaneValid(g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	err = v.ReplicaCount(g8sControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -72,17 +92,25 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.InfraRefValid(g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	return true, nil
 }
 
 func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var g8sControlPlane infrastructurev1alpha2.G8sControlPlane
+	var g8sControlPlaneOld infrastructurev1alpha2.G8sControlPlane
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &g8sControlPlane); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
 	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &g8sControlPlaneOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old g8s control plane: %v", err)
+	}
 
 	err = v.ControlPlaneLabelSet(g8sControlPlane)
 	if err != nil {
@@ -92,10 +120,38 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.HAMigrationValid(g8sControlPlaneOld, g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.UpgradeConflictValid(g8sControlPlaneOld, g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	err = v.ReplicaAZMatch(g8sControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.InfraRefValid(g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (v *Validator) ValidateDelete(request *admissionv1.AdmissionRequest) (bool, error) {
+	var g8sControlPlane infrastructurev1alpha2.G8sControlPlane
+	var err error
+
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &g8sControlPlane); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse g8s control plane: %v", err)
+	}
+
+	err = v.DeletionAllowed(g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	return true, nil
 }
@@ -104,11 +160,120 @@ func (v *Validator) ControlPlaneLabelSet(g8sControlPlane infrastructurev1alpha2.
 	return aws.ValidateLabelSet(&g8sControlPlane, label.ControlPlane)
 }
 
+func (v *Validator) DeletionAllowed(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
+	if aws.IsAnnotationTrue(&g8sControlPlane, aws.AnnotationForceDelete) {
+		v.logger.Log("level", "debug", "message", fmt.Sprintf("Deletion protection of G8sControlPlane %s is skipped due to annotation %s", key.ControlPlane(&g8sControlPlane), aws.AnnotationForceDelete))
+		return nil
+	}
+
+	// Retrieve the `Cluster` CR related to this object.
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane)
+	// If the Cluster is already gone or can not be resolved there is nothing left to protect.
+	if aws.IsNotFound(err) || aws.IsInvalidConfig(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if cluster.GetDeletionTimestamp() == nil {
+		message := fmt.Sprintf("G8sControlPlane %s can not be deleted while Cluster %s still exists. Delete the Cluster instead or set annotation %s to \"true\".",
+			key.ControlPlane(&g8sControlPlane),
+			key.Cluster(cluster),
+			aws.AnnotationForceDelete,
+		)
+		v.logger.Log("level", "debug", "message", message)
+		return microerror.Maskf(notAllowedError, "%s", message)
+	}
+
+	return nil
+}
+
+// HAMigrationValid checks that the migration from a single master to HA masters is only done while the cluster
+// is in a stable state.
+func (v *Validator) HAMigrationValid(g8sControlPlaneOld infrastructurev1alpha2.G8sControlPlane, g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
+	if g8sControlPlaneOld.Spec.Replicas != 1 || g8sControlPlane.Spec.Replicas <= 1 {
+		return nil
+	}
+	return aws.ValidateClusterTransitioned(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane, "G8sControlPlane", "be migrated to HA masters")
+}
+
+// UpgradeConflictValid checks that the number of masters is not changed while a release upgrade of the cluster is
+// pending or ongoing, because overlapping rolling operations are not supported.
+func (v *Validator) UpgradeConflictValid(g8sControlPlaneOld infrastructurev1alpha2.G8sControlPlane, g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
+	if g8sControlPlaneOld.Spec.Replicas == g8sControlPlane.Spec.Replicas {
+		return nil
+	}
+	return aws.ValidateNoPendingUpgrade(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane, "G8sControlPlane", "change its number of masters")
+}
+
+// SingleControlPlaneValid checks that no other G8sControlPlane exists for the cluster.
+func (v *Validator) SingleControlPlaneValid(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
+	g8sControlPlanes, err := aws.FetchG8sControlPlanes(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	var controlPlanes []metav1.Object
+	for i := range g8sControlPlanes {
+		controlPlanes = append(controlPlanes, &g8sControlPlanes[i])
+	}
+	return aws.ValidateSingleControlPlane(&g8sControlPlane, "G8sControlPlane", controlPlanes)
+}
+
+// InfraRefValid checks that the infrastructure reference points to the AWSControlPlane of the cluster.
+func (v *Validator) InfraRefValid(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
+	ref := g8sControlPlane.Spec.InfrastructureRef
+	if ref.Kind != "" && ref.Kind != infrastructureRefKind {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("G8sControlPlane %s infrastructure reference has kind %#q but must reference an %s.",
+			key.ControlPlane(&g8sControlPlane),
+			ref.Kind,
+			infrastructureRefKind),
+		)
+	}
+	if ref.APIVersion != "" && ref.APIVersion != infrastructureRefAPIVersion {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("G8sControlPlane %s infrastructure reference has API version %#q but must have API version %#q.",
+			key.ControlPlane(&g8sControlPlane),
+			ref.APIVersion,
+			infrastructureRefAPIVersion),
+		)
+	}
+
+	if ref.Name == "" {
+		// The reference is completed by the mutator.
+		return nil
+	}
+
+	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane)
+	if aws.IsNotFound(err) {
+		// The AWSControlPlane may be created after the G8sControlPlane.
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	awsControlPlaneNamespace := awsControlPlane.GetNamespace()
+	if awsControlPlaneNamespace == "" {
+		awsControlPlaneNamespace = metav1.NamespaceDefault
+	}
+	if ref.Name != awsControlPlane.GetName() || namespace != awsControlPlaneNamespace {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("G8sControlPlane %s infrastructure reference %s/%s does not match AWSControlPlane %s/%s of the cluster.",
+			key.ControlPlane(&g8sControlPlane),
+			namespace,
+			ref.Name,
+			awsControlPlaneNamespace,
+			awsControlPlane.GetName()),
+		)
+	}
+	return nil
+}
+
 func (v *Validator) ReplicaAZMatch(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
 	var err error
 
 	// Retrieve the `AWSControlPlane` CR related to this object.
-	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane)
+	awsControlPlane, err := aws.FetchAWSControlPlane(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane)
 	// Note that while we do log the error, we don't fail if the AWSControlPlane doesn't exist yet. That is okay because the order of CR creation can vary.
 	if aws.IsNotFound(err) {
 		v.Log("level", "debug", "message", fmt.Sprintf("No AWSControlPlane %s could be found: %v", g8sControlPlane.GetName(), err))
@@ -136,23 +301,36 @@ func (v *Validator) ReplicaAZMatch(g8sControlPlane infrastructurev1alpha2.G8sCon
 
 	return nil
 }
+
+// ReplicaCount checks that the number of masters matches one of the master AZ counts allowed in the installation.
 func (v *Validator) ReplicaCount(g8sControlPlane infrastructurev1alpha2.G8sControlPlane) error {
-	if !aws.IsValidMasterReplicas(g8sControlPlane.Spec.Replicas) {
+	validCounts := v.validMasterAZCounts
+	if len(validCounts) == 0 {
+		validCounts = aws.ValidMasterReplicas()
+	}
+	if !aws.IsValidMasterAZCount(g8sControlPlane.Spec.Replicas, validCounts) {
 		v.logger.Log("level", "debug", "message", fmt.Sprintf("G8sControlPlane %s has an invalid count of %v replicas. Valid replica counts are: %v",
 			key.ControlPlane(&g8sControlPlane),
 			g8sControlPlane.Spec.Replicas,
-			aws.ValidMasterReplicas()),
+			validCounts),
 		)
 		return microerror.Maskf(notAllowedError, fmt.Sprintf("G8sControlPlane %s has an invalid count of %v replicas. Valid replica counts are: %v",
 			key.ControlPlane(&g8sControlPlane),
 			g8sControlPlane.Spec.Replicas,
-			aws.ValidMasterReplicas()),
+			validCounts),
 		)
 	}
 
 	return nil
 }
 
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Validator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
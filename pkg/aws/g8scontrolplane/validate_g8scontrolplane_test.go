@@ -7,7 +7,11 @@ import (
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
 
@@ -74,7 +78,7 @@ func TestReplicaAZMatch(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(&admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, &admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
@@ -87,8 +91,9 @@ func TestReplicaCount(t *testing.T) {
 		ctx  context.Context
 		name string
 
-		allowed  bool
-		replicas int
+		allowed     bool
+		replicas    int
+		validCounts []int
 	}{
 		{
 			ctx:  context.Background(),
@@ -111,6 +116,15 @@ func TestReplicaCount(t *testing.T) {
 			allowed:  false,
 			replicas: 4,
 		},
+		{
+			// two replicas are allowed in regions configured for them
+			ctx:  context.Background(),
+			name: "case 4",
+
+			allowed:     true,
+			replicas:    2,
+			validCounts: []int{1, 2},
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -129,6 +143,8 @@ func TestReplicaCount(t *testing.T) {
 			validate := &Validator{
 				k8sClient: fakeK8sClient,
 				logger:    newLogger,
+
+				validMasterAZCounts: tc.validCounts,
 			}
 
 			admissionRequest, err := g8sControlPlaneCreateAdmissionRequest(tc.replicas, "100.0.0")
@@ -142,10 +158,215 @@ func TestReplicaCount(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			allowed, _ := validate.Validate(admissionRequest)
+			allowed, _ := validate.Validate(tc.ctx, admissionRequest)
 			if allowed != tc.allowed {
 				t.Fatalf("expected %v to not to differ from %v", allowed, tc.allowed)
 			}
 		})
 	}
 }
+
+func TestDeletionAllowed(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		allowed         bool
+		clusterExists   bool
+		clusterDeleting bool
+		clusterLabel    bool
+		forceDelete     bool
+	}{
+		{
+			// Cluster still exists
+			ctx:  context.Background(),
+			name: "case 0",
+
+			allowed:       false,
+			clusterExists: true,
+			clusterLabel:  true,
+		},
+		{
+			// Cluster is being deleted
+			ctx:  context.Background(),
+			name: "case 1",
+
+			allowed:         true,
+			clusterExists:   true,
+			clusterDeleting: true,
+			clusterLabel:    true,
+		},
+		{
+			// Cluster does not exist anymore
+			ctx:  context.Background(),
+			name: "case 2",
+
+			allowed:       true,
+			clusterExists: false,
+			clusterLabel:  true,
+		},
+		{
+			// Cluster still exists but deletion is forced
+			ctx:  context.Background(),
+			name: "case 3",
+
+			allowed:       true,
+			clusterExists: true,
+			clusterLabel:  true,
+			forceDelete:   true,
+		},
+		{
+			// Cluster label is missing
+			ctx:  context.Background(),
+			name: "case 4",
+
+			allowed:       true,
+			clusterExists: true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			// Create a new logger that is used by all admitters.
+			var newLogger micrologger.Logger
+			{
+				newLogger, err = micrologger.New(micrologger.Config{})
+				if err != nil {
+					panic(microerror.JSON(err))
+				}
+			}
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    newLogger,
+			}
+
+			if tc.clusterExists {
+				cluster := unittest.DefaultCluster()
+				if tc.clusterDeleting {
+					now := metav1.Now()
+					cluster.SetDeletionTimestamp(&now)
+				}
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			g8sControlPlane := unittest.DefaultG8sControlPlane()
+			if tc.forceDelete {
+				g8sControlPlane.SetAnnotations(map[string]string{aws.AnnotationForceDelete: "true"})
+			}
+			if !tc.clusterLabel {
+				labels := g8sControlPlane.GetLabels()
+				delete(labels, label.Cluster)
+				g8sControlPlane.SetLabels(labels)
+			}
+
+			err = validate.DeletionAllowed(g8sControlPlane)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestInfraRefValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		allowed   bool
+		reference corev1.ObjectReference
+	}{
+		{
+			// Reference not set
+			ctx:  context.Background(),
+			name: "case 0",
+
+			allowed:   true,
+			reference: corev1.ObjectReference{},
+		},
+		{
+			// Reference matches the AWSControlPlane
+			ctx:  context.Background(),
+			name: "case 1",
+
+			allowed: true,
+			reference: corev1.ObjectReference{
+				APIVersion: "infrastructure.giantswarm.io/v1alpha2",
+				Kind:       "AWSControlPlane",
+				Name:       "a2wax",
+				Namespace:  "default",
+			},
+		},
+		{
+			// Reference has the wrong kind
+			ctx:  context.Background(),
+			name: "case 2",
+
+			allowed: false,
+			reference: corev1.ObjectReference{
+				APIVersion: "infrastructure.giantswarm.io/v1alpha2",
+				Kind:       "AWSCluster",
+				Name:       "a2wax",
+				Namespace:  "default",
+			},
+		},
+		{
+			// Reference points to a different AWSControlPlane
+			ctx:  context.Background(),
+			name: "case 3",
+
+			allowed: false,
+			reference: corev1.ObjectReference{
+				APIVersion: "infrastructure.giantswarm.io/v1alpha2",
+				Kind:       "AWSControlPlane",
+				Name:       "abc12",
+				Namespace:  "default",
+			},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			// Create a new logger that is used by all admitters.
+			var newLogger micrologger.Logger
+			{
+				newLogger, err = micrologger.New(micrologger.Config{})
+				if err != nil {
+					panic(microerror.JSON(err))
+				}
+			}
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    newLogger,
+			}
+
+			awsControlPlane := unittest.DefaultAWSControlPlane()
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsControlPlane)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g8sControlPlane := unittest.DefaultG8sControlPlane()
+			g8sControlPlane.Spec.InfrastructureRef = tc.reference
+
+			err = validate.InfraRefValid(g8sControlPlane)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
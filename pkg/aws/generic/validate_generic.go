@@ -0,0 +1,226 @@
+// Package generic intercepts write activity to resource kinds which are enabled by configuration
+// and applies a set of generic policies to them.
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/aws-admission-controller/v2/config"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+)
+
+const (
+	// PolicyLabels requires the cluster and organization labels on creation and protects
+	// giantswarm.io labels from being changed or removed.
+	PolicyLabels = "labels"
+	// PolicyRelease requires the release label to match the release of the Cluster.
+	PolicyRelease = "release"
+)
+
+// Resource is a resource kind which is validated with generic policies.
+type Resource struct {
+	Group    string   `json:"group"`
+	Version  string   `json:"version"`
+	Kind     string   `json:"kind"`
+	Resource string   `json:"resource"`
+	Policies []string `json:"policies"`
+}
+
+// GroupVersionResource returns the API resource of the resource kind.
+func (r Resource) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// Path returns the path of the validating webhook of the resource kind.
+func (r Resource) Path() string {
+	return fmt.Sprintf("/validate/generic/%s", r.Resource)
+}
+
+// ParseResources parses resource kinds which are configured as JSON objects like
+// {"group":"infrastructure.giantswarm.io","version":"v1alpha2","kind":"AWSFoo","resource":"awsfoos","policies":["labels","release"]}.
+func ParseResources(configs []string) ([]Resource, error) {
+	var resources []Resource
+	for _, c := range configs {
+		var r Resource
+		err := json.Unmarshal([]byte(c), &r)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "generic resource %s is not valid JSON: %v", c, err)
+		}
+		if r.Version == "" || r.Kind == "" || r.Resource == "" {
+			return nil, microerror.Maskf(invalidConfigError, "generic resource %s needs a version, kind and resource", c)
+		}
+		if len(r.Policies) == 0 {
+			return nil, microerror.Maskf(invalidConfigError, "generic resource %s has no policies", c)
+		}
+		for _, p := range r.Policies {
+			if p != PolicyLabels && p != PolicyRelease {
+				return nil, microerror.Maskf(invalidConfigError, "generic resource %s has unknown policy %#q, valid policies are %#q and %#q", c, p, PolicyLabels, PolicyRelease)
+			}
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// Validator for resource kinds enabled by configuration.
+type Validator struct {
+	ctx       context.Context
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+	resource  Resource
+}
+
+// NewValidators returns a validator for every resource kind configured in GenericResources.
+func NewValidators(config config.Config) ([]*Validator, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	resources, err := ParseResources(config.GenericResources)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GenericResources are invalid: %v", config, err)
+	}
+
+	var validators []*Validator
+	for _, r := range resources {
+		validators = append(validators, &Validator{
+			ctx:       context.Background(),
+			k8sClient: config.K8sClient,
+			logger:    config.Logger,
+			resource:  r,
+		})
+	}
+
+	return validators, nil
+}
+
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
+	var object metav1.PartialObjectMetadata
+	var err error
+
+	if err := json.Unmarshal(request.Object.Raw, &object); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse %s: %v", v.resource.Kind, err)
+	}
+
+	if v.hasPolicy(PolicyLabels) {
+		if request.Operation == admissionv1.Create {
+			err = v.LabelsCreateValid(&object)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+		}
+		if request.Operation == admissionv1.Update {
+			var oldObject metav1.PartialObjectMetadata
+			if err := json.Unmarshal(request.OldObject.Raw, &oldObject); err != nil {
+				return false, microerror.Maskf(parsingFailedError, "unable to parse old %s: %v", v.resource.Kind, err)
+			}
+			err = v.LabelsUpdateValid(&oldObject, &object)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+		}
+	}
+
+	if v.hasPolicy(PolicyRelease) {
+		err = v.ReleaseValid(&object)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	return true, nil
+}
+
+// LabelsCreateValid validates that the cluster label is set and the organization exists.
+func (v *Validator) LabelsCreateValid(object metav1.Object) error {
+	err := aws.ValidateLabelSet(object, label.Cluster)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, "%v", err)
+	}
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+// LabelsUpdateValid validates that no giantswarm.io labels are changed or removed.
+func (v *Validator) LabelsUpdateValid(oldObject metav1.Object, object metav1.Object) error {
+	err := aws.ValidateLabelKeys(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, oldObject, object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	err = aws.ValidateLabelValues(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, oldObject, object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+// ReleaseValid validates that the release label is set and matches the release of the Cluster.
+func (v *Validator) ReleaseValid(object metav1.Object) error {
+	if key.Release(object) == "" {
+		return microerror.Maskf(notAllowedError, "Label %#q is not set for %s %s.", label.Release, v.resource.Kind, object.GetName())
+	}
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, object)
+	if aws.IsNotFound(err) || aws.IsInvalidConfig(err) {
+		// Objects without an existing Cluster are checked by the labels policy.
+		v.Log("level", "debug", "message", fmt.Sprintf("Cluster of %s %s could not be fetched: %v", v.resource.Kind, object.GetName(), err))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	if key.Release(cluster) != "" && key.Release(cluster) != key.Release(object) {
+		return microerror.Maskf(notAllowedError, "%s %s has release version %#q but its Cluster %s has release version %#q.", v.resource.Kind, object.GetName(), key.Release(object), cluster.GetName(), key.Release(cluster))
+	}
+	return nil
+}
+
+func (v *Validator) hasPolicy(policy string) bool {
+	for _, p := range v.resource.Policies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// Path returns the path of the validating webhook.
+func (v *Validator) Path() string {
+	return v.resource.Path()
+}
+
+// GroupVersionResource returns the API resource the validator is responsible for.
+func (v *Validator) GroupVersionResource() schema.GroupVersionResource {
+	return v.resource.GroupVersionResource()
+}
+
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
+func (v *Validator) Log(keyVals ...interface{}) {
+	v.logger.Log(keyVals...)
+}
+
+func (v *Validator) Resource() string {
+	return strings.ToLower(v.resource.Kind)
+}
@@ -0,0 +1,149 @@
+package generic
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestParseResources(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		configs []string
+		valid   bool
+	}{
+		{
+			// valid resource with all policies
+			name: "case 0",
+
+			configs: []string{`{"group":"infrastructure.giantswarm.io","version":"v1alpha2","kind":"AWSFoo","resource":"awsfoos","policies":["labels","release"]}`},
+			valid:   true,
+		},
+		{
+			// resource without policies
+			name: "case 1",
+
+			configs: []string{`{"group":"infrastructure.giantswarm.io","version":"v1alpha2","kind":"AWSFoo","resource":"awsfoos"}`},
+			valid:   false,
+		},
+		{
+			// resource with unknown policy
+			name: "case 2",
+
+			configs: []string{`{"group":"infrastructure.giantswarm.io","version":"v1alpha2","kind":"AWSFoo","resource":"awsfoos","policies":["quota"]}`},
+			valid:   false,
+		},
+		{
+			// resource without kind
+			name: "case 3",
+
+			configs: []string{`{"group":"infrastructure.giantswarm.io","version":"v1alpha2","resource":"awsfoos","policies":["labels"]}`},
+			valid:   false,
+		},
+		{
+			// invalid JSON
+			name: "case 4",
+
+			configs: []string{`awsfoos`},
+			valid:   false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParseResources(tc.configs)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestReleaseValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		labels map[string]string
+		valid  bool
+	}{
+		{
+			// release matches the Cluster
+			name: "case 0",
+			ctx:  context.Background(),
+
+			labels: map[string]string{label.Cluster: unittest.DefaultClusterID, label.Release: unittest.DefaultReleaseVersion},
+			valid:  true,
+		},
+		{
+			// release differs from the Cluster
+			name: "case 1",
+			ctx:  context.Background(),
+
+			labels: map[string]string{label.Cluster: unittest.DefaultClusterID, label.Release: "99.0.0"},
+			valid:  false,
+		},
+		{
+			// release is missing
+			name: "case 2",
+			ctx:  context.Background(),
+
+			labels: map[string]string{label.Cluster: unittest.DefaultClusterID},
+			valid:  false,
+		},
+		{
+			// Cluster does not exist
+			name: "case 3",
+			ctx:  context.Background(),
+
+			labels: map[string]string{label.Cluster: "abcde", label.Release: "99.0.0"},
+			valid:  true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+				resource: Resource{
+					Group:    "infrastructure.giantswarm.io",
+					Version:  "v1alpha2",
+					Kind:     "AWSFoo",
+					Resource: "awsfoos",
+					Policies: []string{PolicyRelease},
+				},
+			}
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultCluster())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object := &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: metav1.NamespaceDefault,
+					Labels:    tc.labels,
+				},
+			}
+			err = validate.ReleaseValid(object)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
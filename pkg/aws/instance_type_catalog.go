@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InstanceTypeResources holds the number of vCPUs and the memory in GiB of an EC2 instance type.
+type InstanceTypeResources struct {
+	CPU    int
+	Memory float64
+}
+
+// burstableInstanceSizes lists the resources of the burstable t2, t3 and t3a instance sizes,
+// which do not follow the vCPU to memory ratio of their family.
+var burstableInstanceSizes = map[string]InstanceTypeResources{
+	"nano":    {CPU: 2, Memory: 0.5},
+	"micro":   {CPU: 2, Memory: 1},
+	"small":   {CPU: 2, Memory: 2},
+	"medium":  {CPU: 2, Memory: 4},
+	"large":   {CPU: 2, Memory: 8},
+	"xlarge":  {CPU: 4, Memory: 16},
+	"2xlarge": {CPU: 8, Memory: 32},
+}
+
+// instanceFamilyMemoryPerCPU lists the GiB of memory per vCPU of the general purpose, compute and
+// memory optimized instance families by their class. Generations with a different ratio are listed
+// with their full family name.
+var instanceFamilyMemoryPerCPU = map[string]float64{
+	"c":   2,
+	"c4":  1.875,
+	"c5n": 2.625,
+	"m":   4,
+	"r":   8,
+}
+
+// GetInstanceTypeResources returns the vCPUs and memory of an EC2 instance type from the built-in catalog.
+// The second return value is false when the instance type is not covered by the catalog.
+func GetInstanceTypeResources(instanceType string) (InstanceTypeResources, bool) {
+	parts := strings.SplitN(instanceType, ".", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return InstanceTypeResources{}, false
+	}
+	family, size := parts[0], parts[1]
+
+	if family == "t2" || family == "t3" || family == "t3a" {
+		resources, ok := burstableInstanceSizes[size]
+		return resources, ok
+	}
+
+	memoryPerCPU, ok := instanceFamilyMemoryPerCPU[family]
+	if !ok {
+		memoryPerCPU, ok = instanceFamilyMemoryPerCPU[family[:1]]
+	}
+	if !ok {
+		return InstanceTypeResources{}, false
+	}
+
+	var cpu int
+	switch {
+	case size == "large":
+		cpu = 2
+	case size == "xlarge":
+		cpu = 4
+	case strings.HasSuffix(size, "xlarge"):
+		multiplier, err := strconv.Atoi(strings.TrimSuffix(size, "xlarge"))
+		if err != nil || multiplier < 1 {
+			return InstanceTypeResources{}, false
+		}
+		cpu = 4 * multiplier
+	default:
+		return InstanceTypeResources{}, false
+	}
+
+	return InstanceTypeResources{CPU: cpu, Memory: float64(cpu) * memoryPerCPU}, true
+}
+
+// ValidateInstanceTypeMinimum checks that the instance type of the given object has at least the given number of
+// vCPUs and GiB of memory. Instance types which are not covered by the built-in catalog are admitted.
+func ValidateInstanceTypeMinimum(m *Handler, meta metav1.Object, kind string, instanceType string, minCPU int, minMemory int) error {
+	if instanceType == "" || (minCPU <= 0 && minMemory <= 0) {
+		return nil
+	}
+
+	resources, ok := GetInstanceTypeResources(instanceType)
+	if !ok {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("instance type %s of %s %s is not in the instance type catalog, skipping the minimum size check", instanceType, kind, meta.GetName()))
+		return nil
+	}
+	if resources.CPU < minCPU || resources.Memory < float64(minMemory) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s instance type %s with %d vCPUs and %v GiB memory is too small. At least %d vCPUs and %d GiB memory are required.",
+			kind,
+			meta.GetName(),
+			instanceType,
+			resources.CPU,
+			resources.Memory,
+			minCPU,
+			minMemory),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestInstanceTypeResources(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType      string
+		expectedResources InstanceTypeResources
+		known             bool
+	}{
+		{
+			// general purpose instance type
+			name: "case 0",
+
+			instanceType:      "m5.xlarge",
+			expectedResources: InstanceTypeResources{CPU: 4, Memory: 16},
+			known:             true,
+		},
+		{
+			// memory optimized instance type with size multiplier
+			name: "case 1",
+
+			instanceType:      "r5a.2xlarge",
+			expectedResources: InstanceTypeResources{CPU: 8, Memory: 64},
+			known:             true,
+		},
+		{
+			// compute optimized generation with its own ratio
+			name: "case 2",
+
+			instanceType:      "c4.large",
+			expectedResources: InstanceTypeResources{CPU: 2, Memory: 3.75},
+			known:             true,
+		},
+		{
+			// burstable instance type
+			name: "case 3",
+
+			instanceType:      "t3.medium",
+			expectedResources: InstanceTypeResources{CPU: 2, Memory: 4},
+			known:             true,
+		},
+		{
+			// instance family not in the catalog
+			name: "case 4",
+
+			instanceType: "p3.2xlarge",
+			known:        false,
+		},
+		{
+			// bare metal instance type
+			name: "case 5",
+
+			instanceType: "m5.metal",
+			known:        false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			resources, known := GetInstanceTypeResources(tc.instanceType)
+			if known != tc.known {
+				t.Fatalf("expected known to be %v but got %v", tc.known, known)
+			}
+			if resources != tc.expectedResources {
+				t.Fatalf("expected %v to be equal to %v", tc.expectedResources, resources)
+			}
+		})
+	}
+}
+
+func TestValidateInstanceTypeMinimum(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType string
+		minCPU       int
+		minMemory    int
+		valid        bool
+	}{
+		{
+			// no minimum configured
+			name: "case 0",
+
+			instanceType: "t3.medium",
+			valid:        true,
+		},
+		{
+			// instance type meets the minimum
+			name: "case 1",
+
+			instanceType: "m5.xlarge",
+			minCPU:       4,
+			minMemory:    16,
+			valid:        true,
+		},
+		{
+			// instance type has too little memory
+			name: "case 2",
+
+			instanceType: "c5.xlarge",
+			minCPU:       4,
+			minMemory:    16,
+			valid:        false,
+		},
+		{
+			// instance type has too few vCPUs
+			name: "case 3",
+
+			instanceType: "r5.large",
+			minCPU:       4,
+			minMemory:    16,
+			valid:        false,
+		},
+		{
+			// instance type is not in the catalog
+			name: "case 4",
+
+			instanceType: "p3.2xlarge",
+			minCPU:       4,
+			minMemory:    16,
+			valid:        true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handler := &Handler{
+				K8sClient: unittest.FakeK8sClient(),
+				Logger:    microloggertest.New(),
+			}
+			object := unittest.DefaultAWSControlPlane()
+
+			err := ValidateInstanceTypeMinimum(handler, &object, "AWSControlPlane", tc.instanceType, tc.minCPU, tc.minMemory)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/blang/semver"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IPv4PrefixAddresses is the number of IP addresses of a /28 prefix assigned to an ENI slot with prefix delegation.
+	IPv4PrefixAddresses = 16
+	// MaxPodsSmallInstance is the max pods of instance types with less than 30 vCPUs when prefix delegation is used.
+	MaxPodsSmallInstance = 110
+	// MaxPodsLargeInstance is the max pods of instance types with 30 or more vCPUs when prefix delegation is used.
+	MaxPodsLargeInstance = 250
+)
+
+// ENILimits holds the number of network interfaces of an EC2 instance type and the IPv4 addresses per interface.
+type ENILimits struct {
+	Interfaces int
+	IPv4PerENI int
+	// LargeVCPUs is set for instance types with 30 or more vCPUs.
+	LargeVCPUs bool
+}
+
+// burstableENILimits lists the network interface limits of the burstable t2, t3 and t3a instance sizes.
+var burstableENILimits = map[string]ENILimits{
+	"nano":    {Interfaces: 2, IPv4PerENI: 2},
+	"micro":   {Interfaces: 2, IPv4PerENI: 2},
+	"small":   {Interfaces: 3, IPv4PerENI: 4},
+	"medium":  {Interfaces: 3, IPv4PerENI: 6},
+	"large":   {Interfaces: 3, IPv4PerENI: 12},
+	"xlarge":  {Interfaces: 4, IPv4PerENI: 15},
+	"2xlarge": {Interfaces: 4, IPv4PerENI: 15},
+}
+
+// sizeENILimits lists the network interface limits of the general purpose, compute and memory optimized instance
+// families by size, which are the same across these families.
+var sizeENILimits = map[string]ENILimits{
+	"large":    {Interfaces: 3, IPv4PerENI: 10},
+	"xlarge":   {Interfaces: 4, IPv4PerENI: 15},
+	"2xlarge":  {Interfaces: 4, IPv4PerENI: 15},
+	"4xlarge":  {Interfaces: 8, IPv4PerENI: 30},
+	"8xlarge":  {Interfaces: 8, IPv4PerENI: 30, LargeVCPUs: true},
+	"9xlarge":  {Interfaces: 8, IPv4PerENI: 30, LargeVCPUs: true},
+	"12xlarge": {Interfaces: 8, IPv4PerENI: 30, LargeVCPUs: true},
+	"16xlarge": {Interfaces: 15, IPv4PerENI: 50, LargeVCPUs: true},
+	"18xlarge": {Interfaces: 15, IPv4PerENI: 50, LargeVCPUs: true},
+	"24xlarge": {Interfaces: 15, IPv4PerENI: 50, LargeVCPUs: true},
+}
+
+// GetENILimits returns the network interface limits of an EC2 instance type from the built-in catalog. The second
+// return value is false when the instance type is not covered by the catalog.
+func GetENILimits(instanceType string) (ENILimits, bool) {
+	family := InstanceTypeFamily(instanceType)
+	size := InstanceTypeSize(instanceType)
+
+	if family == "t2" || family == "t3" || family == "t3a" {
+		limits, ok := burstableENILimits[size]
+		return limits, ok
+	}
+	if _, ok := instanceFamilyMemoryPerCPU[family]; !ok {
+		if _, ok := instanceFamilyMemoryPerCPU[InstanceTypeClass(instanceType)]; !ok {
+			return ENILimits{}, false
+		}
+	}
+	limits, ok := sizeENILimits[size]
+	return limits, ok
+}
+
+// InstanceTypeMaxPods returns the number of pods the AWS CNI can assign IP addresses to on a node of the given
+// instance type. Every network interface keeps its primary address and host network pods need no address. With
+// prefix delegation every address slot holds a /28 prefix, limited to the Kubernetes recommendations.
+func InstanceTypeMaxPods(instanceType string, prefixDelegation bool) (int, bool) {
+	limits, ok := GetENILimits(instanceType)
+	if !ok {
+		return 0, false
+	}
+	slots := limits.Interfaces * (limits.IPv4PerENI - 1)
+	if !prefixDelegation {
+		return slots + 2, true
+	}
+	maxPods := slots*IPv4PrefixAddresses + 2
+	limit := MaxPodsSmallInstance
+	if limits.LargeVCPUs {
+		limit = MaxPodsLargeInstance
+	}
+	if maxPods > limit {
+		maxPods = limit
+	}
+	return maxPods, true
+}
+
+// ValidateMaxPods validates that the max pods annotation of a node pool does not exceed the number of pods the AWS
+// CNI can assign IP addresses to on its instance type. Releases using Cilium assign pod IPs from the pod CIDR and are
+// not limited by the network interfaces.
+func ValidateMaxPods(meta metav1.Object, kind string, instanceType string, releaseVersion *semver.Version) error {
+	value, ok := meta.GetAnnotations()[AnnotationMaxPods]
+	if !ok {
+		return nil
+	}
+	maxPods, err := strconv.Atoi(value)
+	if err != nil || maxPods <= 0 {
+		return microerror.Maskf(notAllowedError, "%s %s annotation %s value %#q is not an integer greater than zero.",
+			kind,
+			meta.GetName(),
+			AnnotationMaxPods,
+			value,
+		)
+	}
+	if releaseVersion != nil && IsCiliumVersion(releaseVersion) {
+		return nil
+	}
+
+	prefixDelegation := IsAnnotationTrue(meta, AnnotationPrefixDelegation)
+	limit, ok := InstanceTypeMaxPods(instanceType, prefixDelegation)
+	if !ok {
+		return nil
+	}
+	if maxPods > limit {
+		hint := fmt.Sprintf(" Use a larger instance type or enable prefix delegation with annotation %s.", AnnotationPrefixDelegation)
+		if prefixDelegation {
+			hint = " Use a larger instance type."
+		}
+		return microerror.Maskf(notAllowedError, "%s %s annotation %s value %d exceeds the %d pods the network interfaces of instance type %s can provide IP addresses for.%s",
+			kind,
+			meta.GetName(),
+			AnnotationMaxPods,
+			maxPods,
+			limit,
+			instanceType,
+			hint,
+		)
+	}
+	return nil
+}
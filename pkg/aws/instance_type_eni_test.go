@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/blang/semver"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestInstanceTypeMaxPods(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType     string
+		prefixDelegation bool
+		expectedMaxPods  int
+		expectedOK       bool
+	}{
+		{
+			// general purpose instance type without prefix delegation
+			name: "case 0",
+
+			instanceType:    "m5.large",
+			expectedMaxPods: 29,
+			expectedOK:      true,
+		},
+		{
+			// prefix delegation is limited to the recommendation for small instances
+			name: "case 1",
+
+			instanceType:     "m5.large",
+			prefixDelegation: true,
+			expectedMaxPods:  MaxPodsSmallInstance,
+			expectedOK:       true,
+		},
+		{
+			// prefix delegation is limited to the recommendation for large instances
+			name: "case 2",
+
+			instanceType:     "r5.16xlarge",
+			prefixDelegation: true,
+			expectedMaxPods:  MaxPodsLargeInstance,
+			expectedOK:       true,
+		},
+		{
+			// burstable instance type
+			name: "case 3",
+
+			instanceType:    "t3.medium",
+			expectedMaxPods: 17,
+			expectedOK:      true,
+		},
+		{
+			// instance type is not covered by the catalog
+			name: "case 4",
+
+			instanceType: "x1e.xlarge",
+			expectedOK:   false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			maxPods, ok := InstanceTypeMaxPods(tc.instanceType, tc.prefixDelegation)
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok %t but got %t", tc.expectedOK, ok)
+			}
+			if maxPods != tc.expectedMaxPods {
+				t.Fatalf("expected max pods %d but got %d", tc.expectedMaxPods, maxPods)
+			}
+		})
+	}
+}
+
+func TestValidateMaxPods(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations  map[string]string
+		instanceType string
+		release      string
+		valid        bool
+	}{
+		{
+			// no max pods annotation
+			name: "case 0",
+
+			instanceType: "m5.large",
+			release:      "14.0.0",
+			valid:        true,
+		},
+		{
+			// max pods within the ENI limits
+			name: "case 1",
+
+			annotations:  map[string]string{AnnotationMaxPods: "29"},
+			instanceType: "m5.large",
+			release:      "14.0.0",
+			valid:        true,
+		},
+		{
+			// max pods exceeds the ENI limits
+			name: "case 2",
+
+			annotations:  map[string]string{AnnotationMaxPods: "110"},
+			instanceType: "m5.large",
+			release:      "14.0.0",
+			valid:        false,
+		},
+		{
+			// max pods within the ENI limits with prefix delegation
+			name: "case 3",
+
+			annotations:  map[string]string{AnnotationMaxPods: "110", AnnotationPrefixDelegation: "true"},
+			instanceType: "m5.large",
+			release:      "14.0.0",
+			valid:        true,
+		},
+		{
+			// max pods exceeds the ENI limits with prefix delegation
+			name: "case 4",
+
+			annotations:  map[string]string{AnnotationMaxPods: "200", AnnotationPrefixDelegation: "true"},
+			instanceType: "m5.large",
+			release:      "14.0.0",
+			valid:        false,
+		},
+		{
+			// releases using Cilium are not limited by the ENIs
+			name: "case 5",
+
+			annotations:  map[string]string{AnnotationMaxPods: "110"},
+			instanceType: "m5.large",
+			release:      "19.0.0",
+			valid:        true,
+		},
+		{
+			// max pods is not an integer
+			name: "case 6",
+
+			annotations:  map[string]string{AnnotationMaxPods: "many"},
+			instanceType: "m5.large",
+			release:      "19.0.0",
+			valid:        false,
+		},
+		{
+			// instance type is not covered by the catalog
+			name: "case 7",
+
+			annotations:  map[string]string{AnnotationMaxPods: "500"},
+			instanceType: "x1e.xlarge",
+			release:      "14.0.0",
+			valid:        true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+			object.SetAnnotations(tc.annotations)
+			releaseVersion := semver.MustParse(tc.release)
+
+			err := ValidateMaxPods(&object, "AWSMachineDeployment", tc.instanceType, &releaseVersion)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/ec2offering"
+)
+
+// ValidateInstanceTypeOffered checks that the instance type of the given object exists in the region and is
+// offered in all of its availability zones. The check is skipped when no offerings are configured and
+// objects are admitted when the EC2 API can not be reached.
+func ValidateInstanceTypeOffered(m *Handler, offerings ec2offering.Interface, meta metav1.Object, kind string, instanceType string, availabilityZones []string) error {
+	if offerings == nil || instanceType == "" {
+		return nil
+	}
+
+	offeredAZs, err := offerings.OfferedAvailabilityZones(instanceType)
+	if err != nil {
+		m.Logger.Log("level", "warning", "message", fmt.Sprintf("unable to check the offerings of instance type %s of %s %s", instanceType, kind, meta.GetName()), "stack", microerror.JSON(err))
+		return nil
+	}
+	if len(offeredAZs) == 0 {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s instance type %s does not exist in the region of the installation.",
+			kind,
+			meta.GetName(),
+			instanceType),
+		)
+	}
+
+	var missingAZs []string
+	for _, az := range availabilityZones {
+		if !contains(offeredAZs, az) && !contains(missingAZs, az) {
+			missingAZs = append(missingAZs, az)
+		}
+	}
+	if len(missingAZs) > 0 {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s instance type %s is not offered in availability zones %v. It is offered in %v.",
+			kind,
+			meta.GetName(),
+			instanceType,
+			missingAZs,
+			offeredAZs),
+		)
+	}
+
+	return nil
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
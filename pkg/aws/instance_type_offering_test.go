@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/ec2offering"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+type fakeOfferings struct {
+	offerings map[string][]string
+	err       error
+}
+
+func (f *fakeOfferings) OfferedAvailabilityZones(instanceType string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.offerings[instanceType], nil
+}
+
+func TestValidateInstanceTypeOffered(t *testing.T) {
+	offerings := &fakeOfferings{
+		offerings: map[string][]string{
+			"m5.xlarge":  {"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			"p3.2xlarge": {"eu-central-1a", "eu-central-1b"},
+		},
+	}
+
+	testCases := []struct {
+		name string
+
+		offerings         ec2offering.Interface
+		instanceType      string
+		availabilityZones []string
+		valid             bool
+	}{
+		{
+			// no offerings configured
+			name: "case 0",
+
+			instanceType:      "m5.xlarg",
+			availabilityZones: unittest.DefaultAvailabilityZones(),
+			valid:             true,
+		},
+		{
+			// instance type is offered in all AZs
+			name: "case 1",
+
+			offerings:         offerings,
+			instanceType:      "m5.xlarge",
+			availabilityZones: unittest.DefaultAvailabilityZones(),
+			valid:             true,
+		},
+		{
+			// instance type does not exist
+			name: "case 2",
+
+			offerings:         offerings,
+			instanceType:      "m5.xlarg",
+			availabilityZones: unittest.DefaultAvailabilityZones(),
+			valid:             false,
+		},
+		{
+			// instance type is not offered in one of the AZs
+			name: "case 3",
+
+			offerings:         offerings,
+			instanceType:      "p3.2xlarge",
+			availabilityZones: unittest.DefaultAvailabilityZones(),
+			valid:             false,
+		},
+		{
+			// instance type is offered in the chosen AZs
+			name: "case 4",
+
+			offerings:         offerings,
+			instanceType:      "p3.2xlarge",
+			availabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+			valid:             true,
+		},
+		{
+			// offerings can not be fetched
+			name: "case 5",
+
+			offerings:         &fakeOfferings{err: errors.New("unreachable")},
+			instanceType:      "m5.xlarg",
+			availabilityZones: unittest.DefaultAvailabilityZones(),
+			valid:             true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handler := &Handler{
+				K8sClient: unittest.FakeK8sClient(),
+				Logger:    microloggertest.New(),
+			}
+			object := &metav1.ObjectMeta{Name: "al9qy", Namespace: metav1.NamespaceDefault}
+
+			err := ValidateInstanceTypeOffered(handler, tc.offerings, object, "AWSMachineDeployment", tc.instanceType, tc.availabilityZones)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+)
+
+const (
+	// InstanceTypePolicyKey is the key of the instance type policy in its ConfigMap
+	InstanceTypePolicyKey = "policy.json"
+)
+
+// InstanceTypeRules lists allowed and denied EC2 instance types. Entries may be
+// shell patterns like "p3.*" to match whole instance families.
+type InstanceTypeRules struct {
+	Allowed []string `json:"allowed,omitempty"`
+	Denied  []string `json:"denied,omitempty"`
+}
+
+// InstanceTypePolicy holds the installation wide instance type rules and
+// per-organization overrides which replace them for the given organization.
+type InstanceTypePolicy struct {
+	InstanceTypeRules
+	Organizations map[string]InstanceTypeRules `json:"organizations,omitempty"`
+}
+
+// ParseInstanceTypePolicyRef parses the reference to the instance type policy ConfigMap
+// given as "namespace/name". An empty reference disables the policy.
+func ParseInstanceTypePolicyRef(ref string) (types.NamespacedName, error) {
+	if ref == "" {
+		return types.NamespacedName{}, nil
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, microerror.Maskf(invalidConfigError, "instance type policy %#q has to be given as namespace/name", ref)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// FetchInstanceTypePolicy reads the instance type policy from the given ConfigMap.
+// It returns nil if the policy is disabled or the ConfigMap does not exist.
+func FetchInstanceTypePolicy(m *Handler, ref types.NamespacedName) (*InstanceTypePolicy, error) {
+	if ref.Name == "" {
+		return nil, nil
+	}
+
+	var configMap corev1.ConfigMap
+	err := m.K8sClient.CtrlClient().Get(m.Context(), client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, &configMap)
+	if apierrors.IsNotFound(err) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Instance type policy ConfigMap %s does not exist", ref.String()))
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var policy InstanceTypePolicy
+	err = json.Unmarshal([]byte(configMap.Data[InstanceTypePolicyKey]), &policy)
+	if err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse instance type policy ConfigMap %s: %v", ref.String(), err)
+	}
+	return &policy, nil
+}
+
+// Rules returns the instance type rules which apply to the given organization.
+func (p *InstanceTypePolicy) Rules(organization string) InstanceTypeRules {
+	if rules, ok := p.Organizations[organization]; ok {
+		return rules
+	}
+	return p.InstanceTypeRules
+}
+
+// Allows returns whether the rules allow the given instance type. Denied types take precedence
+// and all types which are not denied are allowed if no allowed types are given.
+func (r InstanceTypeRules) Allows(instanceType string) bool {
+	for _, pattern := range r.Denied {
+		if matchInstanceType(pattern, instanceType) {
+			return false
+		}
+	}
+	if len(r.Allowed) == 0 {
+		return true
+	}
+	for _, pattern := range r.Allowed {
+		if matchInstanceType(pattern, instanceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateInstanceTypePolicy checks the instance type of the given object against the
+// instance type policy of the object's organization.
+func ValidateInstanceTypePolicy(m *Handler, ref types.NamespacedName, meta metav1.Object, kind string, instanceType string) error {
+	policy, err := FetchInstanceTypePolicy(m, ref)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	organization := key.Organization(meta)
+	rules := policy.Rules(organization)
+	if !rules.Allows(instanceType) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s instance type %s is not allowed for organization %s. Allowed instance types are %v, denied instance types are %v.",
+			kind,
+			meta.GetName(),
+			instanceType,
+			organization,
+			rules.Allowed,
+			rules.Denied),
+		)
+	}
+	return nil
+}
+
+func matchInstanceType(pattern string, instanceType string) bool {
+	matched, err := filepath.Match(pattern, instanceType)
+	return err == nil && matched
+}
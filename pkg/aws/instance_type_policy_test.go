@@ -0,0 +1,129 @@
+package aws
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateInstanceTypePolicy(t *testing.T) {
+	policy := `{
+		"allowed": ["m5.*", "r5.xlarge", "p3.2xlarge"],
+		"denied": ["p3.*"],
+		"organizations": {
+			"research": {"allowed": ["m5.*", "p3.*"]}
+		}
+	}`
+
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		policy       string
+		organization string
+		instanceType string
+		valid        bool
+	}{
+		{
+			// no policy
+			ctx:  context.Background(),
+			name: "case 0",
+
+			organization: "example-organization",
+			instanceType: "p3.2xlarge",
+			valid:        true,
+		},
+		{
+			// instance type matches an allowed family
+			ctx:  context.Background(),
+			name: "case 1",
+
+			policy:       policy,
+			organization: "example-organization",
+			instanceType: "m5.2xlarge",
+			valid:        true,
+		},
+		{
+			// instance type is not allowed
+			ctx:  context.Background(),
+			name: "case 2",
+
+			policy:       policy,
+			organization: "example-organization",
+			instanceType: "c5.xlarge",
+			valid:        false,
+		},
+		{
+			// denied instance types take precedence
+			ctx:  context.Background(),
+			name: "case 3",
+
+			policy:       policy,
+			organization: "example-organization",
+			instanceType: "p3.2xlarge",
+			valid:        false,
+		},
+		{
+			// organization override allows the instance type
+			ctx:  context.Background(),
+			name: "case 4",
+
+			policy:       policy,
+			organization: "research",
+			instanceType: "p3.8xlarge",
+			valid:        true,
+		},
+		{
+			// organization override replaces the installation rules
+			ctx:  context.Background(),
+			name: "case 5",
+
+			policy:       policy,
+			organization: "research",
+			instanceType: "r5.xlarge",
+			valid:        false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			ref := types.NamespacedName{Name: "instance-type-policy", Namespace: "giantswarm"}
+			if tc.policy != "" {
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      ref.Name,
+						Namespace: ref.Namespace,
+					},
+					Data: map[string]string{
+						InstanceTypePolicyKey: tc.policy,
+					},
+				}
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, configMap)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			awsMachineDeployment := unittest.DefaultAWSMachineDeployment()
+			awsMachineDeployment.Labels[label.Organization] = tc.organization
+
+			err = ValidateInstanceTypePolicy(&Handler{K8sClient: fakeK8sClient, Logger: microloggertest.New()}, ref, &awsMachineDeployment, "AWSMachineDeployment", tc.instanceType)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// gpuInstanceClasses lists the classes of the EC2 instance types with NVIDIA GPUs.
+var gpuInstanceClasses = []string{"g", "p"}
+
+// IsGPUInstanceType returns whether an EC2 instance type has NVIDIA GPUs, like p3.2xlarge or g4dn.xlarge.
+func IsGPUInstanceType(instanceType string) bool {
+	return contains(gpuInstanceClasses, InstanceTypeClass(instanceType))
+}
+
+// ValidateInstanceTypeRelease validates that the release version supports the instance type. GPU instance types
+// need the NVIDIA device plugin and Graviton instance types need arm64 node images, which older releases lack.
+func ValidateInstanceTypeRelease(meta metav1.Object, kind string, instanceType string, releaseVersion *semver.Version) error {
+	if instanceType == "" || releaseVersion == nil {
+		return nil
+	}
+	if IsGPUInstanceType(instanceType) && !IsGPUVersion(releaseVersion) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s instance type %s has GPUs, but release version %s does not ship the GPU device plugin. The first release supporting GPU instance types is %s.",
+			kind,
+			meta.GetName(),
+			instanceType,
+			releaseVersion.String(),
+			FirstGPURelease),
+		)
+	}
+	if InstanceTypeArchitecture(instanceType) == ArchitectureARM64 && !IsARM64Version(releaseVersion) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s instance type %s has the %s architecture, but release version %s does not support it. The first release supporting %s instance types is %s.",
+			kind,
+			meta.GetName(),
+			instanceType,
+			ArchitectureARM64,
+			releaseVersion.String(),
+			ArchitectureARM64,
+			FirstARM64Release),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/blang/semver"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateInstanceTypeRelease(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType string
+		release      string
+		valid        bool
+	}{
+		{
+			// x86_64 instance type without GPUs
+			name: "case 0",
+
+			instanceType: "m5.xlarge",
+			release:      "11.0.0",
+			valid:        true,
+		},
+		{
+			// GPU instance type with a release shipping the device plugin
+			name: "case 1",
+
+			instanceType: "p3.2xlarge",
+			release:      "12.0.0",
+			valid:        true,
+		},
+		{
+			// GPU instance type with a release lacking the device plugin
+			name: "case 2",
+
+			instanceType: "g4dn.xlarge",
+			release:      "11.5.0",
+			valid:        false,
+		},
+		{
+			// Graviton instance type with a release supporting arm64
+			name: "case 3",
+
+			instanceType: "m6g.xlarge",
+			release:      "18.0.0",
+			valid:        true,
+		},
+		{
+			// Graviton instance type with a release lacking arm64 support
+			name: "case 4",
+
+			instanceType: "c6gn.xlarge",
+			release:      "17.2.0",
+			valid:        false,
+		},
+		{
+			// Graviton GPU instance type needs both
+			name: "case 5",
+
+			instanceType: "g5g.xlarge",
+			release:      "16.0.0",
+			valid:        false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+			releaseVersion := semver.MustParse(tc.release)
+
+			err := ValidateInstanceTypeRelease(&object, "AWSMachineDeployment", tc.instanceType, &releaseVersion)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
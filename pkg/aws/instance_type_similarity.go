@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ArchitectureARM64 is the architecture of the AWS Graviton instance families like m6g.
+	ArchitectureARM64 = "arm64"
+	// ArchitectureX8664 is the architecture of the Intel and AMD instance families.
+	ArchitectureX8664 = "x86_64"
+
+	// AlikeInstanceTypeTolerance is the relative difference of vCPUs and memory up to which instance types
+	// are considered alike.
+	AlikeInstanceTypeTolerance = 0.25
+)
+
+// InstanceTypeClass returns the class of an EC2 instance type, e.g. m for m5.xlarge.
+func InstanceTypeClass(instanceType string) string {
+	family := InstanceTypeFamily(instanceType)
+	i := strings.IndexFunc(family, unicode.IsDigit)
+	if i < 0 {
+		return family
+	}
+	return family[:i]
+}
+
+// InstanceTypeSize returns the size of an EC2 instance type, e.g. xlarge for m5.xlarge.
+func InstanceTypeSize(instanceType string) string {
+	parts := strings.SplitN(instanceType, ".", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// InstanceTypeArchitecture returns the CPU architecture of an EC2 instance type. Graviton families carry a g in
+// the attributes following their generation, e.g. m6g, c6gn or t4g.
+func InstanceTypeArchitecture(instanceType string) string {
+	family := InstanceTypeFamily(instanceType)
+	attributes := strings.TrimLeftFunc(strings.TrimPrefix(family, InstanceTypeClass(instanceType)), unicode.IsDigit)
+	if strings.Contains(attributes, "g") {
+		return ArchitectureARM64
+	}
+	return ArchitectureX8664
+}
+
+// AlikeInstanceTypes returns the candidates with the same class and size as the given instance type, which are
+// used along with it when alike instance types are enabled for a node pool.
+func AlikeInstanceTypes(instanceType string, candidates []string) []string {
+	var alike []string
+	for _, c := range candidates {
+		if c == instanceType {
+			continue
+		}
+		if InstanceTypeClass(c) == InstanceTypeClass(instanceType) && InstanceTypeSize(c) == InstanceTypeSize(instanceType) {
+			alike = append(alike, c)
+		}
+	}
+	return alike
+}
+
+// InstanceTypesSimilar returns whether two instance types have the same architecture and their vCPUs and memory
+// differ by at most the given relative tolerance. Instance types which are not covered by the built-in catalog
+// are only compared by architecture.
+func InstanceTypesSimilar(a string, b string, tolerance float64) bool {
+	if InstanceTypeArchitecture(a) != InstanceTypeArchitecture(b) {
+		return false
+	}
+	resourcesA, okA := GetInstanceTypeResources(a)
+	resourcesB, okB := GetInstanceTypeResources(b)
+	if !okA || !okB {
+		return true
+	}
+	return relativeDifference(float64(resourcesA.CPU), float64(resourcesB.CPU)) <= tolerance &&
+		relativeDifference(resourcesA.Memory, resourcesB.Memory) <= tolerance
+}
+
+// ValidateAlikeInstanceTypes validates that all instance types alike to the given one are similar to it, so that
+// the nodes of a node pool do not differ in architecture or size depending on the available spot capacity.
+func ValidateAlikeInstanceTypes(m *Handler, meta metav1.Object, kind string, instanceType string, candidates []string) error {
+	var dissimilar []string
+	for _, alike := range AlikeInstanceTypes(instanceType, candidates) {
+		if !InstanceTypesSimilar(instanceType, alike, AlikeInstanceTypeTolerance) {
+			dissimilar = append(dissimilar, alike)
+		}
+	}
+	if len(dissimilar) > 0 {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("%s %s instance type %s has dissimilar alike instance types %v", kind, meta.GetName(), instanceType, dissimilar))
+		return microerror.Maskf(notAllowedError, "%s %s uses alike instance types, but instance types %v differ from %s in architecture or by more than %v%% in vCPUs or memory. Disable alike instance types or choose another instance type.",
+			kind,
+			meta.GetName(),
+			dissimilar,
+			instanceType,
+			AlikeInstanceTypeTolerance*100,
+		)
+	}
+	return nil
+}
+
+func relativeDifference(a float64, b float64) float64 {
+	if a == b {
+		return 0
+	}
+	return math.Abs(a-b) / math.Max(a, b)
+}
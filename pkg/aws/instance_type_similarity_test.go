@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestInstanceTypeArchitecture(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType         string
+		expectedArchitecture string
+	}{
+		{
+			// intel instance type
+			name: "case 0",
+
+			instanceType:         "m5.xlarge",
+			expectedArchitecture: ArchitectureX8664,
+		},
+		{
+			// amd instance type
+			name: "case 1",
+
+			instanceType:         "r5a.2xlarge",
+			expectedArchitecture: ArchitectureX8664,
+		},
+		{
+			// graviton instance type
+			name: "case 2",
+
+			instanceType:         "m6g.xlarge",
+			expectedArchitecture: ArchitectureARM64,
+		},
+		{
+			// graviton instance type with further attributes
+			name: "case 3",
+
+			instanceType:         "c6gn.large",
+			expectedArchitecture: ArchitectureARM64,
+		},
+		{
+			// gpu instance type of class g
+			name: "case 4",
+
+			instanceType:         "g4dn.xlarge",
+			expectedArchitecture: ArchitectureX8664,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			architecture := InstanceTypeArchitecture(tc.instanceType)
+			if architecture != tc.expectedArchitecture {
+				t.Fatalf("expected %#q to be equal to %#q", architecture, tc.expectedArchitecture)
+			}
+		})
+	}
+}
+
+func TestValidateAlikeInstanceTypes(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType string
+		candidates   []string
+		valid        bool
+	}{
+		{
+			// alike instance types of the same size
+			name: "case 0",
+
+			instanceType: "m5.xlarge",
+			candidates:   []string{"m4.xlarge", "m5.xlarge", "m5a.xlarge", "m5.2xlarge", "c5.xlarge"},
+			valid:        true,
+		},
+		{
+			// alike instance type of another architecture
+			name: "case 1",
+
+			instanceType: "m5.xlarge",
+			candidates:   []string{"m5.xlarge", "m6g.xlarge"},
+			valid:        false,
+		},
+		{
+			// alike instance type with too little memory
+			name: "case 2",
+
+			instanceType: "c5n.xlarge",
+			candidates:   []string{"c4.xlarge", "c5n.xlarge"},
+			valid:        false,
+		},
+		{
+			// alike instance type with a similar amount of memory
+			name: "case 3",
+
+			instanceType: "c5n.xlarge",
+			candidates:   []string{"c5.xlarge", "c5n.xlarge"},
+			valid:        true,
+		},
+		{
+			// no alike instance types
+			name: "case 4",
+
+			instanceType: "p3.2xlarge",
+			candidates:   []string{"p3.2xlarge", "m5.2xlarge"},
+			valid:        true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handler := &Handler{
+				K8sClient: unittest.FakeK8sClient(),
+				Logger:    microloggertest.New(),
+			}
+			object := unittest.DefaultAWSMachineDeployment()
+
+			err := ValidateAlikeInstanceTypes(handler, &object, "AWSMachineDeployment", tc.instanceType, tc.candidates)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
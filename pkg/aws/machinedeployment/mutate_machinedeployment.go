@@ -2,10 +2,14 @@
 package machinedeployment
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
@@ -22,6 +26,7 @@ type Config struct {
 
 // Mutator for MachineDeployment object.
 type Mutator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 }
@@ -35,6 +40,7 @@ func NewMutator(config config.Config) (*Mutator, error) {
 	}
 
 	mutator := &Mutator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 	}
@@ -43,7 +49,8 @@ func NewMutator(config config.Config) (*Mutator, error) {
 }
 
 // Mutate is the function executed for every matching webhook request.
-func (m *Mutator) Mutate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
 	var result []mutator.PatchOperation
 
 	if request.DryRun != nil && *request.DryRun {
@@ -66,6 +73,22 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	if _, _, err := mutator.Deserializer.Decode(request.Object.Raw, nil, machineDeployment); err != nil {
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse MachineDeployment: %v", err)
 	}
+
+	patch, err = m.MutateClusterNameLabel(machineDeployment)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = aws.MutateOwnerReference(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, machineDeployment)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch = m.MutateInfrastructureRefNamespace(*machineDeployment)
+	result = append(result, patch...)
+
 	capi, err := aws.IsCAPIRelease(machineDeployment)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -83,6 +106,31 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	return result, nil
 }
 
+// MutateClusterNameLabel defaults the cluster-api cluster name label from the giantswarm.io/cluster label, so that
+// cluster-api controllers can find the Cluster of the MachineDeployment.
+func (m *Mutator) MutateClusterNameLabel(machineDeployment *capiv1alpha2.MachineDeployment) ([]mutator.PatchOperation, error) {
+	return aws.MutateDerivedLabel(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, machineDeployment, capiv1alpha2.MachineClusterLabelName, key.Cluster(machineDeployment))
+}
+
+// MutateInfrastructureRefNamespace defaults the namespace of the infrastructure reference of the machine template to
+// the namespace of the MachineDeployment, where the AWSMachineDeployment is expected.
+func (m *Mutator) MutateInfrastructureRefNamespace(machineDeployment capiv1alpha2.MachineDeployment) []mutator.PatchOperation {
+	var result []mutator.PatchOperation
+
+	infrastructureRef := machineDeployment.Spec.Template.Spec.InfrastructureRef
+	if infrastructureRef.Name == "" || infrastructureRef.Namespace != "" {
+		return result
+	}
+	namespace := machineDeployment.GetNamespace()
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	m.Log("level", "debug", "message", fmt.Sprintf("Infrastructure reference namespace of MachineDeployment %s will be defaulted to %s", machineDeployment.GetName(), namespace))
+	result = append(result, mutator.PatchAdd("/spec/template/spec/infrastructureRef/namespace", namespace))
+
+	return result
+}
+
 func (m *Mutator) MutateReleaseVersion(machineDeployment capiv1alpha2.MachineDeployment) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -92,20 +140,20 @@ func (m *Mutator) MutateReleaseVersion(machineDeployment capiv1alpha2.MachineDep
 		return result, nil
 	}
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &machineDeployment)
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &machineDeployment)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	// mutate the release label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &machineDeployment, *cluster, label.Release)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &machineDeployment, *cluster, label.Release)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	result = append(result, patch...)
 
 	// mutate the operator label
-	patch, err = aws.MutateLabelFromCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &machineDeployment, *cluster, label.ClusterOperatorVersion)
+	patch, err = aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &machineDeployment, *cluster, label.ClusterOperatorVersion)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -114,6 +162,13 @@ func (m *Mutator) MutateReleaseVersion(machineDeployment capiv1alpha2.MachineDep
 	return result, nil
 }
 
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
 func (m *Mutator) Log(keyVals ...interface{}) {
 	m.logger.Log(keyVals...)
 }
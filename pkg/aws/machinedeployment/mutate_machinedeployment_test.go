@@ -0,0 +1,90 @@
+package machinedeployment
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestMachineDeploymentClusterDefaults(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		clusterID                 string
+		clusterName               string
+		infrastructureRefName     string
+		infrastructureRefNS       string
+		expectedClusterName       string
+		expectedInfrastructureRef string
+	}{
+		{
+			// cluster name label and infrastructure reference namespace are defaulted
+			name: "case 0",
+
+			clusterID:                 unittest.DefaultClusterID,
+			infrastructureRefName:     unittest.DefaultMachineDeploymentID,
+			expectedClusterName:       unittest.DefaultClusterID,
+			expectedInfrastructureRef: "default",
+		},
+		{
+			// cluster name label and infrastructure reference namespace are kept
+			name: "case 1",
+
+			clusterID:             unittest.DefaultClusterID,
+			clusterName:           "other",
+			infrastructureRefName: unittest.DefaultMachineDeploymentID,
+			infrastructureRefNS:   "org-giantswarm",
+		},
+		{
+			// nothing is defaulted without cluster label and infrastructure reference
+			name: "case 2",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			object := unittest.DefaultMachineDeployment()
+			labels := object.GetLabels()
+			labels[label.Cluster] = tc.clusterID
+			if tc.clusterName != "" {
+				labels[capiv1alpha2.MachineClusterLabelName] = tc.clusterName
+			}
+			object.SetLabels(labels)
+			object.Spec.Template.Spec.InfrastructureRef = corev1.ObjectReference{Name: tc.infrastructureRefName, Namespace: tc.infrastructureRefNS}
+
+			patch, err := mutate.MutateClusterNameLabel(&object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var clusterName string
+			for _, p := range patch {
+				if p.Path == "/metadata/labels/cluster.x-k8s.io~1cluster-name" {
+					clusterName = p.Value.(string)
+				}
+			}
+			if clusterName != tc.expectedClusterName {
+				t.Fatalf("expected cluster name label %#q but got %#q", tc.expectedClusterName, clusterName)
+			}
+
+			var infrastructureRefNS string
+			for _, p := range mutate.MutateInfrastructureRefNamespace(object) {
+				if p.Path == "/spec/template/spec/infrastructureRef/namespace" {
+					infrastructureRefNS = p.Value.(string)
+				}
+			}
+			if infrastructureRefNS != tc.expectedInfrastructureRef {
+				t.Fatalf("expected infrastructure reference namespace %#q but got %#q", tc.expectedInfrastructureRef, infrastructureRefNS)
+			}
+		})
+	}
+}
@@ -8,14 +8,18 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
 )
 
 type Validator struct {
+	ctx       context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 }
@@ -29,6 +33,7 @@ func NewValidator(config config.Config) (*Validator, error) {
 	}
 
 	validator := &Validator{
+		ctx:       context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 	}
@@ -36,10 +41,58 @@ func NewValidator(config config.Config) (*Validator, error) {
 	return validator, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
+	if request.SubResource == aws.SubResourceScale {
+		return v.ValidateScale(request)
+	}
 	if request.Operation == admissionv1.Create {
 		return v.ValidateCreate(request)
 	}
+	if request.Operation == admissionv1.Update {
+		return v.ValidateUpdate(request)
+	}
+	return true, nil
+}
+
+// ValidateScale is the function executed for every scale subresource webhook request.
+func (v *Validator) ValidateScale(request *admissionv1.AdmissionRequest) (bool, error) {
+	var scale autoscalingv1.Scale
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &scale); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse scale: %v", err)
+	}
+
+	err := v.ReplicasValid(&scale, int(scale.Spec.Replicas))
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var machineDeployment capiv1alpha2.MachineDeployment
+	var oldMachineDeployment capiv1alpha2.MachineDeployment
+
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &machineDeployment); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse machinedeployment: %v", err)
+	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &oldMachineDeployment); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old machinedeployment: %v", err)
+	}
+
+	if machineDeployment.Spec.Replicas != nil && (oldMachineDeployment.Spec.Replicas == nil || *machineDeployment.Spec.Replicas != *oldMachineDeployment.Spec.Replicas) {
+		err := v.ReplicasValid(&machineDeployment, int(*machineDeployment.Spec.Replicas))
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	err := v.ClusterLabelsUpdateValid(oldMachineDeployment, machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	return true, nil
 }
 
@@ -63,7 +116,12 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
-	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), &machineDeployment)
+	err = v.ClusterLabelsMatch(machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), &machineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -75,7 +133,7 @@ func (v *Validator) ValidateCluster(machineDeployment capiv1alpha2.MachineDeploy
 	var err error
 
 	// Retrieve the `Cluster` CR related to this object.
-	cluster, err := aws.FetchCluster(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &machineDeployment)
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &machineDeployment)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -88,6 +146,46 @@ func (v *Validator) ValidateCluster(machineDeployment capiv1alpha2.MachineDeploy
 	return nil
 }
 
+// ClusterLabelsMatch checks that the release and cluster-operator version labels match the ones of the Cluster.
+func (v *Validator) ClusterLabelsMatch(machineDeployment capiv1alpha2.MachineDeployment) error {
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &machineDeployment)
+	if aws.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	return aws.ValidateLabelsMatch(&machineDeployment, "MachineDeployment", cluster, "Cluster", label.Release, label.ClusterOperatorVersion)
+}
+
+// ClusterLabelsUpdateValid checks that changed release and cluster-operator version labels match the ones of the
+// Cluster, so that node pools are only upgraded together with their Cluster.
+func (v *Validator) ClusterLabelsUpdateValid(oldMachineDeployment capiv1alpha2.MachineDeployment, newMachineDeployment capiv1alpha2.MachineDeployment) error {
+	if oldMachineDeployment.GetLabels()[label.Release] == newMachineDeployment.GetLabels()[label.Release] &&
+		oldMachineDeployment.GetLabels()[label.ClusterOperatorVersion] == newMachineDeployment.GetLabels()[label.ClusterOperatorVersion] {
+		return nil
+	}
+	return v.ClusterLabelsMatch(newMachineDeployment)
+}
+
+// ReplicasValid checks that the node pool is only scaled within the scaling limits of its AWSMachineDeployment.
+// Node pools without AWSMachineDeployment are admitted.
+func (v *Validator) ReplicasValid(meta metav1.Object, replicas int) error {
+	awsMachineDeployment, err := aws.FetchAWSMachineDeployment(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, meta)
+	if aws.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	return aws.ValidateNodePoolReplicas(meta, "MachineDeployment", replicas, awsMachineDeployment)
+}
+
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (v *Validator) Log(keyVals ...interface{}) {
 	v.logger.Log(keyVals...)
 }
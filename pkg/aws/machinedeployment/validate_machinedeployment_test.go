@@ -9,6 +9,7 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
 
@@ -69,3 +70,82 @@ func TestValidateCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterLabelsMatch(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		release         string
+		clusterOperator string
+		allowed         bool
+	}{
+		{
+			// labels match the cluster
+			ctx:  context.Background(),
+			name: "case 0",
+
+			release:         "100.0.0",
+			clusterOperator: "1.2.3",
+			allowed:         true,
+		},
+		{
+			// release label does not match the cluster
+			ctx:  context.Background(),
+			name: "case 1",
+
+			release:         "101.0.0",
+			clusterOperator: "1.2.3",
+			allowed:         false,
+		},
+		{
+			// cluster-operator label does not match the cluster
+			ctx:  context.Background(),
+			name: "case 2",
+
+			release:         "100.0.0",
+			clusterOperator: "7.3.0",
+			allowed:         false,
+		},
+		{
+			// labels are missing and get defaulted
+			ctx:  context.Background(),
+			name: "case 3",
+
+			release:         "",
+			clusterOperator: "",
+			allowed:         true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			// create the cluster
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultCluster())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// try to create the machinedeployment
+			object := unittest.DefaultMachineDeployment()
+			labels := object.GetLabels()
+			labels[label.Release] = tc.release
+			labels[label.ClusterOperatorVersion] = tc.clusterOperator
+			object.SetLabels(labels)
+			err = validate.ClusterLabelsMatch(object)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package machinepool intercepts write activity to experimental MachinePool objects.
+package machinepool
+
+import (
+	"context"
+
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+
+	"github.com/giantswarm/aws-admission-controller/v2/config"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+)
+
+// Mutator for MachinePool object.
+type Mutator struct {
+	ctx       context.Context
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+}
+
+func NewMutator(config config.Config) (*Mutator, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	mutator := &Mutator{
+		ctx:       context.Background(),
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+	}
+
+	return mutator, nil
+}
+
+// Mutate is the function executed for every matching webhook request.
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
+	var result []mutator.PatchOperation
+
+	if request.DryRun != nil && *request.DryRun {
+		return result, nil
+	}
+	if request.Operation == admissionv1.Create {
+		return m.MutateCreate(request)
+	}
+	return result, nil
+}
+
+// MutateCreate is the function executed for every create webhook request.
+func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+	var patch []mutator.PatchOperation
+	var err error
+
+	// Parse incoming object
+	machinePool := &expcapiv1alpha3.MachinePool{}
+	if _, _, err := mutator.Deserializer.Decode(request.Object.Raw, nil, machinePool); err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse MachinePool: %v", err)
+	}
+	capi, err := aws.IsCAPIRelease(machinePool)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if capi {
+		return result, nil
+	}
+
+	patch, err = m.MutateClusterLabel(machinePool)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	patch, err = m.MutateLabelsFromCluster(*machinePool)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	return result, nil
+}
+
+// MutateClusterLabel defaults the cluster label from the cluster name in the spec, so that the
+// MachinePool can be related to its Cluster like all other Giant Swarm objects.
+// The labels of the given MachinePool are updated to reflect the patch.
+func (m *Mutator) MutateClusterLabel(machinePool *expcapiv1alpha3.MachinePool) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if key.Cluster(machinePool) != "" || machinePool.Spec.ClusterName == "" {
+		return result, nil
+	}
+
+	// The labels map has to exist before a single label can be added
+	if machinePool.GetLabels() == nil {
+		result = append(result, mutator.PatchAdd("/metadata/labels", map[string]string{}))
+		machinePool.SetLabels(map[string]string{})
+	}
+	patch, err := aws.MutateLabel(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, machinePool, label.Cluster, machinePool.Spec.ClusterName)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+	machinePool.Labels[label.Cluster] = machinePool.Spec.ClusterName
+
+	return result, nil
+}
+
+// MutateLabelsFromCluster propagates the release, operator and organization labels of the Cluster.
+func (m *Mutator) MutateLabelsFromCluster(machinePool expcapiv1alpha3.MachinePool) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+	var err error
+
+	if key.Release(&machinePool) != "" && key.ClusterOperator(&machinePool) != "" && key.Organization(&machinePool) != "" {
+		return result, nil
+	}
+	// Retrieve the `Cluster` CR related to this object.
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &machinePool)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, l := range []string{label.Release, label.ClusterOperatorVersion, label.Organization} {
+		patch, err := aws.MutateLabelFromCluster(&aws.Handler{Ctx: m.ctx, K8sClient: m.k8sClient, Logger: m.logger}, &machinePool, *cluster, l)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
+	}
+
+	return result, nil
+}
+
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
+func (m *Mutator) Log(keyVals ...interface{}) {
+	m.logger.Log(keyVals...)
+}
+
+func (m *Mutator) Resource() string {
+	return "machinepool"
+}
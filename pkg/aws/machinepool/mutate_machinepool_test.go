@@ -0,0 +1,86 @@
+package machinepool
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestMutateLabels(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		labels          map[string]string
+		expectedRelease string
+	}{
+		{
+			// all labels are propagated from the cluster
+			ctx:  context.Background(),
+			name: "case 0",
+
+			labels:          nil,
+			expectedRelease: unittest.DefaultReleaseVersion,
+		},
+		{
+			// existing labels are kept
+			ctx:  context.Background(),
+			name: "case 1",
+
+			labels: map[string]string{
+				label.Cluster: unittest.DefaultClusterID,
+				label.Release: "99.0.0",
+			},
+			expectedRelease: "",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Mutator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			cluster := unittest.DefaultCluster()
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			machinePool := unittest.DefaultMachinePool()
+			machinePool.SetLabels(tc.labels)
+
+			var patch []mutator.PatchOperation
+			var result []mutator.PatchOperation
+			patch, err = mutate.MutateClusterLabel(&machinePool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, patch...)
+			patch, err = mutate.MutateLabelsFromCluster(machinePool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, patch...)
+
+			release := ""
+			for _, p := range result {
+				if p.Path == "/metadata/labels/release.giantswarm.io~1version" {
+					release = p.Value.(string)
+				}
+			}
+			if release != tc.expectedRelease {
+				t.Fatalf("expected release label %#q but got %#q", tc.expectedRelease, release)
+			}
+		})
+	}
+}
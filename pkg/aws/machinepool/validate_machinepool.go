@@ -0,0 +1,241 @@
+package machinepool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+
+	"github.com/giantswarm/aws-admission-controller/v2/config"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
+)
+
+type Validator struct {
+	ctx       context.Context
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+
+	maxReplicas int
+	minReplicas int
+}
+
+func NewValidator(config config.Config) (*Validator, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.MachinePoolMinReplicas < 0 || config.MachinePoolMaxReplicas < config.MachinePoolMinReplicas {
+		return nil, microerror.Maskf(invalidConfigError, "%T.MachinePoolMaxReplicas must not be smaller than %T.MachinePoolMinReplicas", config, config)
+	}
+
+	validator := &Validator{
+		ctx:       context.Background(),
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		maxReplicas: config.MachinePoolMaxReplicas,
+		minReplicas: config.MachinePoolMinReplicas,
+	}
+
+	return validator, nil
+}
+
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
+	if request.Operation == admissionv1.Create {
+		return v.ValidateCreate(request)
+	}
+	if request.Operation == admissionv1.Update {
+		return v.ValidateUpdate(request)
+	}
+	return true, nil
+}
+
+func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var err error
+
+	var machinePool expcapiv1alpha3.MachinePool
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &machinePool); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse MachinePool: %v", err)
+	}
+	capi, err := aws.IsCAPIRelease(&machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	if capi {
+		return true, nil
+	}
+
+	err = v.ClusterNameValid(machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.ValidateCluster(machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = aws.ValidateOrganizationLabelContainsExistingOrganization(v.ctx, v.k8sClient.CtrlClient(), &machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.InfrastructureRefValid(machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.ReplicasValid(machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var err error
+
+	var machinePool expcapiv1alpha3.MachinePool
+	var oldMachinePool expcapiv1alpha3.MachinePool
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &machinePool); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse MachinePool: %v", err)
+	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &oldMachinePool); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old MachinePool: %v", err)
+	}
+	capi, err := aws.IsCAPIRelease(&machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	if capi {
+		return true, nil
+	}
+
+	err = v.InfrastructureRefUpdateValid(oldMachinePool, machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.ReplicasValid(machinePool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+// ClusterNameValid checks that the cluster name in the spec matches the cluster label.
+func (v *Validator) ClusterNameValid(machinePool expcapiv1alpha3.MachinePool) error {
+	if key.Cluster(&machinePool) == "" {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s has no %s label.",
+			machinePool.GetName(),
+			label.Cluster),
+		)
+	}
+	if machinePool.Spec.ClusterName != key.Cluster(&machinePool) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s cluster name '%s' does not match its %s label '%s'.",
+			machinePool.GetName(),
+			machinePool.Spec.ClusterName,
+			label.Cluster,
+			key.Cluster(&machinePool)),
+		)
+	}
+	return nil
+}
+
+// InfrastructureRefValid checks that the infrastructure reference points to an object in the same namespace.
+func (v *Validator) InfrastructureRefValid(machinePool expcapiv1alpha3.MachinePool) error {
+	ref := machinePool.Spec.Template.Spec.InfrastructureRef
+	if ref.Name == "" || ref.Kind == "" || ref.APIVersion == "" {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s infrastructure reference must have apiVersion, kind and name set.",
+			machinePool.GetName()),
+		)
+	}
+	if ref.Namespace != "" && ref.Namespace != machinePool.GetNamespace() {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s infrastructure reference %s %s/%s has to be in namespace %s.",
+			machinePool.GetName(),
+			ref.Kind,
+			ref.Namespace,
+			ref.Name,
+			machinePool.GetNamespace()),
+		)
+	}
+	return nil
+}
+
+// InfrastructureRefUpdateValid checks that the infrastructure reference is not changed.
+func (v *Validator) InfrastructureRefUpdateValid(oldMachinePool expcapiv1alpha3.MachinePool, newMachinePool expcapiv1alpha3.MachinePool) error {
+	oldRef := oldMachinePool.Spec.Template.Spec.InfrastructureRef
+	newRef := newMachinePool.Spec.Template.Spec.InfrastructureRef
+	if oldRef.APIVersion != newRef.APIVersion || oldRef.Kind != newRef.Kind || oldRef.Name != newRef.Name || oldRef.Namespace != newRef.Namespace {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s infrastructure reference can not be changed from %s %s to %s %s.",
+			newMachinePool.GetName(),
+			oldRef.Kind,
+			oldRef.Name,
+			newRef.Kind,
+			newRef.Name),
+		)
+	}
+	if oldMachinePool.Spec.ClusterName != newMachinePool.Spec.ClusterName {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s cluster name can not be changed.",
+			newMachinePool.GetName()),
+		)
+	}
+	return nil
+}
+
+// ReplicasValid checks that the desired number of replicas is within the configured bounds.
+func (v *Validator) ReplicasValid(machinePool expcapiv1alpha3.MachinePool) error {
+	// The number of replicas is defaulted to 1 by Cluster API.
+	if machinePool.Spec.Replicas == nil {
+		return nil
+	}
+	replicas := int(*machinePool.Spec.Replicas)
+	if replicas < v.minReplicas || replicas > v.maxReplicas {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool %s replicas %d are not valid. The number of replicas has to be between %d and %d.",
+			machinePool.GetName(),
+			replicas,
+			v.minReplicas,
+			v.maxReplicas),
+		)
+	}
+	return nil
+}
+
+func (v *Validator) ValidateCluster(machinePool expcapiv1alpha3.MachinePool) error {
+	var err error
+
+	// Retrieve the `Cluster` CR related to this object.
+	cluster, err := aws.FetchCluster(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger}, &machinePool)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	// make sure the cluster is not deleted
+	if cluster.DeletionTimestamp != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("MachinePool could not be created because Cluster '%s' is in deleting state.",
+			cluster.Name),
+		)
+	}
+	return nil
+}
+
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
+func (v *Validator) Log(keyVals ...interface{}) {
+	v.logger.Log(keyVals...)
+}
+
+func (v *Validator) Resource() string {
+	return "machinepool"
+}
@@ -0,0 +1,165 @@
+package machinepool
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestReplicasValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		replicas *int32
+		valid    bool
+	}{
+		{
+			// replicas not set
+			name: "case 0",
+
+			replicas: nil,
+			valid:    true,
+		},
+		{
+			// replicas within bounds
+			name: "case 1",
+
+			replicas: int32P(5),
+			valid:    true,
+		},
+		{
+			// replicas below minimum
+			name: "case 2",
+
+			replicas: int32P(0),
+			valid:    false,
+		},
+		{
+			// replicas above maximum
+			name: "case 3",
+
+			replicas: int32P(11),
+			valid:    false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				k8sClient:   unittest.FakeK8sClient(),
+				logger:      microloggertest.New(),
+				maxReplicas: 10,
+				minReplicas: 1,
+			}
+
+			machinePool := unittest.DefaultMachinePool()
+			machinePool.Spec.Replicas = tc.replicas
+
+			err := validate.ReplicasValid(machinePool)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestInfrastructureRefValid(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		refName      string
+		refNamespace string
+		oldRefName   string
+		clusterName  string
+		valid        bool
+	}{
+		{
+			// valid reference
+			name: "case 0",
+
+			refName:     "mp7x2",
+			oldRefName:  "mp7x2",
+			clusterName: unittest.DefaultClusterID,
+			valid:       true,
+		},
+		{
+			// reference without name
+			name: "case 1",
+
+			refName:     "",
+			oldRefName:  "",
+			clusterName: unittest.DefaultClusterID,
+			valid:       false,
+		},
+		{
+			// reference to another namespace
+			name: "case 2",
+
+			refName:      "mp7x2",
+			refNamespace: "other",
+			oldRefName:   "mp7x2",
+			clusterName:  unittest.DefaultClusterID,
+			valid:        false,
+		},
+		{
+			// reference changed
+			name: "case 3",
+
+			refName:     "mp7x2",
+			oldRefName:  "ab1c3",
+			clusterName: unittest.DefaultClusterID,
+			valid:       false,
+		},
+		{
+			// cluster name does not match the cluster label
+			name: "case 4",
+
+			refName:     "mp7x2",
+			oldRefName:  "mp7x2",
+			clusterName: "other",
+			valid:       false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			oldMachinePool := unittest.DefaultMachinePool()
+			oldMachinePool.Spec.Template.Spec.InfrastructureRef.Name = tc.oldRefName
+			oldMachinePool.Spec.ClusterName = tc.clusterName
+
+			machinePool := unittest.DefaultMachinePool()
+			machinePool.Spec.Template.Spec.InfrastructureRef.Name = tc.refName
+			machinePool.Spec.Template.Spec.InfrastructureRef.Namespace = tc.refNamespace
+			machinePool.Spec.ClusterName = tc.clusterName
+
+			err = validate.ClusterNameValid(machinePool)
+			if err == nil {
+				err = validate.InfrastructureRefValid(machinePool)
+			}
+			if err == nil {
+				err = validate.InfrastructureRefUpdateValid(oldMachinePool, machinePool)
+			}
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func int32P(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,117 @@
+// Package networkpool intercepts write activity to NetworkPool objects.
+package networkpool
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/config"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+)
+
+// Mutator for NetworkPool object.
+type Mutator struct {
+	ctx       context.Context
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+}
+
+func NewMutator(config config.Config) (*Mutator, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	mutator := &Mutator{
+		ctx:       context.Background(),
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+	}
+
+	return mutator, nil
+}
+
+// Mutate is the function executed for every matching webhook request.
+func (m *Mutator) Mutate(ctx context.Context, request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	m = m.withContext(ctx)
+	var result []mutator.PatchOperation
+
+	if request.DryRun != nil && *request.DryRun {
+		return result, nil
+	}
+	if request.Operation == admissionv1.Create || request.Operation == admissionv1.Update {
+		return m.MutateCIDRBlock(request)
+	}
+	return result, nil
+}
+
+// MutateCIDRBlock is the function executed for every create and update webhook request.
+func (m *Mutator) MutateCIDRBlock(request *admissionv1.AdmissionRequest) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+	var patch []mutator.PatchOperation
+	var err error
+
+	// Parse incoming object
+	networkPool := &infrastructurev1alpha2.NetworkPool{}
+	if _, _, err := mutator.Deserializer.Decode(request.Object.Raw, nil, networkPool); err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse NetworkPool: %v", err)
+	}
+
+	patch, err = m.MutateCIDRNotation(networkPool)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	return result, nil
+}
+
+// MutateCIDRNotation normalizes the CIDR block to its canonical notation, e.g. 10.1.2.3/16 becomes 10.1.0.0/16.
+// The CIDR block of the given NetworkPool is updated to reflect the patch. Invalid CIDR blocks are left to the
+// validator.
+func (m *Mutator) MutateCIDRNotation(networkPool *infrastructurev1alpha2.NetworkPool) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	_, ipNet, err := net.ParseCIDR(strings.ToLower(strings.TrimSpace(networkPool.Spec.CIDRBlock)))
+	if err != nil {
+		return result, nil
+	}
+	if ipNet.String() == networkPool.Spec.CIDRBlock {
+		return result, nil
+	}
+
+	m.Log("level", "debug", "message", fmt.Sprintf("NetworkPool %s CIDR block %s is normalized to %s",
+		networkPool.GetName(),
+		networkPool.Spec.CIDRBlock,
+		ipNet.String()),
+	)
+	result = append(result, mutator.PatchReplace("/spec/cidrBlock", ipNet.String()))
+	networkPool.Spec.CIDRBlock = ipNet.String()
+
+	return result, nil
+}
+
+// withContext returns a copy of the mutator which makes its API calls with the context of a single admission request.
+func (m *Mutator) withContext(ctx context.Context) *Mutator {
+	c := *m
+	c.ctx = ctx
+	return &c
+}
+
+func (m *Mutator) Log(keyVals ...interface{}) {
+	m.logger.Log(keyVals...)
+}
+
+func (m *Mutator) Resource() string {
+	return "networkpool"
+}
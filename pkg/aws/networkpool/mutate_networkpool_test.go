@@ -0,0 +1,74 @@
+package networkpool
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestNetworkPoolCIDRBlock(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cidrBlock string
+
+		expectedCIDR string
+	}{
+		{
+			// canonical CIDR block is kept
+			name:      "case 0",
+			cidrBlock: "10.1.0.0/16",
+		},
+		{
+			// host bits are stripped
+			name:      "case 1",
+			cidrBlock: "10.1.2.3/16",
+
+			expectedCIDR: "10.1.0.0/16",
+		},
+		{
+			// public ranges are normalized and left to the validator
+			name:      "case 2",
+			cidrBlock: "100.64.1.0/16",
+
+			expectedCIDR: "100.64.0.0/16",
+		},
+		{
+			// invalid CIDR block is left to the validator
+			name:      "case 3",
+			cidrBlock: "10.1.0.0",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.cidrBlock)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			patch, err := mutate.Mutate(context.Background(), &request)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			var cidrBlock string
+			for _, p := range patch {
+				if p.Path == "/spec/cidrBlock" {
+					cidrBlock = p.Value.(string)
+				}
+			}
+			if cidrBlock != tc.expectedCIDR {
+				t.Fatalf("expected CIDR block %#q but got %#q", tc.expectedCIDR, cidrBlock)
+			}
+		})
+	}
+}
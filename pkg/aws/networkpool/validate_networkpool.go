@@ -4,25 +4,30 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"time"
+	"strings"
 
 	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
-	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
 
 	"github.com/giantswarm/aws-admission-controller/v2/config"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/validator"
 )
 
 type Validator struct {
+	ctx                      context.Context
 	dockerCIDR               string
 	ipamNetworkCIDR          string
 	k8sClient                k8sclient.Interface
 	kubernetesClusterIPRange string
 	logger                   micrologger.Logger
+	podCIDRBlock             string
+	prefixMax                int
+	prefixMin                int
+	reservedCIDRs            []string
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -41,78 +46,321 @@ func NewValidator(config config.Config) (*Validator, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.NetworkPoolPrefixMax > 0 && config.NetworkPoolPrefixMin > config.NetworkPoolPrefixMax {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NetworkPoolPrefixMin must not be greater than %T.NetworkPoolPrefixMax", config, config)
+	}
+
+	var podCIDRBlock string
+	if config.PodSubnet != "" && config.PodCIDR != "" {
+		podCIDRBlock = fmt.Sprintf("%s/%s", config.PodSubnet, config.PodCIDR)
+	}
+	var reservedCIDRs []string
+	for _, cidr := range strings.Split(config.ReservedCIDRs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.ReservedCIDRs contains invalid CIDR %#q", config, cidr)
+		}
+		reservedCIDRs = append(reservedCIDRs, cidr)
+	}
 
 	validator := &Validator{
+		ctx:                      context.Background(),
 		dockerCIDR:               config.DockerCIDR,
 		ipamNetworkCIDR:          config.IPAMNetworkCIDR,
 		k8sClient:                config.K8sClient,
 		kubernetesClusterIPRange: config.KubernetesClusterIPRange,
 		logger:                   config.Logger,
+		podCIDRBlock:             podCIDRBlock,
+		prefixMax:                config.NetworkPoolPrefixMax,
+		prefixMin:                config.NetworkPoolPrefixMin,
+		reservedCIDRs:            reservedCIDRs,
 	}
 
 	return validator, nil
 }
 
-func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+func (v *Validator) Validate(ctx context.Context, request *admissionv1.AdmissionRequest) (bool, error) {
+	v = v.withContext(ctx)
+	if request.Operation == admissionv1.Update {
+		return v.ValidateUpdate(request)
+	}
+	if request.Operation == admissionv1.Create {
+		return v.ValidateCreate(request)
+	}
+	return true, nil
+}
+
+func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var networkPool infrastructurev1alpha2.NetworkPool
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &networkPool); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse networkpool: %v", err)
 	}
+
+	err = v.NetworkPoolOverlapValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.PrefixLengthValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.PrivateCIDRValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	err = v.networkPoolAllowed(networkPool)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.ClusterOverlapValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	return true, nil
 }
 
-func (v *Validator) networkPoolAllowed(np infrastructurev1alpha2.NetworkPool) error {
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var networkPool infrastructurev1alpha2.NetworkPool
+	var networkPoolOld infrastructurev1alpha2.NetworkPool
 	var err error
-	var fetch func() error
-	var networkCIDRs []string
-	var networkPoolList infrastructurev1alpha2.NetworkPoolList
-
-	// Fetch all NetworkPools.
-	{
-		v.Log("level", "debug", "message", "Fetching all NetworkPools")
-		fetch = func() error {
-			ctx := context.Background()
-
-			err = v.k8sClient.CtrlClient().List(
-				ctx,
-				&networkPoolList,
+
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &networkPool); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse networkpool: %v", err)
+	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &networkPoolOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old networkpool: %v", err)
+	}
+
+	// Only changes of the CIDR block are checked, so that metadata of existing pools can still be updated.
+	if networkPool.Spec.CIDRBlock == networkPoolOld.Spec.CIDRBlock {
+		return true, nil
+	}
+
+	err = v.AllocatedCIDRUpdateValid(networkPoolOld, networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.NetworkPoolOverlapValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.PrefixLengthValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.PrivateCIDRValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.networkPoolAllowed(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.ClusterOverlapValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+// NetworkPoolOverlapValid checks that the CIDR block of the NetworkPool does not overlap with the CIDR block of any
+// other NetworkPool, since clusters allocated from overlapping pools would get colliding VPC ranges.
+func (v *Validator) NetworkPoolOverlapValid(np infrastructurev1alpha2.NetworkPool) error {
+	_, poolNet, err := net.ParseCIDR(np.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s is not a valid CIDR.",
+			np.GetName(),
+			np.Spec.CIDRBlock),
+		)
+	}
+
+	networkPools, err := aws.FetchNetworkPools(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, other := range networkPools {
+		// the pool itself is skipped when updating, e.g. when extending the IP range
+		if other.GetName() == np.GetName() && other.GetNamespace() == np.GetNamespace() {
+			continue
+		}
+		if other.GetDeletionTimestamp() != nil {
+			continue
+		}
+		_, otherNet, err := net.ParseCIDR(other.Spec.CIDRBlock)
+		if err != nil {
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("NetworkPool %s has an invalid CIDR block %s, skipping it", other.GetName(), other.Spec.CIDRBlock))
+			continue
+		}
+		if aws.CIDRsIntersect(poolNet, otherNet) {
+			return microerror.Maskf(intersectFailedError, fmt.Sprintf("NetworkPool %s CIDR block %s overlaps with the CIDR block %s of NetworkPool %s in namespace %s.",
+				np.GetName(),
+				np.Spec.CIDRBlock,
+				other.Spec.CIDRBlock,
+				other.GetName(),
+				other.GetNamespace()),
 			)
-			if err != nil {
-				return microerror.Maskf(notFoundError, "failed to fetch NetworkPools: %v", err)
-			}
+		}
+	}
 
-			return nil
+	return nil
+}
+
+// AllocatedCIDRUpdateValid checks that the CIDR block of a NetworkPool which clusters have been allocated from is only
+// grown, so that the new CIDR block still covers the old one and all allocated cluster networks.
+func (v *Validator) AllocatedCIDRUpdateValid(oldNP infrastructurev1alpha2.NetworkPool, newNP infrastructurev1alpha2.NetworkPool) error {
+	awsClusters, err := aws.FetchAWSClusters(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	var allocations []string
+	for _, awsCluster := range awsClusters {
+		if awsCluster.Spec.Provider.Nodes.NetworkPool != newNP.GetName() || awsCluster.GetNamespace() != newNP.GetNamespace() {
+			continue
 		}
+		if cidr := awsCluster.Status.Provider.Network.CIDR; cidr != "" {
+			allocations = append(allocations, cidr)
+		}
+	}
+	if len(allocations) == 0 {
+		return nil
 	}
 
-	{
-		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
-		err = backoff.Retry(fetch, b)
-		if IsNotFound(err) {
-			v.Log("level", "debug", "message", fmt.Sprintf("No NetworkPool could be found: %v", err))
-		} else if err != nil {
-			return microerror.Mask(err)
+	_, newNet, err := net.ParseCIDR(newNP.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s is not a valid CIDR.",
+			newNP.GetName(),
+			newNP.Spec.CIDRBlock),
+		)
+	}
+	_, oldNet, err := net.ParseCIDR(oldNP.Spec.CIDRBlock)
+	if err == nil && !aws.CIDRContains(newNet, oldNet) {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block can not be changed from %s to %s because %d cluster networks have been allocated from it. The CIDR block can only be grown to a range covering %s.",
+			newNP.GetName(),
+			oldNP.Spec.CIDRBlock,
+			newNP.Spec.CIDRBlock,
+			len(allocations),
+			oldNP.Spec.CIDRBlock),
+		)
+	}
+	for _, cidr := range allocations {
+		_, clusterNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("NetworkPool %s allocation %s is not a valid CIDR, skipping it", newNP.GetName(), cidr))
+			continue
+		}
+		if !aws.CIDRContains(newNet, clusterNet) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s does not cover the allocated cluster network %s.",
+				newNP.GetName(),
+				newNP.Spec.CIDRBlock,
+				cidr),
+			)
 		}
 	}
 
-	// append all CIDRs from existing NetworkPools
-	for _, networkPool := range networkPoolList.Items {
-		// we do not want to add the same networkpool when updating, e.g. when extending the IP range
-		if networkPool.Name == np.Name && networkPool.Namespace == np.Namespace {
+	return nil
+}
+
+// PrefixLengthValid checks that the size of the CIDR block of the NetworkPool is within the configured bounds, so
+// that it fits at least one cluster network and does not claim an excessive part of the address space.
+func (v *Validator) PrefixLengthValid(np infrastructurev1alpha2.NetworkPool) error {
+	_, poolNet, err := net.ParseCIDR(np.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	ones, _ := poolNet.Mask.Size()
+
+	if v.prefixMax > 0 && ones > v.prefixMax {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s is too small. Its prefix length must be at most /%d.",
+			np.GetName(),
+			np.Spec.CIDRBlock,
+			v.prefixMax),
+		)
+	}
+	if v.prefixMin > 0 && ones < v.prefixMin {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s is too large. Its prefix length must be at least /%d.",
+			np.GetName(),
+			np.Spec.CIDRBlock,
+			v.prefixMin),
+		)
+	}
+
+	return nil
+}
+
+// PrivateCIDRValid checks that the CIDR block is within the RFC 1918 private address ranges, unless the
+// NetworkPool is explicitly allowed to use a public range.
+func (v *Validator) PrivateCIDRValid(networkPool infrastructurev1alpha2.NetworkPool) error {
+	_, ipNet, err := net.ParseCIDR(networkPool.Spec.CIDRBlock)
+	if err != nil {
+		return nil
+	}
+	if aws.IsPrivateCIDR(ipNet) || aws.IsAnnotationTrue(&networkPool, aws.AnnotationAllowPublicCIDR) {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s is outside the RFC 1918 private address ranges 10.0.0.0/8, 172.16.0.0/12 and 192.168.0.0/16. Set the %s annotation to \"true\" to use it anyway.",
+		networkPool.GetName(),
+		networkPool.Spec.CIDRBlock,
+		aws.AnnotationAllowPublicCIDR),
+	)
+}
+
+// ClusterOverlapValid checks that the CIDR block of the NetworkPool does not overlap with the network CIDR of any
+// AWSCluster which is not allocated from this NetworkPool.
+func (v *Validator) ClusterOverlapValid(np infrastructurev1alpha2.NetworkPool) error {
+	_, poolNet, err := net.ParseCIDR(np.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	awsClusters, err := aws.FetchAWSClusters(&aws.Handler{Ctx: v.ctx, K8sClient: v.k8sClient, Logger: v.logger})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, awsCluster := range awsClusters {
+		if awsCluster.Spec.Provider.Nodes.NetworkPool == np.GetName() && awsCluster.GetNamespace() == np.GetNamespace() {
+			continue
+		}
+		cidr := awsCluster.Status.Provider.Network.CIDR
+		if cidr == "" {
 			continue
 		}
-		networkCIDRs = append(networkCIDRs, networkPool.Spec.CIDRBlock)
+		_, clusterNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			v.logger.Log("level", "debug", "message", fmt.Sprintf("AWSCluster %s has an invalid network CIDR %s, skipping it", awsCluster.GetName(), cidr))
+			continue
+		}
+		if aws.CIDRsIntersect(poolNet, clusterNet) {
+			return microerror.Maskf(intersectFailedError, fmt.Sprintf("NetworkPool %s CIDR block %s overlaps with the network CIDR %s of cluster %s.",
+				np.GetName(),
+				np.Spec.CIDRBlock,
+				cidr,
+				awsCluster.GetName()),
+			)
+		}
 	}
 
+	return nil
+}
+
+// networkPoolAllowed checks that the CIDR block of the NetworkPool does not overlap with the reserved ranges of the
+// installation: the Docker CIDR, the Kubernetes cluster IP range, the tenant cluster CIDR, the default pod CIDR and
+// the additionally configured reserved CIDRs like the management cluster VPC.
+func (v *Validator) networkPoolAllowed(np infrastructurev1alpha2.NetworkPool) error {
 	// append Docker CIDR, Kubernetes cluster IP range and tenant cluster CIDR
-	networkCIDRs = append(networkCIDRs, v.dockerCIDR, v.ipamNetworkCIDR, v.kubernetesClusterIPRange)
+	networkCIDRs := []string{v.dockerCIDR, v.ipamNetworkCIDR, v.kubernetesClusterIPRange}
+	if v.podCIDRBlock != "" {
+		networkCIDRs = append(networkCIDRs, v.podCIDRBlock)
+	}
+	networkCIDRs = append(networkCIDRs, v.reservedCIDRs...)
 
 	// parse CIDRBlock from NetworkPool
 	customNet, err := mustParseCIDR(np.Spec.CIDRBlock)
@@ -135,6 +383,13 @@ func (v *Validator) networkPoolAllowed(np infrastructurev1alpha2.NetworkPool) er
 	return nil
 }
 
+// withContext returns a copy of the validator which makes its API calls with the context of a single admission request.
+func (v *Validator) withContext(ctx context.Context) *Validator {
+	c := *v
+	c.ctx = ctx
+	return &c
+}
+
 func (v *Validator) Log(keyVals ...interface{}) {
 	v.logger.Log(keyVals...)
 }
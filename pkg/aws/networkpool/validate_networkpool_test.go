@@ -2,11 +2,14 @@ package networkpool
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"testing"
 
 	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/aws"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
 )
 
@@ -109,7 +112,476 @@ func TestNetworkPool(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			allowed, err := validate.Validate(&request)
+			allowed, err := validate.Validate(tc.ctx, &request)
+			if tc.allowed != allowed {
+				t.Fatalf("expected %v to not to differ from %v: %v", allowed, tc.allowed, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPoolUpdate(t *testing.T) {
+	testCases := []struct {
+		name             string
+		ctx              context.Context
+		oldCIDR          string
+		newCIDR          string
+		networkPoolCIDRs []string
+
+		allowed bool
+	}{
+		{
+			// CIDR block is extended without intersection
+			name:             "case 0",
+			ctx:              context.Background(),
+			oldCIDR:          "192.168.178.0/24",
+			newCIDR:          "192.168.176.0/22",
+			networkPoolCIDRs: []string{"192.168.180.0/24"},
+
+			allowed: true,
+		},
+		{
+			// CIDR block is extended into another NetworkPool
+			name:             "case 1",
+			ctx:              context.Background(),
+			oldCIDR:          "192.168.178.0/24",
+			newCIDR:          "192.168.176.0/21",
+			networkPoolCIDRs: []string{"192.168.180.0/24"},
+
+			allowed: false,
+		},
+		{
+			// CIDR block is unchanged
+			name:             "case 2",
+			ctx:              context.Background(),
+			oldCIDR:          "192.168.180.0/23",
+			newCIDR:          "192.168.180.0/23",
+			networkPoolCIDRs: []string{"192.168.180.0/24"},
+
+			allowed: true,
+		},
+		{
+			// CIDR block is not valid
+			name:    "case 3",
+			ctx:     context.Background(),
+			oldCIDR: "192.168.178.0/24",
+			newCIDR: "192.168.178.0/33",
+
+			allowed: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				dockerCIDR:               "172.18.224.1/19",
+				ipamNetworkCIDR:          "10.0.0.0/16",
+				k8sClient:                fakeK8sClient,
+				kubernetesClusterIPRange: "10.35.0.0/17",
+				logger:                   microloggertest.New(),
+			}
+
+			// create NetworkPools
+			for _, networkPoolCIDR := range tc.networkPoolCIDRs {
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultNetworkPool(networkPoolCIDR))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// simulate a admission request for NetworkPool update
+			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.newCIDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRequest, err := unittest.DefaultAdmissionRequestNetworkPool(tc.oldCIDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Operation = admissionv1.Update
+			request.OldObject = oldRequest.Object
+
+			allowed, err := validate.Validate(tc.ctx, &request)
+			if tc.allowed != allowed {
+				t.Fatalf("expected %v to not to differ from %v: %v", allowed, tc.allowed, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPoolReservedRanges(t *testing.T) {
+	testCases := []struct {
+		name              string
+		ctx               context.Context
+		customNetworkCIDR string
+		clusterCIDR       string
+		clusterPool       string
+		podCIDRBlock      string
+		reservedCIDRs     []string
+
+		allowed bool
+	}{
+		{
+			// No intersection
+			name:              "case 0",
+			ctx:               context.Background(),
+			customNetworkCIDR: "192.168.0.0/20",
+			clusterCIDR:       "10.1.0.0/24",
+			podCIDRBlock:      "100.64.0.0/16",
+			reservedCIDRs:     []string{"10.100.0.0/16"},
+
+			allowed: true,
+		},
+		{
+			// Intersection with the network CIDR of a cluster
+			name:              "case 1",
+			ctx:               context.Background(),
+			customNetworkCIDR: "10.1.0.0/16",
+			clusterCIDR:       "10.1.0.0/24",
+
+			allowed: false,
+		},
+		{
+			// Intersection with the network CIDR of a cluster allocated from this NetworkPool
+			name:              "case 2",
+			ctx:               context.Background(),
+			customNetworkCIDR: "10.1.0.0/16",
+			clusterCIDR:       "10.1.0.0/24",
+			clusterPool:       "pool",
+
+			allowed: true,
+		},
+		{
+			// Intersection with the default pod CIDR
+			name:              "case 3",
+			ctx:               context.Background(),
+			customNetworkCIDR: "100.64.128.0/20",
+			podCIDRBlock:      "100.64.0.0/16",
+
+			allowed: false,
+		},
+		{
+			// Intersection with the management cluster VPC
+			name:              "case 4",
+			ctx:               context.Background(),
+			customNetworkCIDR: "10.100.0.0/24",
+			reservedCIDRs:     []string{"10.100.0.0/16"},
+
+			allowed: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				dockerCIDR:               "172.18.224.1/19",
+				ipamNetworkCIDR:          "10.0.0.0/16",
+				k8sClient:                fakeK8sClient,
+				kubernetesClusterIPRange: "10.35.0.0/17",
+				logger:                   microloggertest.New(),
+				podCIDRBlock:             tc.podCIDRBlock,
+				reservedCIDRs:            tc.reservedCIDRs,
+			}
+
+			networkPool := unittest.DefaultNetworkPool(tc.customNetworkCIDR)
+			networkPool.SetName("pool")
+
+			// create AWSCluster
+			if tc.clusterCIDR != "" {
+				awsCluster := unittest.DefaultAWSCluster()
+				awsCluster.SetNamespace(networkPool.GetNamespace())
+				awsCluster.Status.Provider.Network.CIDR = tc.clusterCIDR
+				awsCluster.Spec.Provider.Nodes.NetworkPool = tc.clusterPool
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// simulate a admission request for NetworkPool creation
+			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.customNetworkCIDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Object.Raw, err = json.Marshal(networkPool)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			allowed, err := validate.Validate(tc.ctx, &request)
+			if tc.allowed != allowed {
+				t.Fatalf("expected %v to not to differ from %v: %v", allowed, tc.allowed, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPoolPrefixLength(t *testing.T) {
+	testCases := []struct {
+		name              string
+		customNetworkCIDR string
+		prefixMax         int
+		prefixMin         int
+
+		allowed bool
+	}{
+		{
+			// prefix length within the bounds
+			name:              "case 0",
+			customNetworkCIDR: "192.168.0.0/16",
+			prefixMax:         24,
+			prefixMin:         8,
+
+			allowed: true,
+		},
+		{
+			// pool is too small to fit a cluster network
+			name:              "case 1",
+			customNetworkCIDR: "192.168.0.0/26",
+			prefixMax:         24,
+			prefixMin:         8,
+
+			allowed: false,
+		},
+		{
+			// pool is too large
+			name:              "case 2",
+			customNetworkCIDR: "64.0.0.0/4",
+			prefixMax:         24,
+			prefixMin:         8,
+
+			allowed: false,
+		},
+		{
+			// bounds are disabled
+			name:              "case 3",
+			customNetworkCIDR: "192.168.0.0/26",
+
+			allowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				dockerCIDR:               "172.18.224.1/19",
+				ipamNetworkCIDR:          "10.0.0.0/16",
+				k8sClient:                unittest.FakeK8sClient(),
+				kubernetesClusterIPRange: "10.35.0.0/17",
+				logger:                   microloggertest.New(),
+				prefixMax:                tc.prefixMax,
+				prefixMin:                tc.prefixMin,
+			}
+
+			// simulate a admission request for NetworkPool creation
+			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.customNetworkCIDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			allowed, err := validate.Validate(context.Background(), &request)
+			if tc.allowed != allowed {
+				t.Fatalf("expected %v to not to differ from %v: %v", allowed, tc.allowed, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPoolPrivateCIDR(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cidrBlock   string
+		oldCIDR     string
+		annotations map[string]string
+
+		allowed bool
+	}{
+		{
+			// private range
+			name:      "case 0",
+			cidrBlock: "192.168.0.0/16",
+
+			allowed: true,
+		},
+		{
+			// public range is rejected
+			name:      "case 1",
+			cidrBlock: "100.64.0.0/16",
+
+			allowed: false,
+		},
+		{
+			// public range is allowed with the override annotation
+			name:        "case 2",
+			cidrBlock:   "100.64.0.0/16",
+			annotations: map[string]string{aws.AnnotationAllowPublicCIDR: "true"},
+
+			allowed: true,
+		},
+		{
+			// range is only partially private
+			name:      "case 3",
+			cidrBlock: "172.0.0.0/8",
+
+			allowed: false,
+		},
+		{
+			// existing public range with an unchanged CIDR block
+			name:      "case 4",
+			cidrBlock: "100.64.0.0/16",
+			oldCIDR:   "100.64.0.0/16",
+
+			allowed: true,
+		},
+		{
+			// existing range is changed to a public range
+			name:      "case 5",
+			cidrBlock: "100.64.0.0/16",
+			oldCIDR:   "192.168.0.0/16",
+
+			allowed: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				dockerCIDR:               "172.18.224.1/19",
+				ipamNetworkCIDR:          "10.0.0.0/16",
+				k8sClient:                unittest.FakeK8sClient(),
+				kubernetesClusterIPRange: "10.35.0.0/17",
+				logger:                   microloggertest.New(),
+			}
+
+			networkPool := unittest.DefaultNetworkPool(tc.cidrBlock)
+			networkPool.SetAnnotations(tc.annotations)
+			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.cidrBlock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Object.Raw, err = json.Marshal(networkPool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.oldCIDR != "" {
+				oldRequest, err := unittest.DefaultAdmissionRequestNetworkPool(tc.oldCIDR)
+				if err != nil {
+					t.Fatal(err)
+				}
+				request.Operation = admissionv1.Update
+				request.OldObject = oldRequest.Object
+			}
+
+			allowed, err := validate.Validate(context.Background(), &request)
+			if tc.allowed != allowed {
+				t.Fatalf("expected %v to not to differ from %v: %v", allowed, tc.allowed, err)
+			}
+		})
+	}
+}
+
+func TestNetworkPoolAllocatedUpdate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		oldCIDR     string
+		newCIDR     string
+		clusterCIDR string
+
+		allowed bool
+	}{
+		{
+			// CIDR block is grown and covers the allocation
+			name:        "case 0",
+			ctx:         context.Background(),
+			oldCIDR:     "192.168.16.0/20",
+			newCIDR:     "192.168.0.0/18",
+			clusterCIDR: "192.168.16.0/24",
+
+			allowed: true,
+		},
+		{
+			// CIDR block is shrunk
+			name:        "case 1",
+			ctx:         context.Background(),
+			oldCIDR:     "192.168.16.0/20",
+			newCIDR:     "192.168.16.0/22",
+			clusterCIDR: "192.168.16.0/24",
+
+			allowed: false,
+		},
+		{
+			// CIDR block is moved
+			name:        "case 2",
+			ctx:         context.Background(),
+			oldCIDR:     "192.168.16.0/20",
+			newCIDR:     "192.168.32.0/20",
+			clusterCIDR: "192.168.16.0/24",
+
+			allowed: false,
+		},
+		{
+			// CIDR block without allocations is moved
+			name:    "case 3",
+			ctx:     context.Background(),
+			oldCIDR: "192.168.16.0/20",
+			newCIDR: "192.168.32.0/20",
+
+			allowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				dockerCIDR:               "172.18.224.1/19",
+				ipamNetworkCIDR:          "10.0.0.0/16",
+				k8sClient:                fakeK8sClient,
+				kubernetesClusterIPRange: "10.35.0.0/17",
+				logger:                   microloggertest.New(),
+			}
+
+			oldNetworkPool := unittest.DefaultNetworkPool(tc.oldCIDR)
+			oldNetworkPool.SetName("pool")
+			newNetworkPool := oldNetworkPool.DeepCopy()
+			newNetworkPool.Spec.CIDRBlock = tc.newCIDR
+
+			// create AWSCluster allocated from the NetworkPool
+			if tc.clusterCIDR != "" {
+				awsCluster := unittest.DefaultAWSCluster()
+				awsCluster.SetNamespace(oldNetworkPool.GetNamespace())
+				awsCluster.Status.Provider.Network.CIDR = tc.clusterCIDR
+				awsCluster.Spec.Provider.Nodes.NetworkPool = oldNetworkPool.GetName()
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// simulate a admission request for NetworkPool update
+			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.newCIDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Operation = admissionv1.Update
+			request.Object.Raw, err = json.Marshal(newNetworkPool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.OldObject.Raw, err = json.Marshal(oldNetworkPool)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			allowed, err := validate.Validate(tc.ctx, &request)
 			if tc.allowed != allowed {
 				t.Fatalf("expected %v to not to differ from %v: %v", allowed, tc.allowed, err)
 			}
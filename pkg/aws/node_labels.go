@@ -0,0 +1,147 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// NodeLabelsMaxEntries is the maximum number of node labels or taints declared in an annotation, since they are
+	// passed to the kubelet of every node of the node pool.
+	NodeLabelsMaxEntries = 20
+)
+
+// reservedNodeLabelPrefixes are the prefixes of node labels and taints which are managed by Kubernetes itself.
+var reservedNodeLabelPrefixes = []string{
+	"kubernetes.io/",
+	"node-role.kubernetes.io/",
+}
+
+// ParseNodeLabels parses a comma separated list of node labels like "key=value,other=value". Keys and values must be
+// valid Kubernetes label keys and values and keys must not use a reserved prefix.
+func ParseNodeLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	entries := strings.Split(value, ",")
+	if len(entries) > NodeLabelsMaxEntries {
+		return nil, microerror.Maskf(notAllowedError, "%d node labels are declared, but at most %d are allowed.", len(entries), NodeLabelsMaxEntries)
+	}
+	for _, entry := range entries {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			return nil, microerror.Maskf(notAllowedError, "Node label %#q is not valid. Node labels have the format key=value.", entry)
+		}
+		err := validateNodeLabelKey(parts[0])
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if errs := validation.IsValidLabelValue(parts[1]); len(errs) > 0 {
+			return nil, microerror.Maskf(notAllowedError, "Node label %#q value is not valid: %s", parts[0], strings.Join(errs, ", "))
+		}
+		if _, ok := labels[parts[0]]; ok {
+			return nil, microerror.Maskf(notAllowedError, "Node label %#q is declared more than once.", parts[0])
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
+// ParseNodeTaints parses a comma separated list of node taints like "key=value:NoSchedule,other:NoExecute". Keys and
+// values follow the syntax of label keys and values, keys must not use a reserved prefix and the effect must be one
+// of the Kubernetes taint effects.
+func ParseNodeTaints(value string) ([]corev1.Taint, error) {
+	var taints []corev1.Taint
+	entries := strings.Split(value, ",")
+	if len(entries) > NodeLabelsMaxEntries {
+		return nil, microerror.Maskf(notAllowedError, "%d node taints are declared, but at most %d are allowed.", len(entries), NodeLabelsMaxEntries)
+	}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		i := strings.LastIndex(entry, ":")
+		if i < 0 {
+			return nil, microerror.Maskf(notAllowedError, "Node taint %#q is not valid. Node taints have the format key=value:effect or key:effect.", entry)
+		}
+		taint := corev1.Taint{Effect: corev1.TaintEffect(entry[i+1:])}
+		parts := strings.SplitN(entry[:i], "=", 2)
+		taint.Key = parts[0]
+		if len(parts) == 2 {
+			taint.Value = parts[1]
+		}
+
+		err := validateNodeLabelKey(taint.Key)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if errs := validation.IsValidLabelValue(taint.Value); len(errs) > 0 {
+			return nil, microerror.Maskf(notAllowedError, "Node taint %#q value is not valid: %s", taint.Key, strings.Join(errs, ", "))
+		}
+		if !isTaintEffect(taint.Effect) {
+			return nil, microerror.Maskf(notAllowedError, "Node taint %#q effect %#q is not valid. Valid effects are %v.", taint.Key, taint.Effect, taintEffects())
+		}
+		for _, t := range taints {
+			if t.Key == taint.Key && t.Effect == taint.Effect {
+				return nil, microerror.Maskf(notAllowedError, "Node taint %#q with effect %#q is declared more than once.", taint.Key, taint.Effect)
+			}
+		}
+		taints = append(taints, taint)
+	}
+	return taints, nil
+}
+
+// IsNodeLabelList returns whether the value is a valid list of node labels.
+func IsNodeLabelList(value string) bool {
+	_, err := ParseNodeLabels(value)
+	return err == nil
+}
+
+// IsNodeTaintList returns whether the value is a valid list of node taints.
+func IsNodeTaintList(value string) bool {
+	_, err := ParseNodeTaints(value)
+	return err == nil
+}
+
+// ValidateNodeLabels validates the node labels and taints annotations of a node pool, so that invalid declarations
+// are denied instead of making the kubelet of all nodes of the node pool fail.
+func ValidateNodeLabels(meta metav1.Object) error {
+	if value, ok := meta.GetAnnotations()[AnnotationNodeLabels]; ok {
+		_, err := ParseNodeLabels(value)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	if value, ok := meta.GetAnnotations()[AnnotationNodeTaints]; ok {
+		_, err := ParseNodeTaints(value)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	return nil
+}
+
+func validateNodeLabelKey(key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return microerror.Maskf(notAllowedError, "Node label or taint key %#q is not valid: %s", key, strings.Join(errs, ", "))
+	}
+	for _, prefix := range reservedNodeLabelPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return microerror.Maskf(notAllowedError, "Node label or taint key %#q uses the reserved prefix %#q, which is managed by Kubernetes.", key, prefix)
+		}
+	}
+	return nil
+}
+
+func isTaintEffect(effect corev1.TaintEffect) bool {
+	for _, e := range taintEffects() {
+		if e == effect {
+			return true
+		}
+	}
+	return false
+}
+
+func taintEffects() []corev1.TaintEffect {
+	return []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute}
+}
@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateNodeLabels(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations map[string]string
+		valid       bool
+	}{
+		{
+			// valid labels and taints
+			name: "case 0",
+
+			annotations: map[string]string{
+				AnnotationNodeLabels: "example.com/team=data, tier=backend",
+				AnnotationNodeTaints: "dedicated=data:NoSchedule,spot:PreferNoSchedule",
+			},
+			valid: true,
+		},
+		{
+			// label without value
+			name: "case 1",
+
+			annotations: map[string]string{AnnotationNodeLabels: "tier"},
+			valid:       false,
+		},
+		{
+			// label with reserved prefix
+			name: "case 2",
+
+			annotations: map[string]string{AnnotationNodeLabels: "node-role.kubernetes.io/master="},
+			valid:       false,
+		},
+		{
+			// label with invalid value
+			name: "case 3",
+
+			annotations: map[string]string{AnnotationNodeLabels: "tier=back end"},
+			valid:       false,
+		},
+		{
+			// taint with invalid effect
+			name: "case 4",
+
+			annotations: map[string]string{AnnotationNodeTaints: "dedicated=data:NoWay"},
+			valid:       false,
+		},
+		{
+			// taint with reserved prefix
+			name: "case 5",
+
+			annotations: map[string]string{AnnotationNodeTaints: "kubernetes.io/arch=arm64:NoSchedule"},
+			valid:       false,
+		},
+		{
+			// too many labels
+			name: "case 6",
+
+			annotations: map[string]string{AnnotationNodeLabels: strings.Repeat("a=b,", NodeLabelsMaxEntries) + "a=b"},
+			valid:       false,
+		},
+		{
+			// duplicate taint
+			name: "case 7",
+
+			annotations: map[string]string{AnnotationNodeTaints: "spot:NoSchedule,spot=true:NoSchedule"},
+			valid:       false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+			object.SetAnnotations(tc.annotations)
+
+			err := ValidateNodeLabels(&object)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
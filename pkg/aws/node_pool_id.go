@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"math/rand"
+	"regexp"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+)
+
+const (
+	// NodePoolIDLength is the length of generated node pool IDs.
+	NodePoolIDLength = 5
+	// NodePoolIDMaxLength is the maximum length of node pool IDs, which are part of the names of the AWS
+	// resources of a node pool.
+	NodePoolIDMaxLength = 10
+	// NodePoolIDMinLength is the minimum length of node pool IDs.
+	NodePoolIDMinLength = 4
+
+	nodePoolIDChars   = "abcdefghijklmnopqrstuvwxyz0123456789"
+	nodePoolIDLetters = "abcdefghijklmnopqrstuvwxyz"
+)
+
+var nodePoolIDPattern = regexp.MustCompile("^[a-z0-9]+$")
+
+// ValidateNodePoolID validates that the node pool ID of a node pool object consists of lowercase alphanumerics
+// and is within the length bounds.
+func ValidateNodePoolID(obj metav1.Object) error {
+	nodePoolID := key.MachineDeployment(obj)
+	if nodePoolID == "" {
+		nodePoolID = NodePoolIDFromName(obj)
+	}
+	if !nodePoolIDPattern.MatchString(nodePoolID) || len(nodePoolID) < NodePoolIDMinLength || len(nodePoolID) > NodePoolIDMaxLength {
+		return microerror.Maskf(notAllowedError, "Node pool ID %#q is invalid. It must consist of %d to %d lowercase alphanumeric characters.",
+			nodePoolID,
+			NodePoolIDMinLength,
+			NodePoolIDMaxLength,
+		)
+	}
+	return nil
+}
+
+// ValidateNodePoolIDUnique validates that no other of the given node pools of the same cluster has the node pool ID
+// of the given object, since the ID is part of the names of the AWS resources of a node pool.
+func ValidateNodePoolIDUnique(obj metav1.Object, nodePools []metav1.Object) error {
+	nodePoolID := key.MachineDeployment(obj)
+	if nodePoolID == "" {
+		nodePoolID = NodePoolIDFromName(obj)
+	}
+	for _, np := range nodePools {
+		if key.Cluster(np) != key.Cluster(obj) {
+			continue
+		}
+		if np.GetName() == obj.GetName() && np.GetNamespace() == obj.GetNamespace() {
+			continue
+		}
+		if key.MachineDeployment(np) == nodePoolID || NodePoolIDFromName(np) == nodePoolID {
+			return microerror.Maskf(notAllowedError, "Node pool ID %#q is already used by node pool %s of cluster %s.",
+				nodePoolID,
+				np.GetName(),
+				key.Cluster(obj),
+			)
+		}
+	}
+	return nil
+}
+
+// GenerateNodePoolID returns a random node pool ID which is not one of the given used IDs. Generated IDs start with
+// a letter so that they are never mistaken for numbers.
+func GenerateNodePoolID(used map[string]bool) string {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		id := []byte{nodePoolIDLetters[r.Intn(len(nodePoolIDLetters))]}
+		for len(id) < NodePoolIDLength {
+			id = append(id, nodePoolIDChars[r.Intn(len(nodePoolIDChars))])
+		}
+		if !used[string(id)] {
+			return string(id)
+		}
+	}
+}
+
+// NodePoolName returns the name of a node pool object with the given generate name and node pool ID. Names are
+// prefixed with the cluster ID if the generate name asks for it, otherwise they are the node pool ID itself.
+func NodePoolName(meta metav1.Object, nodePoolID string) string {
+	if key.Cluster(meta) != "" && meta.GetGenerateName() == key.Cluster(meta)+"-" {
+		return meta.GetGenerateName() + nodePoolID
+	}
+	return nodePoolID
+}
+
+// UsedNodePoolIDs returns the node pool IDs of the given node pools.
+func UsedNodePoolIDs(nodePools []metav1.Object) map[string]bool {
+	used := map[string]bool{}
+	for _, np := range nodePools {
+		used[NodePoolIDFromName(np)] = true
+		if id := np.GetLabels()[label.MachineDeployment]; id != "" {
+			used[id] = true
+		}
+	}
+	return used
+}
@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateNodePoolID(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		objectName string
+		nodePoolID string
+		valid      bool
+	}{
+		{
+			// valid node pool ID
+			name: "case 0",
+
+			objectName: "a2wax",
+			nodePoolID: "a2wax",
+			valid:      true,
+		},
+		{
+			// upper case characters
+			name: "case 1",
+
+			objectName: "A2wax",
+			nodePoolID: "A2wax",
+			valid:      false,
+		},
+		{
+			// too short
+			name: "case 2",
+
+			objectName: "a2w",
+			nodePoolID: "a2w",
+			valid:      false,
+		},
+		{
+			// too long
+			name: "case 3",
+
+			objectName: "a2waxa2waxa",
+			nodePoolID: "a2waxa2waxa",
+			valid:      false,
+		},
+		{
+			// node pool ID derived from the name
+			name: "case 4",
+
+			objectName: unittest.DefaultClusterID + "-np_01",
+			valid:      false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+			object.SetName(tc.objectName)
+			object.Labels[label.MachineDeployment] = tc.nodePoolID
+
+			err := ValidateNodePoolID(&object)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNodePoolIDUnique(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		existingName      string
+		existingClusterID string
+		valid             bool
+	}{
+		{
+			// node pool ID is not used
+			name: "case 0",
+
+			existingName:      "b3xyz",
+			existingClusterID: unittest.DefaultClusterID,
+			valid:             true,
+		},
+		{
+			// node pool ID is used in the cluster
+			name: "case 1",
+
+			existingName:      unittest.DefaultClusterID + "-" + unittest.DefaultMachineDeploymentID,
+			existingClusterID: unittest.DefaultClusterID,
+			valid:             false,
+		},
+		{
+			// node pool ID is used in another cluster
+			name: "case 2",
+
+			existingName:      unittest.DefaultMachineDeploymentID,
+			existingClusterID: "abcde",
+			valid:             true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			existing := unittest.DefaultAWSMachineDeployment()
+			existing.SetName(tc.existingName)
+			existing.SetNamespace("other")
+			existing.Labels[label.Cluster] = tc.existingClusterID
+			delete(existing.Labels, label.MachineDeployment)
+
+			object := unittest.DefaultAWSMachineDeployment()
+			err := ValidateNodePoolIDUnique(&object, []metav1.Object{&existing})
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateNodePoolID(t *testing.T) {
+	used := map[string]bool{"a2wax": true}
+	for i := 0; i < 100; i++ {
+		id := GenerateNodePoolID(used)
+		if used[id] {
+			t.Fatalf("expected %#q to be unused", id)
+		}
+		if !nodePoolIDPattern.MatchString(id) || len(id) != NodePoolIDLength {
+			t.Fatalf("expected %#q to be a valid node pool ID", id)
+		}
+		used[id] = true
+	}
+}
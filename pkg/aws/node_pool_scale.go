@@ -0,0 +1,25 @@
+package aws
+
+import (
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ValidateNodePoolReplicas validates that the number of replicas a node pool is scaled to is within the scaling limits
+// of its AWSMachineDeployment, so that scaling via the scale subresource can not bypass the limits which are
+// validated for spec changes.
+func ValidateNodePoolReplicas(meta metav1.Object, kind string, replicas int, awsMachineDeployment *infrastructurev1alpha2.AWSMachineDeployment) error {
+	scaling := awsMachineDeployment.Spec.NodePool.Scaling
+	if replicas < scaling.Min || (scaling.Max > 0 && replicas > scaling.Max) {
+		return microerror.Maskf(notAllowedError, "%s %s can not be scaled to %d replicas, because the scaling limits of the node pool are min %d and max %d. Change the scaling limits of AWSMachineDeployment %s instead.",
+			kind,
+			meta.GetName(),
+			replicas,
+			scaling.Min,
+			scaling.Max,
+			awsMachineDeployment.GetName(),
+		)
+	}
+	return nil
+}
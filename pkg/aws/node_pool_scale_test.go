@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateNodePoolReplicas(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		min      int
+		max      int
+		replicas int
+		valid    bool
+	}{
+		{
+			// replicas within the scaling limits
+			name: "case 0",
+
+			min:      3,
+			max:      5,
+			replicas: 4,
+			valid:    true,
+		},
+		{
+			// replicas at the scaling limits
+			name: "case 1",
+
+			min:      3,
+			max:      5,
+			replicas: 5,
+			valid:    true,
+		},
+		{
+			// replicas below the scaling min
+			name: "case 2",
+
+			min:      3,
+			max:      5,
+			replicas: 2,
+			valid:    false,
+		},
+		{
+			// replicas above the scaling max
+			name: "case 3",
+
+			min:      3,
+			max:      5,
+			replicas: 6,
+			valid:    false,
+		},
+		{
+			// scaling max is not set
+			name: "case 4",
+
+			min:      0,
+			max:      0,
+			replicas: 10,
+			valid:    true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+			object.Spec.NodePool.Scaling.Min = tc.min
+			object.Spec.NodePool.Scaling.Max = tc.max
+
+			err := ValidateNodePoolReplicas(&object, "MachineDeployment", tc.replicas, &object)
+
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
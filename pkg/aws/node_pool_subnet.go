@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AWSReservedSubnetIPs is the number of IP addresses AWS reserves in every subnet.
+	AWSReservedSubnetIPs = 5
+	// AWSLargestSubnetPrefixLength is the prefix length of the largest subnet AWS allows.
+	AWSLargestSubnetPrefixLength = 16
+	// AWSSmallestSubnetPrefixLength is the prefix length of the smallest subnet AWS allows.
+	AWSSmallestSubnetPrefixLength = 28
+)
+
+// NodePoolSubnetSize returns the prefix length of the subnet of a node pool. It is taken from the subnet size
+// annotation and falls back to the given installation default if the annotation is not set.
+func NodePoolSubnetSize(meta metav1.Object, defaultSize int) (int, error) {
+	value, ok := meta.GetAnnotations()[AnnotationAWSSubnetSize]
+	if !ok {
+		return defaultSize, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, microerror.Maskf(notAllowedError, "Annotation %s value %#q is not a prefix length.", AnnotationAWSSubnetSize, value)
+	}
+	return size, nil
+}
+
+// IsSubnetPrefixLength returns whether the given value is the prefix length of a subnet AWS allows.
+func IsSubnetPrefixLength(value string) bool {
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return false
+	}
+	return size >= AWSLargestSubnetPrefixLength && size <= AWSSmallestSubnetPrefixLength
+}
+
+// AZSubnetSize returns the prefix length of the availability zone subnets of a node pool. The node pool subnet is
+// split into the smallest power of two of equal parts which covers all availability zones.
+func AZSubnetSize(subnetSize int, azCount int) int {
+	size := subnetSize
+	for parts := 1; parts < azCount; parts *= 2 {
+		size++
+	}
+	return size
+}
+
+// SubnetCapacity returns the number of IP addresses of a subnet with the given prefix length which can be assigned
+// to nodes.
+func SubnetCapacity(prefixLength int) int {
+	if prefixLength > AWSSmallestSubnetPrefixLength {
+		return 0
+	}
+	return 1<<uint(32-prefixLength) - AWSReservedSubnetIPs
+}
+
+// ValidateNodePoolSubnetSize validates that the subnet size of a node pool is a prefix length AWS allows, that the
+// subnet fits into the network CIDR of the cluster and that it can be split into a subnet of at least the smallest
+// size for each availability zone of the node pool.
+func ValidateNodePoolSubnetSize(meta metav1.Object, kind string, clusterCIDR string, subnetSize int, azCount int) error {
+	if subnetSize < AWSLargestSubnetPrefixLength || subnetSize > AWSSmallestSubnetPrefixLength {
+		return microerror.Maskf(notAllowedError, "%s %s subnet size /%d is not valid. The prefix length must be between %d and %d.",
+			kind,
+			meta.GetName(),
+			subnetSize,
+			AWSLargestSubnetPrefixLength,
+			AWSSmallestSubnetPrefixLength,
+		)
+	}
+	if _, network, err := net.ParseCIDR(clusterCIDR); err == nil {
+		networkSize, _ := network.Mask.Size()
+		if subnetSize < networkSize {
+			return microerror.Maskf(notAllowedError, "%s %s subnet size /%d does not fit into the cluster network %s.",
+				kind,
+				meta.GetName(),
+				subnetSize,
+				network.String(),
+			)
+		}
+	}
+	if azSubnetSize := AZSubnetSize(subnetSize, azCount); azSubnetSize > AWSSmallestSubnetPrefixLength {
+		return microerror.Maskf(notAllowedError, "%s %s subnet size /%d is too small to be split into /%d subnets for %d availability zones. AWS subnets must be at least /%d.",
+			kind,
+			meta.GetName(),
+			subnetSize,
+			azSubnetSize,
+			azCount,
+			AWSSmallestSubnetPrefixLength,
+		)
+	}
+	return nil
+}
+
+// ValidateNodePoolSubnetCapacity validates that the subnet of a node pool can accommodate its maximum number of nodes
+// spread evenly across its availability zones. The subnet can not be larger than the network CIDR of the cluster, so
+// configurations which would exhaust the IP space only after scaling up are denied upfront.
+func ValidateNodePoolSubnetCapacity(meta metav1.Object, kind string, clusterCIDR string, subnetSize int, azCount int, maxNodes int) error {
+	if subnetSize <= 0 {
+		return nil
+	}
+	if azCount < 1 {
+		azCount = 1
+	}
+	if _, network, err := net.ParseCIDR(clusterCIDR); err == nil {
+		networkSize, _ := network.Mask.Size()
+		if subnetSize < networkSize {
+			subnetSize = networkSize
+		}
+	}
+
+	azSubnetSize := AZSubnetSize(subnetSize, azCount)
+	capacity := SubnetCapacity(azSubnetSize)
+	nodesPerAZ := (maxNodes + azCount - 1) / azCount
+	if nodesPerAZ > capacity {
+		return microerror.Maskf(notAllowedError, "%s %s can scale up to %d nodes, but its /%d subnet split into /%d subnets for %d availability zones only has room for %d nodes per availability zone. Reduce the scaling max, add availability zones or use a larger subnet.",
+			kind,
+			meta.GetName(),
+			maxNodes,
+			subnetSize,
+			azSubnetSize,
+			azCount,
+			capacity,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateNodePoolSubnetCapacity(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		clusterCIDR string
+		subnetSize  int
+		azCount     int
+		maxNodes    int
+		valid       bool
+	}{
+		{
+			// nodes fit into the availability zone subnets
+			name: "case 0",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  24,
+			azCount:     3,
+			maxNodes:    150,
+			valid:       true,
+		},
+		{
+			// nodes exceed the availability zone subnets
+			name: "case 1",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  24,
+			azCount:     3,
+			maxNodes:    200,
+			valid:       false,
+		},
+		{
+			// subnet is limited by the cluster network
+			name: "case 2",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  22,
+			azCount:     3,
+			maxNodes:    600,
+			valid:       false,
+		},
+		{
+			// subnet without cluster network
+			name: "case 3",
+
+			clusterCIDR: "",
+			subnetSize:  22,
+			azCount:     3,
+			maxNodes:    600,
+			valid:       true,
+		},
+		{
+			// smallest subnet in a single availability zone
+			name: "case 4",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  28,
+			azCount:     1,
+			maxNodes:    20,
+			valid:       false,
+		},
+		{
+			// subnet size is not configured
+			name: "case 5",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  0,
+			azCount:     3,
+			maxNodes:    1000,
+			valid:       true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+
+			err := ValidateNodePoolSubnetCapacity(&object, "AWSMachineDeployment", tc.clusterCIDR, tc.subnetSize, tc.azCount, tc.maxNodes)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNodePoolSubnetSize(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		clusterCIDR string
+		subnetSize  int
+		azCount     int
+		valid       bool
+	}{
+		{
+			// subnet fits into the cluster network
+			name: "case 0",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  25,
+			azCount:     3,
+			valid:       true,
+		},
+		{
+			// subnet is larger than the cluster network
+			name: "case 1",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  22,
+			azCount:     3,
+			valid:       false,
+		},
+		{
+			// subnet is too small for the availability zones
+			name: "case 2",
+
+			clusterCIDR: "172.19.73.0/24",
+			subnetSize:  27,
+			azCount:     3,
+			valid:       false,
+		},
+		{
+			// prefix length is not allowed by AWS
+			name: "case 3",
+
+			clusterCIDR: "",
+			subnetSize:  30,
+			azCount:     1,
+			valid:       false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+
+			err := ValidateNodePoolSubnetSize(&object, "AWSMachineDeployment", tc.clusterCIDR, tc.subnetSize, tc.azCount)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"strconv"
+	"time"
+
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// MinHeartbeatTimeout is the shortest heartbeat timeout AWS allows for lifecycle hooks.
+	MinHeartbeatTimeout = 30 * time.Second
+	// MaxHeartbeatTimeout is the longest heartbeat timeout AWS allows for lifecycle hooks.
+	MaxHeartbeatTimeout = 2 * time.Hour
+)
+
+// IsHeartbeatTimeout returns whether the value is a number of seconds AWS allows as lifecycle hook heartbeat timeout.
+func IsHeartbeatTimeout(value string) bool {
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return false
+	}
+	timeout := time.Duration(seconds) * time.Second
+	return timeout >= MinHeartbeatTimeout && timeout <= MaxHeartbeatTimeout
+}
+
+// UsesSpotInstances returns whether a node pool launches spot instances above its on-demand base capacity.
+func UsesSpotInstances(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) bool {
+	percentage := awsMachineDeployment.Spec.Provider.InstanceDistribution.OnDemandPercentageAboveBaseCapacity
+	return percentage != nil && *percentage < 100
+}
+
+// ValidateTerminationHandling validates the node termination handling annotations of a node pool. Spot interruption
+// draining can only be enabled for node pools which launch spot instances.
+func ValidateTerminationHandling(awsMachineDeployment infrastructurev1alpha2.AWSMachineDeployment) error {
+	if value, ok := awsMachineDeployment.GetAnnotations()[AnnotationHeartbeatTimeout]; ok && !IsHeartbeatTimeout(value) {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment %s annotation %s value %#q is not valid. It has to be a number of seconds between %d and %d.",
+			awsMachineDeployment.GetName(),
+			AnnotationHeartbeatTimeout,
+			value,
+			int(MinHeartbeatTimeout.Seconds()),
+			int(MaxHeartbeatTimeout.Seconds()),
+		)
+	}
+	if IsAnnotationTrue(&awsMachineDeployment, AnnotationSpotInterruptionDraining) && !UsesSpotInstances(awsMachineDeployment) {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment %s annotation %s can only be enabled for node pools with spot instances. Set .spec.provider.instanceDistribution.onDemandPercentageAboveBaseCapacity below 100 or remove the annotation.",
+			awsMachineDeployment.GetName(),
+			AnnotationSpotInterruptionDraining,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestValidateTerminationHandling(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		annotations        map[string]string
+		onDemandPercentage *int
+		valid              bool
+	}{
+		{
+			// no termination handling annotations
+			name: "case 0",
+
+			valid: true,
+		},
+		{
+			// valid heartbeat timeout
+			name: "case 1",
+
+			annotations: map[string]string{AnnotationHeartbeatTimeout: "300"},
+			valid:       true,
+		},
+		{
+			// heartbeat timeout below the AWS minimum
+			name: "case 2",
+
+			annotations: map[string]string{AnnotationHeartbeatTimeout: "10"},
+			valid:       false,
+		},
+		{
+			// heartbeat timeout is not a number of seconds
+			name: "case 3",
+
+			annotations: map[string]string{AnnotationHeartbeatTimeout: "5m"},
+			valid:       false,
+		},
+		{
+			// spot interruption draining with spot instances
+			name: "case 4",
+
+			annotations:        map[string]string{AnnotationSpotInterruptionDraining: "true"},
+			onDemandPercentage: intPtr(50),
+			valid:              true,
+		},
+		{
+			// spot interruption draining without spot instances
+			name: "case 5",
+
+			annotations:        map[string]string{AnnotationSpotInterruptionDraining: "true"},
+			onDemandPercentage: intPtr(100),
+			valid:              false,
+		},
+		{
+			// spot interruption draining disabled without spot instances
+			name: "case 6",
+
+			annotations: map[string]string{AnnotationSpotInterruptionDraining: "false"},
+			valid:       true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			object := unittest.DefaultAWSMachineDeployment()
+			object.SetAnnotations(tc.annotations)
+			object.Spec.Provider.InstanceDistribution.OnDemandPercentageAboveBaseCapacity = tc.onDemandPercentage
+
+			err := ValidateTerminationHandling(object)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+)
+
+// IsValidPodSecurityLevel returns whether the given value is a known Pod Security level
+func IsValidPodSecurityLevel(level string) bool {
+	for _, l := range ValidPodSecurityLevels() {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// MutatePodSecurityDefault defaults the enforced Pod Security level of a cluster to the installation standard.
+func MutatePodSecurityDefault(m *Handler, meta metav1.Object, defaultLevel string) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if defaultLevel == "" || meta.GetAnnotations()[AnnotationPodSecurityEnforce] != "" {
+		return result, nil
+	}
+
+	patch, err := MutateAnnotation(m, meta, AnnotationPodSecurityEnforce, defaultLevel)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
+	return result, nil
+}
+
+// ValidatePodSecurity checks that all Pod Security annotations of a cluster configure a known mode with a known level.
+func ValidatePodSecurity(meta metav1.Object) error {
+	for annotation, value := range meta.GetAnnotations() {
+		if !strings.HasPrefix(annotation, AnnotationPodSecurityPrefix) {
+			continue
+		}
+		mode := strings.TrimPrefix(annotation, AnnotationPodSecurityPrefix)
+		if !isOneOf(ValidPodSecurityModes()...)(mode) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("Pod Security annotation %s of object %s configures the unknown mode %#q. Valid modes are %v.",
+				annotation,
+				meta.GetName(),
+				mode,
+				ValidPodSecurityModes()),
+			)
+		}
+		if !IsValidPodSecurityLevel(value) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("Pod Security annotation %s of object %s has the unknown level %#q. Valid levels are %v.",
+				annotation,
+				meta.GetName(),
+				value,
+				ValidPodSecurityLevels()),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// KubernetesServiceDomains returns the domains of Kubernetes services which have to bypass the proxy
+func KubernetesServiceDomains() []string {
+	return []string{"svc", "cluster.local"}
+}
+
+// IsProxyURL returns whether the value is a http or https URL with a host, as expected for proxy settings.
+func IsProxyURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() != ""
+}
+
+// IsNoProxyEntry returns whether the entry is a CIDR, an IP or a domain. Domains may start with a dot or
+// a wildcard to match all subdomains.
+func IsNoProxyEntry(entry string) bool {
+	if _, _, err := net.ParseCIDR(entry); err == nil {
+		return true
+	}
+	if net.ParseIP(entry) != nil {
+		return true
+	}
+	domain := strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+	return len(validation.IsDNS1123Subdomain(domain)) == 0
+}
+
+// IsNoProxyList returns whether the value is a comma separated list of valid no proxy entries.
+func IsNoProxyList(value string) bool {
+	for _, entry := range strings.Split(value, ",") {
+		if !IsNoProxyEntry(strings.TrimSpace(entry)) {
+			return false
+		}
+	}
+	return true
+}
+
+// NoProxyCoversCIDR returns whether the CIDR is fully covered by one of the CIDR entries of the no proxy list.
+func NoProxyCoversCIDR(entries []string, cidr string) bool {
+	_, inner, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		_, outer, err := net.ParseCIDR(entry)
+		if err == nil && CIDRContains(outer, inner) {
+			return true
+		}
+	}
+	return false
+}
+
+// NoProxyCoversDomain returns whether the domain and its subdomains are matched by one of the entries of the no proxy list.
+func NoProxyCoversDomain(entries []string, domain string) bool {
+	for _, entry := range entries {
+		if strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".") == domain {
+			return true
+		}
+	}
+	return false
+}
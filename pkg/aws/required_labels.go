@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/mutator"
+)
+
+// RequiredLabel is a label which an installation requires to be set on certain objects.
+type RequiredLabel struct {
+	Key     string
+	Pattern *regexp.Regexp
+	Default string
+}
+
+type requiredLabelConfig struct {
+	Key     string `json:"key"`
+	Pattern string `json:"pattern"`
+	Default string `json:"default"`
+}
+
+// ParseRequiredLabels parses required labels which are configured as JSON objects like
+// {"key":"cost-center","pattern":"^[0-9]{4}$","default":"0000"}. Pattern and default are optional.
+func ParseRequiredLabels(configs []string) ([]RequiredLabel, error) {
+	var requiredLabels []RequiredLabel
+	for _, c := range configs {
+		var rc requiredLabelConfig
+		err := json.Unmarshal([]byte(c), &rc)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "required label %s is not valid JSON: %v", c, err)
+		}
+		if rc.Key == "" {
+			return nil, microerror.Maskf(invalidConfigError, "required label %s has no key", c)
+		}
+		requiredLabel := RequiredLabel{
+			Key:     rc.Key,
+			Default: rc.Default,
+		}
+		if rc.Pattern != "" {
+			requiredLabel.Pattern, err = regexp.Compile(rc.Pattern)
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "required label %s has an invalid pattern: %v", rc.Key, err)
+			}
+			if rc.Default != "" && !requiredLabel.Pattern.MatchString(rc.Default) {
+				return nil, microerror.Maskf(invalidConfigError, "required label %s default %#q does not match pattern %#q", rc.Key, rc.Default, rc.Pattern)
+			}
+		}
+		requiredLabels = append(requiredLabels, requiredLabel)
+	}
+	return requiredLabels, nil
+}
+
+// MutateRequiredLabels defaults all required labels which are not set and have a default value.
+func MutateRequiredLabels(m *Handler, meta metav1.Object, requiredLabels []RequiredLabel) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	for _, requiredLabel := range requiredLabels {
+		if requiredLabel.Default == "" {
+			continue
+		}
+		patch, err := MutateLabel(m, meta, requiredLabel.Key, requiredLabel.Default)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
+	}
+
+	return result, nil
+}
+
+// ValidateRequiredLabels checks that all required labels are set and match their pattern.
+func ValidateRequiredLabels(meta metav1.Object, requiredLabels []RequiredLabel) error {
+	for _, requiredLabel := range requiredLabels {
+		value, ok := meta.GetLabels()[requiredLabel.Key]
+		if !ok || value == "" {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("Label %#q is required by the installation but not set for object %s.",
+				requiredLabel.Key,
+				meta.GetName()),
+			)
+		}
+		if requiredLabel.Pattern != nil && !requiredLabel.Pattern.MatchString(value) {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("Label %#q value %#q of object %s does not match the required pattern %#q.",
+				requiredLabel.Key,
+				value,
+				meta.GetName(),
+				requiredLabel.Pattern.String()),
+			)
+		}
+	}
+	return nil
+}
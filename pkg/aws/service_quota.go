@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/servicequota"
+)
+
+const (
+	// ServiceQuotaPolicyDeny rejects node pools which can not scale up to their max within the EC2 instance quota
+	ServiceQuotaPolicyDeny = "deny"
+	// ServiceQuotaPolicyWarn only returns a warning for node pools which can not scale up to their max within the EC2 instance quota
+	ServiceQuotaPolicyWarn = "warn"
+)
+
+// ServiceQuotaExceeded returns a message when the given number of additional nodes of the instance type would exceed
+// the remaining EC2 On-Demand instance quota of the account. It is empty when the nodes fit into the quota, when no
+// quotas are configured or when the quota can not be looked up, since the check only gives early feedback.
+func ServiceQuotaExceeded(m *Handler, quotas servicequota.Interface, meta metav1.Object, kind string, instanceType string, additionalNodes int) string {
+	if quotas == nil || instanceType == "" || additionalNodes <= 0 {
+		return ""
+	}
+	resources, ok := GetInstanceTypeResources(instanceType)
+	if !ok {
+		return ""
+	}
+
+	remaining, err := quotas.RemainingVCPUs(instanceType)
+	if err != nil {
+		m.Logger.Log("level", "warning", "message", fmt.Sprintf("unable to check the EC2 service quota of instance type %s of %s %s", instanceType, kind, meta.GetName()), "stack", microerror.JSON(err))
+		return ""
+	}
+	required := additionalNodes * resources.CPU
+	if required <= remaining {
+		return ""
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return fmt.Sprintf("%s %s can scale up by %d nodes of instance type %s, which need %d vCPUs, but only %d vCPUs are left in the EC2 On-Demand instance quota of the account. Scale-ups will fail unless the quota is increased.",
+		kind,
+		meta.GetName(),
+		additionalNodes,
+		instanceType,
+		required,
+		remaining,
+	)
+}
+
+// ValidateServiceQuota denies objects which exceed the remaining EC2 On-Demand instance quota of the account when the
+// service quota policy is deny. With the warn policy the message is returned as admission warning instead.
+func ValidateServiceQuota(m *Handler, quotas servicequota.Interface, meta metav1.Object, kind string, instanceType string, additionalNodes int, policy string) error {
+	if policy != ServiceQuotaPolicyDeny {
+		return nil
+	}
+	if message := ServiceQuotaExceeded(m, quotas, meta, kind, instanceType, additionalNodes); message != "" {
+		return microerror.Maskf(notAllowedError, message)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/servicequota"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+type fakeServiceQuotas struct {
+	remaining int
+	err       error
+}
+
+func (f *fakeServiceQuotas) RemainingVCPUs(instanceType string) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.remaining, nil
+}
+
+func TestValidateServiceQuota(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		quotas          servicequota.Interface
+		policy          string
+		instanceType    string
+		additionalNodes int
+		valid           bool
+		warning         bool
+	}{
+		{
+			// no quotas configured
+			name: "case 0",
+
+			policy:          ServiceQuotaPolicyDeny,
+			instanceType:    "m5.2xlarge",
+			additionalNodes: 10,
+			valid:           true,
+			warning:         false,
+		},
+		{
+			// nodes fit into the remaining quota
+			name: "case 1",
+
+			quotas:          &fakeServiceQuotas{remaining: 80},
+			policy:          ServiceQuotaPolicyDeny,
+			instanceType:    "m5.2xlarge",
+			additionalNodes: 10,
+			valid:           true,
+			warning:         false,
+		},
+		{
+			// nodes exceed the remaining quota
+			name: "case 2",
+
+			quotas:          &fakeServiceQuotas{remaining: 79},
+			policy:          ServiceQuotaPolicyDeny,
+			instanceType:    "m5.2xlarge",
+			additionalNodes: 10,
+			valid:           false,
+			warning:         true,
+		},
+		{
+			// nodes exceed the remaining quota with the warn policy
+			name: "case 3",
+
+			quotas:          &fakeServiceQuotas{remaining: 79},
+			policy:          ServiceQuotaPolicyWarn,
+			instanceType:    "m5.2xlarge",
+			additionalNodes: 10,
+			valid:           true,
+			warning:         true,
+		},
+		{
+			// node pool does not scale up
+			name: "case 4",
+
+			quotas:          &fakeServiceQuotas{remaining: 0},
+			policy:          ServiceQuotaPolicyDeny,
+			instanceType:    "m5.2xlarge",
+			additionalNodes: -2,
+			valid:           true,
+			warning:         false,
+		},
+		{
+			// quota can not be looked up
+			name: "case 5",
+
+			quotas:          &fakeServiceQuotas{err: errors.New("throttled")},
+			policy:          ServiceQuotaPolicyDeny,
+			instanceType:    "m5.2xlarge",
+			additionalNodes: 10,
+			valid:           true,
+			warning:         false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handler := &Handler{
+				K8sClient: unittest.FakeK8sClient(),
+				Logger:    microloggertest.New(),
+			}
+			object := &metav1.ObjectMeta{Name: "al9qy", Namespace: metav1.NamespaceDefault}
+
+			err := ValidateServiceQuota(handler, tc.quotas, object, "AWSMachineDeployment", tc.instanceType, tc.additionalNodes, tc.policy)
+			// check if the result is as expected
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+			message := ServiceQuotaExceeded(handler, tc.quotas, object, "AWSMachineDeployment", tc.instanceType, tc.additionalNodes)
+			if tc.warning != (message != "") {
+				t.Fatalf("expected warning %t but got %#q", tc.warning, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package certcheck periodically inspects the serving certificate, exports its
+// expiry as metrics and reports whether it is still valid, since an expired
+// certificate makes every admission request fail.
+package certcheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	Logger   micrologger.Logger
+	// Threshold is the remaining validity below which the certificate is reported as near expiry.
+	Threshold time.Duration
+}
+
+// Checker keeps track of the expiry of the serving certificate.
+type Checker struct {
+	certFile  string
+	keyFile   string
+	logger    micrologger.Logger
+	threshold time.Duration
+
+	mutex    sync.RWMutex
+	loaded   bool
+	notAfter time.Time
+}
+
+func New(config Config) (*Checker, error) {
+	if config.CertFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CertFile must not be empty", config)
+	}
+	if config.KeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyFile must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	c := &Checker{
+		certFile:  config.CertFile,
+		keyFile:   config.KeyFile,
+		logger:    config.Logger,
+		threshold: config.Threshold,
+	}
+
+	return c, nil
+}
+
+// Check parses the serving certificate and updates the expiry metrics.
+func (c *Checker) Check() error {
+	keyPair, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		c.setLoaded(false)
+		return microerror.Mask(err)
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		c.setLoaded(false)
+		return microerror.Mask(err)
+	}
+
+	c.mutex.Lock()
+	c.loaded = true
+	c.notAfter = leaf.NotAfter
+	c.mutex.Unlock()
+
+	metrics.CertificateExpiry.Set(float64(leaf.NotAfter.Unix()))
+
+	remaining := time.Until(leaf.NotAfter)
+	nearExpiry := 0.0
+	if remaining < c.threshold {
+		nearExpiry = 1.0
+	}
+	metrics.CertificateNearExpiry.Set(nearExpiry)
+
+	if remaining <= 0 {
+		c.logger.Log("level", "error", "message", fmt.Sprintf("serving certificate expired at %s", leaf.NotAfter.Format(time.RFC3339)))
+	} else if remaining < c.threshold {
+		c.logger.Log("level", "warning", "message", fmt.Sprintf("serving certificate expires at %s", leaf.NotAfter.Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// Expired returns whether the certificate was expired at the last successful check.
+// The certificate is assumed to be valid before the first successful check.
+func (c *Checker) Expired() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return !c.notAfter.IsZero() && time.Now().After(c.notAfter)
+}
+
+// Loaded returns whether the certificate and key could be loaded at the last check.
+func (c *Checker) Loaded() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.loaded
+}
+
+// Run checks the certificate in the given interval until stop is closed.
+func (c *Checker) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := c.Check()
+			if err != nil {
+				c.logger.Log("level", "warning", "message", "unable to check serving certificate", "stack", microerror.JSON(err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *Checker) setLoaded(loaded bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.loaded = loaded
+}
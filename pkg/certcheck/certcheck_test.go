@@ -0,0 +1,87 @@
+package certcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestChecker(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		notAfter   time.Duration
+		expired    bool
+		nearExpiry float64
+	}{
+		{
+			// valid certificate
+			name: "case 0",
+
+			notAfter:   30 * 24 * time.Hour,
+			expired:    false,
+			nearExpiry: 0,
+		},
+		{
+			// certificate near expiry
+			name: "case 1",
+
+			notAfter:   24 * time.Hour,
+			expired:    false,
+			nearExpiry: 1,
+		},
+		{
+			// expired certificate
+			name: "case 2",
+
+			notAfter:   -time.Hour,
+			expired:    true,
+			nearExpiry: 1,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "certcheck")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			certFile := filepath.Join(dir, "tls.crt")
+			keyFile := filepath.Join(dir, "tls.key")
+			unittest.WriteKeyPair(t, certFile, keyFile, 1, time.Now().Add(tc.notAfter))
+			c, err := New(Config{
+				CertFile:  certFile,
+				KeyFile:   keyFile,
+				Logger:    microloggertest.New(),
+				Threshold: 7 * 24 * time.Hour,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Expired() {
+				t.Fatal("expected certificate to be valid before the first check")
+			}
+			err = c.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Expired() != tc.expired {
+				t.Fatalf("expected expired to be %t", tc.expired)
+			}
+			if nearExpiry := testutil.ToFloat64(metrics.CertificateNearExpiry); nearExpiry != tc.nearExpiry {
+				t.Fatalf("expected near expiry metric %v but got %v", tc.nearExpiry, nearExpiry)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package certreload serves the TLS certificate from the files it is mounted
+// to and reloads it when they change, so that rotated certificates are used
+// without restarting the process.
+package certreload
+
+import (
+	"bytes"
+	"crypto/tls"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+type Config struct {
+	CertFile string
+	// Interval is the time after which the files are reloaded even without
+	// change notification, in case notifications are lost. Disabled when 0.
+	Interval time.Duration
+	KeyFile  string
+	Logger   micrologger.Logger
+}
+
+// Reloader keeps the last valid certificate loaded from the files.
+type Reloader struct {
+	certFile string
+	interval time.Duration
+	keyFile  string
+	logger   micrologger.Logger
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+}
+
+// New loads the certificate, so that the server never starts without one.
+func New(config Config) (*Reloader, error) {
+	if config.CertFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CertFile must not be empty", config)
+	}
+	if config.KeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyFile must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Reloader{
+		certFile: config.CertFile,
+		interval: config.Interval,
+		keyFile:  config.KeyFile,
+		logger:   config.Logger,
+	}
+
+	err := r.Reload()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It is meant to be used as
+// tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.certificate, nil
+}
+
+// Reload loads the certificate from the files. The current certificate is
+// kept when they can not be loaded, e.g. while only one of them is rotated.
+func (r *Reloader) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		metrics.CertificateReloadErrors.Inc()
+		return microerror.Mask(err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.certificate != nil && sameCertificate(r.certificate, &certificate) {
+		return nil
+	}
+	if r.certificate != nil {
+		r.logger.Log("level", "info", "message", "reloaded rotated serving certificate")
+	}
+	r.certificate = &certificate
+
+	return nil
+}
+
+// Run reloads the certificate whenever the directories of the files change
+// until stop is closed. The directories are watched instead of the files,
+// because mounted Secrets are updated by swapping a symlink, which replaces
+// the watched files.
+func (r *Reloader) Run(stop <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer watcher.Close()
+
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		err = watcher.Add(dir)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-watcher.Events:
+			r.reload()
+		case err := <-watcher.Errors:
+			r.logger.Log("level", "warning", "message", "unable to watch serving certificate", "stack", microerror.JSON(err))
+		case <-tick:
+			r.reload()
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+func (r *Reloader) reload() {
+	err := r.Reload()
+	if err != nil {
+		r.logger.Log("level", "warning", "message", "unable to reload serving certificate, keeping the current one", "stack", microerror.JSON(err))
+	}
+}
+
+func sameCertificate(a, b *tls.Certificate) bool {
+	if len(a.Certificate) != len(b.Certificate) {
+		return false
+	}
+	for i := range a.Certificate {
+		if !bytes.Equal(a.Certificate[i], b.Certificate[i]) {
+			return false
+		}
+	}
+	return true
+}
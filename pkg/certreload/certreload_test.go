@@ -0,0 +1,97 @@
+package certreload
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestReload(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		rotate   bool
+		corrupt  bool
+		reloaded bool
+	}{
+		{
+			// unchanged files
+			name: "case 0",
+
+			reloaded: false,
+		},
+		{
+			// rotated certificate
+			name: "case 1",
+
+			rotate:   true,
+			reloaded: true,
+		},
+		{
+			// corrupted certificate keeps the current one
+			name: "case 2",
+
+			corrupt:  true,
+			reloaded: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "certreload")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			certFile := filepath.Join(dir, "tls.crt")
+			keyFile := filepath.Join(dir, "tls.key")
+			unittest.WriteKeyPair(t, certFile, keyFile, 1, time.Now().Add(24*time.Hour))
+
+			r, err := New(Config{
+				CertFile: certFile,
+				KeyFile:  keyFile,
+				Logger:   microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			current, err := r.GetCertificate(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.rotate {
+				unittest.WriteKeyPair(t, certFile, keyFile, 2, time.Now().Add(24*time.Hour))
+			}
+			if tc.corrupt {
+				err = ioutil.WriteFile(certFile, []byte("corrupt"), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = r.Reload()
+			if tc.corrupt && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+			if !tc.corrupt && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			certificate, err := r.GetCertificate(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (certificate != current) != tc.reloaded {
+				t.Fatalf("expected reloaded to be %t", tc.reloaded)
+			}
+		})
+	}
+}
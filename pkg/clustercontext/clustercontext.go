@@ -0,0 +1,227 @@
+// Package clustercontext maintains an in-memory snapshot of the state of every
+// cluster which is needed by the validators. Snapshots are refreshed in the
+// background from informers, so that admission requests can read them without
+// issuing several API calls each.
+package clustercontext
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+)
+
+// NodePool is the state of a single node pool of a cluster.
+type NodePool struct {
+	ID                  string
+	Replicas            int32
+	ReadyReplicas       int32
+	UnavailableReplicas int32
+}
+
+// Snapshot is the state of a cluster at the time of the last refresh.
+type Snapshot struct {
+	ClusterID      string
+	ReleaseVersion string
+	// Transitioned is true when the latest condition of the AWSCluster is Created or Updated.
+	Transitioned bool
+	NodePools    []NodePool
+	RefreshedAt  time.Time
+}
+
+// NodePoolCount returns the number of node pools of the cluster.
+func (s Snapshot) NodePoolCount() int {
+	return len(s.NodePools)
+}
+
+type Config struct {
+	K8sClient k8sclient.Interface
+	Logger    micrologger.Logger
+	// Resync is the interval in which all snapshots are recomputed even without changes.
+	Resync time.Duration
+}
+
+// Store holds the snapshots of all clusters. Snapshots only exist once the
+// informers are synced, so callers have to fall back to querying the API when
+// no snapshot is found.
+type Store struct {
+	cache  cache.Cache
+	logger micrologger.Logger
+	reader client.Reader
+
+	mutex     sync.RWMutex
+	snapshots map[string]Snapshot
+	// queue holds the IDs of the clusters waiting for a refresh. It never
+	// blocks informer events and holds each cluster at most once.
+	queue workqueue.Interface
+}
+
+func New(config Config) (*Store, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	informerCache, err := cache.New(config.K8sClient.RESTConfig(), cache.Options{
+		Scheme: config.K8sClient.Scheme(),
+		Resync: &config.Resync,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	s := &Store{
+		cache:  informerCache,
+		logger: config.Logger,
+		reader: informerCache,
+
+		snapshots: map[string]Snapshot{},
+		queue:     workqueue.New(),
+	}
+
+	return s, nil
+}
+
+// Get returns the snapshot of the given cluster and whether it exists.
+func (s *Store) Get(clusterID string) (Snapshot, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshot, ok := s.snapshots[clusterID]
+	return snapshot, ok
+}
+
+// Refresh recomputes the snapshot of the given cluster from the informer cache.
+func (s *Store) Refresh(ctx context.Context, clusterID string) error {
+	var clusters capiv1alpha2.ClusterList
+	err := s.reader.List(ctx, &clusters, client.MatchingLabels{label.Cluster: clusterID})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(clusters.Items) == 0 {
+		s.mutex.Lock()
+		delete(s.snapshots, clusterID)
+		s.mutex.Unlock()
+		return nil
+	}
+
+	snapshot := Snapshot{
+		ClusterID:      clusterID,
+		ReleaseVersion: key.Release(&clusters.Items[0]),
+		RefreshedAt:    time.Now(),
+	}
+
+	var awsClusters infrastructurev1alpha2.AWSClusterList
+	err = s.reader.List(ctx, &awsClusters, client.MatchingLabels{label.Cluster: clusterID})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(awsClusters.Items) > 0 {
+		condition := awsClusters.Items[0].GetCommonClusterStatus().LatestCondition()
+		snapshot.Transitioned = condition == infrastructurev1alpha2.ClusterStatusConditionCreated || condition == infrastructurev1alpha2.ClusterStatusConditionUpdated
+	}
+
+	var machineDeployments capiv1alpha2.MachineDeploymentList
+	err = s.reader.List(ctx, &machineDeployments, client.MatchingLabels{label.Cluster: clusterID})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, md := range machineDeployments.Items {
+		if md.GetDeletionTimestamp() != nil {
+			continue
+		}
+		snapshot.NodePools = append(snapshot.NodePools, NodePool{
+			ID:                  key.MachineDeployment(&md),
+			Replicas:            md.Status.Replicas,
+			ReadyReplicas:       md.Status.ReadyReplicas,
+			UnavailableReplicas: md.Status.UnavailableReplicas,
+		})
+	}
+
+	s.mutex.Lock()
+	s.snapshots[clusterID] = snapshot
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Run starts the informers and refreshes snapshots on every change of the
+// watched resources until stop is closed.
+func (s *Store) Run(stop <-chan struct{}) error {
+	watched := []runtime.Object{
+		&capiv1alpha2.Cluster{},
+		&capiv1alpha2.MachineDeployment{},
+		&infrastructurev1alpha2.AWSCluster{},
+	}
+	for _, obj := range watched {
+		informer, err := s.cache.GetInformer(context.Background(), obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    s.enqueue,
+			UpdateFunc: func(_, obj interface{}) { s.enqueue(obj) },
+			DeleteFunc: s.enqueue,
+		})
+	}
+
+	go func() {
+		err := s.cache.Start(stop)
+		if err != nil {
+			s.logger.Log("level", "error", "message", "cluster context informers stopped", "stack", microerror.JSON(err))
+		}
+	}()
+	if !s.cache.WaitForCacheSync(stop) {
+		return microerror.Maskf(executionFailedError, "cluster context informers did not sync")
+	}
+
+	go func() {
+		<-stop
+		s.queue.ShutDown()
+	}()
+
+	for {
+		item, shutdown := s.queue.Get()
+		if shutdown {
+			return nil
+		}
+		clusterID := item.(string)
+		err := s.Refresh(context.Background(), clusterID)
+		if err != nil {
+			s.logger.Log("level", "warning", "message", fmt.Sprintf("unable to refresh context of cluster %s", clusterID), "stack", microerror.JSON(err))
+		}
+		s.queue.Done(item)
+	}
+}
+
+func (s *Store) enqueue(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	meta, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+	clusterID := key.Cluster(meta)
+	if clusterID == "" {
+		return
+	}
+	s.queue.Add(clusterID)
+}
@@ -0,0 +1,149 @@
+package clustercontext
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestRefresh(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterExists    bool
+		condition        string
+		nodePools        int
+		unavailableNodes int32
+
+		expectSnapshot     bool
+		expectTransitioned bool
+	}{
+		{
+			// cluster does not exist
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterExists:  false,
+			expectSnapshot: false,
+		},
+		{
+			// created cluster without node pools
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterExists:      true,
+			condition:          infrastructurev1alpha2.ClusterStatusConditionCreated,
+			expectSnapshot:     true,
+			expectTransitioned: true,
+		},
+		{
+			// updating cluster with a node pool
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterExists:      true,
+			condition:          infrastructurev1alpha2.ClusterStatusConditionUpdating,
+			nodePools:          1,
+			unavailableNodes:   1,
+			expectSnapshot:     true,
+			expectTransitioned: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			s := &Store{
+				logger: microloggertest.New(),
+				reader: fakeK8sClient.CtrlClient(),
+
+				snapshots: map[string]Snapshot{},
+			}
+
+			if tc.clusterExists {
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultCluster())
+				if err != nil {
+					t.Fatal(err)
+				}
+				awsCluster := unittest.DefaultAWSCluster()
+				awsCluster.Status.Cluster.Conditions = []infrastructurev1alpha2.CommonClusterStatusCondition{
+					{LastTransitionTime: metav1.NewTime(time.Now()), Condition: tc.condition},
+				}
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &awsCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.nodePools > 0 {
+				machineDeployment := unittest.DefaultMachineDeployment()
+				machineDeployment.Status.UnavailableReplicas = tc.unavailableNodes
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, &machineDeployment)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = s.Refresh(tc.ctx, unittest.DefaultClusterID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, ok := s.Get(unittest.DefaultClusterID)
+			if ok != tc.expectSnapshot {
+				t.Fatalf("expected snapshot to exist: %t", tc.expectSnapshot)
+			}
+			if !ok {
+				return
+			}
+			if snapshot.ReleaseVersion != unittest.DefaultReleaseVersion {
+				t.Fatalf("expected release version %s but got %s", unittest.DefaultReleaseVersion, snapshot.ReleaseVersion)
+			}
+			if snapshot.Transitioned != tc.expectTransitioned {
+				t.Fatalf("expected transitioned to be %t", tc.expectTransitioned)
+			}
+			if snapshot.NodePoolCount() != tc.nodePools {
+				t.Fatalf("expected %d node pools but got %d", tc.nodePools, snapshot.NodePoolCount())
+			}
+			if tc.nodePools > 0 && snapshot.NodePools[0].UnavailableReplicas != tc.unavailableNodes {
+				t.Fatalf("expected %d unavailable nodes but got %d", tc.unavailableNodes, snapshot.NodePools[0].UnavailableReplicas)
+			}
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	s := &Store{
+		logger: microloggertest.New(),
+		queue:  workqueue.New(),
+	}
+	defer s.queue.ShutDown()
+
+	cluster := unittest.DefaultCluster()
+	machineDeployment := unittest.DefaultMachineDeployment()
+	// Changes of several objects of the same cluster refresh it only once.
+	s.enqueue(cluster)
+	s.enqueue(&machineDeployment)
+	s.enqueue(toolscache.DeletedFinalStateUnknown{Obj: cluster})
+	// Objects without cluster label are ignored.
+	s.enqueue(&capiv1alpha2.Cluster{})
+
+	if s.queue.Len() != 1 {
+		t.Fatalf("expected 1 queued cluster but got %d", s.queue.Len())
+	}
+	item, _ := s.queue.Get()
+	if item != unittest.DefaultClusterID {
+		t.Fatalf("expected cluster %s to be queued but got %v", unittest.DefaultClusterID, item)
+	}
+}
@@ -0,0 +1,219 @@
+// Package crd detects which CRD versions handled by the admission controller
+// are installed, so that handlers for missing ones can be disabled.
+package crd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/handler"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+type Config struct {
+	Discovery discovery.DiscoveryInterface
+	Logger    micrologger.Logger
+}
+
+// Detector keeps track of which of the registered resources are served by the
+// API server. Resources are assumed to be installed until discovery reports
+// otherwise, so that errors talking to the API server never disable handlers.
+type Detector struct {
+	discovery discovery.DiscoveryInterface
+	logger    micrologger.Logger
+
+	mutex     sync.RWMutex
+	checked   bool
+	installed map[schema.GroupVersionResource]bool
+}
+
+func NewDetector(config Config) (*Detector, error) {
+	if config.Discovery == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Discovery must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	d := &Detector{
+		discovery: config.Discovery,
+		logger:    config.Logger,
+
+		installed: map[schema.GroupVersionResource]bool{},
+	}
+
+	return d, nil
+}
+
+// Check refreshes the installation state of all resources which are wrapped
+// by the detector. Resources which can not be discovered keep their state and
+// are reported in the returned error after all others were checked.
+func (d *Detector) Check() error {
+	d.mutex.RLock()
+	var resources []schema.GroupVersionResource
+	for gvr := range d.installed {
+		resources = append(resources, gvr)
+	}
+	d.mutex.RUnlock()
+
+	var failed []string
+	for _, gvr := range resources {
+		installed, err := d.discover(gvr)
+		if err != nil {
+			d.logger.Log("level", "warning", "message", fmt.Sprintf("unable to discover CRD version for %s", gvr.String()), "stack", microerror.JSON(err))
+			failed = append(failed, gvr.String())
+			continue
+		}
+		d.setInstalled(gvr, installed)
+	}
+
+	d.mutex.Lock()
+	d.checked = true
+	d.mutex.Unlock()
+
+	if len(failed) > 0 {
+		return microerror.Maskf(executionFailedError, "unable to discover CRD versions for %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// Checked returns whether the installation state of all resources was
+// discovered at least once.
+func (d *Detector) Checked() bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return d.checked
+}
+
+// Run checks the installed resources in the given interval until stop is closed.
+func (d *Detector) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := d.Check()
+			if err != nil {
+				d.logger.Log("level", "warning", "message", "unable to check installed CRD versions", "stack", microerror.JSON(err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Installed returns whether the given resource is served by the API server.
+func (d *Detector) Installed(gvr schema.GroupVersionResource) bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	installed, ok := d.installed[gvr]
+	return !ok || installed
+}
+
+// Wrap returns a handler which admits requests without processing them while
+// the given resource is not installed and passes them on to the given handler
+// otherwise.
+func (d *Detector) Wrap(gvr schema.GroupVersionResource, next http.Handler) http.Handler {
+	d.mutex.Lock()
+	if _, ok := d.installed[gvr]; !ok {
+		d.installed[gvr] = true
+	}
+	d.mutex.Unlock()
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if d.Installed(gvr) {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		metrics.SkippedRequests.WithLabelValues(gvr.Group, gvr.Version, gvr.Resource).Inc()
+		d.logger.Log("level", "warning", "message", fmt.Sprintf("admitting request for %s without processing because the CRD version is not installed", gvr.String()))
+
+		data, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		review := admissionv1.AdmissionReview{}
+		err = json.Unmarshal(data, &review)
+		apiVersion, ok := handler.ReviewAPIVersion(review.APIVersion)
+		if err != nil || review.Request == nil || !ok {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		resp, err := json.Marshal(admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "AdmissionReview",
+				APIVersion: apiVersion,
+			},
+			Response: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				UID:     review.Request.UID,
+			},
+		})
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if _, err := writer.Write(resp); err != nil {
+			d.logger.Log("level", "error", "message", "unable to write response", "stack", microerror.JSON(err))
+		}
+	})
+}
+
+func (d *Detector) discover(gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := d.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+	if resources == nil {
+		return false, nil
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *Detector) setInstalled(gvr schema.GroupVersionResource, installed bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.installed[gvr] != installed {
+		if installed {
+			d.logger.Log("level", "info", "message", fmt.Sprintf("CRD version for %s is installed, enabling handlers", gvr.String()))
+		} else {
+			d.logger.Log("level", "warning", "message", fmt.Sprintf("CRD version for %s is not installed, disabling handlers", gvr.String()))
+		}
+	}
+	d.installed[gvr] = installed
+
+	missing := 0.0
+	if !installed {
+		missing = 1.0
+	}
+	metrics.CRDMissing.WithLabelValues(gvr.Group, gvr.Version, gvr.Resource).Set(missing)
+}
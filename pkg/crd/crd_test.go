@@ -0,0 +1,174 @@
+package crd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeDiscovery answers like the API server, which returns NotFound for group
+// versions it does not serve, and fails for the group versions in errors.
+type fakeDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	errors map[string]error
+}
+
+func newFakeDiscovery(resources []*metav1.APIResourceList, errors map[string]error) *fakeDiscovery {
+	return &fakeDiscovery{
+		FakeDiscovery: &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}},
+		errors:        errors,
+	}
+}
+
+func (f *fakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if err, ok := f.errors[groupVersion]; ok {
+		return nil, err
+	}
+	for _, resourceList := range f.Resources {
+		if resourceList.GroupVersion == groupVersion {
+			return resourceList, nil
+		}
+	}
+	gv, err := schema.ParseGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	return nil, apierrors.NewNotFound(gv.WithResource("").GroupResource(), "")
+}
+
+func TestDetector(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "infrastructure.giantswarm.io", Version: "v1alpha2", Resource: "awsclusters"}
+
+	testCases := []struct {
+		name string
+
+		resources []*metav1.APIResourceList
+		installed bool
+	}{
+		{
+			// resource installed
+			name: "case 0",
+
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters"}},
+				},
+			},
+			installed: true,
+		},
+		{
+			// group version installed without the resource
+			name: "case 1",
+
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "networkpools"}},
+				},
+			},
+			installed: false,
+		},
+		{
+			// only another version installed
+			name: "case 2",
+
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha3",
+					APIResources: []metav1.APIResource{{Name: "awsclusters"}},
+				},
+			},
+			installed: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			d, err := NewDetector(Config{
+				Discovery: newFakeDiscovery(tc.resources, nil),
+				Logger:    microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			called := false
+			handler := d.Wrap(gvr, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				called = true
+			}))
+
+			err = d.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Installed(gvr) != tc.installed {
+				t.Fatalf("expected installed to be %t", tc.installed)
+			}
+
+			body, err := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{UID: "1234"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate/awscluster", bytes.NewReader(body)))
+
+			if called != tc.installed {
+				t.Fatalf("expected wrapped handler to be called: %t", tc.installed)
+			}
+			if !tc.installed {
+				review := admissionv1.AdmissionReview{}
+				err = json.Unmarshal(recorder.Body.Bytes(), &review)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if review.Response == nil || !review.Response.Allowed || review.Response.UID != "1234" {
+					t.Fatalf("expected request to be admitted but got %s", recorder.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDetectorCheckFailure(t *testing.T) {
+	awsClusters := schema.GroupVersionResource{Group: "infrastructure.giantswarm.io", Version: "v1alpha2", Resource: "awsclusters"}
+	releases := schema.GroupVersionResource{Group: "release.giantswarm.io", Version: "v1alpha1", Resource: "releases"}
+
+	d, err := NewDetector(Config{
+		Discovery: newFakeDiscovery(nil, map[string]error{
+			"infrastructure.giantswarm.io/v1alpha2": apierrors.NewServiceUnavailable("aggregated API unavailable"),
+		}),
+		Logger: microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Wrap(awsClusters, http.NotFoundHandler())
+	d.Wrap(releases, http.NotFoundHandler())
+
+	err = d.Check()
+	if !IsExecutionFailed(err) {
+		t.Fatalf("expected execution failed error but returned %v", err)
+	}
+	if !d.Checked() {
+		t.Fatalf("expected detector to be checked")
+	}
+	// Resources which can not be discovered are assumed to be installed.
+	if !d.Installed(awsClusters) {
+		t.Fatalf("expected %s to be installed", awsClusters.String())
+	}
+	// Other resources are still checked.
+	if d.Installed(releases) {
+		t.Fatalf("expected %s not to be installed", releases.String())
+	}
+}
@@ -0,0 +1,223 @@
+// Package decisiondiff replays a corpus of admission review fixtures against
+// two admission controller deployments and reports where their decisions differ.
+package decisiondiff
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+type Config struct {
+	// Baseline is the base URL of the admission controller currently running, e.g. https://localhost:8443.
+	Baseline string
+	// Candidate is the base URL of the admission controller which is about to be rolled out.
+	Candidate string
+	// CompareMessages reports differing denial messages in addition to differing decisions and patches.
+	CompareMessages bool
+	// Corpus is the directory holding the admission review fixtures. The directory of a fixture
+	// relative to the corpus is the webhook path, e.g. validate/awscluster/ha-masters.json is
+	// sent to /validate/awscluster.
+	Corpus string
+	// Insecure skips the TLS certificate verification of both targets.
+	Insecure bool
+	Timeout  time.Duration
+}
+
+// Decision is the outcome of a single admission review.
+type Decision struct {
+	Allowed bool
+	Message string
+	// Patch is the decoded JSON patch of a mutating webhook.
+	Patch interface{}
+	// Error is set when the webhook could not be reached or did not return a valid admission review.
+	Error string
+}
+
+// Difference records a fixture for which baseline and candidate decided differently.
+type Difference struct {
+	Fixture   string
+	Baseline  Decision
+	Candidate Decision
+}
+
+// Result summarizes a comparison run.
+type Result struct {
+	Fixtures    int
+	Differences []Difference
+}
+
+// Run sends every fixture of the corpus to baseline and candidate and compares the decisions.
+func Run(config Config) (Result, error) {
+	if config.Baseline == "" {
+		return Result{}, microerror.Maskf(invalidConfigError, "%T.Baseline must not be empty", config)
+	}
+	if config.Candidate == "" {
+		return Result{}, microerror.Maskf(invalidConfigError, "%T.Candidate must not be empty", config)
+	}
+	if config.Corpus == "" {
+		return Result{}, microerror.Maskf(invalidConfigError, "%T.Corpus must not be empty", config)
+	}
+
+	fixtures, err := findFixtures(config.Corpus)
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+
+	client := &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.Insecure, //nolint:gosec // comparisons usually target self-signed webhook certificates
+				MinVersion:         tls.VersionTLS12,
+			},
+		},
+	}
+
+	result := Result{
+		Fixtures: len(fixtures),
+	}
+	for _, fixture := range fixtures {
+		body, err := ioutil.ReadFile(filepath.Join(config.Corpus, fixture))
+		if err != nil {
+			return Result{}, microerror.Mask(err)
+		}
+		path := "/" + filepath.ToSlash(filepath.Dir(fixture))
+
+		baseline := send(client, strings.TrimSuffix(config.Baseline, "/")+path, body)
+		candidate := send(client, strings.TrimSuffix(config.Candidate, "/")+path, body)
+		if !equal(baseline, candidate, config.CompareMessages) {
+			result.Differences = append(result.Differences, Difference{
+				Fixture:   fixture,
+				Baseline:  baseline,
+				Candidate: candidate,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// Report writes a human readable summary of the result.
+func (r Result) Report(w io.Writer) {
+	for _, d := range r.Differences {
+		fmt.Fprintf(w, "%s\n", d.Fixture)
+		fmt.Fprintf(w, "  baseline:  %s\n", d.Baseline)
+		fmt.Fprintf(w, "  candidate: %s\n", d.Candidate)
+	}
+	fmt.Fprintf(w, "fixtures:    %d\n", r.Fixtures)
+	fmt.Fprintf(w, "differences: %d\n", len(r.Differences))
+}
+
+func (d Decision) String() string {
+	if d.Error != "" {
+		return fmt.Sprintf("error %q", d.Error)
+	}
+	s := "denied"
+	if d.Allowed {
+		s = "allowed"
+	}
+	if d.Message != "" {
+		s += fmt.Sprintf(" %q", d.Message)
+	}
+	if d.Patch != nil {
+		patch, _ := json.Marshal(d.Patch)
+		s += fmt.Sprintf(" patch %s", patch)
+	}
+	return s
+}
+
+func equal(a Decision, b Decision, compareMessages bool) bool {
+	if a.Error != "" || b.Error != "" {
+		return a.Error != "" && b.Error != ""
+	}
+	if a.Allowed != b.Allowed {
+		return false
+	}
+	if compareMessages && a.Message != b.Message {
+		return false
+	}
+	return reflect.DeepEqual(a.Patch, b.Patch)
+}
+
+// findFixtures returns the paths of all JSON files in the corpus relative to it, in lexical order.
+// Fixtures have to be placed in a directory which names the webhook.
+func findFixtures(corpus string) ([]string, error) {
+	var fixtures []string
+
+	err := filepath.Walk(corpus, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		fixture, err := filepath.Rel(corpus, path)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if filepath.Dir(fixture) == "." {
+			return microerror.Maskf(invalidFixtureError, "fixture %s has to be placed in a directory named after its webhook path", fixture)
+		}
+		fixtures = append(fixtures, fixture)
+		return nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	sort.Strings(fixtures)
+
+	return fixtures, nil
+}
+
+func send(client *http.Client, target string, body []byte) Decision {
+	resp, err := client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Decision{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Decision{Error: err.Error()}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Decision{Error: microerror.Maskf(unexpectedResponseError, "status code %d", resp.StatusCode).Error()}
+	}
+
+	review := admissionv1.AdmissionReview{}
+	err = json.Unmarshal(data, &review)
+	if err != nil {
+		return Decision{Error: err.Error()}
+	}
+	if review.Response == nil {
+		return Decision{Error: microerror.Maskf(unexpectedResponseError, "admission review without response").Error()}
+	}
+
+	decision := Decision{
+		Allowed: review.Response.Allowed,
+	}
+	if review.Response.Result != nil {
+		decision.Message = review.Response.Result.Message
+	}
+	if len(review.Response.Patch) > 0 {
+		err = json.Unmarshal(review.Response.Patch, &decision.Patch)
+		if err != nil {
+			return Decision{Error: err.Error()}
+		}
+	}
+
+	return decision
+}
@@ -0,0 +1,154 @@
+package decisiondiff
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRun(t *testing.T) {
+	type response struct {
+		allowed    bool
+		message    string
+		patch      string
+		statusCode int
+	}
+	testCases := []struct {
+		name string
+
+		baseline        response
+		candidate       response
+		compareMessages bool
+		differences     int
+	}{
+		{
+			// same decisions
+			name: "case 0",
+
+			baseline:    response{allowed: true, statusCode: http.StatusOK},
+			candidate:   response{allowed: true, statusCode: http.StatusOK},
+			differences: 0,
+		},
+		{
+			// candidate denies what baseline admits
+			name: "case 1",
+
+			baseline:    response{allowed: true, statusCode: http.StatusOK},
+			candidate:   response{allowed: false, message: "denied", statusCode: http.StatusOK},
+			differences: 2,
+		},
+		{
+			// same patches in different formatting
+			name: "case 2",
+
+			baseline:    response{allowed: true, patch: `[{"op":"add","path":"/a","value":"b"}]`, statusCode: http.StatusOK},
+			candidate:   response{allowed: true, patch: `[ {"value":"b", "op":"add", "path":"/a"} ]`, statusCode: http.StatusOK},
+			differences: 0,
+		},
+		{
+			// different patches
+			name: "case 3",
+
+			baseline:    response{allowed: true, patch: `[{"op":"add","path":"/a","value":"b"}]`, statusCode: http.StatusOK},
+			candidate:   response{allowed: true, patch: `[{"op":"add","path":"/a","value":"c"}]`, statusCode: http.StatusOK},
+			differences: 2,
+		},
+		{
+			// different messages are ignored by default
+			name: "case 4",
+
+			baseline:    response{allowed: false, message: "old message", statusCode: http.StatusOK},
+			candidate:   response{allowed: false, message: "new message", statusCode: http.StatusOK},
+			differences: 0,
+		},
+		{
+			// different messages are reported if requested
+			name: "case 5",
+
+			baseline:        response{allowed: false, message: "old message", statusCode: http.StatusOK},
+			candidate:       response{allowed: false, message: "new message", statusCode: http.StatusOK},
+			compareMessages: true,
+			differences:     2,
+		},
+		{
+			// candidate fails
+			name: "case 6",
+
+			baseline:    response{allowed: true, statusCode: http.StatusOK},
+			candidate:   response{statusCode: http.StatusInternalServerError},
+			differences: 2,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			webhook := func(r response) *httptest.Server {
+				return httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+					data, _ := ioutil.ReadAll(request.Body)
+					review := admissionv1.AdmissionReview{}
+					_ = json.Unmarshal(data, &review)
+
+					writer.WriteHeader(r.statusCode)
+					admissionResponse := &admissionv1.AdmissionResponse{
+						UID:     review.Request.UID,
+						Allowed: r.allowed,
+					}
+					if r.message != "" {
+						admissionResponse.Result = &metav1.Status{Message: r.message}
+					}
+					if r.patch != "" {
+						admissionResponse.Patch = []byte(r.patch)
+					}
+					resp, _ := json.Marshal(admissionv1.AdmissionReview{Response: admissionResponse})
+					_, _ = writer.Write(resp)
+				}))
+			}
+			baseline := webhook(tc.baseline)
+			defer baseline.Close()
+			candidate := webhook(tc.candidate)
+			defer candidate.Close()
+
+			corpus, err := ioutil.TempDir("", "decisiondiff")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(corpus)
+			for _, fixture := range []string{"mutate/awscluster/a.json", "validate/cluster/b.json"} {
+				err = os.MkdirAll(filepath.Join(corpus, filepath.Dir(fixture)), 0755)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = ioutil.WriteFile(filepath.Join(corpus, fixture), []byte(`{"request":{"uid":"1"}}`), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := Run(Config{
+				Baseline:        baseline.URL,
+				Candidate:       candidate.URL,
+				CompareMessages: tc.compareMessages,
+				Corpus:          corpus,
+				Insecure:        true,
+				Timeout:         time.Second,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Fixtures != 2 {
+				t.Fatalf("expected 2 fixtures but got %d", result.Fixtures)
+			}
+			if len(result.Differences) != tc.differences {
+				t.Fatalf("expected %d differences but got %d", tc.differences, len(result.Differences))
+			}
+		})
+	}
+}
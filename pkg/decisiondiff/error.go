@@ -0,0 +1,32 @@
+package decisiondiff
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidFixtureError = &microerror.Error{
+	Kind: "invalidFixtureError",
+}
+
+// IsInvalidFixture asserts invalidFixtureError.
+func IsInvalidFixture(err error) bool {
+	return microerror.Cause(err) == invalidFixtureError
+}
+
+var unexpectedResponseError = &microerror.Error{
+	Kind: "unexpectedResponseError",
+}
+
+// IsUnexpectedResponse asserts unexpectedResponseError.
+func IsUnexpectedResponse(err error) bool {
+	return microerror.Cause(err) == unexpectedResponseError
+}
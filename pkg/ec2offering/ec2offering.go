@@ -0,0 +1,129 @@
+// Package ec2offering looks up the EC2 instance types which are offered in the
+// availability zones of the installation region. The offerings of the whole
+// region are cached, so that admission requests only reach the EC2 API once
+// per TTL.
+package ec2offering
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+// Interface is implemented by everything which knows the instance type offerings of the region.
+type Interface interface {
+	// OfferedAvailabilityZones returns the availability zones in which the given instance type is
+	// offered. It is empty for instance types which do not exist in the region.
+	OfferedAvailabilityZones(instanceType string) ([]string, error)
+}
+
+// Describer is the part of the EC2 API which is needed to list the instance type offerings.
+type Describer interface {
+	DescribeInstanceTypeOfferings(input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error)
+}
+
+type Config struct {
+	// EC2 is the client used to list the offerings. A client for Region is created when it is nil.
+	EC2    Describer
+	Logger micrologger.Logger
+	Region string
+	// TTL is the time after which the cached offerings are fetched again.
+	TTL time.Duration
+}
+
+// Cache holds the instance type offerings of the region.
+type Cache struct {
+	ec2    Describer
+	logger micrologger.Logger
+	ttl    time.Duration
+
+	mutex       sync.Mutex
+	offerings   map[string][]string
+	refreshedAt time.Time
+}
+
+func New(config Config) (*Cache, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.TTL <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TTL must be greater than zero", config)
+	}
+	if config.EC2 == nil {
+		if config.Region == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Region must not be empty", config)
+		}
+		s, err := session.NewSession(&aws.Config{Region: aws.String(config.Region)})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		config.EC2 = ec2.New(s)
+	}
+
+	c := &Cache{
+		ec2:    config.EC2,
+		logger: config.Logger,
+		ttl:    config.TTL,
+	}
+
+	return c, nil
+}
+
+// OfferedAvailabilityZones returns the availability zones in which the given instance type is offered.
+// Stale offerings are used when they can not be refreshed, since instance types are rarely withdrawn.
+func (c *Cache) OfferedAvailabilityZones(instanceType string) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.offerings == nil || time.Since(c.refreshedAt) > c.ttl {
+		offerings, err := c.fetch()
+		if err != nil && c.offerings == nil {
+			return nil, microerror.Mask(err)
+		} else if err != nil {
+			c.logger.Log("level", "warning", "message", "using stale EC2 instance type offerings", "stack", microerror.JSON(err))
+		} else {
+			c.offerings = offerings
+			c.refreshedAt = time.Now()
+		}
+	}
+
+	return c.offerings[instanceType], nil
+}
+
+func (c *Cache) fetch() (map[string][]string, error) {
+	c.logger.Log("level", "debug", "message", "Fetching EC2 instance type offerings")
+
+	offerings := map[string][]string{}
+	input := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+	}
+	// The offerings are paginated. The SDK version in use has no pagination
+	// helper for them, so the pages are followed with their NextToken.
+	for {
+		output, err := c.ec2.DescribeInstanceTypeOfferings(input)
+		if err != nil {
+			return nil, microerror.Maskf(executionFailedError, "failed to fetch EC2 instance type offerings: %v", err)
+		}
+		for _, o := range output.InstanceTypeOfferings {
+			instanceType := aws.StringValue(o.InstanceType)
+			offerings[instanceType] = append(offerings[instanceType], aws.StringValue(o.Location))
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	for _, azs := range offerings {
+		sort.Strings(azs)
+	}
+	c.logger.Log("level", "debug", "message", fmt.Sprintf("Fetched offerings of %d EC2 instance types", len(offerings)))
+
+	return offerings, nil
+}
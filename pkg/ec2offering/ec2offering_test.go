@@ -0,0 +1,137 @@
+package ec2offering
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+type fakeDescriber struct {
+	calls     int
+	err       error
+	offerings []*ec2.InstanceTypeOffering
+}
+
+func (f *fakeDescriber) DescribeInstanceTypeOfferings(input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	// every offering is returned on its own page
+	var page int
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(aws.StringValue(input.NextToken))
+	}
+	output := &ec2.DescribeInstanceTypeOfferingsOutput{}
+	if page < len(f.offerings) {
+		output.InstanceTypeOfferings = []*ec2.InstanceTypeOffering{f.offerings[page]}
+	}
+	if page+1 < len(f.offerings) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func offering(instanceType string, az string) *ec2.InstanceTypeOffering {
+	return &ec2.InstanceTypeOffering{InstanceType: aws.String(instanceType), Location: aws.String(az), LocationType: aws.String(ec2.LocationTypeAvailabilityZone)}
+}
+
+func TestOfferedAvailabilityZones(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType string
+		expectedAZs  []string
+	}{
+		{
+			// instance type offered in some AZs
+			name: "case 0",
+
+			instanceType: "m5.xlarge",
+			expectedAZs:  []string{"eu-central-1a", "eu-central-1b"},
+		},
+		{
+			// instance type offered in a single AZ
+			name: "case 1",
+
+			instanceType: "p3.2xlarge",
+			expectedAZs:  []string{"eu-central-1c"},
+		},
+		{
+			// instance type does not exist
+			name: "case 2",
+
+			instanceType: "m5.xlarg",
+			expectedAZs:  nil,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			describer := &fakeDescriber{
+				offerings: []*ec2.InstanceTypeOffering{
+					offering("m5.xlarge", "eu-central-1b"),
+					offering("p3.2xlarge", "eu-central-1c"),
+					offering("m5.xlarge", "eu-central-1a"),
+				},
+			}
+			cache, err := New(Config{EC2: describer, Logger: microloggertest.New(), TTL: time.Hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			azs, err := cache.OfferedAvailabilityZones(tc.instanceType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(azs, tc.expectedAZs) {
+				t.Fatalf("expected %v to be equal to %v", tc.expectedAZs, azs)
+			}
+		})
+	}
+}
+
+func TestOfferingsCache(t *testing.T) {
+	describer := &fakeDescriber{offerings: []*ec2.InstanceTypeOffering{offering("m5.xlarge", "eu-central-1a")}}
+	cache, err := New(Config{EC2: describer, Logger: microloggertest.New(), TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// offerings are fetched once within the TTL
+	for i := 0; i < 3; i++ {
+		_, err = cache.OfferedAvailabilityZones("m5.xlarge")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if describer.calls != 1 {
+		t.Fatalf("expected 1 call to the EC2 API but got %d", describer.calls)
+	}
+
+	// stale offerings are used when the refresh fails
+	cache.refreshedAt = time.Now().Add(-2 * time.Hour)
+	describer.err = errors.New("throttled")
+	azs, err := cache.OfferedAvailabilityZones("m5.xlarge")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(azs, []string{"eu-central-1a"}) {
+		t.Fatalf("expected stale offerings but got %v", azs)
+	}
+
+	// the error is returned when there are no offerings yet
+	cache, err = New(Config{EC2: describer, Logger: microloggertest.New(), TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cache.OfferedAvailabilityZones("m5.xlarge")
+	if !IsExecutionFailed(err) {
+		t.Fatalf("expected execution failed error but got %v", err)
+	}
+}
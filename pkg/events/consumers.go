@@ -0,0 +1,143 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+const (
+	eventReasonDenied = "AdmissionDenied"
+	eventSource       = "aws-admission-controller"
+)
+
+// RecordMetrics counts admitted and rejected requests per operation and records the patch counts of mutations and
+// the duration of decisions. It has to be subscribed synchronously, so that the counters are complete under load.
+func RecordMetrics(decision Decision) {
+	var operation string
+	if decision.Request != nil {
+		operation = string(decision.Request.Operation)
+	}
+
+	if decision.Allowed {
+		metrics.SuccessfulRequests.WithLabelValues(decision.Webhook, decision.Resource).Inc()
+		metrics.AllowedRequests.WithLabelValues(decision.Webhook, decision.Resource, operation).Inc()
+		if decision.Webhook == WebhookMutating {
+			metrics.MutationPatches.WithLabelValues(decision.Webhook, decision.Resource, operation).Observe(float64(decision.Patches))
+		}
+	} else {
+		reason := decision.Reason
+		if reason == "" {
+			reason = ReasonDenied
+		}
+		metrics.RejectedRequests.WithLabelValues(decision.Webhook, decision.Resource).Inc()
+		metrics.DeniedRequests.WithLabelValues(decision.Webhook, decision.Resource, operation, reason).Inc()
+	}
+	if decision.Reason == ReasonRateLimited {
+		metrics.RateLimitedRequests.WithLabelValues(decision.Webhook, decision.Resource, operation).Inc()
+	}
+	if decision.Reason == ReasonTimeout {
+		metrics.TimedOutRequests.WithLabelValues(decision.Webhook, decision.Resource, operation).Inc()
+	}
+	if decision.Reason == ReasonBypass {
+		metrics.BypassedRequests.WithLabelValues(decision.Webhook, decision.Resource, operation).Inc()
+	}
+	if decision.Reason == ReasonFailOpen {
+		metrics.FailOpenRequests.WithLabelValues(decision.Webhook, decision.Resource, operation).Inc()
+	}
+	metrics.DecisionDuration.WithLabelValues(decision.Webhook, decision.Resource, operation).Observe(decision.Duration.Seconds())
+}
+
+// KubernetesEvents creates a warning event for every denied request, so that
+// users can find out why their change was rejected with kubectl describe.
+// Events are created by an own worker, so that slow API requests do not hold
+// up other consumers.
+type KubernetesEvents struct {
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+	queue     chan Decision
+}
+
+func NewKubernetesEvents(k8sClient k8sclient.Interface, logger micrologger.Logger) (*KubernetesEvents, error) {
+	if k8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "k8sClient must not be empty")
+	}
+	if logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "logger must not be empty")
+	}
+
+	k := &KubernetesEvents{
+		k8sClient: k8sClient,
+		logger:    logger,
+		queue:     make(chan Decision, defaultQueueSize),
+	}
+	go k.run()
+
+	return k, nil
+}
+
+// Consume queues an event for denied requests without blocking. Events are
+// dropped when the queue is full.
+func (k *KubernetesEvents) Consume(decision Decision) {
+	request := decision.Request
+	if decision.Allowed || request == nil || decision.Name == "" {
+		return
+	}
+	if request.DryRun != nil && *request.DryRun {
+		return
+	}
+
+	select {
+	case k.queue <- decision:
+	default:
+		metrics.DroppedEvents.WithLabelValues(decision.Webhook, decision.Resource).Inc()
+	}
+}
+
+func (k *KubernetesEvents) run() {
+	for decision := range k.queue {
+		k.create(decision)
+	}
+}
+
+func (k *KubernetesEvents) create(decision Decision) {
+	request := decision.Request
+	namespace := request.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", decision.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: metav1.GroupVersion{Group: request.Kind.Group, Version: request.Kind.Version}.String(),
+			Kind:       request.Kind.Kind,
+			Name:       decision.Name,
+			Namespace:  request.Namespace,
+		},
+		Reason:         eventReasonDenied,
+		Message:        fmt.Sprintf("%s %s request by %s was denied: %s", request.Kind.Kind, request.Operation, request.UserInfo.Username, decision.Message),
+		Source:         corev1.EventSource{Component: eventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+
+	_, err := k.k8sClient.K8sClient().CoreV1().Events(namespace).Create(context.Background(), event, metav1.CreateOptions{})
+	if err != nil {
+		k.logger.Log("level", "warning", "message", fmt.Sprintf("unable to create event for denied request on %s %s", request.Kind.Kind, decision.Name), "stack", microerror.JSON(err))
+	}
+}
@@ -0,0 +1,169 @@
+// Package events decouples admission decisions from their side effects.
+// Handlers publish a Decision for every admission request and consumers
+// process them asynchronously, so that the admission path stays fast.
+// Consumers which must not miss decisions are called synchronously instead.
+// Annotations are not written back by consumers. Changes of admitted objects
+// have to be part of the mutation patch, since updating an object after its
+// admission would send another admission request for it.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+const (
+	// defaultQueueSize is the number of decisions which are buffered before new decisions are dropped.
+	defaultQueueSize = 1000
+
+	WebhookMutating   = "mutating"
+	WebhookValidating = "validating"
+
+	// ReasonBypass is the reason of requests admitted without mutation and validation because of their user or group.
+	ReasonBypass = "bypass"
+	// ReasonDenied is the reason of requests a validator did not allow without returning an error.
+	ReasonDenied = "denied"
+	// ReasonInternalError is the reason of requests denied because of an internal error of the admission controller.
+	ReasonInternalError = "internalError"
+	// ReasonFailOpen is the reason of requests admitted without a decision because a dependency of the handler failed.
+	ReasonFailOpen = "failOpen"
+	// ReasonPanic is the reason of requests whose handler panicked.
+	ReasonPanic = "panic"
+	// ReasonRateLimited is the reason of requests denied because their handler exceeded its rate limit.
+	ReasonRateLimited = "rateLimited"
+	// ReasonTimeout is the reason of requests whose handler did not decide within its timeout.
+	ReasonTimeout = "timeout"
+	// ReasonUnknown is the reason of requests denied with errors of unknown kind.
+	ReasonUnknown = "unknown"
+)
+
+// Decision is the outcome of a single admission request.
+type Decision struct {
+	// Webhook is either "mutating" or "validating".
+	Webhook  string
+	Resource string
+	Request  *admissionv1.AdmissionRequest
+	// Name is the name of the object, which is not part of create requests for generated names.
+	Name    string
+	Allowed bool
+	// Message explains why a request was denied.
+	Message string
+	// Reason is a short cause of a denial which is suitable as metric label, e.g. the kind of the error.
+	Reason string
+	// Patches is the number of patch operations returned by a mutating webhook.
+	Patches int
+	// Patch is the JSON patch returned by a mutating webhook.
+	Patch    []byte
+	Duration time.Duration
+}
+
+// Reason returns the cause of a denial for the given error, which is the kind of microerror errors.
+func Reason(err error) string {
+	if e, ok := microerror.Cause(err).(*microerror.Error); ok && e.Kind != "" {
+		return e.Kind
+	}
+	return ReasonUnknown
+}
+
+// Consumer handles published decisions. Consumers are called sequentially
+// from a single goroutine and should hand off slow work themselves.
+type Consumer interface {
+	Consume(decision Decision)
+}
+
+// ConsumerFunc allows to use ordinary functions as consumers.
+type ConsumerFunc func(decision Decision)
+
+func (f ConsumerFunc) Consume(decision Decision) {
+	f(decision)
+}
+
+// Bus delivers published decisions to all subscribed consumers.
+type Bus struct {
+	mutex         sync.RWMutex
+	consumers     []Consumer
+	syncConsumers []Consumer
+	queue         chan Decision
+}
+
+// NewBus returns a bus buffering up to queueSize decisions and starts
+// delivering them.
+func NewBus(queueSize int) *Bus {
+	b := &Bus{
+		queue: make(chan Decision, queueSize),
+	}
+	go b.run()
+
+	return b
+}
+
+// Publish passes the decision to the synchronous consumers and queues it for
+// delivery to the others without blocking. Queued decisions are dropped when
+// the queue is full.
+func (b *Bus) Publish(decision Decision) {
+	b.mutex.RLock()
+	syncConsumers := b.syncConsumers
+	b.mutex.RUnlock()
+
+	for _, c := range syncConsumers {
+		c.Consume(decision)
+	}
+
+	select {
+	case b.queue <- decision:
+	default:
+		metrics.DroppedEvents.WithLabelValues(decision.Webhook, decision.Resource).Inc()
+	}
+}
+
+// Subscribe registers a consumer for all decisions published afterwards.
+func (b *Bus) Subscribe(consumer Consumer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.consumers = append(b.consumers, consumer)
+}
+
+// SubscribeSync registers a consumer which is called by Publish for all
+// decisions published afterwards, so that it never misses one. It is called
+// concurrently from the handlers and has to be fast.
+func (b *Bus) SubscribeSync(consumer Consumer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.syncConsumers = append(b.syncConsumers, consumer)
+}
+
+func (b *Bus) run() {
+	for decision := range b.queue {
+		b.mutex.RLock()
+		consumers := b.consumers
+		b.mutex.RUnlock()
+
+		for _, c := range consumers {
+			c.Consume(decision)
+		}
+	}
+}
+
+var defaultBus = NewBus(defaultQueueSize)
+
+// Publish queues the decision on the default bus used by the webhook handlers.
+func Publish(decision Decision) {
+	defaultBus.Publish(decision)
+}
+
+// Subscribe registers a consumer on the default bus used by the webhook handlers.
+func Subscribe(consumer Consumer) {
+	defaultBus.Subscribe(consumer)
+}
+
+// SubscribeSync registers a synchronous consumer on the default bus used by the webhook handlers.
+func SubscribeSync(consumer Consumer) {
+	defaultBus.SubscribeSync(consumer)
+}
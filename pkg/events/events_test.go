@@ -0,0 +1,106 @@
+package events
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+func TestBus(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		consumers int
+		decisions int
+	}{
+		{
+			// single consumer
+			name: "case 0",
+
+			consumers: 1,
+			decisions: 3,
+		},
+		{
+			// all consumers receive all decisions
+			name: "case 1",
+
+			consumers: 3,
+			decisions: 5,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			bus := NewBus(tc.decisions)
+
+			received := make(chan Decision, tc.consumers*tc.decisions)
+			for c := 0; c < tc.consumers; c++ {
+				bus.Subscribe(ConsumerFunc(func(decision Decision) {
+					received <- decision
+				}))
+			}
+			for d := 0; d < tc.decisions; d++ {
+				bus.Publish(Decision{Webhook: WebhookValidating, Resource: "awscluster", Allowed: true})
+			}
+
+			for r := 0; r < tc.consumers*tc.decisions; r++ {
+				select {
+				case <-received:
+				case <-time.After(time.Second):
+					t.Fatalf("expected %d decisions but received %d", tc.consumers*tc.decisions, r)
+				}
+			}
+		})
+	}
+}
+
+func TestReason(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		err            error
+		expectedReason string
+	}{
+		{
+			// microerror errors are reported by kind
+			name: "case 0",
+
+			err:            microerror.Maskf(invalidConfigError, "test"),
+			expectedReason: "invalidConfigError",
+		},
+		{
+			// other errors have no kind
+			name: "case 1",
+
+			err:            errors.New("test"),
+			expectedReason: ReasonUnknown,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			reason := Reason(tc.err)
+			if reason != tc.expectedReason {
+				t.Fatalf("expected reason %#q but got %#q", tc.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func TestBusSync(t *testing.T) {
+	// A full queue drops decisions for asynchronous consumers only.
+	bus := &Bus{queue: make(chan Decision)}
+
+	var received int
+	bus.SubscribeSync(ConsumerFunc(func(decision Decision) {
+		received++
+	}))
+	for d := 0; d < 3; d++ {
+		bus.Publish(Decision{Webhook: WebhookValidating, Resource: "awscluster", Allowed: true})
+	}
+
+	if received != 3 {
+		t.Fatalf("expected 3 decisions but received %d", received)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+type BypassConfig struct {
+	// Groups whose members skip mutation and validation, e.g. a break-glass group.
+	Groups []string
+	// ServiceAccounts like giantswarm/cluster-operator which skip mutation and validation.
+	ServiceAccounts []string
+	// Users which skip mutation and validation.
+	Users []string
+}
+
+// Bypass holds the users and groups whose requests are admitted without
+// mutation and validation. A nil *Bypass bypasses nobody.
+type Bypass struct {
+	groups map[string]bool
+	users  map[string]bool
+}
+
+func NewBypass(config BypassConfig) (*Bypass, error) {
+	b := &Bypass{
+		groups: map[string]bool{},
+		users:  map[string]bool{},
+	}
+	for _, g := range config.Groups {
+		if g == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Groups must not contain empty groups", config)
+		}
+		b.groups[g] = true
+	}
+	for _, s := range config.ServiceAccounts {
+		parts := strings.Split(s, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "bypass service account %#q has to be like giantswarm/cluster-operator", s)
+		}
+		b.users[fmt.Sprintf("system:serviceaccount:%s:%s", parts[0], parts[1])] = true
+	}
+	for _, u := range config.Users {
+		if u == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Users must not contain empty users", config)
+		}
+		b.users[u] = true
+	}
+
+	return b, nil
+}
+
+// Match returns whether the request of the given user skips mutation and
+// validation, and the user or group it matched for logging.
+func (b *Bypass) Match(userInfo authenticationv1.UserInfo) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+	if b.users[userInfo.Username] {
+		return fmt.Sprintf("user %s", userInfo.Username), true
+	}
+	for _, g := range userInfo.Groups {
+		if b.groups[g] {
+			return fmt.Sprintf("group %s", g), true
+		}
+	}
+	return "", false
+}
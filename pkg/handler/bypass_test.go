@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"strconv"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestBypass(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		config   BypassConfig
+		userInfo authenticationv1.UserInfo
+		bypassed bool
+		valid    bool
+	}{
+		{
+			// bypassed user
+			name: "case 0",
+
+			config:   BypassConfig{Users: []string{"break-glass"}},
+			userInfo: authenticationv1.UserInfo{Username: "break-glass"},
+			bypassed: true,
+			valid:    true,
+		},
+		{
+			// bypassed service account
+			name: "case 1",
+
+			config:   BypassConfig{ServiceAccounts: []string{"giantswarm/cluster-operator"}},
+			userInfo: authenticationv1.UserInfo{Username: "system:serviceaccount:giantswarm:cluster-operator"},
+			bypassed: true,
+			valid:    true,
+		},
+		{
+			// member of a bypassed group
+			name: "case 2",
+
+			config:   BypassConfig{Groups: []string{"giantswarm:break-glass"}},
+			userInfo: authenticationv1.UserInfo{Username: "jane", Groups: []string{"system:authenticated", "giantswarm:break-glass"}},
+			bypassed: true,
+			valid:    true,
+		},
+		{
+			// user who is not bypassed
+			name: "case 3",
+
+			config:   BypassConfig{Groups: []string{"giantswarm:break-glass"}, Users: []string{"break-glass"}},
+			userInfo: authenticationv1.UserInfo{Username: "jane", Groups: []string{"system:authenticated"}},
+			bypassed: false,
+			valid:    true,
+		},
+		{
+			// service account without namespace
+			name: "case 4",
+
+			config: BypassConfig{ServiceAccounts: []string{"cluster-operator"}},
+			valid:  false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			bypass, err := NewBypass(tc.config)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but returned %v", err)
+				}
+				return
+			}
+			if _, bypassed := bypass.Match(tc.userInfo); bypassed != tc.bypassed {
+				t.Fatalf("expected bypassed to be %t", tc.bypassed)
+			}
+		})
+	}
+}
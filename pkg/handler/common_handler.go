@@ -6,6 +6,42 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	// AdmissionReviewV1 is the API version of admission reviews sent by API servers with v1 webhook configurations.
+	AdmissionReviewV1 = "admission.k8s.io/v1"
+	// AdmissionReviewV1beta1 is the API version of admission reviews sent by older API servers. Requests and responses
+	// have the same fields as in v1.
+	AdmissionReviewV1beta1 = "admission.k8s.io/v1beta1"
+
+	// PolicyAllow admits requests the handler could not decide about.
+	PolicyAllow = "allow"
+	// PolicyDeny rejects requests the handler could not decide about.
+	PolicyDeny = "deny"
+)
+
+// Policies are applied by the mutating and validating handlers to requests
+// they can not or must not decide about. The zero value applies none.
+type Policies struct {
+	Bypass     *Bypass
+	FailOpen   *FailOpen
+	RateLimits *RateLimits
+	Recovery   *Recovery
+	Timeouts   *Timeouts
+}
+
+// ReviewAPIVersion returns the API version the response to an admission review is sent in, which has to be the
+// version of the request. Reviews without API version are answered in admission.k8s.io/v1. The second return value
+// is false for unsupported versions.
+func ReviewAPIVersion(apiVersion string) (string, bool) {
+	switch apiVersion {
+	case "", AdmissionReviewV1:
+		return AdmissionReviewV1, true
+	case AdmissionReviewV1beta1:
+		return AdmissionReviewV1beta1, true
+	}
+	return "", false
+}
+
 func ExtractName(request *admissionv1.AdmissionRequest, deserializer runtime.Decoder) string {
 	if request.Name != "" {
 		return request.Name
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestReviewAPIVersion(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		apiVersion         string
+		expectedAPIVersion string
+		expectedOK         bool
+	}{
+		{
+			// admission.k8s.io/v1 review
+			name: "case 0",
+
+			apiVersion:         "admission.k8s.io/v1",
+			expectedAPIVersion: AdmissionReviewV1,
+			expectedOK:         true,
+		},
+		{
+			// admission.k8s.io/v1beta1 review of an older API server
+			name: "case 1",
+
+			apiVersion:         "admission.k8s.io/v1beta1",
+			expectedAPIVersion: AdmissionReviewV1beta1,
+			expectedOK:         true,
+		},
+		{
+			// review without API version
+			name: "case 2",
+
+			expectedAPIVersion: AdmissionReviewV1,
+			expectedOK:         true,
+		},
+		{
+			// unsupported API version
+			name: "case 3",
+
+			apiVersion: "admission.k8s.io/v2",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			apiVersion, ok := ReviewAPIVersion(tc.apiVersion)
+			if ok != tc.expectedOK {
+				t.Fatalf("expected ok %t but got %t", tc.expectedOK, ok)
+			}
+			if apiVersion != tc.expectedAPIVersion {
+				t.Fatalf("expected API version %#q but got %#q", tc.expectedAPIVersion, apiVersion)
+			}
+		})
+	}
+}
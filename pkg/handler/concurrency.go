@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+type ConcurrencyLimiterConfig struct {
+	Logger micrologger.Logger
+	// Max is the number of admission requests processed at the same time by
+	// all handlers. Unlimited when 0.
+	Max int
+}
+
+// ConcurrencyLimiter caps the admission requests processed at the same time,
+// so that a burst of requests can not exhaust the memory of the webhook.
+type ConcurrencyLimiter struct {
+	logger micrologger.Logger
+	slots  chan struct{}
+}
+
+func NewConcurrencyLimiter(config ConcurrencyLimiterConfig) (*ConcurrencyLimiter, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Max < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Max must not be negative", config)
+	}
+
+	c := &ConcurrencyLimiter{
+		logger: config.Logger,
+	}
+	if config.Max > 0 {
+		c.slots = make(chan struct{}, config.Max)
+	}
+
+	return c, nil
+}
+
+// Wrap returns a handler which answers requests exceeding the limit with 429
+// Too Many Requests before reading their body. The API server then applies the
+// failure policy of the webhook.
+func (c *ConcurrencyLimiter) Wrap(next http.Handler) http.Handler {
+	if c.slots == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case c.slots <- struct{}{}:
+		default:
+			metrics.ConcurrencyLimitedRequests.WithLabelValues(request.URL.Path).Inc()
+			c.logger.Log("level", "warning", "message", fmt.Sprintf("rejected request to %s because %d requests are in flight", request.URL.Path, cap(c.slots)))
+			writer.Header().Set("Retry-After", "1")
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		metrics.InFlightRequests.Inc()
+		defer func() {
+			metrics.InFlightRequests.Dec()
+			<-c.slots
+		}()
+
+		next.ServeHTTP(writer, request)
+	})
+}
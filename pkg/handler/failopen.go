@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// FailOpen holds the handlers which admit requests when their dependencies
+// fail, e.g. when a Release can not be read. Denials of their checks still
+// apply. A nil *FailOpen fails closed for all handlers.
+type FailOpen struct {
+	handlers map[string]bool
+}
+
+// NewFailOpen returns the fail-open policy of the given handlers like
+// validating/awsmachinedeployment.
+func NewFailOpen(handlers []string) (*FailOpen, error) {
+	f := &FailOpen{
+		handlers: map[string]bool{},
+	}
+	for _, h := range handlers {
+		parts := strings.Split(h, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "fail-open handler %#q has to be like validating/awsmachinedeployment", h)
+		}
+		f.handlers[h] = true
+	}
+
+	return f, nil
+}
+
+// Allow returns whether the handler of the given webhook and resource admits
+// the request although deciding about it failed with err.
+func (f *FailOpen) Allow(webhook, resource string, err error) bool {
+	if f == nil || !f.handlers[webhook+"/"+resource] {
+		return false
+	}
+	return IsDependencyFailure(err)
+}
+
+// IsDependencyFailure returns whether err is caused by a dependency of a
+// handler like the Kubernetes API instead of a decision of one of its checks,
+// which are reported with microerror errors.
+func IsDependencyFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	cause := microerror.Cause(err)
+	if _, ok := cause.(*microerror.Error); ok {
+		return false
+	}
+
+	if apierrors.IsForbidden(cause) ||
+		apierrors.IsInternalError(cause) ||
+		apierrors.IsServerTimeout(cause) ||
+		apierrors.IsServiceUnavailable(cause) ||
+		apierrors.IsTimeout(cause) ||
+		apierrors.IsTooManyRequests(cause) ||
+		apierrors.IsUnauthorized(cause) ||
+		apierrors.IsUnexpectedServerError(cause) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(cause, &netErr) {
+		return true
+	}
+	return errors.Is(cause, context.DeadlineExceeded)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var notAllowedError = &microerror.Error{
+	Kind: "notAllowedError",
+}
+
+func TestFailOpen(t *testing.T) {
+	releases := schema.GroupResource{Group: "release.giantswarm.io", Resource: "releases"}
+
+	testCases := []struct {
+		name string
+
+		handlers []string
+		webhook  string
+		resource string
+		err      error
+		allowed  bool
+		valid    bool
+	}{
+		{
+			// unavailable Kubernetes API
+			name: "case 0",
+
+			handlers: []string{"validating/awsmachinedeployment"},
+			webhook:  "validating",
+			resource: "awsmachinedeployment",
+			err:      microerror.Mask(apierrors.NewServiceUnavailable("etcd leader changed")),
+			allowed:  true,
+			valid:    true,
+		},
+		{
+			// forbidden lookup
+			name: "case 1",
+
+			handlers: []string{"validating/awsmachinedeployment"},
+			webhook:  "validating",
+			resource: "awsmachinedeployment",
+			err:      microerror.Mask(apierrors.NewForbidden(releases, "v13.0.0", errors.New("missing permission"))),
+			allowed:  true,
+			valid:    true,
+		},
+		{
+			// denial of a check
+			name: "case 2",
+
+			handlers: []string{"validating/awsmachinedeployment"},
+			webhook:  "validating",
+			resource: "awsmachinedeployment",
+			err:      microerror.Maskf(notAllowedError, "instance type not allowed"),
+			allowed:  false,
+			valid:    true,
+		},
+		{
+			// handler which fails closed
+			name: "case 3",
+
+			handlers: []string{"validating/awsmachinedeployment"},
+			webhook:  "mutating",
+			resource: "awsmachinedeployment",
+			err:      microerror.Mask(apierrors.NewServiceUnavailable("etcd leader changed")),
+			allowed:  false,
+			valid:    true,
+		},
+		{
+			// not found objects are no dependency failures
+			name: "case 4",
+
+			handlers: []string{"validating/awsmachinedeployment"},
+			webhook:  "validating",
+			resource: "awsmachinedeployment",
+			err:      microerror.Mask(apierrors.NewNotFound(releases, "v13.0.0")),
+			allowed:  false,
+			valid:    true,
+		},
+		{
+			// handler without webhook
+			name: "case 5",
+
+			handlers: []string{"awsmachinedeployment"},
+			valid:    false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			failOpen, err := NewFailOpen(tc.handlers)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but returned %v", err)
+				}
+				return
+			}
+			if allowed := failOpen.Allow(tc.webhook, tc.resource, tc.err); allowed != tc.allowed {
+				t.Fatalf("expected allowed to be %t", tc.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+type RateLimitsConfig struct {
+	// Burst is the number of requests a handler admits at once above its rate.
+	Burst int
+	// Limits are requests per second of single handlers like
+	// validating/awsmachinedeployment=10. Other handlers are unlimited.
+	Limits []string
+}
+
+// RateLimits holds the rate limiters of the handlers. A nil *RateLimits never
+// limits.
+type RateLimits struct {
+	limiters map[string]flowcontrol.RateLimiter
+}
+
+func NewRateLimits(config RateLimitsConfig) (*RateLimits, error) {
+	if len(config.Limits) > 0 && config.Burst < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Burst must be at least 1", config)
+	}
+
+	r := &RateLimits{
+		limiters: map[string]flowcontrol.RateLimiter{},
+	}
+	for _, l := range config.Limits {
+		parts := strings.SplitN(l, "=", 2)
+		if len(parts) != 2 || !strings.Contains(parts[0], "/") {
+			return nil, microerror.Maskf(invalidConfigError, "rate limit %#q has to be like validating/awsmachinedeployment=10", l)
+		}
+		qps, err := strconv.ParseFloat(parts[1], 32)
+		if err != nil || qps <= 0 {
+			return nil, microerror.Maskf(invalidConfigError, "rate limit %#q has no valid number of requests per second", l)
+		}
+		r.limiters[parts[0]] = flowcontrol.NewTokenBucketRateLimiter(float32(qps), config.Burst)
+	}
+
+	return r, nil
+}
+
+// Accept returns whether the handler of the given webhook and resource may
+// process another request now.
+func (r *RateLimits) Accept(webhook, resource string) bool {
+	if r == nil {
+		return true
+	}
+	limiter, ok := r.limiters[fmt.Sprintf("%s/%s", webhook, resource)]
+	if !ok {
+		return true
+	}
+	return limiter.TryAccept()
+}
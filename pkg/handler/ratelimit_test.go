@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+func TestRateLimits(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		config   RateLimitsConfig
+		webhook  string
+		resource string
+		accepted int
+		valid    bool
+	}{
+		{
+			// handler without rate limit
+			name: "case 0",
+
+			config:   RateLimitsConfig{Burst: 2, Limits: []string{"validating/awsmachinedeployment=0.001"}},
+			webhook:  "validating",
+			resource: "awscluster",
+			accepted: 5,
+			valid:    true,
+		},
+		{
+			// rate limited handler accepts its burst
+			name: "case 1",
+
+			config:   RateLimitsConfig{Burst: 2, Limits: []string{"validating/awsmachinedeployment=0.001"}},
+			webhook:  "validating",
+			resource: "awsmachinedeployment",
+			accepted: 2,
+			valid:    true,
+		},
+		{
+			// rate limit without webhook
+			name: "case 2",
+
+			config: RateLimitsConfig{Burst: 2, Limits: []string{"awsmachinedeployment=10"}},
+			valid:  false,
+		},
+		{
+			// rate limit without valid rate
+			name: "case 3",
+
+			config: RateLimitsConfig{Burst: 2, Limits: []string{"validating/awsmachinedeployment=0"}},
+			valid:  false,
+		},
+		{
+			// rate limit without burst
+			name: "case 4",
+
+			config: RateLimitsConfig{Limits: []string{"validating/awsmachinedeployment=10"}},
+			valid:  false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rateLimits, err := NewRateLimits(tc.config)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but returned %v", err)
+				}
+				return
+			}
+
+			var accepted int
+			for i := 0; i < 5; i++ {
+				if rateLimits.Accept(tc.webhook, tc.resource) {
+					accepted++
+				}
+			}
+			if accepted != tc.accepted {
+				t.Fatalf("expected %d accepted requests but got %d", tc.accepted, accepted)
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter, err := NewConcurrencyLimiter(ConcurrencyLimiterConfig{
+		Logger: microloggertest.New(),
+		Max:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nested *httptest.ResponseRecorder
+	handler := limiter.Wrap(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// A request arriving while this one is in flight exceeds the limit.
+		if nested == nil {
+			nested = httptest.NewRecorder()
+			limiter.Wrap(http.NotFoundHandler()).ServeHTTP(nested, httptest.NewRequest(http.MethodPost, "/validate/awscluster", nil))
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate/awscluster", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, recorder.Code)
+	}
+	if nested.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d but got %d", http.StatusTooManyRequests, nested.Code)
+	}
+
+	// The slot is released after the request finished.
+	recorder = httptest.NewRecorder()
+	limiter.Wrap(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate/awscluster", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, recorder.Code)
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+)
+
+// panicMessage is returned to users instead of the panic value, which may
+// contain internals.
+const panicMessage = "Internal error of the admission controller. The request was %s without being processed."
+
+type RecoveryConfig struct {
+	Logger micrologger.Logger
+	// Policy is either PolicyAllow or PolicyDeny.
+	Policy string
+}
+
+// Recovery converts panics of handlers into admission responses, so that the
+// API server gets an answer instead of a dropped connection.
+type Recovery struct {
+	allow  bool
+	logger micrologger.Logger
+}
+
+func NewRecovery(config RecoveryConfig) (*Recovery, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Policy != PolicyAllow && config.Policy != PolicyDeny {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Policy must be either %s or %s", config, PolicyAllow, PolicyDeny)
+	}
+
+	r := &Recovery{
+		allow:  config.Policy == PolicyAllow,
+		logger: config.Logger,
+	}
+
+	return r, nil
+}
+
+// Allow returns whether requests are admitted when their handler panicked.
+func (r *Recovery) Allow() bool {
+	return r != nil && r.allow
+}
+
+// Message returns the message of responses to requests whose handler panicked.
+func (r *Recovery) Message() string {
+	if r.Allow() {
+		return fmt.Sprintf(panicMessage, "admitted")
+	}
+	return fmt.Sprintf(panicMessage, "rejected")
+}
+
+// Recover has to be deferred around code which may panic, also in goroutines,
+// where a panic would crash the process. It logs and counts the panic and sets
+// err to a panicError.
+func Recover(log func(keyVals ...interface{}), path string, err *error) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	*err = recovered(log, path, value)
+}
+
+// Wrap returns a handler which answers admission reviews whose handler
+// panicked according to the policy, unless a response was written already.
+func (r *Recovery) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(data))
+		recorder := &writtenRecorder{ResponseWriter: writer}
+
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+			_ = recovered(r.logger.Log, request.URL.Path, value)
+			if !recorder.written {
+				r.writeResponse(writer, data)
+			}
+		}()
+
+		next.ServeHTTP(recorder, request)
+	})
+}
+
+func (r *Recovery) writeResponse(writer http.ResponseWriter, data []byte) {
+	review := admissionv1.AdmissionReview{}
+	err := json.Unmarshal(data, &review)
+	apiVersion, ok := ReviewAPIVersion(review.APIVersion)
+	if err != nil || review.Request == nil || !ok {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{
+		Allowed: r.Allow(),
+		UID:     review.Request.UID,
+	}
+	if !response.Allowed {
+		response.Result = &metav1.Status{
+			Reason:  metav1.StatusReasonInternalError,
+			Code:    http.StatusInternalServerError,
+			Message: r.Message(),
+		}
+	}
+	resp, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AdmissionReview",
+			APIVersion: apiVersion,
+		},
+		Response: response,
+	})
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, err := writer.Write(resp); err != nil {
+		r.logger.Log("level", "error", "message", "unable to write response", "stack", microerror.JSON(err))
+	}
+}
+
+func recovered(log func(keyVals ...interface{}), path string, value interface{}) error {
+	metrics.RecoveredPanics.WithLabelValues(path).Inc()
+	log("level", "error", "message", fmt.Sprintf("recovered panic handling %s: %v", path, value), "stack", string(debug.Stack()))
+
+	return microerror.Maskf(panicError, "%v", value)
+}
+
+// writtenRecorder records whether a response was started.
+type writtenRecorder struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *writtenRecorder) WriteHeader(statusCode int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *writtenRecorder) Write(data []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(data)
+}
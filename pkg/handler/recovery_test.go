@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestRecoveryWrap(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		policy  string
+		panics  bool
+		allowed bool
+	}{
+		{
+			// handler does not panic
+			name: "case 0",
+
+			policy:  PolicyDeny,
+			panics:  false,
+			allowed: true,
+		},
+		{
+			// panic is admitted
+			name: "case 1",
+
+			policy:  PolicyAllow,
+			panics:  true,
+			allowed: true,
+		},
+		{
+			// panic is rejected
+			name: "case 2",
+
+			policy:  PolicyDeny,
+			panics:  true,
+			allowed: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			recovery, err := NewRecovery(RecoveryConfig{
+				Logger: microloggertest.New(),
+				Policy: tc.policy,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := recovery.Wrap(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if tc.panics {
+					panic("assignment to entry in nil map")
+				}
+				resp, err := json.Marshal(admissionv1.AdmissionReview{Response: &admissionv1.AdmissionResponse{Allowed: true, UID: "uid"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, _ = writer.Write(resp)
+			}))
+
+			body, err := json.Marshal(admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{UID: "uid"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate/awscluster", bytes.NewReader(body)))
+
+			review := admissionv1.AdmissionReview{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &review)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if review.Response == nil || review.Response.UID != "uid" {
+				t.Fatalf("expected response for request uid but got %v", review.Response)
+			}
+			if review.Response.Allowed != tc.allowed {
+				t.Fatalf("expected allowed to be %t", tc.allowed)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var err error
+	func() {
+		defer Recover(microloggertest.New().Log, "/validate/awscluster", &err)
+		panic("nil map")
+	}()
+	if !IsPanic(err) {
+		t.Fatalf("expected panic error but returned %v", err)
+	}
+}
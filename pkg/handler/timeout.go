@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+type TimeoutsConfig struct {
+	// Default is the time every handler has to decide. Unlimited when 0.
+	Default time.Duration
+	// Overrides are timeouts of single handlers like validating/awsmachinedeployment=5s.
+	Overrides []string
+	// Policy is either PolicyAllow or PolicyDeny.
+	Policy string
+}
+
+// Timeouts holds the time budgets of the handlers. A nil *Timeouts never
+// times out.
+type Timeouts struct {
+	allow          bool
+	defaultTimeout time.Duration
+	overrides      map[string]time.Duration
+}
+
+func NewTimeouts(config TimeoutsConfig) (*Timeouts, error) {
+	if config.Policy != PolicyAllow && config.Policy != PolicyDeny {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Policy must be either %s or %s", config, PolicyAllow, PolicyDeny)
+	}
+
+	t := &Timeouts{
+		allow:          config.Policy == PolicyAllow,
+		defaultTimeout: config.Default,
+		overrides:      map[string]time.Duration{},
+	}
+	for _, o := range config.Overrides {
+		parts := strings.SplitN(o, "=", 2)
+		if len(parts) != 2 || !strings.Contains(parts[0], "/") {
+			return nil, microerror.Maskf(invalidConfigError, "timeout override %#q has to be like validating/awsmachinedeployment=5s", o)
+		}
+		timeout, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "timeout override %#q has no valid duration", o)
+		}
+		t.overrides[parts[0]] = timeout
+	}
+
+	return t, nil
+}
+
+// Allow returns whether requests are admitted when their handler did not
+// decide in time.
+func (t *Timeouts) Allow() bool {
+	return t != nil && t.allow
+}
+
+// Timeout returns the time the handler of the given webhook and resource has
+// to decide, which is 0 when unlimited.
+func (t *Timeouts) Timeout(webhook, resource string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	if timeout, ok := t.overrides[fmt.Sprintf("%s/%s", webhook, resource)]; ok {
+		return timeout
+	}
+	return t.defaultTimeout
+}
+
+// RunWithin calls decide and waits for it to return at most the given
+// timeout, or without limit when it is 0. It returns false when decide did not
+// return in time. The context passed to decide is cancelled after the timeout,
+// so that its API calls are aborted. decide may still be running in the
+// background afterwards and must not write to variables read after a timeout.
+func RunWithin(ctx context.Context, timeout time.Duration, decide func(ctx context.Context)) bool {
+	if timeout <= 0 {
+		decide(ctx)
+		return true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		decide(ctx)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		config   TimeoutsConfig
+		webhook  string
+		resource string
+		timeout  time.Duration
+		valid    bool
+	}{
+		{
+			// default timeout
+			name: "case 0",
+
+			config:   TimeoutsConfig{Default: 8 * time.Second, Policy: PolicyAllow},
+			webhook:  "validating",
+			resource: "awscluster",
+			timeout:  8 * time.Second,
+			valid:    true,
+		},
+		{
+			// timeout of a single handler
+			name: "case 1",
+
+			config:   TimeoutsConfig{Default: 8 * time.Second, Overrides: []string{"validating/awsmachinedeployment=5s"}, Policy: PolicyDeny},
+			webhook:  "validating",
+			resource: "awsmachinedeployment",
+			timeout:  5 * time.Second,
+			valid:    true,
+		},
+		{
+			// override of another webhook
+			name: "case 2",
+
+			config:   TimeoutsConfig{Default: 8 * time.Second, Overrides: []string{"validating/awsmachinedeployment=5s"}, Policy: PolicyDeny},
+			webhook:  "mutating",
+			resource: "awsmachinedeployment",
+			timeout:  8 * time.Second,
+			valid:    true,
+		},
+		{
+			// override without webhook
+			name: "case 3",
+
+			config: TimeoutsConfig{Overrides: []string{"awsmachinedeployment=5s"}, Policy: PolicyAllow},
+			valid:  false,
+		},
+		{
+			// override without valid duration
+			name: "case 4",
+
+			config: TimeoutsConfig{Overrides: []string{"validating/awsmachinedeployment=5"}, Policy: PolicyAllow},
+			valid:  false,
+		},
+		{
+			// unknown policy
+			name: "case 5",
+
+			config: TimeoutsConfig{Policy: "ignore"},
+			valid:  false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			timeouts, err := NewTimeouts(tc.config)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but returned %v", err)
+				}
+				return
+			}
+			if timeout := timeouts.Timeout(tc.webhook, tc.resource); timeout != tc.timeout {
+				t.Fatalf("expected timeout %s but got %s", tc.timeout, timeout)
+			}
+			if timeouts.Allow() != (tc.config.Policy == PolicyAllow) {
+				t.Fatalf("expected allow to be %t", tc.config.Policy == PolicyAllow)
+			}
+		})
+	}
+}
+
+func TestRunWithin(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		timeout  time.Duration
+		duration time.Duration
+		finished bool
+	}{
+		{
+			// unlimited
+			name: "case 0",
+
+			duration: 10 * time.Millisecond,
+			finished: true,
+		},
+		{
+			// finished in time
+			name: "case 1",
+
+			timeout:  time.Second,
+			duration: 10 * time.Millisecond,
+			finished: true,
+		},
+		{
+			// timed out
+			name: "case 2",
+
+			timeout:  10 * time.Millisecond,
+			duration: time.Second,
+			finished: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			finished := RunWithin(context.Background(), tc.timeout, func(ctx context.Context) {
+				time.Sleep(tc.duration)
+			})
+			if finished != tc.finished {
+				t.Fatalf("expected finished to be %t", tc.finished)
+			}
+		})
+	}
+}
+
+func TestRunWithinCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	finished := RunWithin(context.Background(), 10*time.Millisecond, func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+	if finished {
+		t.Fatalf("expected decide not to finish in time")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the context of decide to be cancelled after the timeout")
+	}
+}
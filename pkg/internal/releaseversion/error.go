@@ -0,0 +1,14 @@
+package releaseversion
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidVersionError = &microerror.Error{
+	Kind: "invalidVersionError",
+}
+
+// IsInvalidVersion asserts invalidVersionError.
+func IsInvalidVersion(err error) bool {
+	return microerror.Cause(err) == invalidVersionError
+}
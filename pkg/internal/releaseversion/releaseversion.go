@@ -0,0 +1,86 @@
+package releaseversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var labelsPath = field.NewPath("metadata", "labels")
+
+// Parse parses a release version like "14.1.0" or "v14.1.0-beta1". A leading
+// "v" is accepted because Release CRs are named that way. The returned error
+// describes which part of the version is malformed.
+func Parse(value string) (*semver.Version, error) {
+	reason := validate(strings.TrimPrefix(value, "v"))
+	if reason != "" {
+		return nil, microerror.Maskf(invalidVersionError, "release version %q is invalid: %s", value, reason)
+	}
+
+	version, err := semver.New(strings.TrimPrefix(value, "v"))
+	if err != nil {
+		return nil, microerror.Maskf(invalidVersionError, "release version %q is invalid: %v", value, err)
+	}
+
+	return version, nil
+}
+
+// ParseLabel parses the release version stored in the given label. Other than
+// Parse it does not accept a leading "v" since release labels never contain
+// one. The returned error is a field error naming the label and its value.
+func ParseLabel(labelKey string, value string) (*semver.Version, error) {
+	path := labelsPath.Key(labelKey)
+
+	if strings.HasPrefix(value, "v") {
+		return nil, microerror.Maskf(invalidVersionError, "%s", field.Invalid(path, value, fmt.Sprintf("must not start with \"v\", use %q instead", strings.TrimPrefix(value, "v"))).Error())
+	}
+	reason := validate(value)
+	if reason != "" {
+		return nil, microerror.Maskf(invalidVersionError, "%s", field.Invalid(path, value, reason).Error())
+	}
+
+	version, err := semver.New(value)
+	if err != nil {
+		return nil, microerror.Maskf(invalidVersionError, "%s", field.Invalid(path, value, err.Error()).Error())
+	}
+
+	return version, nil
+}
+
+// validate checks the major, minor and patch part of a version without "v"
+// prefix and returns a human readable reason in case it is malformed.
+func validate(value string) string {
+	if value == "" {
+		return "must not be empty"
+	}
+
+	core := value
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		if i == len(core)-1 {
+			return fmt.Sprintf("must not end with %q", core[i])
+		}
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return "must consist of major, minor and patch version separated by dots, e.g. \"14.1.0\""
+	}
+	for i, name := range []string{"major", "minor", "patch"} {
+		part := parts[i]
+		if part == "" {
+			return fmt.Sprintf("%s version must not be empty", name)
+		}
+		if strings.Trim(part, "0123456789") != "" {
+			return fmt.Sprintf("%s version %q must be numeric", name, part)
+		}
+		if len(part) > 1 && part[0] == '0' {
+			return fmt.Sprintf("%s version %q must not contain leading zeros", name, part)
+		}
+	}
+
+	return ""
+}
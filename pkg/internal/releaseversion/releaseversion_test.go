@@ -0,0 +1,120 @@
+package releaseversion
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_Parse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		valid    bool
+	}{
+		{
+			name:     "case 0: plain version",
+			input:    "14.1.0",
+			expected: "14.1.0",
+			valid:    true,
+		},
+		{
+			name:     "case 1: version with v prefix",
+			input:    "v14.1.0",
+			expected: "14.1.0",
+			valid:    true,
+		},
+		{
+			name:     "case 2: pre-release version",
+			input:    "v15.0.0-beta1",
+			expected: "15.0.0-beta1",
+			valid:    true,
+		},
+		{
+			name:  "case 3: empty minor and patch version",
+			input: "14..",
+			valid: false,
+		},
+		{
+			name:  "case 4: missing patch version",
+			input: "14.1",
+			valid: false,
+		},
+		{
+			name:  "case 5: non numeric minor version",
+			input: "14.x.0",
+			valid: false,
+		},
+		{
+			name:  "case 6: leading zeros",
+			input: "14.01.0",
+			valid: false,
+		},
+		{
+			name:  "case 7: empty pre-release",
+			input: "14.1.0-",
+			valid: false,
+		},
+		{
+			name:  "case 8: empty version",
+			input: "",
+			valid: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			version, err := Parse(tc.input)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidVersion(err) {
+				t.Fatalf("expected invalid version error but returned %v", err)
+			}
+			if tc.valid && version.String() != tc.expected {
+				t.Fatalf("expected %s but got %s", tc.expected, version.String())
+			}
+		})
+	}
+}
+
+func Test_ParseLabel(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{
+			name:  "case 0: valid label",
+			input: "14.1.0",
+			valid: true,
+		},
+		{
+			name:  "case 1: label with v prefix",
+			input: "v14.1.0",
+			valid: false,
+		},
+		{
+			name:  "case 2: malformed label",
+			input: "14..",
+			valid: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParseLabel("release.giantswarm.io/version", tc.input)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidVersion(err) {
+				t.Fatalf("expected invalid version error but returned %v", err)
+			}
+			// The error has to name the label and its value.
+			if !tc.valid && (!strings.Contains(err.Error(), "metadata.labels[release.giantswarm.io/version]") || !strings.Contains(err.Error(), tc.input)) {
+				t.Fatalf("expected error to name label and value but returned %v", err)
+			}
+		})
+	}
+}
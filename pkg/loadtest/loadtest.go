@@ -0,0 +1,182 @@
+// Package loadtest sends synthetic admission reviews to a webhook endpoint and
+// measures latencies and error rates.
+package loadtest
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/to"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type Config struct {
+	// Concurrency is the number of requests in flight at the same time.
+	Concurrency int
+	// Insecure skips the TLS certificate verification of the target.
+	Insecure bool
+	// Object is the raw JSON object embedded in every admission review.
+	Object []byte
+	// Operation is the admission operation of every admission review.
+	Operation admissionv1.Operation
+	// Requests is the total number of requests sent.
+	Requests int
+	// Target is the full URL of the webhook, e.g. https://localhost:8443/validate/awscluster.
+	Target  string
+	Timeout time.Duration
+}
+
+// Result summarizes a load test run.
+type Result struct {
+	Requests int
+	// Errors counts requests which failed or did not return a valid admission review.
+	Errors int
+	// Denied counts requests which the webhook did not admit.
+	Denied   int
+	Duration time.Duration
+	// Latencies of all requests in ascending order.
+	Latencies []time.Duration
+}
+
+type sample struct {
+	latency time.Duration
+	err     error
+	allowed bool
+}
+
+// Run sends the configured number of requests to the target and collects the results.
+func Run(config Config) (Result, error) {
+	if config.Target == "" {
+		return Result{}, microerror.Maskf(invalidConfigError, "%T.Target must not be empty", config)
+	}
+	if config.Concurrency <= 0 {
+		return Result{}, microerror.Maskf(invalidConfigError, "%T.Concurrency must be greater than zero", config)
+	}
+	if config.Requests <= 0 {
+		return Result{}, microerror.Maskf(invalidConfigError, "%T.Requests must be greater than zero", config)
+	}
+
+	client := &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: config.Concurrency,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.Insecure, //nolint:gosec // load tests usually target self-signed webhook certificates
+				MinVersion:         tls.VersionTLS12,
+			},
+		},
+	}
+
+	jobs := make(chan int)
+	samples := make(chan sample, config.Requests)
+
+	var wg sync.WaitGroup
+	for w := 0; w < config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				samples <- send(client, config, i)
+			}
+		}()
+	}
+
+	start := time.Now()
+	for i := 0; i < config.Requests; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(samples)
+
+	result := Result{
+		Requests: config.Requests,
+		Duration: time.Since(start),
+	}
+	for s := range samples {
+		result.Latencies = append(result.Latencies, s.latency)
+		if s.err != nil {
+			result.Errors++
+		} else if !s.allowed {
+			result.Denied++
+		}
+	}
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+
+	return result, nil
+}
+
+// Percentile returns the latency below which the given percentage of requests finished.
+func (r Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(r.Latencies)-1) * p / 100)
+	return r.Latencies[i]
+}
+
+// Report writes a human readable summary of the result.
+func (r Result) Report(w io.Writer) {
+	fmt.Fprintf(w, "requests:   %d in %s (%.1f/s)\n", r.Requests, r.Duration.Round(time.Millisecond), float64(r.Requests)/r.Duration.Seconds())
+	fmt.Fprintf(w, "errors:     %d (%.2f%%)\n", r.Errors, 100*float64(r.Errors)/float64(r.Requests))
+	fmt.Fprintf(w, "denied:     %d (%.2f%%)\n", r.Denied, 100*float64(r.Denied)/float64(r.Requests))
+	for _, p := range []float64{50, 90, 95, 99, 100} {
+		fmt.Fprintf(w, "latency p%-3.0f %s\n", p, r.Percentile(p))
+	}
+}
+
+func send(client *http.Client, config Config, i int) sample {
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AdmissionReview",
+			APIVersion: "admission.k8s.io/v1",
+		},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID(fmt.Sprintf("loadtest-%d", i)),
+			Operation: config.Operation,
+			Object:    runtime.RawExtension{Raw: config.Object},
+			DryRun:    to.BoolP(true),
+		},
+	})
+	if err != nil {
+		return sample{err: microerror.Mask(err)}
+	}
+
+	start := time.Now()
+	resp, err := client.Post(config.Target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return sample{latency: time.Since(start), err: microerror.Mask(err)}
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return sample{latency: latency, err: microerror.Mask(err)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return sample{latency: latency, err: microerror.Maskf(unexpectedResponseError, "status code %d", resp.StatusCode)}
+	}
+
+	review := admissionv1.AdmissionReview{}
+	err = json.Unmarshal(data, &review)
+	if err != nil {
+		return sample{latency: latency, err: microerror.Mask(err)}
+	}
+	if review.Response == nil {
+		return sample{latency: latency, err: microerror.Maskf(unexpectedResponseError, "admission review without response")}
+	}
+
+	return sample{latency: latency, allowed: review.Response.Allowed}
+}
@@ -0,0 +1,92 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		allowed    bool
+		statusCode int
+		errors     int
+		denied     int
+	}{
+		{
+			// all requests admitted
+			name: "case 0",
+
+			allowed:    true,
+			statusCode: http.StatusOK,
+			errors:     0,
+			denied:     0,
+		},
+		{
+			// all requests denied
+			name: "case 1",
+
+			allowed:    false,
+			statusCode: http.StatusOK,
+			errors:     0,
+			denied:     10,
+		},
+		{
+			// webhook fails
+			name: "case 2",
+
+			allowed:    true,
+			statusCode: http.StatusInternalServerError,
+			errors:     10,
+			denied:     0,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			webhook := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				data, _ := ioutil.ReadAll(request.Body)
+				review := admissionv1.AdmissionReview{}
+				_ = json.Unmarshal(data, &review)
+
+				writer.WriteHeader(tc.statusCode)
+				resp, _ := json.Marshal(admissionv1.AdmissionReview{
+					Response: &admissionv1.AdmissionResponse{
+						UID:     review.Request.UID,
+						Allowed: tc.allowed,
+					},
+				})
+				_, _ = writer.Write(resp)
+			}))
+			defer webhook.Close()
+
+			result, err := Run(Config{
+				Concurrency: 3,
+				Insecure:    true,
+				Operation:   admissionv1.Create,
+				Requests:    10,
+				Target:      webhook.URL + "/validate/awscluster",
+				Timeout:     time.Second,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Errors != tc.errors {
+				t.Fatalf("expected %d errors but got %d", tc.errors, result.Errors)
+			}
+			if result.Denied != tc.denied {
+				t.Fatalf("expected %d denied requests but got %d", tc.denied, result.Denied)
+			}
+			if len(result.Latencies) != 10 {
+				t.Fatalf("expected 10 latencies but got %d", len(result.Latencies))
+			}
+		})
+	}
+}
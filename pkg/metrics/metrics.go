@@ -10,7 +10,116 @@ const (
 )
 
 var (
-	labels = []string{"webhook", "resource"}
+	labels          = []string{"webhook", "resource"}
+	crdLabels       = []string{"group", "version", "resource"}
+	operationLabels = []string{"webhook", "resource", "operation"}
+	denialLabels    = []string{"webhook", "resource", "operation", "reason"}
+
+	CertificateExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "certificate_expiry_timestamp_seconds",
+		Help:      "Time when the serving certificate expires in seconds since epoch",
+	})
+	CertificateNearExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "certificate_near_expiry",
+		Help:      "Whether the serving certificate expires within the configured threshold",
+	})
+	CertificateReloadErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "certificate_reload_errors_total",
+		Help:      "Total number of failed attempts to reload the serving certificate",
+	})
+	ConcurrencyLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_concurrency_limited_total",
+		Help:      "Total number of requests rejected because the maximum number of requests was in flight",
+	}, []string{"path"})
+	InFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_in_flight",
+		Help:      "Number of admission requests processed at the moment",
+	})
+	RecoveredPanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "panics_recovered_total",
+		Help:      "Total number of panics of handlers which were converted into admission responses",
+	}, []string{"path"})
+	CRDMissing = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "crd_missing",
+		Help:      "Whether the CRD version of a handler is missing and the handler is disabled",
+	}, crdLabels)
+	DroppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "events_dropped_total",
+		Help:      "Total number of admission decisions dropped because the event queue was full",
+	}, labels)
+	SkippedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_skipped_total",
+		Help:      "Total number of requests admitted without processing because the CRD version is missing",
+	}, crdLabels)
+
+	AllowedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_allowed_total",
+		Help:      "Total number of allowed requests per operation",
+	}, operationLabels)
+	DecisionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "decision_duration_seconds",
+		Help:      "Duration of admission decisions per operation",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, .75, 1, 1.25, 1.5, 2, 2.5, 5, 10},
+	}, operationLabels)
+	DeniedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_denied_total",
+		Help:      "Total number of denied requests per operation and reason",
+	}, denialLabels)
+	BypassedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_bypassed_total",
+		Help:      "Total number of requests admitted without mutation and validation because of their user or group per operation",
+	}, operationLabels)
+	FailOpenRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_failed_open_total",
+		Help:      "Total number of requests admitted without a decision because a dependency of the handler failed per operation",
+	}, operationLabels)
+	RateLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_rate_limited_total",
+		Help:      "Total number of requests denied because their handler exceeded its rate limit per operation",
+	}, operationLabels)
+	TimedOutRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_timed_out_total",
+		Help:      "Total number of requests whose handler did not decide within its timeout per operation",
+	}, operationLabels)
+	MutationPatches = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "mutation_patches",
+		Help:      "Number of patch operations returned for admitted mutating requests",
+		Buckets:   []float64{0, 1, 2, 5, 10, 20, 50},
+	}, operationLabels)
 
 	DurationRequests = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: metricNamespace,
@@ -52,5 +161,6 @@ var (
 )
 
 func init() {
-	prometheus.MustRegister(TotalRequests, InvalidRequests, RejectedRequests, SuccessfulRequests, DurationRequests)
+	prometheus.MustRegister(TotalRequests, InvalidRequests, RejectedRequests, SuccessfulRequests, DurationRequests, InternalError, CRDMissing, SkippedRequests, DroppedEvents, CertificateExpiry, CertificateNearExpiry, CertificateReloadErrors, RecoveredPanics, ConcurrencyLimitedRequests, InFlightRequests)
+	prometheus.MustRegister(AllowedRequests, DeniedRequests, DecisionDuration, MutationPatches, TimedOutRequests, FailOpenRequests, BypassedRequests, RateLimitedRequests)
 }
@@ -0,0 +1,157 @@
+package mirror
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/to"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+const (
+	// queueSize is the number of requests which are buffered before new requests are dropped.
+	queueSize = 100
+	// requestTimeout is the maximum time a mirrored request may take.
+	requestTimeout = 5 * time.Second
+	// sanitizedUsername replaces the name of the requesting user in mirrored requests.
+	sanitizedUsername = "admission-controller-mirror"
+)
+
+type Config struct {
+	// Endpoint is the base URL of the staging controller, e.g.
+	// https://aws-admission-controller-staging.giantswarm.svc:8443. Mirroring is
+	// disabled when it is empty.
+	Endpoint           string
+	InsecureSkipVerify bool
+	Logger             micrologger.Logger
+}
+
+// Mirror sends copies of admission requests to a staging controller. Sending
+// happens asynchronously and errors are only logged, so the mirror never
+// influences the admission decision of this controller.
+type Mirror struct {
+	client   *http.Client
+	endpoint string
+	logger   micrologger.Logger
+	queue    chan mirroredRequest
+}
+
+type mirroredRequest struct {
+	path string
+	body []byte
+}
+
+func New(config Config) (*Mirror, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	m := &Mirror{
+		endpoint: strings.TrimSuffix(config.Endpoint, "/"),
+		logger:   config.Logger,
+	}
+	if m.endpoint == "" {
+		return m, nil
+	}
+
+	m.client = &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec // explicitly configured for staging endpoints with self-signed certificates
+				MinVersion:         tls.VersionTLS12,
+			},
+		},
+	}
+	m.queue = make(chan mirroredRequest, queueSize)
+	go m.run()
+
+	return m, nil
+}
+
+// Wrap returns a handler which mirrors every request before passing it on to
+// the given handler. The handler is returned unchanged if mirroring is
+// disabled.
+func (m *Mirror) Wrap(next http.Handler) http.Handler {
+	if m.endpoint == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			m.logger.Log("level", "error", "message", "unable to read request for mirroring", "stack", microerror.JSON(err))
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+		select {
+		case m.queue <- mirroredRequest{path: request.URL.Path, body: data}:
+		default:
+			m.logger.Log("level", "debug", "message", fmt.Sprintf("mirror queue is full, dropping request to %s", request.URL.Path))
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func (m *Mirror) run() {
+	for r := range m.queue {
+		err := m.send(r)
+		if err != nil {
+			m.logger.Log("level", "debug", "message", fmt.Sprintf("unable to mirror request to %s", r.path), "stack", microerror.JSON(err))
+		}
+	}
+}
+
+func (m *Mirror) send(r mirroredRequest) error {
+	body, err := sanitize(r.body)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	resp, err := m.client.Post(m.endpoint+r.path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	// The response is irrelevant but has to be drained so the connection can be reused.
+	_, _ = ioutil.ReadAll(resp.Body)
+
+	return nil
+}
+
+// sanitize removes information about the requesting user which is not needed
+// to exercise the staging controller. Group memberships are kept because
+// validators depend on them. Mirrored requests are always marked as dry run so
+// that the staging controller skips side effects like IPAM allocations and
+// denial events for requests which it does not decide on.
+func sanitize(data []byte) ([]byte, error) {
+	review := admissionv1.AdmissionReview{}
+	err := json.Unmarshal(data, &review)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if review.Request != nil {
+		review.Request.UserInfo.Username = sanitizedUsername
+		review.Request.UserInfo.UID = ""
+		review.Request.UserInfo.Extra = nil
+		review.Request.DryRun = to.BoolP(true)
+	}
+
+	sanitized, err := json.Marshal(review)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return sanitized, nil
+}
@@ -0,0 +1,106 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestMirror(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		enabled bool
+	}{
+		{
+			// mirroring enabled
+			name: "case 0",
+
+			enabled: true,
+		},
+		{
+			// mirroring disabled
+			name: "case 1",
+
+			enabled: false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mirrored := make(chan admissionv1.AdmissionReview, 1)
+			staging := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				review := admissionv1.AdmissionReview{}
+				data, _ := ioutil.ReadAll(request.Body)
+				_ = json.Unmarshal(data, &review)
+				if request.URL.Path != "/validate/cluster" {
+					t.Errorf("expected path /validate/cluster but got %s", request.URL.Path)
+				}
+				mirrored <- review
+			}))
+			defer staging.Close()
+
+			config := Config{Logger: microloggertest.New()}
+			if tc.enabled {
+				config.Endpoint = staging.URL
+			}
+			m, err := New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var received []byte
+			next := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				received, _ = ioutil.ReadAll(request.Body)
+			})
+
+			body, err := json.Marshal(admissionv1.AdmissionReview{
+				Request: &admissionv1.AdmissionRequest{
+					UID: "1234",
+					UserInfo: authenticationv1.UserInfo{
+						Username: "jane@example.com",
+						Groups:   []string{"giantswarm-admins"},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(http.MethodPost, "/validate/cluster", bytes.NewReader(body))
+			m.Wrap(next).ServeHTTP(httptest.NewRecorder(), request)
+
+			// The wrapped handler always has to receive the original request.
+			if !bytes.Equal(received, body) {
+				t.Fatalf("expected wrapped handler to receive %s but got %s", body, received)
+			}
+
+			select {
+			case review := <-mirrored:
+				if !tc.enabled {
+					t.Fatalf("unexpected mirrored request")
+				}
+				if review.Request.UserInfo.Username != sanitizedUsername {
+					t.Fatalf("expected username to be sanitized but got %s", review.Request.UserInfo.Username)
+				}
+				if len(review.Request.UserInfo.Groups) != 1 {
+					t.Fatalf("expected groups to be kept but got %v", review.Request.UserInfo.Groups)
+				}
+				if review.Request.DryRun == nil || !*review.Request.DryRun {
+					t.Fatalf("expected mirrored request to be a dry run")
+				}
+			case <-time.After(time.Second):
+				if tc.enabled {
+					t.Fatalf("expected request to be mirrored")
+				}
+			}
+		})
+	}
+}
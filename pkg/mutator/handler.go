@@ -1,6 +1,7 @@
 package mutator
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,19 +10,22 @@ import (
 	"time"
 
 	"github.com/giantswarm/microerror"
+	"go.opentelemetry.io/otel/attribute"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/events"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/handler"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/tracing"
 )
 
 type Mutator interface {
 	Log(keyVals ...interface{})
-	Mutate(review *admissionv1.AdmissionRequest) ([]PatchOperation, error)
+	Mutate(ctx context.Context, review *admissionv1.AdmissionRequest) ([]PatchOperation, error)
 	Resource() string
 }
 
@@ -32,10 +36,15 @@ var (
 	InternalError = errors.New("internal admission controller error")
 )
 
-func Handler(mutator Mutator) http.HandlerFunc {
+// Handler answers mutating admission reviews. Mutators which do not decide
+// within their timeout or panic are answered according to the policies, which
+// admit requests without patches.
+func Handler(mutator Mutator, policies handler.Policies) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		start := time.Now()
-		defer metrics.DurationRequests.WithLabelValues("mutating", mutator.Resource()).Observe(float64(time.Since(start)) / float64(time.Second))
+		defer func() {
+			metrics.DurationRequests.WithLabelValues("mutating", mutator.Resource()).Observe(time.Since(start).Seconds())
+		}()
 
 		metrics.TotalRequests.WithLabelValues("mutating", mutator.Resource()).Inc()
 		if request.Header.Get("Content-Type") != "application/json" {
@@ -54,48 +63,164 @@ func Handler(mutator Mutator) http.HandlerFunc {
 		}
 
 		review := admissionv1.AdmissionReview{}
-		if _, _, err := Deserializer.Decode(data, nil, &review); err != nil {
+		_, gvk, err := Deserializer.Decode(data, nil, &review)
+		if err != nil || review.Request == nil {
 			mutator.Log("level", "error", "message", "unable to parse admission review request")
 			metrics.InvalidRequests.WithLabelValues("mutating", mutator.Resource()).Inc()
 			writer.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		resourceName := fmt.Sprintf("%s %s/%s", review.Request.Kind, review.Request.Namespace, handler.ExtractName(review.Request, Deserializer))
+		// Responses are sent in the admission.k8s.io version of the request.
+		apiVersion, ok := handler.ReviewAPIVersion(gvk.GroupVersion().String())
+		if !ok {
+			mutator.Log("level", "error", "message", fmt.Sprintf("unsupported admission review version: %s", gvk.GroupVersion().String()))
+			metrics.InvalidRequests.WithLabelValues("mutating", mutator.Resource()).Inc()
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := handler.ExtractName(review.Request, Deserializer)
+		resourceName := fmt.Sprintf("%s %s/%s", review.Request.Kind, review.Request.Namespace, name)
+		decision := events.Decision{
+			Webhook:  events.WebhookMutating,
+			Resource: mutator.Resource(),
+			Request:  review.Request,
+			Name:     name,
+		}
+		if matched, ok := policies.Bypass.Match(review.Request.UserInfo); ok {
+			message := fmt.Sprintf("Mutation of %s was bypassed for %s.", resourceName, matched)
+			mutator.Log("level", "info", "message", message, "user", review.Request.UserInfo.Username)
+			writeResponse(mutator, writer, apiVersion, &admissionv1.AdmissionResponse{
+				Allowed: true,
+				UID:     review.Request.UID,
+			})
+			decision.Allowed = true
+			decision.Message = message
+			decision.Reason = events.ReasonBypass
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+		if !policies.RateLimits.Accept(events.WebhookMutating, mutator.Resource()) {
+			message := fmt.Sprintf("Too many requests to mutate %s. Please retry later.", mutator.Resource())
+			mutator.Log("level", "warning", "message", fmt.Sprintf("rate limited %s", resourceName), "user", review.Request.UserInfo.Username)
+			writeResponse(mutator, writer, apiVersion, &admissionv1.AdmissionResponse{
+				Allowed: false,
+				UID:     review.Request.UID,
+				Result: &metav1.Status{
+					Reason:  metav1.StatusReasonTooManyRequests,
+					Code:    http.StatusTooManyRequests,
+					Message: message,
+				},
+			})
+			decision.Message = message
+			decision.Reason = events.ReasonRateLimited
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
 
-		patch, err := mutator.Mutate(review.Request)
+		ctx, span := tracing.Start(request.Context(), fmt.Sprintf("mutate %s", mutator.Resource()),
+			attribute.String("admission.operation", string(review.Request.Operation)),
+			attribute.String("admission.namespace", review.Request.Namespace),
+			attribute.String("admission.name", name),
+		)
+		timeout := policies.Timeouts.Timeout(events.WebhookMutating, mutator.Resource())
+		var patch []PatchOperation
+		var mutateErr error
+		finished := handler.RunWithin(ctx, timeout, func(ctx context.Context) {
+			defer handler.Recover(mutator.Log, request.URL.Path, &mutateErr)
+			patch, mutateErr = mutator.Mutate(ctx, review.Request)
+		})
+		if !finished {
+			message := fmt.Sprintf("Mutation of %s did not finish within %s.", resourceName, timeout)
+			tracing.End(span, errors.New(message))
+			mutator.Log("level", "warning", "message", message)
+
+			response := errorResponse(review.Request.UID, errors.New(message))
+			if policies.Timeouts.Allow() {
+				response = &admissionv1.AdmissionResponse{Allowed: true, UID: review.Request.UID}
+			}
+			writeResponse(mutator, writer, apiVersion, response)
+			decision.Allowed = response.Allowed
+			decision.Message = message
+			decision.Reason = events.ReasonTimeout
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+		err = mutateErr
+		span.SetAttributes(attribute.Int("admission.patches", len(patch)))
+		tracing.End(span, err)
+		if handler.IsPanic(err) {
+			message := policies.Recovery.Message()
+			response := errorResponse(review.Request.UID, errors.New(message))
+			if policies.Recovery.Allow() {
+				response = &admissionv1.AdmissionResponse{Allowed: true, UID: review.Request.UID}
+			}
+			writeResponse(mutator, writer, apiVersion, response)
+			decision.Allowed = response.Allowed
+			decision.Message = message
+			decision.Reason = events.ReasonPanic
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+		if policies.FailOpen.Allow(events.WebhookMutating, mutator.Resource(), err) {
+			mutator.Log("level", "warning", "message", fmt.Sprintf("admitting %s without mutation because a dependency failed", resourceName), "stack", microerror.JSON(err))
+			writeResponse(mutator, writer, apiVersion, &admissionv1.AdmissionResponse{
+				Allowed: true,
+				UID:     review.Request.UID,
+			})
+			decision.Allowed = true
+			decision.Message = fmt.Sprintf("Mutation of %s was skipped because a dependency of the admission controller failed.", resourceName)
+			decision.Reason = events.ReasonFailOpen
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
 		if err != nil {
 			mutator.Log("level", "error", "message", fmt.Sprintf("error during mutation process of %s: %v", resourceName, err))
-			writeResponse(mutator, writer, errorResponse(review.Request.UID, microerror.Mask(err)))
-			metrics.RejectedRequests.WithLabelValues("mutating", mutator.Resource()).Inc()
+			writeResponse(mutator, writer, apiVersion, errorResponse(review.Request.UID, microerror.Mask(err)))
+			decision.Message = err.Error()
+			decision.Reason = events.Reason(err)
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
 			return
 		}
 
 		patchData, err := json.Marshal(patch)
 		if err != nil {
 			mutator.Log("level", "error", "message", fmt.Sprintf("unable to serialize patch for %s: %v", resourceName, err))
-			writeResponse(mutator, writer, errorResponse(review.Request.UID, InternalError))
-			metrics.RejectedRequests.WithLabelValues("mutating", mutator.Resource()).Inc()
+			writeResponse(mutator, writer, apiVersion, errorResponse(review.Request.UID, InternalError))
+			decision.Message = InternalError.Error()
+			decision.Reason = events.ReasonInternalError
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
 			return
 		}
 
 		mutator.Log("level", "debug", "message", fmt.Sprintf("mutator admitted %s (with %d patches)", resourceName, len(patch)))
-		metrics.SuccessfulRequests.WithLabelValues("mutating", mutator.Resource()).Inc()
 
 		pt := admissionv1.PatchTypeJSONPatch
-		writeResponse(mutator, writer, &admissionv1.AdmissionResponse{
+		writeResponse(mutator, writer, apiVersion, &admissionv1.AdmissionResponse{
 			Allowed:   true,
 			UID:       review.Request.UID,
 			Patch:     patchData,
 			PatchType: &pt,
 		})
+		decision.Allowed = true
+		decision.Patches = len(patch)
+		decision.Patch = patchData
+		decision.Duration = time.Since(start)
+		events.Publish(decision)
 	}
 }
 
-func writeResponse(mutator Mutator, writer http.ResponseWriter, response *admissionv1.AdmissionResponse) {
+func writeResponse(mutator Mutator, writer http.ResponseWriter, apiVersion string, response *admissionv1.AdmissionResponse) {
 	resp, err := json.Marshal(admissionv1.AdmissionReview{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "AdmissionReview",
-			APIVersion: "admission.k8s.io/v1",
+			APIVersion: apiVersion,
 		},
 		Response: response,
 	})
@@ -0,0 +1,172 @@
+// Package readiness decides whether the admission controller can actually serve
+// decisions, so that the API server does not route admission requests to it
+// before the serving certificate, the Kubernetes API and the CRDs it relies on
+// are available.
+package readiness
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/certcheck"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/crd"
+)
+
+type Config struct {
+	CertChecker *certcheck.Checker
+	CRDDetector *crd.Detector
+	// CRDs are the resources which have to be installed. Handlers of other
+	// resources admit requests without processing them while their CRD is missing.
+	CRDs      []schema.GroupVersionResource
+	Discovery discovery.DiscoveryInterface
+	Logger    micrologger.Logger
+	// TTL is the time the result of talking to the Kubernetes API is cached, so
+	// that frequent probes do not put load on the API server.
+	TTL time.Duration
+}
+
+// Checker reports the readiness of the admission controller.
+type Checker struct {
+	certChecker *certcheck.Checker
+	crdDetector *crd.Detector
+	crds        []schema.GroupVersionResource
+	discovery   discovery.DiscoveryInterface
+	logger      micrologger.Logger
+	ttl         time.Duration
+
+	mutex        sync.Mutex
+	apiErr       error
+	checkedAt    time.Time
+	shuttingDown bool
+}
+
+func New(config Config) (*Checker, error) {
+	if config.CertChecker == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CertChecker must not be empty", config)
+	}
+	if config.CRDDetector == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CRDDetector must not be empty", config)
+	}
+	if config.Discovery == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Discovery must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	c := &Checker{
+		certChecker: config.CertChecker,
+		crdDetector: config.CRDDetector,
+		crds:        config.CRDs,
+		discovery:   config.Discovery,
+		logger:      config.Logger,
+		ttl:         config.TTL,
+	}
+
+	return c, nil
+}
+
+// Check returns a notReadyError listing all reasons the admission controller
+// can not serve decisions, or nil when it is ready.
+func (c *Checker) Check() error {
+	reasons := c.reasons()
+	if len(reasons) > 0 {
+		return microerror.Maskf(notReadyError, "%s", strings.Join(reasons, ", "))
+	}
+	return nil
+}
+
+// ServeHTTP answers readiness probes. Failures are reported with status 503
+// and the reasons in the body.
+func (c *Checker) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	status := http.StatusOK
+	body := "ok"
+
+	reasons := c.reasons()
+	if len(reasons) > 0 {
+		status = http.StatusServiceUnavailable
+		body = strings.Join(reasons, "\n")
+	}
+
+	writer.WriteHeader(status)
+	_, err := writer.Write([]byte(body))
+	if err != nil {
+		c.logger.Log("level", "error", "message", "unable to write readiness response", "stack", microerror.JSON(err))
+	}
+}
+
+// Shutdown makes all following checks fail, so that the pod is removed from
+// the webhook endpoints before it stops accepting connections.
+func (c *Checker) Shutdown() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.shuttingDown = true
+}
+
+func (c *Checker) reasons() []string {
+	c.mutex.Lock()
+	shuttingDown := c.shuttingDown
+	c.mutex.Unlock()
+	if shuttingDown {
+		return []string{"shutting down"}
+	}
+
+	var reasons []string
+
+	if !c.certChecker.Loaded() {
+		reasons = append(reasons, "serving certificate can not be loaded")
+	} else if c.certChecker.Expired() {
+		reasons = append(reasons, "serving certificate expired")
+	}
+
+	err := c.checkAPI()
+	if err != nil {
+		reasons = append(reasons, fmt.Sprintf("kubernetes API unreachable: %s", err))
+	} else if !c.crdDetector.Checked() {
+		reasons = append(reasons, "installed CRD versions unknown")
+	} else {
+		for _, gvr := range c.crds {
+			if !c.crdDetector.Installed(gvr) {
+				reasons = append(reasons, fmt.Sprintf("CRD version for %s not installed", gvr.String()))
+			}
+		}
+	}
+
+	return reasons
+}
+
+// checkAPI talks to the Kubernetes API at most once per TTL. The installed CRD
+// versions are discovered again as long as the initial discovery failed.
+func (c *Checker) checkAPI() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		return c.apiErr
+	}
+
+	_, err := c.discovery.ServerVersion()
+	if err != nil && c.apiErr == nil {
+		c.logger.Log("level", "warning", "message", "kubernetes API unreachable, failing readiness", "stack", microerror.JSON(err))
+	}
+	if err == nil && !c.crdDetector.Checked() {
+		crdErr := c.crdDetector.Check()
+		if crdErr != nil {
+			c.logger.Log("level", "warning", "message", "unable to check installed CRD versions", "stack", microerror.JSON(crdErr))
+		}
+	}
+
+	c.apiErr = err
+	c.checkedAt = time.Now()
+
+	return c.apiErr
+}
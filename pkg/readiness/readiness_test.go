@@ -0,0 +1,186 @@
+package readiness
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/certcheck"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/crd"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestChecker(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "infrastructure.giantswarm.io", Version: "v1alpha2", Resource: "awsclusters"}
+
+	testCases := []struct {
+		name string
+
+		certificate    bool
+		resources      []*metav1.APIResourceList
+		apiUnreachable bool
+		shutdown       bool
+		ready          bool
+	}{
+		{
+			// certificate loaded, API reachable and CRD installed
+			name: "case 0",
+
+			certificate: true,
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters"}},
+				},
+			},
+			ready: true,
+		},
+		{
+			// certificate can not be loaded
+			name: "case 1",
+
+			certificate: false,
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters"}},
+				},
+			},
+			ready: false,
+		},
+		{
+			// required CRD not installed
+			name: "case 2",
+
+			certificate: true,
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "networkpools"}},
+				},
+			},
+			ready: false,
+		},
+		{
+			// kubernetes API unreachable
+			name: "case 3",
+
+			certificate: true,
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters"}},
+				},
+			},
+			apiUnreachable: true,
+			ready:          false,
+		},
+		{
+			// shutting down
+			name: "case 4",
+
+			certificate: true,
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.giantswarm.io/v1alpha2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters"}},
+				},
+			},
+			shutdown: true,
+			ready:    false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "readiness")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			certFile := filepath.Join(dir, "tls.crt")
+			keyFile := filepath.Join(dir, "tls.key")
+			if tc.certificate {
+				unittest.WriteKeyPair(t, certFile, keyFile, 1, time.Now().Add(24*time.Hour))
+			}
+			certChecker, err := certcheck.New(certcheck.Config{
+				CertFile: certFile,
+				KeyFile:  keyFile,
+				Logger:   microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = certChecker.Check()
+
+			fakeDiscovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: tc.resources}}
+			crdDetector, err := crd.NewDetector(crd.Config{
+				Discovery: fakeDiscovery,
+				Logger:    microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			crdDetector.Wrap(gvr, http.NotFoundHandler())
+
+			var apiDiscovery discovery.DiscoveryInterface = fakeDiscovery
+			if tc.apiUnreachable {
+				apiDiscovery = unreachableDiscovery{fakeDiscovery}
+			}
+			c, err := New(Config{
+				CertChecker: certChecker,
+				CRDDetector: crdDetector,
+				CRDs:        []schema.GroupVersionResource{gvr},
+				Discovery:   apiDiscovery,
+				Logger:      microloggertest.New(),
+				TTL:         time.Minute,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.shutdown {
+				c.Shutdown()
+			}
+
+			err = c.Check()
+			if tc.ready && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.ready && !IsNotReady(err) {
+				t.Fatalf("expected not ready error but returned %v", err)
+			}
+
+			recorder := httptest.NewRecorder()
+			c.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			expected := http.StatusOK
+			if !tc.ready {
+				expected = http.StatusServiceUnavailable
+			}
+			if recorder.Code != expected {
+				t.Fatalf("expected status %d but got %d", expected, recorder.Code)
+			}
+		})
+	}
+}
+
+type unreachableDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d unreachableDiscovery) ServerVersion() (*version.Info, error) {
+	return nil, errors.New("connection refused")
+}
@@ -0,0 +1,211 @@
+// Package servicequota looks up how many vCPUs of the EC2 On-Demand instance
+// quotas of the account are still available. The quotas and the vCPUs of the
+// running instances are cached, so that admission requests only reach the AWS
+// APIs once per TTL.
+package servicequota
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	ec2ServiceCode = "ec2"
+
+	// standardQuotaCode is the quota of running On-Demand A, C, D, H, I, M, R, T and Z instances.
+	standardQuotaCode = "L-1216C47A"
+)
+
+// familyQuotaCodes lists the quotas of running On-Demand instances by the instance family class. Instance families
+// which are not listed count against the standard quota.
+var familyQuotaCodes = map[string]string{
+	"f":   "L-74FC7D96",
+	"g":   "L-DB2E81BA",
+	"inf": "L-1945791B",
+	"p":   "L-417A185B",
+	"vt":  "L-DB2E81BA",
+	"x":   "L-7295265B",
+}
+
+// Interface is implemented by everything which knows the remaining EC2 instance quotas of the account.
+type Interface interface {
+	// RemainingVCPUs returns the number of vCPUs which can still be launched for the given instance type
+	// before the On-Demand instance quota of its family is exhausted.
+	RemainingVCPUs(instanceType string) (int, error)
+}
+
+// EC2Describer is the part of the EC2 API which is needed to count the vCPUs of the running instances.
+type EC2Describer interface {
+	DescribeInstancesPages(input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error
+}
+
+// QuotaGetter is the part of the Service Quotas API which is needed to look up the instance quotas.
+type QuotaGetter interface {
+	GetServiceQuota(input *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error)
+}
+
+type Config struct {
+	// EC2 is the client used to list the running instances. A client for Region is created when it is nil.
+	EC2    EC2Describer
+	Logger micrologger.Logger
+	// Quotas is the client used to look up the quotas. A client for Region is created when it is nil.
+	Quotas QuotaGetter
+	Region string
+	// TTL is the time after which the cached quotas and usage are fetched again.
+	TTL time.Duration
+}
+
+// Cache holds the instance quotas of the account and their usage.
+type Cache struct {
+	ec2    EC2Describer
+	logger micrologger.Logger
+	quotas QuotaGetter
+	ttl    time.Duration
+
+	mutex       sync.Mutex
+	limits      map[string]int
+	usage       map[string]int
+	refreshedAt time.Time
+}
+
+func New(config Config) (*Cache, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.TTL <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TTL must be greater than zero", config)
+	}
+	if config.EC2 == nil || config.Quotas == nil {
+		if config.Region == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Region must not be empty", config)
+		}
+		s, err := session.NewSession(&aws.Config{Region: aws.String(config.Region)})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if config.EC2 == nil {
+			config.EC2 = ec2.New(s)
+		}
+		if config.Quotas == nil {
+			config.Quotas = servicequotas.New(s)
+		}
+	}
+
+	c := &Cache{
+		ec2:    config.EC2,
+		logger: config.Logger,
+		quotas: config.Quotas,
+		ttl:    config.TTL,
+
+		limits: map[string]int{},
+	}
+
+	return c, nil
+}
+
+// QuotaCode returns the code of the On-Demand instance quota the given instance type counts against.
+func QuotaCode(instanceType string) string {
+	family := strings.SplitN(instanceType, ".", 2)[0]
+	for class, code := range familyQuotaCodes {
+		if strings.HasPrefix(family, class) && (len(family) == len(class) || isDigit(family[len(class)])) {
+			return code
+		}
+	}
+	return standardQuotaCode
+}
+
+// RemainingVCPUs returns the number of vCPUs which can still be launched for the given instance type. Stale usage
+// is used when it can not be refreshed, since the check only gives early feedback and does not reserve capacity.
+func (c *Cache) RemainingVCPUs(instanceType string) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.usage == nil || time.Since(c.refreshedAt) > c.ttl {
+		usage, err := c.fetchUsage()
+		if err != nil && c.usage == nil {
+			return 0, microerror.Mask(err)
+		} else if err != nil {
+			c.logger.Log("level", "warning", "message", "using stale EC2 instance usage", "stack", microerror.JSON(err))
+		} else {
+			c.usage = usage
+			c.limits = map[string]int{}
+			c.refreshedAt = time.Now()
+		}
+	}
+
+	code := QuotaCode(instanceType)
+	limit, ok := c.limits[code]
+	if !ok {
+		var err error
+		limit, err = c.fetchLimit(code)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+		c.limits[code] = limit
+	}
+
+	return limit - c.usage[code], nil
+}
+
+func (c *Cache) fetchLimit(code string) (int, error) {
+	c.logger.Log("level", "debug", "message", fmt.Sprintf("Fetching EC2 service quota %s", code))
+
+	output, err := c.quotas.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+		QuotaCode:   aws.String(code),
+		ServiceCode: aws.String(ec2ServiceCode),
+	})
+	if err != nil {
+		return 0, microerror.Maskf(executionFailedError, "failed to fetch EC2 service quota %s: %v", code, err)
+	}
+	if output.Quota == nil || output.Quota.Value == nil {
+		return 0, microerror.Maskf(executionFailedError, "EC2 service quota %s has no value", code)
+	}
+
+	return int(aws.Float64Value(output.Quota.Value)), nil
+}
+
+func (c *Cache) fetchUsage() (map[string]int, error) {
+	c.logger.Log("level", "debug", "message", "Fetching running EC2 instances")
+
+	usage := map[string]int{}
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning}),
+			},
+		},
+	}
+	err := c.ec2.DescribeInstancesPages(input, func(output *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, r := range output.Reservations {
+			for _, i := range r.Instances {
+				// Spot instances count against separate quotas.
+				if aws.StringValue(i.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot || i.CpuOptions == nil {
+					continue
+				}
+				vcpus := aws.Int64Value(i.CpuOptions.CoreCount) * aws.Int64Value(i.CpuOptions.ThreadsPerCore)
+				usage[QuotaCode(aws.StringValue(i.InstanceType))] += int(vcpus)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, microerror.Maskf(executionFailedError, "failed to fetch running EC2 instances: %v", err)
+	}
+	c.logger.Log("level", "debug", "message", fmt.Sprintf("Fetched vCPU usage of %d EC2 service quotas", len(usage)))
+
+	return usage, nil
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,172 @@
+package servicequota
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+type fakeDescriber struct {
+	calls     int
+	err       error
+	instances []*ec2.Instance
+}
+
+func (f *fakeDescriber) DescribeInstancesPages(input *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool) error {
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	// every instance is returned on its own page
+	for i, instance := range f.instances {
+		output := &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: []*ec2.Instance{instance}}}}
+		if !fn(output, i == len(f.instances)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+type fakeQuotaGetter struct {
+	calls  int
+	limits map[string]float64
+}
+
+func (f *fakeQuotaGetter) GetServiceQuota(input *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error) {
+	f.calls++
+	limit, ok := f.limits[aws.StringValue(input.QuotaCode)]
+	if !ok {
+		return nil, errors.New("no such resource")
+	}
+	return &servicequotas.GetServiceQuotaOutput{Quota: &servicequotas.ServiceQuota{Value: aws.Float64(limit)}}, nil
+}
+
+func instance(instanceType string, vcpus int64, lifecycle string) *ec2.Instance {
+	i := &ec2.Instance{
+		InstanceType: aws.String(instanceType),
+		CpuOptions:   &ec2.CpuOptions{CoreCount: aws.Int64(vcpus / 2), ThreadsPerCore: aws.Int64(2)},
+	}
+	if lifecycle != "" {
+		i.InstanceLifecycle = aws.String(lifecycle)
+	}
+	return i
+}
+
+func TestQuotaCode(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		instanceType string
+		expectedCode string
+	}{
+		{
+			// general purpose instance type
+			name: "case 0",
+
+			instanceType: "m5.xlarge",
+			expectedCode: standardQuotaCode,
+		},
+		{
+			// GPU instance type
+			name: "case 1",
+
+			instanceType: "g4dn.xlarge",
+			expectedCode: "L-DB2E81BA",
+		},
+		{
+			// inferentia instance type is not confused with storage optimized instances
+			name: "case 2",
+
+			instanceType: "inf1.xlarge",
+			expectedCode: "L-1945791B",
+		},
+		{
+			// storage optimized instance type
+			name: "case 3",
+
+			instanceType: "i3.large",
+			expectedCode: standardQuotaCode,
+		},
+		{
+			// memory optimized x instance type
+			name: "case 4",
+
+			instanceType: "x1e.xlarge",
+			expectedCode: "L-7295265B",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			code := QuotaCode(tc.instanceType)
+			if code != tc.expectedCode {
+				t.Fatalf("expected %s to be equal to %s", tc.expectedCode, code)
+			}
+		})
+	}
+}
+
+func TestRemainingVCPUs(t *testing.T) {
+	describer := &fakeDescriber{
+		instances: []*ec2.Instance{
+			instance("m5.xlarge", 4, ""),
+			instance("m5.2xlarge", 8, ""),
+			instance("m5.2xlarge", 8, ec2.InstanceLifecycleTypeSpot),
+			instance("p3.2xlarge", 8, ""),
+		},
+	}
+	quotas := &fakeQuotaGetter{limits: map[string]float64{standardQuotaCode: 64, "L-417A185B": 8}}
+	cache, err := New(Config{EC2: describer, Logger: microloggertest.New(), Quotas: quotas, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// spot instances do not count against the On-Demand quota
+	remaining, err := cache.RemainingVCPUs("m5.large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 52 {
+		t.Fatalf("expected 52 remaining vCPUs but got %d", remaining)
+	}
+
+	// quotas are counted by instance family class
+	remaining, err = cache.RemainingVCPUs("p3.8xlarge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Fatalf("expected 0 remaining vCPUs but got %d", remaining)
+	}
+
+	// quotas and usage are fetched once within the TTL
+	_, err = cache.RemainingVCPUs("m5.large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if describer.calls != 1 || quotas.calls != 2 {
+		t.Fatalf("expected 1 call to the EC2 API and 2 calls to the Service Quotas API but got %d and %d", describer.calls, quotas.calls)
+	}
+
+	// stale usage is used when the refresh fails
+	cache.refreshedAt = time.Now().Add(-2 * time.Hour)
+	describer.err = errors.New("throttled")
+	remaining, err = cache.RemainingVCPUs("m5.large")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if remaining != 52 {
+		t.Fatalf("expected 52 remaining vCPUs but got %d", remaining)
+	}
+
+	// unknown quotas return an error
+	_, err = cache.RemainingVCPUs("f1.2xlarge")
+	if !IsExecutionFailed(err) {
+		t.Fatalf("expected execution failed error but got %v", err)
+	}
+}
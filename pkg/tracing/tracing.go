@@ -0,0 +1,128 @@
+// Package tracing instruments admission handling with OpenTelemetry spans, so
+// that slow admissions, e.g. because of release lookups, can be diagnosed in
+// the tracing backend of the installation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/giantswarm/aws-admission-controller"
+	serviceName         = "aws-admission-controller"
+)
+
+type Config struct {
+	// Endpoint is the host and port of the OTLP HTTP receiver spans are exported to. Tracing is disabled when empty.
+	Endpoint string
+	// Insecure exports spans without TLS.
+	Insecure bool
+	Logger   micrologger.Logger
+	// SampleRatio is the share of traces which are sampled when the API server did not decide about sampling.
+	SampleRatio float64
+}
+
+// Tracing owns the tracer provider which exports the spans of the admission controller.
+type Tracing struct {
+	logger   micrologger.Logger
+	provider *sdktrace.TracerProvider
+}
+
+// New configures the global tracer provider and the propagation of the W3C trace context. Without endpoint the
+// global tracer provider stays a no-op, so that instrumented code does not record anything.
+func New(config Config) (*Tracing, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.SampleRatio must be between 0 and 1", config)
+	}
+
+	t := &Tracing{
+		logger: config.Logger,
+	}
+	if config.Endpoint == "" {
+		return t, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(t.provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	config.Logger.Log("level", "info", "message", fmt.Sprintf("exporting traces to %s", config.Endpoint))
+
+	return t, nil
+}
+
+// Shutdown exports the remaining spans.
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	err := t.provider.Shutdown(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+// Wrap returns a handler which records a span for every request to the given handler, named after the request path.
+// The trace context propagated by the API server becomes the parent of the span. Health checks are not traced.
+func Wrap(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, serviceName,
+		otelhttp.WithFilter(func(request *http.Request) bool {
+			return request.URL.Path != "/healthz" && request.URL.Path != "/readyz"
+		}),
+		otelhttp.WithSpanNameFormatter(func(operation string, request *http.Request) string {
+			return request.URL.Path
+		}),
+	)
+}
+
+// WrapTransport returns a transport which records a span for every request to the API server, e.g. for the lookups
+// of releases and clusters.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(rt)
+}
+
+// Start starts a span as child of the span in the given context.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends the span and records the error if there is one.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
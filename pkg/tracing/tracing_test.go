@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		sampleRatio float64
+		valid       bool
+	}{
+		{
+			// tracing is disabled without endpoint
+			name: "case 0",
+
+			sampleRatio: 0.1,
+			valid:       true,
+		},
+		{
+			// sample ratio above 1
+			name: "case 1",
+
+			sampleRatio: 2,
+			valid:       false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tracer, err := New(Config{
+				Logger:      microloggertest.New(),
+				SampleRatio: tc.sampleRatio,
+			})
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+			if tracer != nil {
+				err = tracer.Shutdown(context.Background())
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	var called bool
+	handler := Wrap(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		called = true
+		writer.WriteHeader(http.StatusTeapot)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate/awscluster", nil))
+
+	if !called {
+		t.Fatalf("expected wrapped handler to be called")
+	}
+	if recorder.Code != http.StatusTeapot {
+		t.Fatalf("expected status %d but got %d", http.StatusTeapot, recorder.Code)
+	}
+}
@@ -88,11 +88,11 @@ func CustomAdmissionRequestAWSControlPlaneUpdate(oldAZs []string, newAZs []strin
 		},
 		Operation: admissionv1.Update,
 		Object: runtime.RawExtension{
-			Raw:    oldByt,
+			Raw:    newByt,
 			Object: nil,
 		},
 		OldObject: runtime.RawExtension{
-			Raw:    newByt,
+			Raw:    oldByt,
 			Object: nil,
 		},
 	}
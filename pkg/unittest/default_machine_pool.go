@@ -0,0 +1,48 @@
+package unittest
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+)
+
+const (
+	DefaultMachinePoolID = "mp7x2"
+)
+
+func DefaultMachinePool() expcapiv1alpha3.MachinePool {
+	replicas := int32(3)
+	cr := expcapiv1alpha3.MachinePool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachinePool",
+			APIVersion: "exp.cluster.x-k8s.io/v1alpha3",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultMachinePoolID,
+			Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{
+				label.Cluster:                DefaultClusterID,
+				label.ClusterOperatorVersion: "7.3.0",
+				label.Release:                "100.0.0",
+			},
+		},
+		Spec: expcapiv1alpha3.MachinePoolSpec{
+			ClusterName: DefaultClusterID,
+			Replicas:    &replicas,
+			Template: apiv1alpha3.MachineTemplateSpec{
+				Spec: apiv1alpha3.MachineSpec{
+					ClusterName: DefaultClusterID,
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+						Kind:       "AWSMachinePool",
+						Name:       DefaultMachinePoolID,
+					},
+				},
+			},
+		},
+	}
+	return cr
+}
@@ -0,0 +1,270 @@
+package unittest
+
+import (
+	"context"
+	"fmt"
+
+	securityv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/security/v1alpha1"
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+)
+
+// Scenario holds all objects of a tenant cluster which were created in the
+// fake client by a ScenarioBuilder.
+type Scenario struct {
+	Cluster               *capiv1alpha2.Cluster
+	AWSCluster            *infrastructurev1alpha2.AWSCluster
+	G8sControlPlane       *infrastructurev1alpha2.G8sControlPlane
+	AWSControlPlane       *infrastructurev1alpha2.AWSControlPlane
+	MachineDeployments    []capiv1alpha2.MachineDeployment
+	AWSMachineDeployments []infrastructurev1alpha2.AWSMachineDeployment
+	Releases              []releasev1alpha1.Release
+	Organization          *securityv1alpha1.Organization
+}
+
+// ScenarioBuilder creates a consistent set of tenant cluster objects in a
+// fake client. All objects are based on the defaults of this package and can
+// be adjusted with overrides before they are created.
+type ScenarioBuilder struct {
+	k8sClient k8sclient.Interface
+
+	nodePools int
+	releases  []ReleaseData
+
+	clusterOverrides              []func(*capiv1alpha2.Cluster)
+	awsClusterOverrides           []func(*infrastructurev1alpha2.AWSCluster)
+	g8sControlPlaneOverrides      []func(*infrastructurev1alpha2.G8sControlPlane)
+	awsControlPlaneOverrides      []func(*infrastructurev1alpha2.AWSControlPlane)
+	machineDeploymentOverrides    []func(int, *capiv1alpha2.MachineDeployment)
+	awsMachineDeploymentOverrides []func(int, *infrastructurev1alpha2.AWSMachineDeployment)
+
+	withoutAWSCluster    bool
+	withoutControlPlanes bool
+	withoutOrganization  bool
+}
+
+// NewScenarioBuilder returns a builder for a cluster with one node pool and
+// the default release.
+func NewScenarioBuilder(k8sClient k8sclient.Interface) *ScenarioBuilder {
+	return &ScenarioBuilder{
+		k8sClient: k8sClient,
+		nodePools: 1,
+	}
+}
+
+// WithNodePools sets the number of node pools of the cluster.
+func (b *ScenarioBuilder) WithNodePools(count int) *ScenarioBuilder {
+	b.nodePools = count
+	return b
+}
+
+// WithReleases replaces the default release with the given releases.
+func (b *ScenarioBuilder) WithReleases(releases ...ReleaseData) *ScenarioBuilder {
+	b.releases = append(b.releases, releases...)
+	return b
+}
+
+func (b *ScenarioBuilder) WithCluster(override func(*capiv1alpha2.Cluster)) *ScenarioBuilder {
+	b.clusterOverrides = append(b.clusterOverrides, override)
+	return b
+}
+
+func (b *ScenarioBuilder) WithAWSCluster(override func(*infrastructurev1alpha2.AWSCluster)) *ScenarioBuilder {
+	b.awsClusterOverrides = append(b.awsClusterOverrides, override)
+	return b
+}
+
+func (b *ScenarioBuilder) WithG8sControlPlane(override func(*infrastructurev1alpha2.G8sControlPlane)) *ScenarioBuilder {
+	b.g8sControlPlaneOverrides = append(b.g8sControlPlaneOverrides, override)
+	return b
+}
+
+func (b *ScenarioBuilder) WithAWSControlPlane(override func(*infrastructurev1alpha2.AWSControlPlane)) *ScenarioBuilder {
+	b.awsControlPlaneOverrides = append(b.awsControlPlaneOverrides, override)
+	return b
+}
+
+// WithMachineDeployment registers an override which is called for every node
+// pool with the index of the node pool.
+func (b *ScenarioBuilder) WithMachineDeployment(override func(int, *capiv1alpha2.MachineDeployment)) *ScenarioBuilder {
+	b.machineDeploymentOverrides = append(b.machineDeploymentOverrides, override)
+	return b
+}
+
+// WithAWSMachineDeployment registers an override which is called for every
+// node pool with the index of the node pool.
+func (b *ScenarioBuilder) WithAWSMachineDeployment(override func(int, *infrastructurev1alpha2.AWSMachineDeployment)) *ScenarioBuilder {
+	b.awsMachineDeploymentOverrides = append(b.awsMachineDeploymentOverrides, override)
+	return b
+}
+
+// WithoutAWSCluster skips the creation of the AWSCluster.
+func (b *ScenarioBuilder) WithoutAWSCluster() *ScenarioBuilder {
+	b.withoutAWSCluster = true
+	return b
+}
+
+// WithoutControlPlanes skips the creation of the G8sControlPlane and AWSControlPlane.
+func (b *ScenarioBuilder) WithoutControlPlanes() *ScenarioBuilder {
+	b.withoutControlPlanes = true
+	return b
+}
+
+// WithoutOrganization skips the creation of the Organization.
+func (b *ScenarioBuilder) WithoutOrganization() *ScenarioBuilder {
+	b.withoutOrganization = true
+	return b
+}
+
+// Build creates all objects of the scenario in the fake client. Cluster ID,
+// release version and organization of the Cluster are propagated to all
+// other objects before their own overrides are applied.
+func (b *ScenarioBuilder) Build(ctx context.Context) (*Scenario, error) {
+	var err error
+	s := &Scenario{}
+
+	cluster := DefaultCluster()
+	for _, o := range b.clusterOverrides {
+		o(cluster)
+	}
+	s.Cluster = cluster
+	err = b.create(ctx, s.Cluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if !b.withoutOrganization {
+		s.Organization = DefaultOrganization()
+		s.Organization.SetName(key.Organization(cluster))
+		err = b.create(ctx, s.Organization)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	releases := b.releases
+	if len(releases) == 0 {
+		releases = []ReleaseData{{Name: fmt.Sprintf("v%s", key.Release(cluster)), State: releasev1alpha1.StateActive}}
+	}
+	for _, r := range releases {
+		release := DefaultRelease()
+		release.SetName(r.Name)
+		release.Spec.State = r.State
+		err = b.create(ctx, &release)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		s.Releases = append(s.Releases, release)
+	}
+
+	if !b.withoutAWSCluster {
+		awsCluster := DefaultAWSCluster()
+		b.inherit(cluster, &awsCluster)
+		awsCluster.SetName(key.Cluster(cluster))
+		for _, o := range b.awsClusterOverrides {
+			o(&awsCluster)
+		}
+		s.AWSCluster = &awsCluster
+		err = b.create(ctx, s.AWSCluster)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	if !b.withoutControlPlanes {
+		g8sControlPlane := DefaultG8sControlPlane()
+		b.inherit(cluster, &g8sControlPlane)
+		for _, o := range b.g8sControlPlaneOverrides {
+			o(&g8sControlPlane)
+		}
+		s.G8sControlPlane = &g8sControlPlane
+		err = b.create(ctx, s.G8sControlPlane)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		awsControlPlane := DefaultAWSControlPlane()
+		b.inherit(cluster, &awsControlPlane)
+		for _, o := range b.awsControlPlaneOverrides {
+			o(&awsControlPlane)
+		}
+		s.AWSControlPlane = &awsControlPlane
+		err = b.create(ctx, s.AWSControlPlane)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	for i := 0; i < b.nodePools; i++ {
+		id := nodePoolID(i)
+
+		machineDeployment := DefaultMachineDeployment()
+		b.inherit(cluster, &machineDeployment)
+		machineDeployment.SetName(id)
+		machineDeployment.Labels[label.MachineDeployment] = id
+		for _, o := range b.machineDeploymentOverrides {
+			o(i, &machineDeployment)
+		}
+		err = b.create(ctx, &machineDeployment)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		s.MachineDeployments = append(s.MachineDeployments, machineDeployment)
+
+		awsMachineDeployment := DefaultAWSMachineDeployment()
+		b.inherit(cluster, &awsMachineDeployment)
+		awsMachineDeployment.SetName(id)
+		awsMachineDeployment.Labels[label.MachineDeployment] = id
+		for _, o := range b.awsMachineDeploymentOverrides {
+			o(i, &awsMachineDeployment)
+		}
+		err = b.create(ctx, &awsMachineDeployment)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		s.AWSMachineDeployments = append(s.AWSMachineDeployments, awsMachineDeployment)
+	}
+
+	return s, nil
+}
+
+func (b *ScenarioBuilder) create(ctx context.Context, obj runtime.Object) error {
+	err := b.k8sClient.CtrlClient().Create(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+// inherit copies the cluster ID, release version, organization and namespace
+// of the Cluster to the given object.
+func (b *ScenarioBuilder) inherit(cluster *capiv1alpha2.Cluster, obj metav1.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[label.Cluster] = key.Cluster(cluster)
+	labels[label.Release] = key.Release(cluster)
+	if organization := key.Organization(cluster); organization != "" {
+		labels[label.Organization] = organization
+	}
+	obj.SetLabels(labels)
+	obj.SetNamespace(cluster.GetNamespace())
+}
+
+// nodePoolID returns the default node pool ID for the first node pool and
+// unique IDs of the same format for all others.
+func nodePoolID(i int) string {
+	if i == 0 {
+		return DefaultMachineDeploymentID
+	}
+	return fmt.Sprintf("np%03d", i)
+}
@@ -0,0 +1,99 @@
+package unittest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	infrastructurev1alpha2 "github.com/giantswarm/apiextensions/v3/pkg/apis/infrastructure/v1alpha2"
+	capiv1alpha2 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/label"
+)
+
+func TestScenarioBuilder(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		nodePools      int
+		organization   string
+		releaseVersion string
+	}{
+		{
+			// default scenario
+			ctx:  context.Background(),
+			name: "case 0",
+
+			nodePools:      1,
+			organization:   "example-organization",
+			releaseVersion: DefaultReleaseVersion,
+		},
+		{
+			// multiple node pools, a different organization and a different release
+			ctx:  context.Background(),
+			name: "case 1",
+
+			nodePools:      3,
+			organization:   "acme",
+			releaseVersion: "14.1.0",
+		},
+		{
+			// no node pools
+			ctx:  context.Background(),
+			name: "case 2",
+
+			nodePools:      0,
+			organization:   "example-organization",
+			releaseVersion: DefaultReleaseVersion,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := FakeK8sClient()
+
+			scenario, err := NewScenarioBuilder(fakeK8sClient).
+				WithNodePools(tc.nodePools).
+				WithCluster(func(cluster *capiv1alpha2.Cluster) {
+					cluster.Labels[label.Organization] = tc.organization
+					cluster.Labels[label.Release] = tc.releaseVersion
+				}).
+				Build(tc.ctx)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if len(scenario.Releases) != 1 || scenario.Releases[0].GetName() != "v"+tc.releaseVersion {
+				t.Fatalf("expected release v%s but got %v", tc.releaseVersion, scenario.Releases)
+			}
+			if key.Release(scenario.AWSCluster) != tc.releaseVersion || key.Release(scenario.G8sControlPlane) != tc.releaseVersion {
+				t.Fatalf("expected release version %s to be propagated", tc.releaseVersion)
+			}
+			if key.Organization(scenario.AWSCluster) != tc.organization || key.Organization(scenario.AWSControlPlane) != tc.organization {
+				t.Fatalf("expected organization %s to be propagated", tc.organization)
+			}
+			if scenario.Organization.GetName() != tc.organization {
+				t.Fatalf("expected Organization %s but got %s", tc.organization, scenario.Organization.GetName())
+			}
+
+			awsMachineDeployments := infrastructurev1alpha2.AWSMachineDeploymentList{}
+			err = fakeK8sClient.CtrlClient().List(tc.ctx, &awsMachineDeployments, client.MatchingLabels{label.Cluster: DefaultClusterID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(awsMachineDeployments.Items) != tc.nodePools {
+				t.Fatalf("expected %d node pools but got %d", tc.nodePools, len(awsMachineDeployments.Items))
+			}
+			for _, md := range awsMachineDeployments.Items {
+				if key.Release(&md) != tc.releaseVersion {
+					t.Fatalf("expected release version %s for node pool %s but got %s", tc.releaseVersion, md.GetName(), key.Release(&md))
+				}
+				if key.Organization(&md) != tc.organization {
+					t.Fatalf("expected organization %s for node pool %s but got %s", tc.organization, md.GetName(), key.Organization(&md))
+				}
+			}
+		})
+	}
+}
@@ -1,27 +1,49 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/giantswarm/microerror"
+	"go.opentelemetry.io/otel/attribute"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/events"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/handler"
 	"github.com/giantswarm/aws-admission-controller/v2/pkg/metrics"
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/tracing"
 )
 
 type Validator interface {
 	Log(keyVals ...interface{})
 	Resource() string
-	Validate(review *admissionv1.AdmissionRequest) (bool, error)
+	Validate(ctx context.Context, review *admissionv1.AdmissionRequest) (bool, error)
+}
+
+// WarningValidator is implemented by validators which explain side effects of admitted requests to the user.
+type WarningValidator interface {
+	Warnings(ctx context.Context, request *admissionv1.AdmissionRequest) []string
+}
+
+// admissionResponse adds the warnings of admission.k8s.io/v1 responses which are not part of the vendored
+// API types yet. API servers before Kubernetes 1.19 ignore them.
+type admissionResponse struct {
+	*admissionv1.AdmissionResponse
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type admissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Response        *admissionResponse `json:"response,omitempty"`
 }
 
 var (
@@ -30,10 +52,16 @@ var (
 	Deserializer = codecs.UniversalDeserializer()
 )
 
-func Handler(validator Validator) http.HandlerFunc {
+// Handler answers validating admission reviews. Validators which do not
+// decide within their timeout or panic are answered according to the policies.
+func Handler(validator Validator, policies handler.Policies) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		start := time.Now()
-		defer metrics.DurationRequests.WithLabelValues("validating", validator.Resource()).Observe(float64(time.Since(start)) / float64(time.Second))
+		defer func() {
+			metrics.DurationRequests.WithLabelValues("validating", validator.Resource()).Observe(time.Since(start).Seconds())
+		}()
+
+		metrics.TotalRequests.WithLabelValues("validating", validator.Resource()).Inc()
 
 		if request.Header.Get("Content-Type") != "application/json" {
 			validator.Log("level", "error", "message", fmt.Sprintf("invalid content-type: %s", request.Header.Get("Content-Type")))
@@ -51,38 +79,162 @@ func Handler(validator Validator) http.HandlerFunc {
 		}
 
 		review := admissionv1.AdmissionReview{}
-		if _, _, err := Deserializer.Decode(data, nil, &review); err != nil {
+		_, gvk, err := Deserializer.Decode(data, nil, &review)
+		if err != nil || review.Request == nil {
 			validator.Log("level", "error", "message", "unable to parse admission review request")
 			metrics.InvalidRequests.WithLabelValues("validating", validator.Resource()).Inc()
 			writer.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		resourceName := fmt.Sprintf("%s %s/%s", review.Request.Kind, review.Request.Namespace, handler.ExtractName(review.Request, Deserializer))
+		// Responses are sent in the admission.k8s.io version of the request.
+		apiVersion, ok := handler.ReviewAPIVersion(gvk.GroupVersion().String())
+		if !ok {
+			validator.Log("level", "error", "message", fmt.Sprintf("unsupported admission review version: %s", gvk.GroupVersion().String()))
+			metrics.InvalidRequests.WithLabelValues("validating", validator.Resource()).Inc()
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := handler.ExtractName(review.Request, Deserializer)
+		resourceName := fmt.Sprintf("%s %s/%s", review.Request.Kind, review.Request.Namespace, name)
+		decision := events.Decision{
+			Webhook:  events.WebhookValidating,
+			Resource: validator.Resource(),
+			Request:  review.Request,
+			Name:     name,
+		}
+		if matched, ok := policies.Bypass.Match(review.Request.UserInfo); ok {
+			message := fmt.Sprintf("Validation of %s was bypassed for %s.", resourceName, matched)
+			validator.Log("level", "info", "message", message, "user", review.Request.UserInfo.Username)
+			writeResponse(validator, writer, apiVersion, &admissionv1.AdmissionResponse{
+				Allowed: true,
+				UID:     review.Request.UID,
+			}, nil)
+			decision.Allowed = true
+			decision.Message = message
+			decision.Reason = events.ReasonBypass
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+		if !policies.RateLimits.Accept(events.WebhookValidating, validator.Resource()) {
+			message := fmt.Sprintf("Too many requests to validate %s. Please retry later.", validator.Resource())
+			validator.Log("level", "warning", "message", fmt.Sprintf("rate limited %s", resourceName), "user", review.Request.UserInfo.Username)
+			writeResponse(validator, writer, apiVersion, &admissionv1.AdmissionResponse{
+				Allowed: false,
+				UID:     review.Request.UID,
+				Result: &metav1.Status{
+					Reason:  metav1.StatusReasonTooManyRequests,
+					Code:    http.StatusTooManyRequests,
+					Message: message,
+				},
+			}, nil)
+			decision.Message = message
+			decision.Reason = events.ReasonRateLimited
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+
+		ctx, span := tracing.Start(request.Context(), fmt.Sprintf("validate %s", validator.Resource()),
+			attribute.String("admission.operation", string(review.Request.Operation)),
+			attribute.String("admission.namespace", review.Request.Namespace),
+			attribute.String("admission.name", name),
+		)
+		timeout := policies.Timeouts.Timeout(events.WebhookValidating, validator.Resource())
+		var allowed bool
+		var validateErr error
+		finished := handler.RunWithin(ctx, timeout, func(ctx context.Context) {
+			defer handler.Recover(validator.Log, request.URL.Path, &validateErr)
+			allowed, validateErr = validator.Validate(ctx, review.Request)
+		})
+		if !finished {
+			message := fmt.Sprintf("Validation of %s did not finish within %s.", resourceName, timeout)
+			tracing.End(span, errors.New(message))
+			validator.Log("level", "warning", "message", message)
 
-		allowed, err := validator.Validate(review.Request)
+			response := errorResponse(review.Request.UID, errors.New(message))
+			var warnings []string
+			if policies.Timeouts.Allow() {
+				response = &admissionv1.AdmissionResponse{Allowed: true, UID: review.Request.UID}
+				warnings = []string{message + " The request was admitted without validation."}
+			}
+			writeResponse(validator, writer, apiVersion, response, warnings)
+			decision.Allowed = response.Allowed
+			decision.Message = message
+			decision.Reason = events.ReasonTimeout
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+		err = validateErr
+		span.SetAttributes(attribute.Bool("admission.allowed", allowed && err == nil))
+		tracing.End(span, err)
+		if handler.IsPanic(err) {
+			message := policies.Recovery.Message()
+			response := errorResponse(review.Request.UID, errors.New(message))
+			var warnings []string
+			if policies.Recovery.Allow() {
+				response = &admissionv1.AdmissionResponse{Allowed: true, UID: review.Request.UID}
+				warnings = []string{message}
+			}
+			writeResponse(validator, writer, apiVersion, response, warnings)
+			decision.Allowed = response.Allowed
+			decision.Message = message
+			decision.Reason = events.ReasonPanic
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
+		if policies.FailOpen.Allow(events.WebhookValidating, validator.Resource(), err) {
+			message := fmt.Sprintf("Validation of %s was skipped because a dependency of the admission controller failed.", resourceName)
+			validator.Log("level", "warning", "message", fmt.Sprintf("admitting %s without validation because a dependency failed", resourceName), "stack", microerror.JSON(err))
+			writeResponse(validator, writer, apiVersion, &admissionv1.AdmissionResponse{
+				Allowed: true,
+				UID:     review.Request.UID,
+			}, []string{message})
+			decision.Allowed = true
+			decision.Message = message
+			decision.Reason = events.ReasonFailOpen
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
+			return
+		}
 		if err != nil {
 			validator.Log("level", "error", "message", fmt.Sprintf("error during validation process of %s: %v", resourceName, err))
-			writeResponse(validator, writer, errorResponse(review.Request.UID, microerror.Mask(err)))
-			metrics.RejectedRequests.WithLabelValues("validating", validator.Resource()).Inc()
+			writeResponse(validator, writer, apiVersion, errorResponse(review.Request.UID, microerror.Mask(err)), nil)
+			decision.Message = err.Error()
+			decision.Reason = events.Reason(err)
+			decision.Duration = time.Since(start)
+			events.Publish(decision)
 			return
 		}
 		validator.Log("level", "debug", "message", fmt.Sprintf("validator admitted %s", resourceName))
-		metrics.SuccessfulRequests.WithLabelValues("validating", validator.Resource()).Inc()
 
-		writeResponse(validator, writer, &admissionv1.AdmissionResponse{
+		var warnings []string
+		if w, ok := validator.(WarningValidator); ok && allowed {
+			warnings = w.Warnings(ctx, review.Request)
+		}
+
+		writeResponse(validator, writer, apiVersion, &admissionv1.AdmissionResponse{
 			Allowed: allowed,
 			UID:     review.Request.UID,
-		})
+		}, warnings)
+		decision.Allowed = allowed
+		decision.Duration = time.Since(start)
+		events.Publish(decision)
 	}
 }
 
-func writeResponse(validator Validator, writer http.ResponseWriter, response *admissionv1.AdmissionResponse) {
-	resp, err := json.Marshal(admissionv1.AdmissionReview{
+func writeResponse(validator Validator, writer http.ResponseWriter, apiVersion string, response *admissionv1.AdmissionResponse, warnings []string) {
+	resp, err := json.Marshal(admissionReview{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "AdmissionReview",
-			APIVersion: "admission.k8s.io/v1",
+			APIVersion: apiVersion,
+		},
+		Response: &admissionResponse{
+			AdmissionResponse: response,
+			Warnings:          warnings,
 		},
-		Response: response,
 	})
 	if err != nil {
 		validator.Log("level", "error", "message", "unable to serialize response", microerror.JSON(err))
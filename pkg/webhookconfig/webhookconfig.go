@@ -0,0 +1,348 @@
+// Package webhookconfig manages the MutatingWebhookConfiguration and
+// ValidatingWebhookConfiguration of the admission controller from the webhooks
+// registered in code, so that rules and paths can not drift from the handlers.
+package webhookconfig
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Mutating webhooks are registered in the MutatingWebhookConfiguration.
+	Mutating = "mutating"
+	// Validating webhooks are registered in the ValidatingWebhookConfiguration.
+	Validating = "validating"
+)
+
+var (
+	// CreateUpdate are the operations most webhooks are called for.
+	CreateUpdate = []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update}
+	// CreateUpdateDelete are the operations of webhooks which also guard deletions.
+	CreateUpdateDelete = []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update, admissionregistrationv1.Delete}
+
+	admissionReviewVersions = []string{"v1", "v1beta1"}
+)
+
+// Webhook registers a handler and the requests the API server sends to it.
+type Webhook struct {
+	Handler    http.Handler
+	Operations []admissionregistrationv1.OperationType
+	Path       string
+	Resource   schema.GroupVersionResource
+	// SideEffects defaults to None.
+	SideEffects admissionregistrationv1.SideEffectClass
+	// Subresources the webhook is called for in addition to the resource, e.g. status.
+	Subresources []string
+	// Type is either Mutating or Validating.
+	Type string
+}
+
+type Config struct {
+	// CAFile contains the CA bundle the API server verifies the serving certificate with.
+	CAFile        string
+	FailurePolicy admissionregistrationv1.FailurePolicyType
+	K8sClient     k8sclient.Interface
+	Logger        micrologger.Logger
+	// Name of both webhook configurations, which is also part of the webhook names.
+	Name string
+	// NamespaceSelector is a label selector limiting namespaced resources to
+	// matching namespaces. All namespaces when empty.
+	NamespaceSelector string
+	ServiceName       string
+	ServiceNamespace  string
+	Webhooks          []Webhook
+}
+
+// Reconciler creates and updates the webhook configurations.
+type Reconciler struct {
+	caFile            string
+	failurePolicy     admissionregistrationv1.FailurePolicyType
+	k8sClient         k8sclient.Interface
+	logger            micrologger.Logger
+	name              string
+	namespaceSelector *metav1.LabelSelector
+	serviceName       string
+	serviceNamespace  string
+	webhooks          []Webhook
+}
+
+func New(config Config) (*Reconciler, error) {
+	if config.CAFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CAFile must not be empty", config)
+	}
+	if config.FailurePolicy == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FailurePolicy must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Name == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Name must not be empty", config)
+	}
+	if config.ServiceName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ServiceName must not be empty", config)
+	}
+	if config.ServiceNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ServiceNamespace must not be empty", config)
+	}
+	namespaceSelector, err := metav1.ParseToLabelSelector(config.NamespaceSelector)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NamespaceSelector %#q is not a label selector: %s", config, config.NamespaceSelector, err)
+	}
+	// Empty fields are dropped by the API server, so that the reconciled
+	// configurations would never compare equal.
+	if len(namespaceSelector.MatchLabels) == 0 {
+		namespaceSelector.MatchLabels = nil
+	}
+	if len(namespaceSelector.MatchExpressions) == 0 {
+		namespaceSelector.MatchExpressions = nil
+	}
+	for _, w := range config.Webhooks {
+		if w.Type != Mutating && w.Type != Validating {
+			return nil, microerror.Maskf(invalidConfigError, "webhook %s has unknown type %#q", w.Path, w.Type)
+		}
+	}
+
+	r := &Reconciler{
+		caFile:            config.CAFile,
+		failurePolicy:     config.FailurePolicy,
+		k8sClient:         config.K8sClient,
+		logger:            config.Logger,
+		name:              config.Name,
+		namespaceSelector: namespaceSelector,
+		serviceName:       config.ServiceName,
+		serviceNamespace:  config.ServiceNamespace,
+		webhooks:          config.Webhooks,
+	}
+
+	return r, nil
+}
+
+// Reconcile creates the webhook configurations or updates them when they
+// differ from the registered webhooks or the CA bundle changed.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	caBundle, err := ioutil.ReadFile(r.caFile)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = r.reconcileMutating(ctx, r.mutatingWebhooks(caBundle))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	err = r.reconcileValidating(ctx, r.validatingWebhooks(caBundle))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Run reconciles the webhook configurations in the given interval until stop
+// is closed, which reverts manual changes and picks up rotated CA bundles.
+func (r *Reconciler) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := r.Reconcile(context.Background())
+			if err != nil {
+				r.logger.Log("level", "warning", "message", "unable to reconcile webhook configurations", "stack", microerror.JSON(err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (r *Reconciler) reconcileMutating(ctx context.Context, webhooks []admissionregistrationv1.MutatingWebhook) error {
+	client := r.k8sClient.K8sClient().AdmissionregistrationV1().MutatingWebhookConfigurations()
+
+	current, err := client.Get(ctx, r.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		desired := &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: r.name},
+			Webhooks:   webhooks,
+		}
+		_, err = client.Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		r.logger.Log("level", "info", "message", fmt.Sprintf("created MutatingWebhookConfiguration %s", r.name))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if reflect.DeepEqual(current.Webhooks, webhooks) {
+		return nil
+	}
+	current.Webhooks = webhooks
+	_, err = client.Update(ctx, current, metav1.UpdateOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	r.logger.Log("level", "info", "message", fmt.Sprintf("updated MutatingWebhookConfiguration %s", r.name))
+
+	return nil
+}
+
+func (r *Reconciler) reconcileValidating(ctx context.Context, webhooks []admissionregistrationv1.ValidatingWebhook) error {
+	client := r.k8sClient.K8sClient().AdmissionregistrationV1().ValidatingWebhookConfigurations()
+
+	current, err := client.Get(ctx, r.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		desired := &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: r.name},
+			Webhooks:   webhooks,
+		}
+		_, err = client.Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		r.logger.Log("level", "info", "message", fmt.Sprintf("created ValidatingWebhookConfiguration %s", r.name))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if reflect.DeepEqual(current.Webhooks, webhooks) {
+		return nil
+	}
+	current.Webhooks = webhooks
+	_, err = client.Update(ctx, current, metav1.UpdateOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	r.logger.Log("level", "info", "message", fmt.Sprintf("updated ValidatingWebhookConfiguration %s", r.name))
+
+	return nil
+}
+
+func (r *Reconciler) mutatingWebhooks(caBundle []byte) []admissionregistrationv1.MutatingWebhook {
+	var webhooks []admissionregistrationv1.MutatingWebhook
+	for _, w := range r.webhooks {
+		if w.Type != Mutating {
+			continue
+		}
+		reinvocationPolicy := admissionregistrationv1.NeverReinvocationPolicy
+		webhooks = append(webhooks, admissionregistrationv1.MutatingWebhook{
+			Name:                    r.webhookName(w),
+			AdmissionReviewVersions: admissionReviewVersions,
+			ClientConfig:            r.clientConfig(w, caBundle),
+			FailurePolicy:           r.failurePolicyPtr(),
+			MatchPolicy:             matchPolicy(),
+			NamespaceSelector:       r.selector(),
+			ObjectSelector:          &metav1.LabelSelector{},
+			ReinvocationPolicy:      &reinvocationPolicy,
+			Rules:                   rules(w),
+			SideEffects:             sideEffects(w),
+			TimeoutSeconds:          timeoutSeconds(),
+		})
+	}
+	return webhooks
+}
+
+func (r *Reconciler) validatingWebhooks(caBundle []byte) []admissionregistrationv1.ValidatingWebhook {
+	var webhooks []admissionregistrationv1.ValidatingWebhook
+	for _, w := range r.webhooks {
+		if w.Type != Validating {
+			continue
+		}
+		webhooks = append(webhooks, admissionregistrationv1.ValidatingWebhook{
+			Name:                    r.webhookName(w),
+			AdmissionReviewVersions: admissionReviewVersions,
+			ClientConfig:            r.clientConfig(w, caBundle),
+			FailurePolicy:           r.failurePolicyPtr(),
+			MatchPolicy:             matchPolicy(),
+			NamespaceSelector:       r.selector(),
+			ObjectSelector:          &metav1.LabelSelector{},
+			Rules:                   rules(w),
+			SideEffects:             sideEffects(w),
+			TimeoutSeconds:          timeoutSeconds(),
+		})
+	}
+	return webhooks
+}
+
+func (r *Reconciler) webhookName(w Webhook) string {
+	return fmt.Sprintf("%s.%s.giantswarm.io", w.Resource.Resource, r.name)
+}
+
+func (r *Reconciler) clientConfig(w Webhook, caBundle []byte) admissionregistrationv1.WebhookClientConfig {
+	path := w.Path
+	port := int32(443)
+	return admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{
+			Name:      r.serviceName,
+			Namespace: r.serviceNamespace,
+			Path:      &path,
+			Port:      &port,
+		},
+		CABundle: caBundle,
+	}
+}
+
+func (r *Reconciler) failurePolicyPtr() *admissionregistrationv1.FailurePolicyType {
+	failurePolicy := r.failurePolicy
+	return &failurePolicy
+}
+
+func (r *Reconciler) selector() *metav1.LabelSelector {
+	return r.namespaceSelector.DeepCopy()
+}
+
+func rules(w Webhook) []admissionregistrationv1.RuleWithOperations {
+	resources := []string{w.Resource.Resource}
+	for _, s := range w.Subresources {
+		resources = append(resources, w.Resource.Resource+"/"+s)
+	}
+	scope := admissionregistrationv1.AllScopes
+	return []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: w.Operations,
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{w.Resource.Group},
+				APIVersions: []string{w.Resource.Version},
+				Resources:   resources,
+				Scope:       &scope,
+			},
+		},
+	}
+}
+
+func sideEffects(w Webhook) *admissionregistrationv1.SideEffectClass {
+	sideEffects := w.SideEffects
+	if sideEffects == "" {
+		sideEffects = admissionregistrationv1.SideEffectClassNone
+	}
+	return &sideEffects
+}
+
+func matchPolicy() *admissionregistrationv1.MatchPolicyType {
+	matchPolicy := admissionregistrationv1.Equivalent
+	return &matchPolicy
+}
+
+func timeoutSeconds() *int32 {
+	timeout := int32(10)
+	return &timeout
+}
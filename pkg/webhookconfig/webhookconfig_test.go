@@ -0,0 +1,160 @@
+package webhookconfig
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/k8sclient/v5/pkg/k8sclient"
+	"github.com/giantswarm/micrologger/microloggertest"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/aws-admission-controller/v2/pkg/unittest"
+)
+
+func TestReconcile(t *testing.T) {
+	awsClusters := schema.GroupVersionResource{Group: "infrastructure.giantswarm.io", Version: "v1alpha2", Resource: "awsclusters"}
+
+	testCases := []struct {
+		name string
+
+		change func(t *testing.T, k8sClient k8sclient.Interface, caFile string)
+	}{
+		{
+			// configurations are created
+			name: "case 0",
+
+			change: func(t *testing.T, k8sClient k8sclient.Interface, caFile string) {},
+		},
+		{
+			// manually changed failure policy is reverted
+			name: "case 1",
+
+			change: func(t *testing.T, k8sClient k8sclient.Interface, caFile string) {
+				client := k8sClient.K8sClient().AdmissionregistrationV1().ValidatingWebhookConfigurations()
+				current, err := client.Get(context.Background(), "aws-admission-controller", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				failurePolicy := admissionregistrationv1.Fail
+				current.Webhooks[0].FailurePolicy = &failurePolicy
+				_, err = client.Update(context.Background(), current, metav1.UpdateOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			// rotated CA bundle is injected
+			name: "case 2",
+
+			change: func(t *testing.T, k8sClient k8sclient.Interface, caFile string) {
+				err := ioutil.WriteFile(caFile, []byte("rotated"), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			// manually changed match policy is reverted
+			name: "case 3",
+
+			change: func(t *testing.T, k8sClient k8sclient.Interface, caFile string) {
+				client := k8sClient.K8sClient().AdmissionregistrationV1().MutatingWebhookConfigurations()
+				current, err := client.Get(context.Background(), "aws-admission-controller", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				matchPolicy := admissionregistrationv1.Exact
+				current.Webhooks[0].MatchPolicy = &matchPolicy
+				_, err = client.Update(context.Background(), current, metav1.UpdateOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "webhookconfig")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			caFile := filepath.Join(dir, "ca.crt")
+			err = ioutil.WriteFile(caFile, []byte("initial"), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			k8sClient := unittest.FakeK8sClient()
+			r, err := New(Config{
+				CAFile:           caFile,
+				FailurePolicy:    admissionregistrationv1.Ignore,
+				K8sClient:        k8sClient,
+				Logger:           microloggertest.New(),
+				Name:             "aws-admission-controller",
+				ServiceName:      "aws-admission-controller",
+				ServiceNamespace: "giantswarm",
+				Webhooks: []Webhook{
+					{Type: Mutating, Path: "/mutate/awscluster", Resource: awsClusters, Operations: CreateUpdate, Handler: http.NotFoundHandler()},
+					{Type: Validating, Path: "/validate/awscluster", Resource: awsClusters, Subresources: []string{"status"}, Operations: CreateUpdate, Handler: http.NotFoundHandler()},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.Reconcile(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.change(t, k8sClient, caFile)
+			err = r.Reconcile(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			caBundle, err := ioutil.ReadFile(caFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mutating, err := k8sClient.K8sClient().AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), "aws-admission-controller", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			validating, err := k8sClient.K8sClient().AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.Background(), "aws-admission-controller", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(mutating.Webhooks) != 1 || len(validating.Webhooks) != 1 {
+				t.Fatalf("expected one mutating and one validating webhook but got %d and %d", len(mutating.Webhooks), len(validating.Webhooks))
+			}
+			if name := validating.Webhooks[0].Name; name != "awsclusters.aws-admission-controller.giantswarm.io" {
+				t.Fatalf("unexpected webhook name %#q", name)
+			}
+			if path := *validating.Webhooks[0].ClientConfig.Service.Path; path != "/validate/awscluster" {
+				t.Fatalf("unexpected webhook path %#q", path)
+			}
+			if resources := validating.Webhooks[0].Rules[0].Resources; len(resources) != 2 || resources[1] != "awsclusters/status" {
+				t.Fatalf("unexpected webhook resources %v", resources)
+			}
+			if failurePolicy := *validating.Webhooks[0].FailurePolicy; failurePolicy != admissionregistrationv1.Ignore {
+				t.Fatalf("expected failure policy Ignore but got %s", failurePolicy)
+			}
+			if *mutating.Webhooks[0].MatchPolicy != admissionregistrationv1.Equivalent || *validating.Webhooks[0].MatchPolicy != admissionregistrationv1.Equivalent {
+				t.Fatalf("expected match policy Equivalent but got %s and %s", *mutating.Webhooks[0].MatchPolicy, *validating.Webhooks[0].MatchPolicy)
+			}
+			if string(mutating.Webhooks[0].ClientConfig.CABundle) != string(caBundle) || string(validating.Webhooks[0].ClientConfig.CABundle) != string(caBundle) {
+				t.Fatalf("expected CA bundle %#q to be injected", caBundle)
+			}
+		})
+	}
+}